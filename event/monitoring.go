@@ -47,3 +47,40 @@ type CommandMonitor struct {
 	Succeeded func(context.Context, *CommandSucceededEvent)
 	Failed    func(context.Context, *CommandFailedEvent)
 }
+
+// ConnectionClosedReason describes why a pooled connection was closed.
+type ConnectionClosedReason string
+
+// These constants are the reasons reported in a PoolEvent when a connection is closed.
+const (
+	// ReasonIdle indicates the connection exceeded its idle timeout or lifetime, or that there was no
+	// room left in the pool to keep it idle.
+	ReasonIdle ConnectionClosedReason = "idle"
+	// ReasonStale indicates the connection was created before the pool was last cleared.
+	ReasonStale ConnectionClosedReason = "stale"
+	// ReasonError indicates the connection was closed after a network error.
+	ReasonError ConnectionClosedReason = "error"
+	// ReasonPoolClosed indicates the connection was closed because the pool was disconnected.
+	ReasonPoolClosed ConnectionClosedReason = "poolClosed"
+	// ReasonLeaked indicates the connection was still checked out when the pool was disconnected and
+	// was closed once the disconnect's deadline passed. It usually means that the connection was
+	// never returned to the pool.
+	ReasonLeaked ConnectionClosedReason = "leaked"
+)
+
+// PoolEvent represents a connection pool lifecycle event. ConnectionID is empty for events about
+// the pool as a whole and Reason is only set for closed connections.
+type PoolEvent struct {
+	Address      string
+	ConnectionID string
+	Reason       ConnectionClosedReason
+}
+
+// PoolMonitor represents a monitor that is triggered for connection pool lifecycle events.
+type PoolMonitor struct {
+	ConnectionCreated    func(*PoolEvent)
+	ConnectionCheckedOut func(*PoolEvent)
+	ConnectionCheckedIn  func(*PoolEvent)
+	ConnectionClosed     func(*PoolEvent)
+	PoolCleared          func(*PoolEvent)
+}
@@ -207,6 +207,15 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 	if opts.AppName != nil {
 		appName = *opts.AppName
 	}
+	// DriverInfo
+	var driverInfo *driver.DriverInfo
+	if opts.DriverInfo != nil {
+		driverInfo = &driver.DriverInfo{
+			Name:     opts.DriverInfo.Name,
+			Version:  opts.DriverInfo.Version,
+			Platform: opts.DriverInfo.Platform,
+		}
+	}
 	// Compressors & ZlibLevel
 	var comps []string
 	if len(opts.Compressors) > 0 {
@@ -230,9 +239,16 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 			func(opts ...string) []string { return append(opts, comps...) },
 		))
 	}
+	// LoadBalanced
+	var loadBalanced bool
+	if opts.LoadBalanced != nil && *opts.LoadBalanced {
+		loadBalanced = true
+		serverOpts = append(serverOpts, topology.WithLoadBalanced(func(bool) bool { return true }))
+	}
 	// Handshaker
 	var handshaker = func(driver.Handshaker) driver.Handshaker {
-		return driver.IsMaster().AppName(appName).Compressors(comps)
+		return driver.IsMaster().AppName(appName).Compressors(comps).DriverInfo(driverInfo).
+			LoadBalanced(loadBalanced)
 	}
 	// Auth & Database & Password & Username
 	if opts.Auth != nil {
@@ -263,6 +279,8 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 			AppName:       appName,
 			Authenticator: authenticator,
 			Compressors:   comps,
+			DriverInfo:    driverInfo,
+			LoadBalanced:  loadBalanced,
 		}
 		if mechanism == "" {
 			// Required for SASL mechanism negotiation during handshake
@@ -325,6 +343,12 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 			func(time.Duration) time.Duration { return *opts.MaxConnIdleTime },
 		))
 	}
+	// MaxConnLifetime
+	if opts.MaxConnLifetime != nil {
+		connOpts = append(connOpts, topology.WithLifeTimeout(
+			func(time.Duration) time.Duration { return *opts.MaxConnLifetime },
+		))
+	}
 	// MaxPoolSize
 	if opts.MaxPoolSize != nil {
 		serverOpts = append(
@@ -325,15 +325,8 @@ func (coll *Collection) InsertMany(ctx context.Context, documents []interface{},
 	}
 	if len(res.WriteErrors) > 0 || res.WriteConcernError != nil {
 		bwErrors := make([]BulkWriteError, 0, len(res.WriteErrors))
-		for _, we := range res.WriteErrors {
-			bwErrors = append(bwErrors, BulkWriteError{
-				WriteError{
-					Index:   we.Index,
-					Code:    we.Code,
-					Message: we.ErrMsg,
-				},
-				nil,
-			})
+		for _, we := range writeErrorsFromResult(res.WriteErrors) {
+			bwErrors = append(bwErrors, BulkWriteError{we, nil})
 		}
 
 		err = BulkWriteException{
@@ -719,6 +712,7 @@ func (coll *Collection) Aggregate(ctx context.Context, pipeline interface{},
 		coll.client.id,
 		coll.client.topology.SessionPool,
 		coll.registry,
+		coll.client.retryWrites,
 		aggOpts,
 	)
 	if err != nil {
@@ -989,6 +983,7 @@ func (coll *Collection) FindOne(ctx context.Context, filter interface{},
 			Skip:                opt.Skip,
 			Snapshot:            opt.Snapshot,
 			Sort:                opt.Sort,
+			ValidateProjection:  opt.ValidateProjection,
 		}
 	}
 
@@ -1235,11 +1230,12 @@ func (coll *Collection) Drop(ctx context.Context) error {
 	}
 
 	cmd := command.DropCollection{
-		DB:           coll.db.name,
-		Collection:   coll.name,
-		WriteConcern: wc,
-		Session:      sess,
-		Clock:        coll.client.clock,
+		DB:             coll.db.name,
+		Collection:     coll.name,
+		WriteConcern:   wc,
+		Session:        sess,
+		Clock:          coll.client.clock,
+		IgnoreNotFound: true,
 	}
 	_, err = driverlegacy.DropCollection(
 		ctx, cmd,
@@ -1248,8 +1244,5 @@ func (coll *Collection) Drop(ctx context.Context) error {
 		coll.client.id,
 		coll.client.topology.SessionPool,
 	)
-	if err != nil && !command.IsNotFound(err) {
-		return replaceErrors(err)
-	}
-	return nil
+	return replaceErrors(err)
 }
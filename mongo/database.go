@@ -194,9 +194,10 @@ func (db *Database) Drop(ctx context.Context) error {
 	}
 
 	cmd := command.DropDatabase{
-		DB:      db.name,
-		Session: sess,
-		Clock:   db.client.clock,
+		DB:             db.name,
+		Session:        sess,
+		Clock:          db.client.clock,
+		IgnoreNotFound: true,
 	}
 	_, err = driverlegacy.DropDatabase(
 		ctx, cmd,
@@ -205,10 +206,7 @@ func (db *Database) Drop(ctx context.Context) error {
 		db.client.id,
 		db.client.topology.SessionPool,
 	)
-	if err != nil && !command.IsNotFound(err) {
-		return replaceErrors(err)
-	}
-	return nil
+	return replaceErrors(err)
 }
 
 // ListCollections list collections from mongodb database.
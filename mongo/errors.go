@@ -85,6 +85,10 @@ type WriteError struct {
 	Index   int
 	Code    int
 	Message string
+
+	// Details is the errInfo document returned by the server, if any. For document validation
+	// failures on MongoDB 5.0 and later it describes the rule that the document did not satisfy.
+	Details bson.Raw
 }
 
 func (we WriteError) Error() string { return we.Message }
@@ -109,7 +113,7 @@ func (we WriteErrors) Error() string {
 func writeErrorsFromResult(rwes []result.WriteError) WriteErrors {
 	wes := make(WriteErrors, 0, len(rwes))
 	for _, err := range rwes {
-		wes = append(wes, WriteError{Index: err.Index, Code: err.Code, Message: err.ErrMsg})
+		wes = append(wes, WriteError{Index: err.Index, Code: err.Code, Message: err.ErrMsg, Details: err.ErrInfo})
 	}
 	return wes
 }
@@ -146,6 +150,7 @@ func convertBulkWriteErrors(errors []driverlegacy.BulkWriteError) []BulkWriteErr
 				Index:   err.Index,
 				Code:    err.Code,
 				Message: err.ErrMsg,
+				Details: err.ErrInfo,
 			},
 			dispatchToMongoModel(err.Model),
 		})
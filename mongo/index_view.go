@@ -134,11 +134,17 @@ func (iv IndexView) CreateMany(ctx context.Context, models []IndexModel, opts ..
 		return nil, err
 	}
 
+	wc := iv.coll.writeConcern
+	if sess.TransactionRunning() {
+		wc = nil
+	}
+
 	cmd := command.CreateIndexes{
-		NS:      iv.coll.namespace(),
-		Indexes: indexes,
-		Session: sess,
-		Clock:   iv.coll.client.clock,
+		NS:           iv.coll.namespace(),
+		Indexes:      indexes,
+		WriteConcern: wc,
+		Session:      sess,
+		Clock:        iv.coll.client.clock,
 	}
 
 	_, err = driverlegacy.CreateIndexes(
@@ -256,11 +262,17 @@ func (iv IndexView) DropOne(ctx context.Context, name string, opts ...*options.D
 		return nil, err
 	}
 
+	wc := iv.coll.writeConcern
+	if sess.TransactionRunning() {
+		wc = nil
+	}
+
 	cmd := command.DropIndexes{
-		NS:      iv.coll.namespace(),
-		Index:   name,
-		Session: sess,
-		Clock:   iv.coll.client.clock,
+		NS:           iv.coll.namespace(),
+		Index:        name,
+		WriteConcern: wc,
+		Session:      sess,
+		Clock:        iv.coll.client.clock,
 	}
 
 	return driverlegacy.DropIndexes(
@@ -282,11 +294,17 @@ func (iv IndexView) DropAll(ctx context.Context, opts ...*options.DropIndexesOpt
 		return nil, err
 	}
 
+	wc := iv.coll.writeConcern
+	if sess.TransactionRunning() {
+		wc = nil
+	}
+
 	cmd := command.DropIndexes{
-		NS:      iv.coll.namespace(),
-		Index:   "*",
-		Session: sess,
-		Clock:   iv.coll.client.clock,
+		NS:           iv.coll.namespace(),
+		Index:        "*",
+		WriteConcern: wc,
+		Session:      sess,
+		Clock:        iv.coll.client.clock,
 	}
 
 	return driverlegacy.DropIndexes(
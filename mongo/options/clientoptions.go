@@ -63,6 +63,15 @@ type Credential struct {
 	PasswordSet             bool
 }
 
+// DriverInfo describes a library that wraps this driver. Each non-empty field is appended to the
+// corresponding driver name, driver version, or platform metadata sent to the server in the
+// connection handshake.
+type DriverInfo struct {
+	Name     string
+	Version  string
+	Platform string
+}
+
 // ClientOptions represents all possible options to configure a client.
 type ClientOptions struct {
 	AppName                *string
@@ -70,10 +79,13 @@ type ClientOptions struct {
 	ConnectTimeout         *time.Duration
 	Compressors            []string
 	Dialer                 ContextDialer
+	DriverInfo             *DriverInfo
 	HeartbeatInterval      *time.Duration
 	Hosts                  []string
+	LoadBalanced           *bool
 	LocalThreshold         *time.Duration
 	MaxConnIdleTime        *time.Duration
+	MaxConnLifetime        *time.Duration
 	MaxPoolSize            *uint16
 	Monitor                *event.CommandMonitor
 	ReadConcern            *readconcern.ReadConcern
@@ -155,6 +167,10 @@ func (c *ClientOptions) ApplyURI(uri string) *ClientOptions {
 
 	c.Hosts = cs.Hosts
 
+	if cs.LoadBalancedSet {
+		c.LoadBalanced = &cs.LoadBalanced
+	}
+
 	if cs.LocalThresholdSet {
 		c.LocalThreshold = &cs.LocalThreshold
 	}
@@ -320,6 +336,13 @@ func (c *ClientOptions) SetDirect(b bool) *ClientOptions {
 	return c
 }
 
+// SetDriverInfo specifies information about a library that wraps this driver, such as an ODM. It is
+// included in the metadata the driver sends to the server when opening a connection.
+func (c *ClientOptions) SetDriverInfo(info *DriverInfo) *ClientOptions {
+	c.DriverInfo = info
+	return c
+}
+
 // SetHeartbeatInterval specifies the interval to wait between server monitoring checks.
 func (c *ClientOptions) SetHeartbeatInterval(d time.Duration) *ClientOptions {
 	c.HeartbeatInterval = &d
@@ -332,6 +355,12 @@ func (c *ClientOptions) SetHosts(s []string) *ClientOptions {
 	return c
 }
 
+// SetLoadBalanced specifies whether the client connects to the cluster through a load balancer.
+func (c *ClientOptions) SetLoadBalanced(b bool) *ClientOptions {
+	c.LoadBalanced = &b
+	return c
+}
+
 // SetLocalThreshold specifies how far to distribute queries, beyond the server with the fastest
 // round-trip time. If a server's roundtrip time is more than LocalThreshold slower than the
 // the fastest, the driver will not send queries to that server.
@@ -347,6 +376,15 @@ func (c *ClientOptions) SetMaxConnIdleTime(d time.Duration) *ClientOptions {
 	return c
 }
 
+// SetMaxConnLifetime specifies the maximum amount of time a connection can be open before it is
+// closed and replaced, regardless of how recently it was used. Recycling connections lets load
+// balancers spread load and lets the driver pick up DNS and routing changes. The default is 30
+// minutes and a value of 0 means connections are never recycled because of their age.
+func (c *ClientOptions) SetMaxConnLifetime(d time.Duration) *ClientOptions {
+	c.MaxConnLifetime = &d
+	return c
+}
+
 // SetMaxPoolSize specifies the max size of a server's connection pool.
 func (c *ClientOptions) SetMaxPoolSize(u uint16) *ClientOptions {
 	c.MaxPoolSize = &u
@@ -454,18 +492,27 @@ func MergeClientOptions(opts ...*ClientOptions) *ClientOptions {
 		if opt.ConnectTimeout != nil {
 			c.ConnectTimeout = opt.ConnectTimeout
 		}
+		if opt.DriverInfo != nil {
+			c.DriverInfo = opt.DriverInfo
+		}
 		if opt.HeartbeatInterval != nil {
 			c.HeartbeatInterval = opt.HeartbeatInterval
 		}
 		if len(opt.Hosts) > 0 {
 			c.Hosts = opt.Hosts
 		}
+		if opt.LoadBalanced != nil {
+			c.LoadBalanced = opt.LoadBalanced
+		}
 		if opt.LocalThreshold != nil {
 			c.LocalThreshold = opt.LocalThreshold
 		}
 		if opt.MaxConnIdleTime != nil {
 			c.MaxConnIdleTime = opt.MaxConnIdleTime
 		}
+		if opt.MaxConnLifetime != nil {
+			c.MaxConnLifetime = opt.MaxConnLifetime
+		}
 		if opt.MaxPoolSize != nil {
 			c.MaxPoolSize = opt.MaxPoolSize
 		}
@@ -57,10 +57,13 @@ func TestClientOptions(t *testing.T) {
 			{"Compressors", (*ClientOptions).SetCompressors, []string{"zstd", "snappy", "zlib"}, "Compressors", true},
 			{"ConnectTimeout", (*ClientOptions).SetConnectTimeout, 5 * time.Second, "ConnectTimeout", true},
 			{"Dialer", (*ClientOptions).SetDialer, testDialer{Num: 12345}, "Dialer", true},
+			{"DriverInfo", (*ClientOptions).SetDriverInfo, &DriverInfo{Name: "odm", Version: "1.0"}, "DriverInfo", false},
 			{"HeartbeatInterval", (*ClientOptions).SetHeartbeatInterval, 5 * time.Second, "HeartbeatInterval", true},
 			{"Hosts", (*ClientOptions).SetHosts, []string{"localhost:27017", "localhost:27018", "localhost:27019"}, "Hosts", true},
+			{"LoadBalanced", (*ClientOptions).SetLoadBalanced, true, "LoadBalanced", true},
 			{"LocalThreshold", (*ClientOptions).SetLocalThreshold, 5 * time.Second, "LocalThreshold", true},
 			{"MaxConnIdleTime", (*ClientOptions).SetMaxConnIdleTime, 5 * time.Second, "MaxConnIdleTime", true},
+			{"MaxConnLifetime", (*ClientOptions).SetMaxConnLifetime, 30 * time.Minute, "MaxConnLifetime", true},
 			{"MaxPoolSize", (*ClientOptions).SetMaxPoolSize, uint16(250), "MaxPoolSize", true},
 			{"Monitor", (*ClientOptions).SetMonitor, &event.CommandMonitor{}, "Monitor", false},
 			{"ReadConcern", (*ClientOptions).SetReadConcern, readconcern.Majority(), "ReadConcern", false},
@@ -280,6 +283,11 @@ func TestClientOptions(t *testing.T) {
 				"mongodb://localhost:27017,localhost:27018,localhost:27019/",
 				baseClient().SetHosts([]string{"localhost:27017", "localhost:27018", "localhost:27019"}),
 			},
+			{
+				"LoadBalanced",
+				"mongodb://localhost/?loadBalanced=true",
+				baseClient().SetLoadBalanced(true),
+			},
 			{
 				"LocalThreshold",
 				"mongodb://localhost/?localThresholdMS=200",
@@ -8,7 +8,8 @@ package options
 
 // DeleteOptions represents all possible options to the DeleteOne() and DeleteMany() functions.
 type DeleteOptions struct {
-	Collation *Collation // Specifies a collation
+	Collation *Collation  // Specifies a collation
+	Hint      interface{} // The index to use for the delete, as an index name or specification document
 }
 
 // Delete returns a pointer to a new DeleteOptions
@@ -23,6 +24,14 @@ func (do *DeleteOptions) SetCollation(c *Collation) *DeleteOptions {
 	return do
 }
 
+// SetHint specifies the index to use for the delete. The hint can be either the index name or the
+// index specification document.
+// Valid for servers >= 3.4. Unacknowledged writes require servers >= 4.4.
+func (do *DeleteOptions) SetHint(h interface{}) *DeleteOptions {
+	do.Hint = h
+	return do
+}
+
 // MergeDeleteOptions combines the argued DeleteOptions into a single DeleteOptions in a last-one-wins fashion
 func MergeDeleteOptions(opts ...*DeleteOptions) *DeleteOptions {
 	dOpts := Delete()
@@ -33,6 +42,9 @@ func MergeDeleteOptions(opts ...*DeleteOptions) *DeleteOptions {
 		if do.Collation != nil {
 			dOpts.Collation = do.Collation
 		}
+		if do.Hint != nil {
+			dOpts.Hint = do.Hint
+		}
 	}
 
 	return dOpts
@@ -31,6 +31,7 @@ type FindOptions struct {
 	Skip                *int64         // Specifies the number of documents to skip before returning
 	Snapshot            *bool          // If true, prevents the cursor from returning a document more than once because of an intervening write operation.
 	Sort                interface{}    // Specifies the order in which to return results.
+	ValidateProjection  *bool          // If true, rejects projections that mix inclusions and exclusions before sending.
 }
 
 // Find creates a new FindOptions instance.
@@ -45,7 +46,8 @@ func (f *FindOptions) SetAllowPartialResults(b bool) *FindOptions {
 	return f
 }
 
-// SetBatchSize sets the number of documents to return in each batch.
+// SetBatchSize sets the number of documents to return in each batch. Batch sizes larger than the
+// number of documents that could fit in a single reply from the server are reduced to that number.
 func (f *FindOptions) SetBatchSize(i int32) *FindOptions {
 	f.BatchSize = &i
 	return f
@@ -161,6 +163,13 @@ func (f *FindOptions) SetSort(sort interface{}) *FindOptions {
 	return f
 }
 
+// SetValidateProjection specifies whether the projection should be checked for a mix of inclusions and
+// exclusions before the operation is sent. The _id field may be excluded in an inclusion projection.
+func (f *FindOptions) SetValidateProjection(b bool) *FindOptions {
+	f.ValidateProjection = &b
+	return f
+}
+
 // MergeFindOptions combines the argued FindOptions into a single FindOptions in a last-one-wins fashion
 func MergeFindOptions(opts ...*FindOptions) *FindOptions {
 	fo := Find()
@@ -225,6 +234,9 @@ func MergeFindOptions(opts ...*FindOptions) *FindOptions {
 		if opt.Sort != nil {
 			fo.Sort = opt.Sort
 		}
+		if opt.ValidateProjection != nil {
+			fo.ValidateProjection = opt.ValidateProjection
+		}
 	}
 
 	return fo
@@ -250,6 +262,7 @@ type FindOneOptions struct {
 	Skip                *int64         // Specifies the number of documents to skip before returning
 	Snapshot            *bool          // If true, prevents the cursor from returning a document more than once because of an intervening write operation.
 	Sort                interface{}    // Specifies the order in which to return results.
+	ValidateProjection  *bool          // If true, rejects projections that mix inclusions and exclusions before sending.
 }
 
 // FindOne creates a new FindOneOptions instance.
@@ -367,6 +380,13 @@ func (f *FindOneOptions) SetSort(sort interface{}) *FindOneOptions {
 	return f
 }
 
+// SetValidateProjection specifies whether the projection should be checked for a mix of inclusions and
+// exclusions before the operation is sent. The _id field may be excluded in an inclusion projection.
+func (f *FindOneOptions) SetValidateProjection(b bool) *FindOneOptions {
+	f.ValidateProjection = &b
+	return f
+}
+
 // MergeFindOneOptions combines the argued FindOneOptions into a single FindOneOptions in a last-one-wins fashion
 func MergeFindOneOptions(opts ...*FindOneOptions) *FindOneOptions {
 	fo := FindOne()
@@ -428,6 +448,9 @@ func MergeFindOneOptions(opts ...*FindOneOptions) *FindOneOptions {
 		if opt.Sort != nil {
 			fo.Sort = opt.Sort
 		}
+		if opt.ValidateProjection != nil {
+			fo.ValidateProjection = opt.ValidateProjection
+		}
 	}
 
 	return fo
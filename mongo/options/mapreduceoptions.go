@@ -0,0 +1,51 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import "time"
+
+// MapReduceOptions represents all possible options to the MapReduce() function.
+type MapReduceOptions struct {
+	Collation *Collation     // Specifies a collation
+	MaxTime   *time.Duration // The maximum amount of time to allow the operation to run
+}
+
+// MapReduce returns a pointer to a new MapReduceOptions
+func MapReduce() *MapReduceOptions {
+	return &MapReduceOptions{}
+}
+
+// SetCollation specifies a collation
+// Valid for server versions >= 3.4
+func (mro *MapReduceOptions) SetCollation(c *Collation) *MapReduceOptions {
+	mro.Collation = c
+	return mro
+}
+
+// SetMaxTime specifies the maximum amount of time to allow the operation to run
+func (mro *MapReduceOptions) SetMaxTime(d time.Duration) *MapReduceOptions {
+	mro.MaxTime = &d
+	return mro
+}
+
+// MergeMapReduceOptions combines the argued MapReduceOptions into a single MapReduceOptions in a last-one-wins fashion
+func MergeMapReduceOptions(opts ...*MapReduceOptions) *MapReduceOptions {
+	mrOpts := MapReduce()
+	for _, mro := range opts {
+		if mro == nil {
+			continue
+		}
+		if mro.Collation != nil {
+			mrOpts.Collation = mro.Collation
+		}
+		if mro.MaxTime != nil {
+			mrOpts.MaxTime = mro.MaxTime
+		}
+	}
+
+	return mrOpts
+}
@@ -11,6 +11,7 @@ type UpdateOptions struct {
 	ArrayFilters             *ArrayFilters // A set of filters specifying to which array elements an update should apply
 	BypassDocumentValidation *bool         // If true, allows the write to opt-out of document level validation
 	Collation                *Collation    // Specifies a collation
+	Hint                     interface{}   // The index to use for the update, as an index name or specification document
 	Upsert                   *bool         // When true, creates a new document if no document matches the query
 }
 
@@ -40,6 +41,14 @@ func (uo *UpdateOptions) SetCollation(c *Collation) *UpdateOptions {
 	return uo
 }
 
+// SetHint specifies the index to use for the update. The hint can be either the index name or the
+// index specification document.
+// Valid for server versions >= 3.4. Unacknowledged writes require server versions >= 4.2.
+func (uo *UpdateOptions) SetHint(h interface{}) *UpdateOptions {
+	uo.Hint = h
+	return uo
+}
+
 // SetUpsert allows the creation of a new document if not document matches the query
 func (uo *UpdateOptions) SetUpsert(b bool) *UpdateOptions {
 	uo.Upsert = &b
@@ -62,6 +71,9 @@ func MergeUpdateOptions(opts ...*UpdateOptions) *UpdateOptions {
 		if uo.Collation != nil {
 			uOpts.Collation = uo.Collation
 		}
+		if uo.Hint != nil {
+			uOpts.Hint = uo.Hint
+		}
 		if uo.Upsert != nil {
 			uOpts.Upsert = uo.Upsert
 		}
@@ -65,6 +65,11 @@ func New(options ...Option) *ReadConcern {
 	return concern
 }
 
+// GetLevel returns the read concern level.
+func (rc *ReadConcern) GetLevel() string {
+	return rc.level
+}
+
 // MarshalBSONValue implements the bson.ValueMarshaler interface.
 func (rc *ReadConcern) MarshalBSONValue() (bsontype.Type, []byte, error) {
 	var elems []byte
@@ -58,3 +58,13 @@ func WithTagSets(tagSets ...tag.Set) Option {
 		return nil
 	}
 }
+
+// WithStrictTags requires that a server matching the tag sets be selected. Selection fails
+// instead of falling back to any other server, including the primary for SecondaryPreferred.
+// It is intended for workloads, such as analytics, that must only run on dedicated members.
+func WithStrictTags() Option {
+	return func(rp *ReadPref) error {
+		rp.strictTags = true
+		return nil
+	}
+}
@@ -78,6 +78,7 @@ type ReadPref struct {
 	maxStalenessSet bool
 	mode            Mode
 	tagSets         []tag.Set
+	strictTags      bool
 }
 
 // MaxStaleness is the maximum amount of time to allow
@@ -97,3 +98,9 @@ func (r *ReadPref) Mode() Mode {
 func (r *ReadPref) TagSets() []tag.Set {
 	return r.tagSets
 }
+
+// StrictTags indicates whether selection must fail rather than fall back
+// when no server matches the tag sets.
+func (r *ReadPref) StrictTags() bool {
+	return r.strictTags
+}
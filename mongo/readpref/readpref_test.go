@@ -97,6 +97,18 @@ func TestSecondary_with_options(t *testing.T) {
 	require.Equal([]tag.Set{{tag.Tag{Name: "a", Value: "1"}, tag.Tag{Name: "b", Value: "2"}}}, subject.TagSets())
 }
 
+func TestSecondary_with_strict_tags(t *testing.T) {
+	require := require.New(t)
+	subject := Secondary(
+		WithTags("workload", "analytics"),
+		WithStrictTags(),
+	)
+
+	require.Equal(SecondaryMode, subject.Mode())
+	require.True(subject.StrictTags())
+	require.False(Secondary(WithTags("workload", "analytics")).StrictTags())
+}
+
 func TestNearest(t *testing.T) {
 	require := require.New(t)
 	subject := Nearest()
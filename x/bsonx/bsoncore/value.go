@@ -73,13 +73,32 @@ func (v Value) AsInt32OK() (int32, bool) { return 0, false }
 // will panic.
 //
 // TODO(skriptble): Add support for Decimal128.
-func (v Value) AsInt64() int64 { return 0 }
+func (v Value) AsInt64() int64 {
+	i64, ok := v.AsInt64OK()
+	if !ok {
+		panic(ElementTypeError{"bsoncore.Value.AsInt64", v.Type})
+	}
+	return i64
+}
 
 // AsInt64OK functions the same as AsInt64 but returns a boolean instead of panicking. False
 // indicates an error.
 //
 // TODO(skriptble): Add support for Decimal128.
-func (v Value) AsInt64OK() (int64, bool) { return 0, false }
+func (v Value) AsInt64OK() (int64, bool) {
+	switch v.Type {
+	case bsontype.Int32:
+		i32, ok := v.Int32OK()
+		return int64(i32), ok
+	case bsontype.Int64:
+		return v.Int64OK()
+	case bsontype.Double:
+		f64, ok := v.DoubleOK()
+		return int64(f64), ok
+	default:
+		return 0, false
+	}
+}
 
 // AsFloat64 returns a BSON number as an float64. If the BSON type is not a numeric one, this method
 // will panic.
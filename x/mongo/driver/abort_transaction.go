@@ -0,0 +1,96 @@
+package driver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// AbortTransactionOperation aborts the transaction running on a session. Aborting is best effort:
+// the command is retried once after a retryable error, any remaining error is discarded, and the
+// session's transaction state is always reset, because the server aborts the transaction by itself
+// once it times out.
+type AbortTransactionOperation struct {
+	client *session.Client
+	clock  *session.ClusterClock
+
+	d Deployment
+}
+
+// AbortTransaction constructs an AbortTransactionOperation that aborts the transaction on client.
+func AbortTransaction(client *session.Client) *AbortTransactionOperation {
+	return &AbortTransactionOperation{client: client}
+}
+
+// Clock sets the cluster clock for this operation.
+func (ato *AbortTransactionOperation) Clock(clock *session.ClusterClock) *AbortTransactionOperation {
+	ato.clock = clock
+	return ato
+}
+
+// Deployment sets the Deployment for this operation.
+func (ato *AbortTransactionOperation) Deployment(d Deployment) *AbortTransactionOperation {
+	ato.d = d
+	return ato
+}
+
+func (ato *AbortTransactionOperation) command(dst []byte, _ description.SelectedServer) ([]byte, error) {
+	dst = bsoncore.AppendInt32Element(dst, "abortTransaction", 1)
+	if ato.client.RecoveryToken != nil {
+		dst = bsoncore.AppendDocumentElement(dst, "recoveryToken", bsoncore.Document(ato.client.RecoveryToken))
+	}
+	return dst, nil
+}
+
+// Execute runs this operation. An error is only returned if the operation is misconfigured or the
+// session has no transaction that can be aborted; failures of the command itself are not returned.
+func (ato *AbortTransactionOperation) Execute(ctx context.Context) error {
+	if ato.d == nil {
+		return errors.New("an AbortTransactionOperation must have a Deployment set before Execute can be called")
+	}
+	if ato.client == nil {
+		return errors.New("an AbortTransactionOperation must have a session to abort")
+	}
+	if err := ato.client.CheckAbortTransaction(); err != nil {
+		return err
+	}
+	// A transaction that has not sent a command yet does not exist on the server.
+	if ato.client.TransactionStarting() {
+		return ato.client.AbortTransaction()
+	}
+
+	// A sharded transaction must be aborted on the mongos it is pinned to.
+	selector := description.WriteSelector()
+	if pinned := ato.client.PinnedServer; pinned != nil {
+		selector = description.AddressSelector(pinned.Addr)
+	}
+
+	ato.client.Aborting = true
+	op := Operation{
+		CommandFn:  ato.command,
+		Database:   "admin",
+		Deployment: ato.d,
+		Selector:   selector,
+		Clock:      ato.clock,
+		Client:     ato.client,
+	}
+	if err := op.Execute(ctx, nil); retryableAbortError(err) {
+		_ = op.Execute(ctx, nil)
+	}
+
+	return ato.client.AbortTransaction()
+}
+
+// retryableAbortError returns true if an abortTransaction that failed with err should be retried.
+func retryableAbortError(err error) bool {
+	switch tt := err.(type) {
+	case Error:
+		return tt.Retryable()
+	case WriteCommandError:
+		return tt.Retryable()
+	}
+	return false
+}
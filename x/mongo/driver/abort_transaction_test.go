@@ -0,0 +1,148 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/uuid"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// flakyConnection fails the first failures writes with a network error.
+type flakyConnection struct {
+	*mockConnection
+	failures int
+}
+
+func (fc *flakyConnection) WriteWireMessage(ctx context.Context, wm []byte) error {
+	err := fc.mockConnection.WriteWireMessage(ctx, wm)
+	if fc.failures > 0 {
+		fc.failures--
+		return errors.New("connection reset by peer")
+	}
+	return err
+}
+
+func TestAbortTransaction(t *testing.T) {
+	ok := opMsgReply(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendDoubleElement(nil, "ok", 1)))
+	running := func(t *testing.T) *session.Client {
+		t.Helper()
+		id, err := uuid.New()
+		noerr(t, err)
+		sess, err := session.NewClientSession(session.NewPool(nil), id, session.Explicit)
+		noerr(t, err)
+		noerr(t, sess.StartTransaction(nil))
+		sess.ApplyCommand(description.Server{Kind: description.Mongos})
+		return sess
+	}
+	deployment := func(failures int) (*mockDeployment, *flakyConnection) {
+		conn := &flakyConnection{
+			mockConnection: &mockConnection{
+				rDesc:   description.Server{Kind: description.RSPrimary, WireVersion: &description.VersionRange{Max: 7}},
+				rReadWM: ok,
+			},
+			failures: failures,
+		}
+		d := new(mockDeployment)
+		d.returns.server = SingleConnectionDeployment{C: conn}
+		d.returns.kind = description.ReplicaSetWithPrimary
+		return d, conn
+	}
+	requireAborted := func(t *testing.T, sess *session.Client) {
+		t.Helper()
+		if sess.TransactionRunning() || sess.Aborting || sess.PinnedServer != nil {
+			t.Errorf("Expected the transaction state to be reset")
+		}
+		if err := sess.CheckAbortTransaction(); err != session.ErrAbortTwice {
+			t.Errorf("Expected the transaction to be aborted. got %v", err)
+		}
+	}
+
+	t.Run("retries once after a network error", func(t *testing.T) {
+		sess := running(t)
+		d, conn := deployment(1)
+		noerr(t, AbortTransaction(sess).Clock(&session.ClusterClock{}).Deployment(d).Execute(context.Background()))
+
+		if len(conn.pWriteWMs) != 2 {
+			t.Fatalf("Expected abortTransaction to be sent twice. got %d", len(conn.pWriteWMs))
+		}
+		cmd := msgCommand(t, conn.pWriteWMs[1])
+		if v, ok := cmd.Lookup("abortTransaction").Int32OK(); !ok || v != 1 {
+			t.Errorf("Expected abortTransaction: 1. got %v", cmd)
+		}
+		if db, _ := cmd.Lookup("$db").StringValueOK(); db != "admin" {
+			t.Errorf("Expected the command to run on admin. got %q", db)
+		}
+		requireAborted(t, sess)
+	})
+	t.Run("swallows repeated network errors", func(t *testing.T) {
+		sess := running(t)
+		d, conn := deployment(2)
+		noerr(t, AbortTransaction(sess).Clock(&session.ClusterClock{}).Deployment(d).Execute(context.Background()))
+
+		if len(conn.pWriteWMs) != 2 {
+			t.Errorf("Expected abortTransaction to be retried only once. got %d", len(conn.pWriteWMs))
+		}
+		requireAborted(t, sess)
+	})
+	t.Run("does not retry other errors", func(t *testing.T) {
+		sess := running(t)
+		d, conn := deployment(0)
+		conn.rReadWM = opMsgReply(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendDoubleElement(nil, "ok", 0),
+			bsoncore.AppendInt32Element(nil, "code", 251),
+			bsoncore.AppendStringElement(nil, "errmsg", "NoSuchTransaction"),
+		))
+		noerr(t, AbortTransaction(sess).Clock(&session.ClusterClock{}).Deployment(d).Execute(context.Background()))
+
+		if len(conn.pWriteWMs) != 1 {
+			t.Errorf("Expected abortTransaction to be sent once. got %d", len(conn.pWriteWMs))
+		}
+		requireAborted(t, sess)
+	})
+	t.Run("selects the pinned server", func(t *testing.T) {
+		sess := running(t)
+		pinned := description.Server{Addr: address.Address("localhost:27018"), Kind: description.Mongos}
+		sess.PinnedServer = &pinned
+		d, _ := deployment(0)
+		noerr(t, AbortTransaction(sess).Clock(&session.ClusterClock{}).Deployment(d).Execute(context.Background()))
+
+		topo := description.Topology{
+			Kind: description.Sharded,
+			Servers: []description.Server{
+				{Addr: address.Address("localhost:27017"), Kind: description.Mongos},
+				pinned,
+			},
+		}
+		requireRoutedTo(t, d, topo, pinned.Addr)
+		requireAborted(t, sess)
+	})
+	t.Run("starting transaction sends nothing", func(t *testing.T) {
+		id, err := uuid.New()
+		noerr(t, err)
+		sess, err := session.NewClientSession(session.NewPool(nil), id, session.Explicit)
+		noerr(t, err)
+		noerr(t, sess.StartTransaction(nil))
+		d, conn := deployment(0)
+		noerr(t, AbortTransaction(sess).Clock(&session.ClusterClock{}).Deployment(d).Execute(context.Background()))
+
+		if len(conn.pWriteWMs) != 0 {
+			t.Errorf("Expected no command to be sent. got %d", len(conn.pWriteWMs))
+		}
+		requireAborted(t, sess)
+	})
+	t.Run("no transaction", func(t *testing.T) {
+		id, err := uuid.New()
+		noerr(t, err)
+		sess, err := session.NewClientSession(session.NewPool(nil), id, session.Explicit)
+		noerr(t, err)
+		d, _ := deployment(0)
+		if err := AbortTransaction(sess).Clock(&session.ClusterClock{}).Deployment(d).Execute(context.Background()); err != session.ErrNoTransactStarted {
+			t.Errorf("Expected ErrNoTransactStarted. got %v", err)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import "sync"
+
+// BufferPool is a pool of byte slices that operations use to build wire messages and read replies
+// into. Reusing buffers across operations avoids allocating new slices for every command, which
+// reduces garbage collection pressure for workloads that run many small operations. A BufferPool is
+// safe for concurrent use.
+type BufferPool struct {
+	pool    sync.Pool
+	maxSize int
+}
+
+// NewBufferPool creates a BufferPool whose new buffers have a capacity of size bytes. Buffers that
+// have grown larger than maxSize, for example to read a large reply, are discarded instead of being
+// returned to the pool so that one large reply does not keep a large allocation alive.
+func NewBufferPool(size, maxSize int) *BufferPool {
+	bp := &BufferPool{maxSize: maxSize}
+	bp.pool.New = func() interface{} {
+		b := make([]byte, 0, size)
+		return &b
+	}
+	return bp
+}
+
+// Get returns an empty buffer from the pool. The caller owns the buffer until it is returned with
+// Put.
+func (bp *BufferPool) Get() []byte {
+	return (*bp.pool.Get().(*[]byte))[:0]
+}
+
+// Put returns b to the pool. Neither b nor any slice of it may be used after it has been returned.
+func (bp *BufferPool) Put(b []byte) {
+	if b == nil || cap(b) > bp.maxSize {
+		return
+	}
+	b = b[:0]
+	bp.pool.Put(&b)
+}
@@ -0,0 +1,194 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+func TestBufferPool(t *testing.T) {
+	t.Run("discards buffers larger than the maximum size", func(t *testing.T) {
+		bp := NewBufferPool(16, 32)
+		if got := bp.Get(); len(got) != 0 || cap(got) != 16 {
+			t.Errorf("Unexpected new buffer. got len %d, cap %d; want len 0, cap 16", len(got), cap(got))
+		}
+		bp.Put(make([]byte, 10, 64))
+		for i := 0; i < 10; i++ {
+			if got := bp.Get(); cap(got) > 32 {
+				t.Fatalf("Buffer larger than the maximum size was returned. got cap %d; want at most %d", cap(got), 32)
+			}
+		}
+	})
+	t.Run("concurrent operations do not share replies", func(t *testing.T) {
+		bp := NewBufferPool(64, 1024)
+		var wg sync.WaitGroup
+		for i := int32(0); i < 8; i++ {
+			wg.Add(1)
+			go func(id int32) {
+				defer wg.Done()
+				conn := newReplyConnection(bsoncore.BuildDocumentFromElements(nil,
+					bsoncore.AppendInt32Element(nil, "ok", 1),
+					bsoncore.AppendInt32Element(nil, "id", id),
+				))
+				for j := 0; j < 100; j++ {
+					var got int32
+					op := Operation{
+						CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+							return bsoncore.AppendInt32Element(dst, "ping", id), nil
+						},
+						Database:   "admin",
+						Deployment: SingleConnectionDeployment{C: conn},
+						BufferPool: bp,
+						ProcessResponseFn: func(response bsoncore.Document, _ Server) error {
+							got = response.Lookup("id").Int32()
+							return nil
+						},
+					}
+					err := op.Execute(context.Background(), nil)
+					if err != nil {
+						t.Errorf("Unexpected error: %v", err)
+						return
+					}
+					if got != id {
+						t.Errorf("Reply was corrupted. got id %d; want %d", got, id)
+						return
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+	t.Run("command result outlives the pooled buffer", func(t *testing.T) {
+		conn := newReplyConnection(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt32Element(nil, "ok", 1),
+			bsoncore.AppendInt32Element(nil, "id", 7),
+		))
+		op := Command(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ping", 1))).
+			Database("admin").
+			Deployment(SingleConnectionDeployment{C: conn}).
+			BufferPool(NewBufferPool(64, 1024))
+		noerr(t, op.Execute(context.Background()))
+
+		// Overwrite the buffer as the next operation to take it from the pool would.
+		for i := range conn.read {
+			conn.read[i] = 0
+		}
+		if id, ok := op.Result().Lookup("id").Int32OK(); !ok || id != 7 {
+			t.Errorf("Result was corrupted by reusing the buffer. got %v", op.Result())
+		}
+	})
+	t.Run("insert keeps the write errors of earlier batches", func(t *testing.T) {
+		docs := make([]bsoncore.Document, 4)
+		for i := range docs {
+			docs[i] = bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "_id", int32(i)))
+		}
+		errInfo := bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendStringElement(nil, "reason", "duplicate"))
+		conn := newReplyConnection(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1)))
+		conn.queue = [][]byte{
+			opMsgReply(bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+				bsoncore.AppendInt32Element(nil, "n", 1),
+				bsoncore.AppendArrayElement(nil, "writeErrors", bsoncore.BuildArray(nil,
+					bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: bsoncore.BuildDocumentFromElements(nil,
+						bsoncore.AppendInt32Element(nil, "index", 1),
+						bsoncore.AppendInt32Element(nil, "code", 11000),
+						bsoncore.AppendStringElement(nil, "errmsg", "E11000 duplicate key error"),
+						bsoncore.AppendDocumentElement(nil, "errInfo", errInfo),
+					)},
+				)),
+			)),
+			opMsgReply(bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+				bsoncore.AppendInt32Element(nil, "n", 2),
+			)),
+		}
+		conn.rDesc.MaxDocumentSize = uint32(2*len(docs[0]) + 1)
+		conn.rDesc.MaxBatchCount = 2
+
+		op := Insert(docs...).Ordered(false).
+			Database("foo").
+			Collection("bar").
+			Deployment(SingleConnectionDeployment{C: conn}).
+			BufferPool(NewBufferPool(1024, 4096))
+		err := op.Execute(context.Background())
+		wce, ok := err.(WriteCommandError)
+		if !ok || len(wce.WriteErrors) != 1 {
+			t.Fatalf("Expected one write error. got %v", err)
+		}
+		if !bytes.Equal(wce.WriteErrors[0].Details, errInfo) {
+			t.Errorf("Returned write error was corrupted by the next batch. got %v; want %v", wce.WriteErrors[0].Details, errInfo)
+		}
+		if res := op.Result(); len(res.WriteErrors) != 1 || !bytes.Equal(res.WriteErrors[0].ErrInfo, errInfo) {
+			t.Errorf("Result write error was corrupted by the next batch. got %v", res.WriteErrors)
+		}
+	})
+}
+
+func BenchmarkExecute(b *testing.B) {
+	conn := newReplyConnection(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1)))
+	op := Operation{
+		CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+			return bsoncore.AppendInt32Element(dst, "ping", 1), nil
+		},
+		Database:   "admin",
+		Deployment: SingleConnectionDeployment{C: conn},
+	}
+
+	b.Run("without BufferPool", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := op.Execute(context.Background(), nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("with BufferPool", func(b *testing.B) {
+		op := op
+		op.BufferPool = NewBufferPool(256, 16*1024)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := op.Execute(context.Background(), nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// replyConnection is a Connection that discards written wire messages and reads a copy of the same
+// reply into the destination buffer it is given, like a network connection does. The replies in
+// queue, if any, are read first. The last message read is kept in read.
+type replyConnection struct {
+	*mockConnection
+	reply []byte
+	queue [][]byte
+	read  []byte
+}
+
+func newReplyConnection(doc bsoncore.Document) *replyConnection {
+	return &replyConnection{
+		mockConnection: &mockConnection{rDesc: description.Server{WireVersion: &description.VersionRange{Max: 6}}},
+		reply:          opMsgReply(doc),
+	}
+}
+
+func (c *replyConnection) WriteWireMessage(context.Context, []byte) error { return nil }
+
+func (c *replyConnection) ReadWireMessage(_ context.Context, dst []byte) ([]byte, error) {
+	reply := c.reply
+	if len(c.queue) > 0 {
+		reply, c.queue = c.queue[0], c.queue[1:]
+	}
+	c.read = append(dst[:0], reply...)
+	return c.read, nil
+}
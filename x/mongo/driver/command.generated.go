@@ -94,3 +94,43 @@ func (co *CommandOperation) ServerSelector(selector description.ServerSelector)
 	co.selector = selector
 	return co
 }
+
+// Comment sets a comment to attach to the command.
+func (co *CommandOperation) Comment(comment string) *CommandOperation {
+	if co == nil {
+		co = new(CommandOperation)
+	}
+
+	co.comment = comment
+	return co
+}
+
+// ServerAPI sets the Stable API options for this operation.
+func (co *CommandOperation) ServerAPI(serverAPI *ServerAPIOptions) *CommandOperation {
+	if co == nil {
+		co = new(CommandOperation)
+	}
+
+	co.serverAPI = serverAPI
+	return co
+}
+
+// Explain runs the command under explain with the given verbosity instead of running it.
+func (co *CommandOperation) Explain(verbosity ExplainVerbosity) *CommandOperation {
+	if co == nil {
+		co = new(CommandOperation)
+	}
+
+	co.explain = verbosity
+	return co
+}
+
+// BufferPool sets the pool that supplies the buffers used to send the command and read its reply.
+func (co *CommandOperation) BufferPool(bufferPool *BufferPool) *CommandOperation {
+	if co == nil {
+		co = new(CommandOperation)
+	}
+
+	co.bufferPool = bufferPool
+	return co
+}
@@ -3,11 +3,13 @@ package driver
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/lakshay2395/mongo-go-driver/mongo/readconcern"
 	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
 	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
 	"github.com/lakshay2395/mongo-go-driver/x/network/description"
 )
 
@@ -29,21 +31,63 @@ type CommandOperation struct {
 	clock    *session.ClusterClock      `drivergen:"Clock,pointerExempt"`
 	client   *session.Client            `drivergen:"Session,pointerExempt"`
 
+	serverAPI *ServerAPIOptions `drivergen:"ServerAPI,pointerExempt"`
+
+	// Comment sets a comment to attach to the command.
+	comment string
+
+	// Explain runs the command under explain with the given verbosity instead of running it.
+	explain ExplainVerbosity `drivergen:"Explain"`
+
+	// BufferPool sets the pool that supplies the buffers used to send the command and read its reply.
+	bufferPool *BufferPool `drivergen:"BufferPool,pointerExempt"`
+
 	result bsoncore.Document `drivergen:"-"`
 }
 
+// ExplainVerbosity is the level of detail returned by explain.
+type ExplainVerbosity string
+
+// These are the verbosities supported by explain.
+const (
+	ExplainQueryPlanner      ExplainVerbosity = "queryPlanner"
+	ExplainExecutionStats    ExplainVerbosity = "executionStats"
+	ExplainAllPlansExecution ExplainVerbosity = "allPlansExecution"
+)
+
+// Valid returns true if ev is a verbosity supported by explain.
+func (ev ExplainVerbosity) Valid() bool {
+	return ev == ExplainQueryPlanner || ev == ExplainExecutionStats || ev == ExplainAllPlansExecution
+}
+
+// RunCommandOnServer constructs a CommandOperation that runs cmd against the server at addr instead
+// of a server chosen by read preference. Executing the operation returns an error if addr is not
+// part of the Deployment's topology. The read preference is set to nearest so that the command can
+// be run against any member of a replica set.
+func RunCommandOnServer(cmd bsoncore.Document, addr address.Address) *CommandOperation {
+	return Command(cmd).ServerSelector(description.AddressSelector(addr)).ReadPreference(readpref.Nearest())
+}
+
 // Result returns the result of executing this operation.
 //
 // TODO(GODRIVER-617): This should be generated by drivergen.
 func (co *CommandOperation) Result() bsoncore.Document { return co.result }
 
 func (co *CommandOperation) processResponse(response bsoncore.Document, _ Server) error {
+	// A pooled response is reused once Execute returns, so the result must not refer to it.
+	if co.bufferPool != nil {
+		response = append(bsoncore.Document(nil), response...)
+	}
 	co.result = response
 	return nil
 }
 
 // TODO(GODRIVER-617): This should be generated by drivergen.
 func (co *CommandOperation) command(dst []byte, _ description.SelectedServer) ([]byte, error) {
+	if co.explain != "" {
+		dst = bsoncore.AppendDocumentElement(dst, "explain", co.cmd)
+		return bsoncore.AppendStringElement(dst, "verbosity", string(co.explain)), nil
+	}
 	return append(dst, co.cmd[4:len(co.cmd)-1]...), nil
 }
 
@@ -58,6 +102,14 @@ func (co *CommandOperation) Execute(ctx context.Context) error {
 	if co.database == "" {
 		return errors.New("Database must be of non-zero length")
 	}
+	if co.explain != "" && !co.explain.Valid() {
+		return fmt.Errorf(
+			"invalid explain verbosity %q: must be one of %s, %s or %s",
+			co.explain, ExplainQueryPlanner, ExplainExecutionStats, ExplainAllPlansExecution,
+		)
+	}
+	// explain is a read even when the explained command is a write, so it is sent to a server chosen
+	// by the read preference.
 	return Operation{
 		CommandFn:  co.command,
 		Deployment: co.d,
@@ -71,5 +123,9 @@ func (co *CommandOperation) Execute(ctx context.Context) error {
 
 		Client: co.client,
 		Clock:  co.clock,
+
+		ServerAPI:  co.serverAPI,
+		Comment:    co.comment,
+		BufferPool: co.bufferPool,
 	}.Execute(ctx, nil)
 }
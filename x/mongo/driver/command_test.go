@@ -0,0 +1,111 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
+	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+func TestRunCommandOnServer(t *testing.T) {
+	primary := description.Server{Addr: address.Address("localhost:27017"), Kind: description.RSPrimary}
+	secondary := description.Server{Addr: address.Address("localhost:27018"), Kind: description.RSSecondary}
+	topo := description.Topology{Kind: description.ReplicaSetWithPrimary, Servers: []description.Server{primary, secondary}}
+	cmd := bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "fsync", 1))
+
+	selectorFor := func(t *testing.T, addr address.Address) description.ServerSelector {
+		t.Helper()
+		want := errors.New("selection finished")
+		d := new(mockDeployment)
+		d.returns.err = want
+		err := RunCommandOnServer(cmd, addr).Deployment(d).Database("admin").Execute(context.Background())
+		if err != want {
+			t.Fatalf("Expected error from Deployment. got %v; want %v", err, want)
+		}
+		return d.params.selector
+	}
+
+	t.Run("selects only the requested server", func(t *testing.T) {
+		got, err := selectorFor(t, secondary.Addr).SelectServer(topo, topo.Servers)
+		noerr(t, err)
+		if !cmp.Equal(got, []description.Server{secondary}) {
+			t.Errorf("Did not select the requested server. got %v; want %v", got, secondary)
+		}
+	})
+	t.Run("errors when the address is unknown", func(t *testing.T) {
+		_, err := selectorFor(t, address.Address("localhost:27019")).SelectServer(topo, topo.Servers)
+		if err == nil {
+			t.Error("Expected an error for an address that is not part of the topology")
+		}
+	})
+}
+
+func TestCommandExplain(t *testing.T) {
+	update := bsoncore.BuildDocumentFromElements(nil,
+		bsoncore.AppendStringElement(nil, "update", "bar"),
+		bsoncore.AppendArrayElement(nil, "updates", bsoncore.BuildArray(nil,
+			bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendDocumentElement(nil, "q", bsoncore.BuildDocument(nil, nil)),
+				bsoncore.AppendDocumentElement(nil, "u", bsoncore.BuildDocumentFromElements(nil,
+					bsoncore.AppendInt32Element(nil, "x", 1),
+				)),
+			)},
+		)),
+	)
+
+	t.Run("wraps the command", func(t *testing.T) {
+		conn := &mockConnection{
+			rDesc:   description.Server{Kind: description.RSSecondary, WireVersion: &description.VersionRange{Max: 6}},
+			rReadWM: opMsgReply(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1))),
+		}
+		d := new(mockDeployment)
+		d.returns.server = mockServer{conn: conn}
+		err := Command(update).Explain(ExplainExecutionStats).Deployment(d).Database("foo").Execute(context.Background())
+		noerr(t, err)
+
+		cmd := msgCommand(t, conn.pWriteWM)
+		if name := cmd.Index(0).Key(); name != "explain" {
+			t.Errorf("Expected explain to be the first element. got %q", name)
+		}
+		if explained, ok := cmd.Lookup("explain").DocumentOK(); !ok || !bytes.Equal(explained, update) {
+			t.Errorf("Expected the command to be wrapped in explain. got %v", cmd)
+		}
+		if verbosity, _ := cmd.Lookup("verbosity").StringValueOK(); verbosity != "executionStats" {
+			t.Errorf("Expected verbosity executionStats. got %q", verbosity)
+		}
+	})
+	t.Run("invalid verbosity", func(t *testing.T) {
+		d := new(mockDeployment)
+		err := Command(update).Explain("everything").Deployment(d).Database("foo").Execute(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "invalid explain verbosity") {
+			t.Errorf("Expected an invalid verbosity error. got %v", err)
+		}
+	})
+	t.Run("write commands are routed as reads", func(t *testing.T) {
+		primary := description.Server{Addr: address.Address("localhost:27017"), Kind: description.RSPrimary}
+		secondary := description.Server{Addr: address.Address("localhost:27018"), Kind: description.RSSecondary}
+		topo := description.Topology{Kind: description.ReplicaSetWithPrimary, Servers: []description.Server{primary, secondary}}
+
+		want := errors.New("selection finished")
+		d := new(mockDeployment)
+		d.returns.err = want
+		err := Command(update).Explain(ExplainQueryPlanner).ReadPreference(readpref.Secondary()).
+			Deployment(d).Database("foo").Execute(context.Background())
+		if err != want {
+			t.Fatalf("Expected error from Deployment. got %v; want %v", err, want)
+		}
+		got, err := d.params.selector.SelectServer(topo, topo.Servers)
+		noerr(t, err)
+		if !cmp.Equal(got, []description.Server{secondary}) {
+			t.Errorf("Expected explain of a write to select a secondary. got %v", got)
+		}
+	})
+}
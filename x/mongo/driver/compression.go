@@ -0,0 +1,94 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+// compressionThreshold is the size in bytes that a wire message must exceed before it is compressed.
+// Compressing smaller messages costs more than the bytes it saves, so they are sent uncompressed.
+const compressionThreshold = 512
+
+// maxMessageSize is the largest wire message a server sends. Decompressed sizes reported by the
+// server are checked against it before any memory is allocated for them.
+const maxMessageSize = 48000000
+
+// CompressionOpts holds settings for how to compress a payload.
+type CompressionOpts struct {
+	Compressor       wiremessage.CompressorID
+	ZlibLevel        int
+	UncompressedSize int32
+}
+
+// CompressPayload takes a byte slice and compresses it according to the options passed.
+func CompressPayload(in []byte, opts CompressionOpts) ([]byte, error) {
+	switch opts.Compressor {
+	case wiremessage.CompressorNoOp:
+		return in, nil
+	case wiremessage.CompressorSnappy:
+		return snappy.Encode(nil, in), nil
+	case wiremessage.CompressorZLib:
+		var b bytes.Buffer
+		w, err := zlib.NewWriterLevel(&b, opts.ZlibLevel)
+		if err != nil {
+			return nil, err
+		}
+		_, err = w.Write(in)
+		if err != nil {
+			return nil, err
+		}
+		err = w.Close()
+		if err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown compressor ID %v", opts.Compressor)
+	}
+}
+
+// DecompressPayload takes a byte slice that has been compressed and undoes it according to the
+// options passed. The UncompressedSize field must be set to the size of the original payload.
+func DecompressPayload(in []byte, opts CompressionOpts) ([]byte, error) {
+	if opts.UncompressedSize < 0 || opts.UncompressedSize > maxMessageSize {
+		return nil, fmt.Errorf("invalid uncompressed size: %d", opts.UncompressedSize)
+	}
+	uncompressed := make([]byte, opts.UncompressedSize)
+	switch opts.Compressor {
+	case wiremessage.CompressorNoOp:
+		return in, nil
+	case wiremessage.CompressorSnappy:
+		n, err := snappy.DecodedLen(in)
+		if err != nil {
+			return nil, err
+		}
+		if n != int(opts.UncompressedSize) {
+			return nil, fmt.Errorf("unexpected decompressed size: got %d; want %d", n, opts.UncompressedSize)
+		}
+		return snappy.Decode(uncompressed, in)
+	case wiremessage.CompressorZLib:
+		r, err := zlib.NewReader(bytes.NewReader(in))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		_, err = io.ReadFull(r, uncompressed)
+		if err != nil {
+			return nil, err
+		}
+		return uncompressed, nil
+	default:
+		return nil, fmt.Errorf("unknown compressor ID %v", opts.Compressor)
+	}
+}
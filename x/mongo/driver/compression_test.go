@@ -0,0 +1,158 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	wiremessagex "github.com/lakshay2395/mongo-go-driver/x/mongo/driver/wiremessage"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+func TestCompression(t *testing.T) {
+	cmd := bsoncore.BuildDocumentFromElements(nil,
+		bsoncore.AppendStringElement(nil, "insert", "bar"),
+		bsoncore.AppendStringElement(nil, "comment", strings.Repeat("compressible ", 100)),
+		bsoncore.AppendStringElement(nil, "$db", "foo"),
+	)
+	compressors := []wiremessage.CompressorID{wiremessage.CompressorZLib, wiremessage.CompressorSnappy}
+
+	t.Run("payload round trip", func(t *testing.T) {
+		for _, id := range compressors {
+			opts := CompressionOpts{Compressor: id, ZlibLevel: wiremessage.DefaultZlibLevel, UncompressedSize: int32(len(cmd))}
+			compressed, err := CompressPayload(cmd, opts)
+			noerr(t, err)
+			if len(compressed) >= len(cmd) {
+				t.Errorf("Compressor %v did not shrink the payload. got %d bytes; want < %d", id, len(compressed), len(cmd))
+			}
+			got, err := DecompressPayload(compressed, opts)
+			noerr(t, err)
+			if !bytes.Equal(got, cmd) {
+				t.Errorf("Compressor %v did not round trip. got %v; want %v", id, got, cmd)
+			}
+		}
+	})
+	t.Run("wire message round trip", func(t *testing.T) {
+		wm := opMsgReply(cmd)
+		for _, id := range compressors {
+			compressed := compressWireMessage(t, wm, id)
+			_, _, _, opcode, rem, _ := wiremessagex.ReadHeader(compressed)
+			if opcode != wiremessage.OpCompressed {
+				t.Fatalf("Expected an OP_COMPRESSED wire message. got %v", opcode)
+			}
+			original, rem, _ := wiremessagex.ReadCompressedOriginalOpCode(rem)
+			if original != wiremessage.OpMsg {
+				t.Errorf("Original opcode was not preserved. got %v; want %v", original, wiremessage.OpMsg)
+			}
+			size, _, _ := wiremessagex.ReadCompressedUncompressedSize(rem)
+			if int(size) != len(wm)-16 {
+				t.Errorf("Uncompressed size was not preserved. got %d; want %d", size, len(wm)-16)
+			}
+
+			got, err := Operation{}.decompressWireMessage(compressed)
+			noerr(t, err)
+			if !bytes.Equal(got, wm) {
+				t.Errorf("Compressor %v did not round trip. got %v; want %v", id, got, wm)
+			}
+		}
+	})
+	t.Run("rejects invalid sizes", func(t *testing.T) {
+		for _, size := range []int32{-1, maxMessageSize + 1} {
+			_, err := DecompressPayload(nil, CompressionOpts{Compressor: wiremessage.CompressorZLib, UncompressedSize: size})
+			if err == nil {
+				t.Errorf("Expected an error for uncompressed size %d", size)
+			}
+		}
+
+		wm := compressWireMessage(t, opMsgReply(cmd), wiremessage.CompressorZLib)
+		for _, size := range []int32{-1, maxMessageSize} {
+			bad := append([]byte{}, wm...)
+			copy(bad[20:24], wiremessagex.AppendCompressedUncompressedSize(nil, size))
+			if _, err := (Operation{}).decompressWireMessage(bad); err == nil {
+				t.Errorf("Expected an error for uncompressed size %d", size)
+			}
+		}
+
+		short := wiremessagex.AppendHeader(nil, 20, 1, 0, wiremessage.OpCompressed)
+		short = append(short, 0, 0, 0, 0)
+		if _, err := (Operation{}).decompressWireMessage(short); err == nil {
+			t.Error("Expected an error for a message length shorter than the OP_COMPRESSED header")
+		}
+	})
+	t.Run("Execute compresses large commands", func(t *testing.T) {
+		reply := opMsgReply(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1)))
+		conn := &compressingConnection{
+			mockConnection: &mockConnection{
+				rDesc:   description.Server{WireVersion: &description.VersionRange{Max: 6}},
+				rReadWM: compressWireMessage(t, reply, wiremessage.CompressorZLib),
+			},
+			t: t,
+		}
+		d := new(mockDeployment)
+		d.returns.server = mockServer{conn: conn}
+		comment := ""
+		op := Operation{
+			CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+				dst = bsoncore.AppendStringElement(dst, "insert", "bar")
+				return bsoncore.AppendStringElement(dst, "comment", comment), nil
+			},
+			Database:   "foo",
+			Deployment: d,
+		}
+
+		err := op.Execute(context.Background(), nil)
+		noerr(t, err)
+		if conn.compressed != 0 {
+			t.Errorf("Small commands should not be compressed. got %d compressed messages", conn.compressed)
+		}
+
+		comment = strings.Repeat("x", compressionThreshold)
+		err = op.Execute(context.Background(), nil)
+		noerr(t, err)
+		if conn.compressed != 1 {
+			t.Errorf("Large commands should be compressed. got %d compressed messages; want %d", conn.compressed, 1)
+		}
+		_, _, _, opcode, _, _ := wiremessagex.ReadHeader(conn.pWriteWM)
+		if opcode != wiremessage.OpCompressed {
+			t.Errorf("Expected an OP_COMPRESSED wire message to be written. got %v", opcode)
+		}
+	})
+}
+
+// compressWireMessage wraps wm in an OP_COMPRESSED wire message using the given compressor.
+func compressWireMessage(t *testing.T, wm []byte, id wiremessage.CompressorID) []byte {
+	t.Helper()
+	_, reqid, respto, opcode, rem, ok := wiremessagex.ReadHeader(wm)
+	if !ok {
+		t.Fatal("Could not read wire message header")
+	}
+	compressed, err := CompressPayload(rem, CompressionOpts{Compressor: id, ZlibLevel: wiremessage.DefaultZlibLevel})
+	noerr(t, err)
+	idx, dst := wiremessagex.AppendHeaderStart(nil, reqid, respto, wiremessage.OpCompressed)
+	dst = wiremessagex.AppendCompressedOriginalOpCode(dst, opcode)
+	dst = wiremessagex.AppendCompressedUncompressedSize(dst, int32(len(rem)))
+	dst = wiremessagex.AppendCompressedCompressorID(dst, id)
+	dst = wiremessagex.AppendCompressedCompressedMessage(dst, compressed)
+	return bsoncore.UpdateLength(dst, idx, int32(len(dst[idx:])))
+}
+
+// compressingConnection is a mockConnection that implements Compressor using zlib.
+type compressingConnection struct {
+	*mockConnection
+	t          *testing.T
+	compressed int
+}
+
+func (c *compressingConnection) CompressWireMessage(src, dst []byte) ([]byte, error) {
+	c.compressed++
+	return append(dst, compressWireMessage(c.t, src, wiremessage.CompressorZLib)...), nil
+}
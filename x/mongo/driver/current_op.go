@@ -0,0 +1,165 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// CurrentOpOperation lists the operations in progress on a server. Servers from 3.6 are sent an
+// aggregation with a $currentOp stage, which returns a cursor and so is not limited by the maximum
+// reply size; older servers are sent the currentOp command.
+type CurrentOpOperation struct {
+	filter bsoncore.Document
+	clock  *session.ClusterClock
+	client *session.Client
+
+	d Deployment
+
+	aggregate  bool
+	operations []bsoncore.Document
+}
+
+// CurrentOp constructs a CurrentOpOperation. If filter is not empty only the operations matching
+// it are returned.
+func CurrentOp(filter bsoncore.Document) *CurrentOpOperation {
+	return &CurrentOpOperation{filter: filter}
+}
+
+// Clock sets the cluster clock for this operation.
+func (coo *CurrentOpOperation) Clock(clock *session.ClusterClock) *CurrentOpOperation {
+	coo.clock = clock
+	return coo
+}
+
+// Session sets the session for this operation.
+func (coo *CurrentOpOperation) Session(client *session.Client) *CurrentOpOperation {
+	coo.client = client
+	return coo
+}
+
+// Deployment sets the Deployment for this operation.
+func (coo *CurrentOpOperation) Deployment(d Deployment) *CurrentOpOperation {
+	coo.d = d
+	return coo
+}
+
+// Operations returns the operations listed by the last successful Execute.
+func (coo *CurrentOpOperation) Operations() []bsoncore.Document { return coo.operations }
+
+func (coo *CurrentOpOperation) command(dst []byte, desc description.SelectedServer) ([]byte, error) {
+	// The $currentOp stage was added in 3.6, which is wire version 6.
+	coo.aggregate = desc.WireVersion != nil && desc.WireVersion.Max >= 6
+	if !coo.aggregate {
+		dst = bsoncore.AppendInt32Element(dst, "currentOp", 1)
+		if len(coo.filter) == 0 {
+			return dst, nil
+		}
+		elems, err := coo.filter.Elements()
+		if err != nil {
+			return dst, err
+		}
+		for _, elem := range elems {
+			dst = append(dst, elem...)
+		}
+		return dst, nil
+	}
+
+	dst = bsoncore.AppendInt32Element(dst, "aggregate", 1)
+	var idx, stage int32
+	idx, dst = bsoncore.AppendArrayElementStart(dst, "pipeline")
+	stage, dst = bsoncore.AppendDocumentElementStart(dst, "0")
+	dst = bsoncore.AppendDocumentElement(dst, "$currentOp", bsoncore.BuildDocumentFromElements(nil))
+	dst, _ = bsoncore.AppendDocumentEnd(dst, stage)
+	if len(coo.filter) > 0 {
+		stage, dst = bsoncore.AppendDocumentElementStart(dst, "1")
+		dst = bsoncore.AppendDocumentElement(dst, "$match", coo.filter)
+		dst, _ = bsoncore.AppendDocumentEnd(dst, stage)
+	}
+	dst, _ = bsoncore.AppendArrayEnd(dst, idx)
+	return bsoncore.AppendDocumentElement(dst, "cursor", bsoncore.BuildDocumentFromElements(nil)), nil
+}
+
+// appendOperations appends the documents in the array under key in response to the operations.
+func (coo *CurrentOpOperation) appendOperations(response bsoncore.Document, key ...string) error {
+	arr, ok := response.Lookup(key...).ArrayOK()
+	if !ok {
+		return fmt.Errorf("currentOp reply does not contain a %s array", strings.Join(key, "."))
+	}
+	vals, err := arr.Values()
+	if err != nil {
+		return err
+	}
+	for _, val := range vals {
+		if val.Type != bsontype.EmbeddedDocument {
+			return fmt.Errorf("currentOp reply contains a %s instead of an operation document", val.Type)
+		}
+		coo.operations = append(coo.operations, val.Document())
+	}
+	return nil
+}
+
+// Execute runs this operation. When the aggregation form is used, the cursor it returns is
+// exhausted with getMore commands against the same server.
+func (coo *CurrentOpOperation) Execute(ctx context.Context) error {
+	if coo.d == nil {
+		return errors.New("a CurrentOpOperation must have a Deployment set before Execute can be called")
+	}
+
+	coo.operations = nil
+	var cursorID int64
+	var ns string
+	var srvr Server
+	err := Operation{
+		CommandFn:  coo.command,
+		Database:   "admin",
+		Deployment: coo.d,
+		Clock:      coo.clock,
+		Client:     coo.client,
+		ProcessResponseFn: func(response bsoncore.Document, s Server) error {
+			if !coo.aggregate {
+				return coo.appendOperations(response, "inprog")
+			}
+			cursorID, _ = response.Lookup("cursor", "id").Int64OK()
+			ns, _ = response.Lookup("cursor", "ns").StringValueOK()
+			srvr = s
+			return coo.appendOperations(response, "cursor", "firstBatch")
+		},
+	}.Execute(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for cursorID != 0 {
+		dot := strings.Index(ns, ".")
+		if dot < 0 {
+			return fmt.Errorf("currentOp reply contains an invalid cursor namespace %q", ns)
+		}
+		id, coll := cursorID, ns[dot+1:]
+		err = Operation{
+			CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+				dst = bsoncore.AppendInt64Element(dst, "getMore", id)
+				return bsoncore.AppendStringElement(dst, "collection", coll), nil
+			},
+			Database:   ns[:dot],
+			Deployment: coo.d,
+			Server:     srvr,
+			Clock:      coo.clock,
+			Client:     coo.client,
+			ProcessResponseFn: func(response bsoncore.Document, _ Server) error {
+				cursorID, _ = response.Lookup("cursor", "id").Int64OK()
+				return coo.appendOperations(response, "cursor", "nextBatch")
+			},
+		}.Execute(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
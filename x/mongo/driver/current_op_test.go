@@ -0,0 +1,140 @@
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	wiremessagex "github.com/lakshay2395/mongo-go-driver/x/mongo/driver/wiremessage"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+func TestCurrentOp(t *testing.T) {
+	filter := bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendBooleanElement(nil, "active", true))
+	opDoc := func(opid int32) bsoncore.Document {
+		return bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "opid", opid))
+	}
+	cursorReply := func(id int64, batch string, ops ...bsoncore.Document) []byte {
+		vals := make([]bsoncore.Value, 0, len(ops))
+		for _, op := range ops {
+			vals = append(vals, bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: op})
+		}
+		return opMsgReply(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendDocumentElement(nil, "cursor", bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt64Element(nil, "id", id),
+				bsoncore.AppendStringElement(nil, "ns", "admin.$cmd.aggregate"),
+				bsoncore.BuildArrayElement(nil, batch, vals...),
+			)),
+			bsoncore.AppendDoubleElement(nil, "ok", 1),
+		))
+	}
+	deployment := func(maxWireVersion int32, replies ...[]byte) (*mockDeployment, *mockConnection) {
+		conn := &mockConnection{
+			rDesc:    description.Server{Kind: description.Standalone, WireVersion: &description.VersionRange{Max: maxWireVersion}},
+			rReadWMs: replies,
+		}
+		d := new(mockDeployment)
+		d.returns.server = SingleConnectionDeployment{C: conn}
+		d.returns.kind = description.Single
+		return d, conn
+	}
+
+	t.Run("aggregation on 3.6", func(t *testing.T) {
+		d, conn := deployment(6,
+			cursorReply(42, "firstBatch", opDoc(1)),
+			cursorReply(0, "nextBatch", opDoc(2)),
+		)
+		op := CurrentOp(filter).Deployment(d)
+		noerr(t, op.Execute(context.Background()))
+
+		if len(conn.pWriteWMs) != 2 {
+			t.Fatalf("Expected an aggregate and a getMore to be sent. got %d commands", len(conn.pWriteWMs))
+		}
+		cmd := msgCommand(t, conn.pWriteWMs[0])
+		if db, _ := cmd.Lookup("$db").StringValueOK(); db != "admin" {
+			t.Errorf("Expected the command to run on admin. got %q", db)
+		}
+		if v, ok := cmd.Lookup("aggregate").Int32OK(); !ok || v != 1 {
+			t.Errorf("Expected aggregate: 1. got %v", cmd)
+		}
+		if _, err := cmd.LookupErr("pipeline", "0", "$currentOp"); err != nil {
+			t.Errorf("Expected the pipeline to start with $currentOp. got %v", cmd)
+		}
+		if match, ok := cmd.Lookup("pipeline", "1", "$match").DocumentOK(); !ok || !match.Lookup("active").Boolean() {
+			t.Errorf("Expected the filter as a $match stage. got %v", cmd)
+		}
+
+		getMore := msgCommand(t, conn.pWriteWMs[1])
+		if id, ok := getMore.Lookup("getMore").Int64OK(); !ok || id != 42 {
+			t.Errorf("Expected getMore: 42. got %v", getMore)
+		}
+		if coll, _ := getMore.Lookup("collection").StringValueOK(); coll != "$cmd.aggregate" {
+			t.Errorf("Expected collection: %q. got %v", "$cmd.aggregate", getMore)
+		}
+
+		ops := op.Operations()
+		if len(ops) != 2 || ops[0].Lookup("opid").Int32() != 1 || ops[1].Lookup("opid").Int32() != 2 {
+			t.Errorf("Expected the operations from both batches. got %v", ops)
+		}
+	})
+	t.Run("command before 3.6", func(t *testing.T) {
+		d, conn := deployment(5, opMsgReply(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.BuildArrayElement(nil, "inprog", bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: opDoc(1)}),
+			bsoncore.AppendDoubleElement(nil, "ok", 1),
+		)))
+		op := CurrentOp(filter).Deployment(d)
+		noerr(t, op.Execute(context.Background()))
+
+		cmd, coll := queryCommand(t, conn.pWriteWM)
+		if coll != "admin.$cmd" {
+			t.Errorf("Expected the command to run on admin. got %q", coll)
+		}
+		elems, err := cmd.Elements()
+		noerr(t, err)
+		if v, ok := elems[0].Value().Int32OK(); elems[0].Key() != "currentOp" || !ok || v != 1 {
+			t.Errorf("Expected the command to start with currentOp: 1. got %v", cmd)
+		}
+		if active, ok := cmd.Lookup("active").BooleanOK(); !ok || !active {
+			t.Errorf("Expected the filter to be merged into the command. got %v", cmd)
+		}
+		if _, err := cmd.LookupErr("pipeline"); err == nil {
+			t.Errorf("Expected no pipeline. got %v", cmd)
+		}
+		if ops := op.Operations(); len(ops) != 1 || ops[0].Lookup("opid").Int32() != 1 {
+			t.Errorf("Expected the inprog operations. got %v", ops)
+		}
+	})
+	t.Run("no filter", func(t *testing.T) {
+		d, conn := deployment(6, cursorReply(0, "firstBatch"))
+		op := CurrentOp(nil).Deployment(d)
+		noerr(t, op.Execute(context.Background()))
+
+		cmd := msgCommand(t, conn.pWriteWM)
+		if _, err := cmd.LookupErr("pipeline", "1"); err == nil {
+			t.Errorf("Expected no $match stage. got %v", cmd)
+		}
+		if len(op.Operations()) != 0 {
+			t.Errorf("Expected no operations. got %v", op.Operations())
+		}
+	})
+}
+
+// queryCommand returns the command document and full collection name of an OP_QUERY wire message.
+func queryCommand(t *testing.T, wm []byte) (bsoncore.Document, string) {
+	t.Helper()
+	_, _, _, opcode, rem, ok := wiremessagex.ReadHeader(wm)
+	if !ok || opcode != wiremessage.OpQuery {
+		t.Fatalf("Could not read OP_QUERY header")
+	}
+	_, rem, _ = wiremessagex.ReadQueryFlags(rem)
+	coll, rem, _ := wiremessagex.ReadQueryFullCollectionName(rem)
+	_, rem, _ = wiremessagex.ReadQueryNumberToSkip(rem)
+	_, rem, _ = wiremessagex.ReadQueryNumberToReturn(rem)
+	cmd, _, ok := wiremessagex.ReadQueryQuery(rem)
+	if !ok {
+		t.Fatalf("Could not read OP_QUERY query")
+	}
+	return cmd, coll
+}
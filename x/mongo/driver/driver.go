@@ -2,6 +2,7 @@ package driver // import "github.com/lakshay2395/mongo-go-driver/x/mongo/driver"
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/lakshay2395/mongo-go-driver/x/network/address"
 	"github.com/lakshay2395/mongo-go-driver/x/network/description"
@@ -14,6 +15,14 @@ type Deployment interface {
 	Kind() description.TopologyKind
 }
 
+// TopologyChangeNotifier is implemented by Deployments that can signal when their topology changes.
+// When a Deployment's SelectServer method returns ErrNoSuitableServer, Operation.Execute waits for
+// a value on the channel returned by TopologyChanged before selecting again. Deployments that do not
+// implement this interface are polled instead.
+type TopologyChangeNotifier interface {
+	TopologyChanged() <-chan struct{}
+}
+
 // Server represents a MongoDB server. Implementations should pool connections and handle the
 // retrieving and returning of connections.
 type Server interface {
@@ -39,9 +48,9 @@ type Compressor interface {
 
 // ErrorProcessor implementations can handle processing errors, which may modify their internal state.
 // If this type is implemented by a Server, then Operation.Execute will call it's ProcessError
-// method after it decodes a wire message.
+// method after it decodes a wire message, passing the Connection the error occurred on.
 type ErrorProcessor interface {
-	ProcessError(error)
+	ProcessError(err error, conn Connection)
 }
 
 // Handshaker is the interface implemented by types that can perform a MongoDB
@@ -125,11 +134,26 @@ type RetryType uint
 
 // THese are the availables types of retry.
 const (
-	_ RetryType = iota
+	// RetryTypeNone means the operation is not retried.
+	RetryTypeNone RetryType = iota
 	RetryWrite
 	RetryRead
 )
 
+// String implements the fmt.Stringer interface.
+func (rt RetryType) String() string {
+	switch rt {
+	case RetryTypeNone:
+		return "None"
+	case RetryWrite:
+		return "RetryWrite"
+	case RetryRead:
+		return "RetryRead"
+	default:
+		return fmt.Sprintf("RetryType(%d)", uint(rt))
+	}
+}
+
 // RetryMode specifies the way that retries are handled for retryable operations.
 type RetryMode uint
 
@@ -148,7 +172,90 @@ const (
 	RetryContext
 )
 
+// String implements the fmt.Stringer interface.
+func (rm RetryMode) String() string {
+	switch rm {
+	case RetryNone:
+		return "RetryNone"
+	case RetryOnce:
+		return "RetryOnce"
+	case RetryOncePerCommand:
+		return "RetryOncePerCommand"
+	case RetryContext:
+		return "RetryContext"
+	default:
+		return fmt.Sprintf("RetryMode(%d)", uint(rm))
+	}
+}
+
 // Enabled returns if this RetryMode enables retrying.
 func (rm RetryMode) Enabled() bool {
 	return rm == RetryOnce || rm == RetryOncePerCommand || rm == RetryContext
 }
+
+// StandaloneMajority specifies how a majority write concern is handled when an operation is sent to
+// a standalone server, which has no replica set members to acknowledge the write.
+type StandaloneMajority uint
+
+// These are the ways a majority write concern can be handled on a standalone server.
+const (
+	// StandaloneMajorityPassThrough sends the majority write concern to the server unchanged.
+	StandaloneMajorityPassThrough StandaloneMajority = iota
+	// StandaloneMajorityDowngrade removes w from the write concern so the server uses its default.
+	// Any j or wtimeout values are still sent.
+	StandaloneMajorityDowngrade
+	// StandaloneMajorityError returns ErrMajorityOnStandalone instead of sending the operation.
+	StandaloneMajorityError
+)
+
+// String implements the fmt.Stringer interface.
+func (sm StandaloneMajority) String() string {
+	switch sm {
+	case StandaloneMajorityPassThrough:
+		return "StandaloneMajorityPassThrough"
+	case StandaloneMajorityDowngrade:
+		return "StandaloneMajorityDowngrade"
+	case StandaloneMajorityError:
+		return "StandaloneMajorityError"
+	default:
+		return fmt.Sprintf("StandaloneMajority(%d)", uint(sm))
+	}
+}
+
+// RetryObserver is called after each retry attempt an Operation makes. The attempt number starts at
+// 1 for the first retry, err is the error that caused the operation to be retried, and success
+// reports whether the retry attempt succeeded.
+type RetryObserver func(attempt int, err error, success bool)
+
+// ServerAPIOptions represents the Stable API configuration that is sent with every command. When
+// ServerAPIVersion is empty no Stable API fields are sent.
+type ServerAPIOptions struct {
+	// ServerAPIVersion is the value of the apiVersion field.
+	ServerAPIVersion string
+	// Strict is the value of the apiStrict field. If nil, apiStrict is not sent.
+	Strict *bool
+	// DeprecationErrors is the value of the apiDeprecationErrors field. If nil,
+	// apiDeprecationErrors is not sent.
+	DeprecationErrors *bool
+}
+
+// NewServerAPIOptions creates a ServerAPIOptions for the given API version. Neither apiStrict nor
+// apiDeprecationErrors is sent until it is set.
+func NewServerAPIOptions(version string) *ServerAPIOptions {
+	return &ServerAPIOptions{ServerAPIVersion: version}
+}
+
+// SetStrict sets whether the server rejects commands that are not part of the declared API
+// version. It is independent of SetDeprecationErrors.
+func (s *ServerAPIOptions) SetStrict(strict bool) *ServerAPIOptions {
+	s.Strict = &strict
+	return s
+}
+
+// SetDeprecationErrors sets whether the server returns an error for deprecated commands and
+// behaviors. This is useful in testing, but is typically left unset in production. It is
+// independent of SetStrict.
+func (s *ServerAPIOptions) SetDeprecationErrors(deprecationErrors bool) *ServerAPIOptions {
+	s.DeprecationErrors = &deprecationErrors
+	return s
+}
@@ -0,0 +1,55 @@
+package driver
+
+import "testing"
+
+func TestRetryTypeString(t *testing.T) {
+	testCases := []struct {
+		rt   RetryType
+		want string
+	}{
+		{RetryTypeNone, "None"},
+		{RetryWrite, "RetryWrite"},
+		{RetryRead, "RetryRead"},
+		{RetryType(42), "RetryType(42)"},
+	}
+	for _, tc := range testCases {
+		if got := tc.rt.String(); got != tc.want {
+			t.Errorf("RetryType strings do not match. got %q; want %q", got, tc.want)
+		}
+	}
+}
+
+func TestRetryModeString(t *testing.T) {
+	testCases := []struct {
+		rm   RetryMode
+		want string
+	}{
+		{RetryNone, "RetryNone"},
+		{RetryOnce, "RetryOnce"},
+		{RetryOncePerCommand, "RetryOncePerCommand"},
+		{RetryContext, "RetryContext"},
+		{RetryMode(42), "RetryMode(42)"},
+	}
+	for _, tc := range testCases {
+		if got := tc.rm.String(); got != tc.want {
+			t.Errorf("RetryMode strings do not match. got %q; want %q", got, tc.want)
+		}
+	}
+}
+
+func TestStandaloneMajorityString(t *testing.T) {
+	testCases := []struct {
+		sm   StandaloneMajority
+		want string
+	}{
+		{StandaloneMajorityPassThrough, "StandaloneMajorityPassThrough"},
+		{StandaloneMajorityDowngrade, "StandaloneMajorityDowngrade"},
+		{StandaloneMajorityError, "StandaloneMajorityError"},
+		{StandaloneMajority(42), "StandaloneMajority(42)"},
+	}
+	for _, tc := range testCases {
+		if got := tc.sm.String(); got != tc.want {
+			t.Errorf("StandaloneMajority strings do not match. got %q; want %q", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package drivertest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driver"
+	wiremessagex "github.com/lakshay2395/mongo-go-driver/x/mongo/driver/wiremessage"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+// ErrNoReply is returned by MockConnection.ReadWireMessage when there are no replies left to read.
+var ErrNoReply = errors.New("no reply left to read")
+
+// MockDeployment implements the driver.Deployment interface by returning a configured server from
+// SelectServer. The selectors passed to SelectServer are recorded.
+type MockDeployment struct {
+	Server       driver.Server
+	SelectErr    error
+	Retry        bool
+	TopologyKind description.TopologyKind
+
+	mu        sync.Mutex
+	selectors []description.ServerSelector
+}
+
+var _ driver.Deployment = (*MockDeployment)(nil)
+
+// SelectServer implements the driver.Deployment interface.
+func (m *MockDeployment) SelectServer(_ context.Context, selector description.ServerSelector) (driver.Server, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.selectors = append(m.selectors, selector)
+	return m.Server, m.SelectErr
+}
+
+// SupportsRetry implements the driver.Deployment interface.
+func (m *MockDeployment) SupportsRetry() bool { return m.Retry }
+
+// Kind implements the driver.Deployment interface.
+func (m *MockDeployment) Kind() description.TopologyKind { return m.TopologyKind }
+
+// Selectors returns the selectors passed to SelectServer, in order.
+func (m *MockDeployment) Selectors() []description.ServerSelector {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]description.ServerSelector(nil), m.selectors...)
+}
+
+// MockServer implements the driver.Server interface by returning a configured connection.
+type MockServer struct {
+	Conn driver.Connection
+	Err  error
+}
+
+var _ driver.Server = MockServer{}
+
+// Connection implements the driver.Server interface.
+func (m MockServer) Connection(context.Context) (driver.Connection, error) { return m.Conn, m.Err }
+
+// MockServerSelector implements the description.ServerSelector interface. If Servers is nil the
+// candidates are returned unchanged, otherwise Servers is returned.
+type MockServerSelector struct {
+	Servers []description.Server
+	Err     error
+}
+
+var _ description.ServerSelector = MockServerSelector{}
+
+// SelectServer implements the description.ServerSelector interface.
+func (m MockServerSelector) SelectServer(_ description.Topology, candidates []description.Server) ([]description.Server, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if m.Servers == nil {
+		return candidates, nil
+	}
+	return m.Servers, nil
+}
+
+// MockConnection implements the driver.Connection interface. Written wire messages are recorded and
+// Replies are returned in order by successive reads.
+type MockConnection struct {
+	Replies  [][]byte
+	WriteErr error
+	ReadErr  error
+	CloseErr error
+	Desc     description.Server
+	ConnID   string
+	Addr     address.Address
+
+	mu      sync.Mutex
+	written [][]byte
+}
+
+var _ driver.Connection = (*MockConnection)(nil)
+
+// WriteWireMessage implements the driver.Connection interface.
+func (m *MockConnection) WriteWireMessage(_ context.Context, wm []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.written = append(m.written, append([]byte(nil), wm...))
+	return m.WriteErr
+}
+
+// ReadWireMessage implements the driver.Connection interface.
+func (m *MockConnection) ReadWireMessage(context.Context, []byte) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ReadErr != nil {
+		return nil, m.ReadErr
+	}
+	if len(m.Replies) == 0 {
+		return nil, ErrNoReply
+	}
+	wm := m.Replies[0]
+	m.Replies = m.Replies[1:]
+	return wm, nil
+}
+
+// Written returns the wire messages written to the connection, in order.
+func (m *MockConnection) Written() [][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([][]byte(nil), m.written...)
+}
+
+// Description implements the driver.Connection interface.
+func (m *MockConnection) Description() description.Server { return m.Desc }
+
+// Close implements the driver.Connection interface.
+func (m *MockConnection) Close() error { return m.CloseErr }
+
+// ID implements the driver.Connection interface.
+func (m *MockConnection) ID() string { return m.ConnID }
+
+// Address implements the driver.Connection interface.
+func (m *MockConnection) Address() address.Address { return m.Addr }
+
+// MakeMsgReply creates an OP_MSG wiremessage with doc as its single document section.
+func MakeMsgReply(doc bsoncore.Document) []byte {
+	var dst []byte
+	idx, dst := wiremessagex.AppendHeaderStart(dst, 10, 9, wiremessage.OpMsg)
+	dst = wiremessagex.AppendMsgFlags(dst, 0)
+	dst = wiremessagex.AppendMsgSectionType(dst, wiremessage.SingleDocument)
+	dst = append(dst, doc...)
+	return bsoncore.UpdateLength(dst, idx, int32(len(dst[idx:])))
+}
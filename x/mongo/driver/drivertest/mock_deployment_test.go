@@ -0,0 +1,124 @@
+package drivertest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driver"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driver/drivertest"
+	wiremessagex "github.com/lakshay2395/mongo-go-driver/x/mongo/driver/wiremessage"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+// countOperation is an operation built outside of the driver package, as a user of the driver would.
+func countOperation(d driver.Deployment, selector description.ServerSelector) (int32, error) {
+	var n int32
+	err := driver.Operation{
+		CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+			return bsoncore.AppendStringElement(dst, "count", "bar"), nil
+		},
+		ProcessResponseFn: func(response bsoncore.Document, _ driver.Server) error {
+			n, _ = response.Lookup("n").Int32OK()
+			return nil
+		},
+		Database:   "foo",
+		Deployment: d,
+		Selector:   selector,
+	}.Execute(context.Background(), nil)
+	return n, err
+}
+
+func TestMockDeployment(t *testing.T) {
+	t.Run("OP_MSG", func(t *testing.T) {
+		reply := bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendDoubleElement(nil, "ok", 1),
+			bsoncore.AppendInt32Element(nil, "n", 42),
+		)
+		conn := &drivertest.MockConnection{
+			Replies: [][]byte{drivertest.MakeMsgReply(reply)},
+			Desc:    description.Server{WireVersion: &description.VersionRange{Max: 6}},
+		}
+		selector := drivertest.MockServerSelector{}
+		d := &drivertest.MockDeployment{Server: drivertest.MockServer{Conn: conn}, TopologyKind: description.Single}
+
+		n, err := countOperation(d, selector)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != 42 {
+			t.Errorf("unexpected count. got %d; want %d", n, 42)
+		}
+		if selectors := d.Selectors(); len(selectors) != 1 {
+			t.Errorf("expected 1 server selection, got %d", len(selectors))
+		}
+
+		written := conn.Written()
+		if len(written) != 1 {
+			t.Fatalf("expected 1 wire message to be written, got %d", len(written))
+		}
+		_, _, _, opcode, rem, ok := wiremessagex.ReadHeader(written[0])
+		if !ok || opcode != wiremessage.OpMsg {
+			t.Fatalf("expected an OP_MSG to be written, got %v", opcode)
+		}
+		_, rem, _ = wiremessagex.ReadMsgFlags(rem)
+		_, rem, _ = wiremessagex.ReadMsgSectionType(rem)
+		cmd, _, ok := wiremessagex.ReadMsgSectionSingleDocument(rem)
+		if !ok {
+			t.Fatal("could not read command document")
+		}
+		if coll, ok := cmd.Lookup("count").StringValueOK(); !ok || coll != "bar" {
+			t.Errorf("unexpected command sent: %v", cmd)
+		}
+		if db, ok := cmd.Lookup("$db").StringValueOK(); !ok || db != "foo" {
+			t.Errorf("unexpected $db. got %q; want %q", db, "foo")
+		}
+	})
+	t.Run("OP_QUERY", func(t *testing.T) {
+		reply := bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendDoubleElement(nil, "ok", 1),
+			bsoncore.AppendInt32Element(nil, "n", 7),
+		)
+		conn := &drivertest.MockConnection{Replies: [][]byte{drivertest.MakeReply(reply)}}
+		d := &drivertest.MockDeployment{Server: drivertest.MockServer{Conn: conn}, TopologyKind: description.Single}
+
+		n, err := countOperation(d, drivertest.MockServerSelector{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != 7 {
+			t.Errorf("unexpected count. got %d; want %d", n, 7)
+		}
+	})
+	t.Run("server error", func(t *testing.T) {
+		reply := bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendDoubleElement(nil, "ok", 0),
+			bsoncore.AppendInt32Element(nil, "code", 26),
+			bsoncore.AppendStringElement(nil, "errmsg", "ns not found"),
+		)
+		conn := &drivertest.MockConnection{Replies: [][]byte{drivertest.MakeReply(reply)}}
+		d := &drivertest.MockDeployment{Server: drivertest.MockServer{Conn: conn}, TopologyKind: description.Single}
+
+		_, err := countOperation(d, drivertest.MockServerSelector{})
+		derr, ok := err.(driver.Error)
+		if !ok {
+			t.Fatalf("expected a driver.Error, got %T: %v", err, err)
+		}
+		if derr.Code != 26 {
+			t.Errorf("unexpected error code. got %d; want %d", derr.Code, 26)
+		}
+	})
+	t.Run("MockServerSelector", func(t *testing.T) {
+		candidates := []description.Server{{Addr: "a"}, {Addr: "b"}}
+		got, err := drivertest.MockServerSelector{}.SelectServer(description.Topology{}, candidates)
+		if err != nil || len(got) != 2 {
+			t.Errorf("expected candidates to be returned unchanged, got %v, %v", got, err)
+		}
+		want := []description.Server{{Addr: "c"}}
+		got, err = drivertest.MockServerSelector{Servers: want}.SelectServer(description.Topology{}, candidates)
+		if err != nil || len(got) != 1 || got[0].Addr != "c" {
+			t.Errorf("expected configured servers to be returned, got %v, %v", got, err)
+		}
+	})
+}
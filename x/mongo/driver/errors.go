@@ -2,17 +2,46 @@ package driver
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
 	"strings"
+	"syscall"
 
 	"github.com/lakshay2395/mongo-go-driver/bson"
 	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
 )
 
+// ErrUnacknowledgedWrite is returned from functions that have an unacknowledged write concern.
+var ErrUnacknowledgedWrite = errors.New("unacknowledged write")
+
+// ErrNoSuitableServer is returned by a Deployment's SelectServer method when no server currently
+// matches the selector. Operation.Execute retries server selection when it sees this error.
+var ErrNoSuitableServer = errors.New("no suitable server")
+
+// ErrNoStaticServer is returned by StaticDeployment's SelectServer method when none of its servers
+// match the selector. Unlike ErrNoSuitableServer it is not retried, because the servers never change.
+var ErrNoStaticServer = errors.New("no server in the static deployment matches the selector")
+
+// ErrServerSelectionTimeout is returned when no suitable server is found before the server
+// selection timeout elapses.
+var ErrServerSelectionTimeout = errors.New("server selection timeout")
+
+// ErrMajorityOnStandalone is returned when an operation with a majority write concern would be sent
+// to a standalone server and the operation's StandaloneMajority is StandaloneMajorityError.
+var ErrMajorityOnStandalone = errors.New("a majority write concern cannot be used with a standalone server")
+
 var (
-	retryableCodes        = []int32{11600, 11602, 10107, 13435, 13436, 189, 91, 7, 6, 89, 9001}
-	nodeIsRecoveringCodes = []int32{11600, 11602, 13436, 189, 91}
-	notMasterCodes        = []int32{10107, 13435}
+	retryableCodes          = []int32{11600, 11602, 10107, 13435, 13436, 189, 91, 7, 6, 89, 9001}
+	nodeIsRecoveringCodes   = []int32{11600, 11602, 13436, 189, 91}
+	notMasterCodes          = []int32{10107, 13435}
+	nodeIsShuttingDownCodes = []int32{11600, 91}
 )
 
 var (
@@ -22,6 +51,114 @@ var (
 	NetworkError = "NetworkError"
 )
 
+// ErrorOrigin identifies where an Error originated, so that retry logic and callers can tell server
+// failures apart from failures that happened before or while talking to the server.
+type ErrorOrigin uint8
+
+// These constants are the possible origins of an Error. OriginUnknown is the zero value and is used
+// for errors that were not created by the driver's round trip or response processing.
+const (
+	OriginUnknown ErrorOrigin = iota
+	OriginServer
+	OriginNetwork
+	OriginClient
+	OriginTimeout
+)
+
+// String implements the fmt.Stringer interface.
+func (eo ErrorOrigin) String() string {
+	switch eo {
+	case OriginServer:
+		return "server"
+	case OriginNetwork:
+		return "network"
+	case OriginClient:
+		return "client"
+	case OriginTimeout:
+		return "timeout"
+	default:
+		return "unknown"
+	}
+}
+
+// OriginOf returns the origin of err. An Error reports its own Origin, failures returned by the
+// server as write errors or as a query failure originate from the server, and an invalid Operation
+// originates from the client.
+func OriginOf(err error) ErrorOrigin {
+	switch e := err.(type) {
+	case Error:
+		return e.Origin
+	case WriteCommandError, WriteConcernError, WriteError, WriteErrors, QueryFailureError:
+		return OriginServer
+	case InvalidOperationError:
+		return OriginClient
+	}
+	return OriginUnknown
+}
+
+// newNetworkError wraps an error returned while writing a wire message to or reading a wire message
+// from a connection.
+func newNetworkError(err error) Error {
+	return Error{Message: err.Error(), Labels: networkErrorLabels(err), Origin: networkErrorOrigin(err)}
+}
+
+// networkErrorOrigin returns OriginTimeout if err, or an error it wraps, is a timeout or an expired
+// context deadline and OriginNetwork otherwise.
+func networkErrorOrigin(err error) ErrorOrigin {
+	for err != nil {
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			return OriginTimeout
+		}
+		if err == context.DeadlineExceeded {
+			return OriginTimeout
+		}
+		err = unwrapNetworkError(err)
+	}
+	return OriginNetwork
+}
+
+// networkErrorLabels returns the error labels for an error that occurred while writing a wire
+// message to or reading a wire message from a connection. Timeouts and cancellations are labeled
+// only as NetworkError since the server may still be running the command, connection resets and
+// unexpected EOFs are also labeled TransientTransactionError, and TLS certificate failures receive
+// no labels because retrying them cannot succeed. Errors that cannot be classified are treated as
+// transient network errors.
+func networkErrorLabels(err error) []string {
+	for err != nil {
+		switch e := err.(type) {
+		case x509.UnknownAuthorityError, x509.CertificateInvalidError, x509.HostnameError, tls.RecordHeaderError:
+			return nil
+		case net.Error:
+			if e.Timeout() {
+				return []string{NetworkError}
+			}
+		}
+
+		switch err {
+		case context.DeadlineExceeded, context.Canceled:
+			return []string{NetworkError}
+		case io.EOF, io.ErrUnexpectedEOF, syscall.ECONNRESET, syscall.EPIPE:
+			return []string{TransientTransactionError, NetworkError}
+		}
+
+		err = unwrapNetworkError(err)
+	}
+	return []string{TransientTransactionError, NetworkError}
+}
+
+// unwrapNetworkError returns the error wrapped by err, or nil if err does not wrap another error.
+func unwrapNetworkError(err error) error {
+	switch e := err.(type) {
+	case *net.OpError:
+		return e.Err
+	case *os.SyscallError:
+		return e.Err
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	}
+	return nil
+}
+
 // QueryFailureError is an error representing a command failure as a document.
 type QueryFailureError struct {
 	Message  string
@@ -104,6 +241,10 @@ type WriteError struct {
 	Index   int64
 	Code    int64
 	Message string
+
+	// Details is the errInfo document the server attached to the error, if any. Servers running
+	// MongoDB 5.0 and later use it to describe which rule a document failed validation against.
+	Details bsoncore.Document
 }
 
 func (we WriteError) Error() string { return we.Message }
@@ -131,6 +272,10 @@ type Error struct {
 	Message string
 	Labels  []string
 	Name    string
+	Origin  ErrorOrigin
+
+	// TopologyVersion is the topologyVersion reported by the server along with the error, if any.
+	TopologyVersion *description.TopologyVersion
 }
 
 // Error implements the error interface.
@@ -192,6 +337,17 @@ func (e Error) NodeIsRecovering() bool {
 	return strings.Contains(e.Message, "node is recovering")
 }
 
+// NodeIsShuttingDown returns true if this error indicates the server is shutting down, as opposed
+// to having only stepped down.
+func (e Error) NodeIsShuttingDown() bool {
+	for _, code := range nodeIsShuttingDownCodes {
+		if e.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
 // NotMaster returns true if this error is a not master error.
 func (e Error) NotMaster() bool {
 	for _, code := range notMasterCodes {
@@ -208,6 +364,7 @@ func extractError(rdr bsoncore.Document) error {
 	var errmsg, codeName string
 	var code int32
 	var labels []string
+	var tv *description.TopologyVersion
 	var ok bool
 	var wcError WriteCommandError
 	elems, err := rdr.Elements()
@@ -258,6 +415,10 @@ func extractError(rdr bsoncore.Document) error {
 				}
 
 			}
+		case "topologyVersion":
+			if doc, okay := elem.Value().DocumentOK(); okay {
+				tv = description.NewTopologyVersion(doc)
+			}
 		case "writeErrors":
 			arr, exists := elem.Value().ArrayOK()
 			if !exists {
@@ -279,9 +440,13 @@ func extractError(rdr bsoncore.Document) error {
 				if code, exists := doc.Lookup("code").AsInt64OK(); exists {
 					we.Code = code
 				}
-				if msg, exists := doc.Lookup("errMsg").StringValueOK(); exists {
+				if msg, exists := doc.Lookup("errmsg").StringValueOK(); exists {
 					we.Message = msg
 				}
+				if info, exists := doc.Lookup("errInfo").DocumentOK(); exists {
+					we.Details = make([]byte, len(info))
+					copy(we.Details, info)
+				}
 				wcError.WriteErrors = append(wcError.WriteErrors, we)
 			}
 		case "writeConcernError":
@@ -293,7 +458,7 @@ func extractError(rdr bsoncore.Document) error {
 			if code, exists := doc.Lookup("code").AsInt64OK(); exists {
 				wcError.WriteConcernError.Code = code
 			}
-			if msg, exists := doc.Lookup("errMsg").StringValueOK(); exists {
+			if msg, exists := doc.Lookup("errmsg").StringValueOK(); exists {
 				wcError.WriteConcernError.Message = msg
 			}
 			if info, exists := doc.Lookup("errInfo").DocumentOK(); exists {
@@ -309,10 +474,12 @@ func extractError(rdr bsoncore.Document) error {
 		}
 
 		return Error{
-			Code:    code,
-			Message: errmsg,
-			Name:    codeName,
-			Labels:  labels,
+			Code:            code,
+			Message:         errmsg,
+			Name:            codeName,
+			Labels:          labels,
+			Origin:          OriginServer,
+			TopologyVersion: tv,
 		}
 	}
 
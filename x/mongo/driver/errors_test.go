@@ -0,0 +1,186 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+type wrappedError struct{ err error }
+
+func (we wrappedError) Error() string { return "wrapped: " + we.err.Error() }
+func (we wrappedError) Unwrap() error { return we.err }
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestNetworkErrorLabels(t *testing.T) {
+	transient := []string{TransientTransactionError, NetworkError}
+	network := []string{NetworkError}
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+
+	testCases := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"unknown error", errors.New("boom"), transient},
+		{"io.EOF", io.EOF, transient},
+		{"io.ErrUnexpectedEOF", io.ErrUnexpectedEOF, transient},
+		{"connection reset", reset, transient},
+		{"broken pipe", syscall.EPIPE, transient},
+		{"deadline exceeded", context.DeadlineExceeded, network},
+		{"canceled", context.Canceled, network},
+		{"net timeout", &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}, network},
+		{"wrapped timeout", wrappedError{context.DeadlineExceeded}, network},
+		{"wrapped reset", wrappedError{reset}, transient},
+		{"unknown authority", x509.UnknownAuthorityError{}, nil},
+		{"wrapped hostname mismatch", wrappedError{x509.HostnameError{Host: "localhost"}}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := networkErrorLabels(tc.err)
+			if !cmp.Equal(got, tc.want) {
+				t.Errorf("Labels do not match. got %v; want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtractErrorLabels(t *testing.T) {
+	// A failed getMore inside a transaction reports its labels in the same way as any other command.
+	reply := bsoncore.BuildDocumentFromElements(nil,
+		bsoncore.AppendInt32Element(nil, "ok", 0),
+		bsoncore.AppendStringElement(nil, "errmsg", "cursor not found"),
+		bsoncore.AppendInt32Element(nil, "code", 43),
+		bsoncore.BuildArrayElement(nil, "errorLabels", bsoncore.Value{Type: bsontype.String, Data: bsoncore.AppendString(nil, TransientTransactionError)}),
+	)
+	err := extractError(reply)
+	derr, ok := err.(Error)
+	if !ok {
+		t.Fatalf("Expected an Error. got %T; want %T", err, Error{})
+	}
+	if !derr.HasErrorLabel(TransientTransactionError) {
+		t.Errorf("Expected error to have the %s label. got %v", TransientTransactionError, derr.Labels)
+	}
+}
+
+func TestExtractWriteErrorDetails(t *testing.T) {
+	errInfo := bsoncore.BuildDocumentFromElements(nil,
+		bsoncore.AppendStringElement(nil, "failingDocumentId", "abc"),
+		bsoncore.AppendDocumentElement(nil, "details", bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendStringElement(nil, "operatorName", "$jsonSchema"),
+		)),
+	)
+	reply := bsoncore.BuildDocumentFromElements(nil,
+		bsoncore.AppendInt32Element(nil, "ok", 1),
+		bsoncore.AppendInt32Element(nil, "n", 0),
+		bsoncore.BuildArrayElement(nil, "writeErrors",
+			bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "index", 0),
+				bsoncore.AppendInt32Element(nil, "code", 121),
+				bsoncore.AppendStringElement(nil, "errmsg", "Document failed validation"),
+				bsoncore.AppendDocumentElement(nil, "errInfo", errInfo),
+			)},
+			bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "index", 1),
+				bsoncore.AppendInt32Element(nil, "code", 11000),
+				bsoncore.AppendStringElement(nil, "errmsg", "E11000 duplicate key error"),
+			)},
+		),
+	)
+	err := extractError(reply)
+	wce, ok := err.(WriteCommandError)
+	if !ok {
+		t.Fatalf("Expected a WriteCommandError. got %T; want %T", err, WriteCommandError{})
+	}
+	if len(wce.WriteErrors) != 2 {
+		t.Fatalf("Expected 2 write errors. got %v", wce.WriteErrors)
+	}
+	if got := wce.WriteErrors[0]; got.Code != 121 || got.Message != "Document failed validation" {
+		t.Errorf("Unexpected write error. got %+v", got)
+	}
+	if !bytes.Equal(wce.WriteErrors[0].Details, errInfo) {
+		t.Errorf("errInfo was not preserved. got %v; want %v", wce.WriteErrors[0].Details, errInfo)
+	}
+	if wce.WriteErrors[1].Details != nil {
+		t.Errorf("Expected no details for a write error without errInfo. got %v", wce.WriteErrors[1].Details)
+	}
+}
+
+func TestErrorOrigin(t *testing.T) {
+	cmdFn := func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+		return bsoncore.AppendInt32Element(dst, "ping", 1), nil
+	}
+	execute := func(conn *mockConnection) error {
+		conn.rDesc = description.Server{WireVersion: &description.VersionRange{Max: 6}}
+		d := new(mockDeployment)
+		d.returns.server = SingleConnectionDeployment{C: conn}
+		return Operation{CommandFn: cmdFn, Deployment: d, Database: "admin"}.Execute(context.Background(), nil)
+	}
+
+	testCases := []struct {
+		name string
+		err  func() error
+		want ErrorOrigin
+	}{
+		{
+			"write failure",
+			func() error { return execute(&mockConnection{rWriteErr: errors.New("write error")}) },
+			OriginNetwork,
+		},
+		{
+			"read timeout",
+			func() error { return execute(&mockConnection{rReadErr: context.DeadlineExceeded}) },
+			OriginTimeout,
+		},
+		{
+			"server error",
+			func() error {
+				return execute(&mockConnection{rReadWM: opMsgReply(bsoncore.BuildDocumentFromElements(nil,
+					bsoncore.AppendInt32Element(nil, "ok", 0),
+					bsoncore.AppendStringElement(nil, "errmsg", "command failed"),
+					bsoncore.AppendInt32Element(nil, "code", 2),
+				))})
+			},
+			OriginServer,
+		},
+		{
+			"validation failure",
+			func() error { return Operation{CommandFn: cmdFn}.Execute(context.Background(), nil) },
+			OriginClient,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.err()
+			if err == nil {
+				t.Fatal("Expected an error but got nil")
+			}
+			if got := OriginOf(err); got != tc.want {
+				t.Errorf("Unexpected origin for %v. got %v; want %v", err, got, tc.want)
+			}
+		})
+	}
+	t.Run("unknown errors", func(t *testing.T) {
+		if got := OriginOf(errors.New("boom")); got != OriginUnknown {
+			t.Errorf("Unexpected origin. got %v; want %v", got, OriginUnknown)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package driver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// FsyncOperation runs the fsync command against a single server. With Lock set the server also
+// blocks writes until a matching FsyncUnlockOperation is run against the same node, which is how
+// backups take a consistent copy of its data files.
+type FsyncOperation struct {
+	lock  bool
+	addr  address.Address
+	clock *session.ClusterClock
+
+	d Deployment
+
+	lockCount int64
+}
+
+// Fsync constructs an FsyncOperation that flushes pending writes on the server at addr.
+func Fsync(addr address.Address) *FsyncOperation {
+	return &FsyncOperation{addr: addr}
+}
+
+// Lock sets whether the server should block writes after flushing.
+func (fo *FsyncOperation) Lock(lock bool) *FsyncOperation {
+	fo.lock = lock
+	return fo
+}
+
+// Clock sets the cluster clock for this operation.
+func (fo *FsyncOperation) Clock(clock *session.ClusterClock) *FsyncOperation {
+	fo.clock = clock
+	return fo
+}
+
+// Deployment sets the Deployment for this operation.
+func (fo *FsyncOperation) Deployment(d Deployment) *FsyncOperation {
+	fo.d = d
+	return fo
+}
+
+// LockCount returns the number of fsync locks held on the server after the last successful
+// Execute. Servers that do not report it leave this at zero.
+func (fo *FsyncOperation) LockCount() int64 { return fo.lockCount }
+
+func (fo *FsyncOperation) command(dst []byte, _ description.SelectedServer) ([]byte, error) {
+	dst = bsoncore.AppendInt32Element(dst, "fsync", 1)
+	if fo.lock {
+		dst = bsoncore.AppendBooleanElement(dst, "lock", true)
+	}
+	return dst, nil
+}
+
+func (fo *FsyncOperation) processResponse(response bsoncore.Document, _ Server) error {
+	fo.lockCount, _ = response.Lookup("lockCount").AsInt64OK()
+	return nil
+}
+
+// Execute runs this operation.
+func (fo *FsyncOperation) Execute(ctx context.Context) error {
+	if fo.d == nil {
+		return errors.New("an FsyncOperation must have a Deployment set before Execute can be called")
+	}
+
+	fo.lockCount = 0
+	return runOnServer(ctx, fo.d, "admin", fo.addr, fo.clock, fo.command, fo.processResponse)
+}
+
+// FsyncUnlockOperation runs the fsyncUnlock command against a single server, releasing one fsync
+// lock taken by an FsyncOperation. Writes resume once every lock on the node has been released.
+type FsyncUnlockOperation struct {
+	addr  address.Address
+	clock *session.ClusterClock
+
+	d Deployment
+
+	lockCount int64
+}
+
+// FsyncUnlock constructs an FsyncUnlockOperation that releases an fsync lock on the server at addr.
+func FsyncUnlock(addr address.Address) *FsyncUnlockOperation {
+	return &FsyncUnlockOperation{addr: addr}
+}
+
+// Clock sets the cluster clock for this operation.
+func (fuo *FsyncUnlockOperation) Clock(clock *session.ClusterClock) *FsyncUnlockOperation {
+	fuo.clock = clock
+	return fuo
+}
+
+// Deployment sets the Deployment for this operation.
+func (fuo *FsyncUnlockOperation) Deployment(d Deployment) *FsyncUnlockOperation {
+	fuo.d = d
+	return fuo
+}
+
+// LockCount returns the number of fsync locks still held on the server after the last successful
+// Execute.
+func (fuo *FsyncUnlockOperation) LockCount() int64 { return fuo.lockCount }
+
+func (fuo *FsyncUnlockOperation) command(dst []byte, _ description.SelectedServer) ([]byte, error) {
+	return bsoncore.AppendInt32Element(dst, "fsyncUnlock", 1), nil
+}
+
+func (fuo *FsyncUnlockOperation) processResponse(response bsoncore.Document, _ Server) error {
+	fuo.lockCount, _ = response.Lookup("lockCount").AsInt64OK()
+	return nil
+}
+
+// Execute runs this operation.
+func (fuo *FsyncUnlockOperation) Execute(ctx context.Context) error {
+	if fuo.d == nil {
+		return errors.New("an FsyncUnlockOperation must have a Deployment set before Execute can be called")
+	}
+
+	fuo.lockCount = 0
+	return runOnServer(ctx, fuo.d, "admin", fuo.addr, fuo.clock, fuo.command, fuo.processResponse)
+}
@@ -0,0 +1,67 @@
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+func TestFsync(t *testing.T) {
+	topo, _, secondary := replicaSetTopology()
+	deployment := func(lockCount int32) (*mockDeployment, *mockConnection) {
+		return replyDeployment(description.RSSecondary, bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt32Element(nil, "lockCount", lockCount),
+			bsoncore.AppendDoubleElement(nil, "ok", 1),
+		))
+	}
+	requireAdminOnSecondary := func(t *testing.T, d *mockDeployment, cmd bsoncore.Document) {
+		t.Helper()
+		if db, _ := cmd.Lookup("$db").StringValueOK(); db != "admin" {
+			t.Errorf("Expected the command to run on admin. got %q", db)
+		}
+		requireRoutedTo(t, d, topo, secondary.Addr)
+	}
+
+	t.Run("fsync with lock", func(t *testing.T) {
+		d, conn := deployment(1)
+		op := Fsync(secondary.Addr).Lock(true).Deployment(d)
+		noerr(t, op.Execute(context.Background()))
+
+		cmd := msgCommand(t, conn.pWriteWM)
+		if v, ok := cmd.Lookup("fsync").Int32OK(); !ok || v != 1 {
+			t.Errorf("Expected fsync: 1. got %v", cmd)
+		}
+		if lock, ok := cmd.Lookup("lock").BooleanOK(); !ok || !lock {
+			t.Errorf("Expected lock: true. got %v", cmd)
+		}
+		requireAdminOnSecondary(t, d, cmd)
+		if op.LockCount() != 1 {
+			t.Errorf("Unexpected lock count. got %d; want %d", op.LockCount(), 1)
+		}
+	})
+	t.Run("fsync without lock", func(t *testing.T) {
+		d, conn := deployment(0)
+		noerr(t, Fsync(secondary.Addr).Deployment(d).Execute(context.Background()))
+		if _, err := msgCommand(t, conn.pWriteWM).LookupErr("lock"); err == nil {
+			t.Error("Expected lock to be omitted")
+		}
+	})
+	t.Run("fsyncUnlock", func(t *testing.T) {
+		d, conn := deployment(0)
+		op := FsyncUnlock(secondary.Addr).Deployment(d)
+		noerr(t, op.Execute(context.Background()))
+
+		cmd := msgCommand(t, conn.pWriteWM)
+		elems, err := cmd.Elements()
+		noerr(t, err)
+		if v, ok := elems[0].Value().Int32OK(); elems[0].Key() != "fsyncUnlock" || !ok || v != 1 {
+			t.Errorf("Expected the command to start with fsyncUnlock: 1. got %v", cmd)
+		}
+		requireAdminOnSecondary(t, d, cmd)
+		if op.LockCount() != 0 {
+			t.Errorf("Unexpected lock count. got %d; want %d", op.LockCount(), 0)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+// Code will be generated by drivergen. DO NOT EDIT.
+
+package driver
+
+import (
+	"github.com/lakshay2395/mongo-go-driver/mongo/writeconcern"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// Insert constructs and returns a new InsertOperation.
+func Insert(documents ...bsoncore.Document) *InsertOperation {
+	return &InsertOperation{documents: documents}
+}
+
+// Documents sets the documents to insert.
+func (io *InsertOperation) Documents(documents ...bsoncore.Document) *InsertOperation {
+	if io == nil {
+		io = new(InsertOperation)
+	}
+
+	io.documents = documents
+	return io
+}
+
+// Ordered sets whether the server stops at the first failed insert. Unordered inserts, known as
+// keepGoing in the legacy wire protocol, attempt every document. The default is ordered.
+func (io *InsertOperation) Ordered(ordered bool) *InsertOperation {
+	if io == nil {
+		io = new(InsertOperation)
+	}
+
+	io.ordered = &ordered
+	return io
+}
+
+// Collection sets the collection to insert into.
+func (io *InsertOperation) Collection(collection string) *InsertOperation {
+	if io == nil {
+		io = new(InsertOperation)
+	}
+
+	io.collection = collection
+	return io
+}
+
+// Database sets the database of the collection.
+func (io *InsertOperation) Database(database string) *InsertOperation {
+	if io == nil {
+		io = new(InsertOperation)
+	}
+
+	io.database = database
+	return io
+}
+
+// Deployment sets the Deployment to run the insert against.
+func (io *InsertOperation) Deployment(d Deployment) *InsertOperation {
+	if io == nil {
+		io = new(InsertOperation)
+	}
+
+	io.d = d
+	return io
+}
+
+// ServerSelector sets the server selector for this operation.
+func (io *InsertOperation) ServerSelector(selector description.ServerSelector) *InsertOperation {
+	if io == nil {
+		io = new(InsertOperation)
+	}
+
+	io.selector = selector
+	return io
+}
+
+// WriteConcern sets the write concern for this operation.
+func (io *InsertOperation) WriteConcern(writeConcern *writeconcern.WriteConcern) *InsertOperation {
+	if io == nil {
+		io = new(InsertOperation)
+	}
+
+	io.writeConcern = writeConcern
+	return io
+}
+
+// Clock sets the cluster clock for this operation.
+func (io *InsertOperation) Clock(clock *session.ClusterClock) *InsertOperation {
+	if io == nil {
+		io = new(InsertOperation)
+	}
+
+	io.clock = clock
+	return io
+}
+
+// Session sets the session for this operation.
+func (io *InsertOperation) Session(client *session.Client) *InsertOperation {
+	if io == nil {
+		io = new(InsertOperation)
+	}
+
+	io.client = client
+	return io
+}
+
+// BufferPool sets the pool that supplies the buffers used to send the batches and read their
+// replies.
+func (io *InsertOperation) BufferPool(bufferPool *BufferPool) *InsertOperation {
+	if io == nil {
+		io = new(InsertOperation)
+	}
+
+	io.bufferPool = bufferPool
+	return io
+}
@@ -0,0 +1,122 @@
+package driver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/mongo/writeconcern"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/result"
+)
+
+// InsertOperation inserts documents into a collection. The documents are split into batches that
+// fit the server's limits, and are sent as an OP_MSG document sequence when the server supports it.
+type InsertOperation struct {
+	_ struct{} `drivergen:"-"`
+	// Documents sets the documents to insert.
+	documents []bsoncore.Document `drivergen:"Documents,constructorArg,variadic"`
+	// Ordered sets whether the server stops at the first failed insert. Unordered inserts, known as
+	// keepGoing in the legacy wire protocol, attempt every document. The default is ordered.
+	ordered *bool
+
+	// Collection sets the collection to insert into.
+	collection string
+	// Database sets the database of the collection.
+	database string
+	// Deployment sets the Deployment to run the insert against.
+	d Deployment `drivergen:"Deployment"`
+
+	selector     description.ServerSelector `drivergen:"ServerSelector"`
+	writeConcern *writeconcern.WriteConcern `drivergen:"WriteConcern,pointerExempt"`
+	clock        *session.ClusterClock      `drivergen:"Clock,pointerExempt"`
+	client       *session.Client            `drivergen:"Session,pointerExempt"`
+
+	// BufferPool sets the pool that supplies the buffers used to send the batches and read their
+	// replies.
+	bufferPool *BufferPool `drivergen:"BufferPool,pointerExempt"`
+
+	batches *Batches      `drivergen:"-"`
+	result  result.Insert `drivergen:"-"`
+}
+
+// Result returns the result of executing this operation. The indexes of the write errors refer to
+// the documents passed to Insert.
+//
+// TODO(GODRIVER-617): This should be generated by drivergen.
+func (io *InsertOperation) Result() result.Insert { return io.result }
+
+// processResponse adds the reply for the current batch to the result.
+func (io *InsertOperation) processResponse(response bsoncore.Document, _ Server) error {
+	// The server reports write error indexes relative to the batch.
+	offset := len(io.documents) - len(io.batches.Documents) - len(io.batches.Current)
+	if n, ok := response.Lookup("n").AsInt64OK(); ok {
+		io.result.N += int(n)
+	}
+	var wcError WriteCommandError
+	if err, ok := extractError(response).(WriteCommandError); ok {
+		wcError = err
+	}
+	for _, we := range wcError.WriteErrors {
+		io.result.WriteErrors = append(io.result.WriteErrors, result.WriteError{
+			Index:   offset + int(we.Index),
+			Code:    int(we.Code),
+			ErrMsg:  we.Message,
+			ErrInfo: bson.Raw(we.Details),
+		})
+	}
+	if wce := wcError.WriteConcernError; wce != nil {
+		io.result.WriteConcernError = &result.WriteConcernError{Code: int(wce.Code), ErrMsg: wce.Message, ErrInfo: bson.Raw(wce.Details)}
+	}
+	return nil
+}
+
+// TODO(GODRIVER-617): This should be generated by drivergen.
+func (io *InsertOperation) command(dst []byte, _ description.SelectedServer) ([]byte, error) {
+	dst = bsoncore.AppendStringElement(dst, "insert", io.collection)
+	if io.ordered != nil {
+		dst = bsoncore.AppendBooleanElement(dst, "ordered", *io.ordered)
+	}
+	return dst, nil
+}
+
+// Execute runs this operations. If any document fails to be inserted a WriteCommandError is
+// returned, with the indexes of its write errors referring to the documents passed to Insert.
+//
+// TODO(GODRIVER-617): This should be generated by drivergen.
+func (io *InsertOperation) Execute(ctx context.Context) error {
+	if io.d == nil {
+		return errors.New("an InsertOperation must have a Deployment set before Execute can be called")
+	}
+	if io.database == "" || io.collection == "" {
+		return errors.New("Database and Collection must be of non-zero length")
+	}
+	if len(io.documents) == 0 {
+		return errors.New("an InsertOperation must have at least one document")
+	}
+
+	io.result = result.Insert{}
+	io.batches = &Batches{
+		Identifier: "documents",
+		Documents:  io.documents,
+		Ordered:    io.ordered,
+	}
+	return Operation{
+		CommandFn:  io.command,
+		Deployment: io.d,
+		Database:   io.database,
+
+		ProcessResponseFn: io.processResponse,
+
+		Batches:      io.batches,
+		Selector:     io.selector,
+		WriteConcern: io.writeConcern,
+
+		Client: io.client,
+		Clock:  io.clock,
+
+		BufferPool: io.bufferPool,
+	}.Execute(ctx, nil)
+}
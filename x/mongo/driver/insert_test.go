@@ -0,0 +1,137 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	wiremessagex "github.com/lakshay2395/mongo-go-driver/x/mongo/driver/wiremessage"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+func TestInsertOperation(t *testing.T) {
+	docs := make([]bsoncore.Document, 5)
+	for i := range docs {
+		docs[i] = bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "_id", int32(i)))
+	}
+	// insertReply is the reply to a batch in which the documents at errIndexes, relative to the
+	// batch, violate a unique index.
+	insertReply := func(n int32, errIndexes ...int32) []byte {
+		elems := [][]byte{bsoncore.AppendInt32Element(nil, "ok", 1), bsoncore.AppendInt32Element(nil, "n", n)}
+		if len(errIndexes) > 0 {
+			var errs []bsoncore.Value
+			for _, idx := range errIndexes {
+				errs = append(errs, bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: bsoncore.BuildDocumentFromElements(nil,
+					bsoncore.AppendInt32Element(nil, "index", idx),
+					bsoncore.AppendInt32Element(nil, "code", 11000),
+					bsoncore.AppendStringElement(nil, "errmsg", "E11000 duplicate key error"),
+				)})
+			}
+			elems = append(elems, bsoncore.AppendArrayElement(nil, "writeErrors", bsoncore.BuildArray(nil, errs...)))
+		}
+		return opMsgReply(bsoncore.BuildDocumentFromElements(nil, elems...))
+	}
+	// execute inserts docs with a MaxDocumentSize that fits two of them in each batch, so the third
+	// document is the first of the second batch.
+	execute := func(t *testing.T, op *InsertOperation, replies ...[]byte) (*mockConnection, error) {
+		t.Helper()
+		conn := &mockConnection{
+			rDesc: description.Server{
+				WireVersion:     &description.VersionRange{Max: 6},
+				MaxDocumentSize: uint32(2*len(docs[0]) + 1),
+				MaxBatchCount:   2,
+			},
+			rReadWMs: replies,
+		}
+		d := new(mockDeployment)
+		d.returns.server = mockServer{conn: conn}
+		return conn, op.Database("foo").Collection("bar").Deployment(d).Execute(context.Background())
+	}
+	requireIndex := func(t *testing.T, err error, want int64) {
+		t.Helper()
+		wce, ok := err.(WriteCommandError)
+		if !ok {
+			t.Fatalf("Expected a WriteCommandError. got %T: %v", err, err)
+		}
+		if len(wce.WriteErrors) != 1 || wce.WriteErrors[0].Index != want {
+			t.Errorf("Expected one write error at index %d. got %v", want, wce.WriteErrors)
+		}
+	}
+
+	t.Run("ordered stops at the first error", func(t *testing.T) {
+		op := Insert(docs...)
+		conn, err := execute(t, op, insertReply(2), insertReply(0, 0), insertReply(1))
+		requireIndex(t, err, 2)
+
+		if len(conn.pWriteWMs) != 2 {
+			t.Errorf("Expected the insert to stop after the failed batch. got %d batches", len(conn.pWriteWMs))
+		}
+		res := op.Result()
+		if res.N != 2 {
+			t.Errorf("Unexpected n. got %d; want %d", res.N, 2)
+		}
+		if len(res.WriteErrors) != 1 || res.WriteErrors[0].Index != 2 || res.WriteErrors[0].Code != 11000 {
+			t.Errorf("Expected one duplicate key error at index 2. got %v", res.WriteErrors)
+		}
+	})
+	t.Run("unordered continues after an error", func(t *testing.T) {
+		op := Insert(docs...).Ordered(false)
+		conn, err := execute(t, op, insertReply(2), insertReply(1, 0), insertReply(1))
+		requireIndex(t, err, 2)
+
+		if len(conn.pWriteWMs) != 3 {
+			t.Errorf("Expected every batch to be sent. got %d batches", len(conn.pWriteWMs))
+		}
+		res := op.Result()
+		if res.N != 4 {
+			t.Errorf("Unexpected n. got %d; want %d", res.N, 4)
+		}
+		if len(res.WriteErrors) != 1 || res.WriteErrors[0].Index != 2 {
+			t.Errorf("Expected one write error at index 2. got %v", res.WriteErrors)
+		}
+		cmd := msgCommand(t, conn.pWriteWMs[0])
+		if ordered, ok := cmd.Lookup("ordered").BooleanOK(); !ok || ordered {
+			t.Errorf("Expected ordered to be false. got %v", cmd)
+		}
+	})
+	t.Run("write errors keep their errInfo", func(t *testing.T) {
+		errInfo := bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendStringElement(nil, "failingDocumentId", "abc"))
+		reply := opMsgReply(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt32Element(nil, "ok", 1),
+			bsoncore.AppendInt32Element(nil, "n", 0),
+			bsoncore.BuildArrayElement(nil, "writeErrors", bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "index", 0),
+				bsoncore.AppendInt32Element(nil, "code", 121),
+				bsoncore.AppendStringElement(nil, "errmsg", "Document failed validation"),
+				bsoncore.AppendDocumentElement(nil, "errInfo", errInfo),
+			)}),
+		))
+		op := Insert(docs[0])
+		_, err := execute(t, op, reply)
+		requireIndex(t, err, 0)
+
+		res := op.Result()
+		if len(res.WriteErrors) != 1 || !bytes.Equal(res.WriteErrors[0].ErrInfo, errInfo) {
+			t.Errorf("Expected the write error to keep its errInfo. got %v", res.WriteErrors)
+		}
+	})
+	t.Run("documents are sent as a document sequence", func(t *testing.T) {
+		conn, err := execute(t, Insert(docs[0]), insertReply(1))
+		noerr(t, err)
+
+		_, _, _, _, rem, _ := wiremessagex.ReadHeader(conn.pWriteWMs[0])
+		_, rem, _ = wiremessagex.ReadMsgFlags(rem)
+		_, rem, _ = wiremessagex.ReadMsgSectionType(rem)
+		body, rem, _ := wiremessagex.ReadMsgSectionSingleDocument(rem)
+		if _, err := body.LookupErr("ordered"); err == nil {
+			t.Errorf("ordered should only be sent when set. got %v", body)
+		}
+		_, rem, _ = wiremessagex.ReadMsgSectionType(rem)
+		identifier, sent, _, ok := wiremessagex.ReadMsgSectionDocumentSequence(rem)
+		if !ok || identifier != "documents" || len(sent) != 1 {
+			t.Errorf("Expected the document to be sent in a documents sequence. got %q %v", identifier, sent)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package driver
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// killTimeout is the maximum amount of time an Interrupter waits for the command that interrupts
+// server-side work.
+const killTimeout = 10 * time.Second
+
+// Interrupter interrupts the server-side work of the operations it is attached to. Cancelling the
+// context of an operation only stops the driver from waiting on the server, so when the context is
+// cancelled or Kill is called while an operation with an Interrupter is waiting on a reply, a command
+// is sent to the same server to stop the work: abortTransaction if the operation is part of a running
+// transaction, killCursors if a cursor id is known, or killOp if an operation id has been set with
+// SetOpID. The abortTransaction completes before the operation returns, because it changes the state
+// of the operation's session; the other commands are sent in the background.
+//
+// Cursor ids are recorded from replies automatically. Operation ids are not, because the server does
+// not report them to the client running the operation, so killOp is only sent for an id found
+// separately, such as with CurrentOp. An Interrupter can be shared by several operations, such as
+// the find and getMore commands of a cursor, and is safe for concurrent use.
+type Interrupter struct {
+	killed   chan struct{}
+	killOnce sync.Once
+	pending  sync.WaitGroup
+
+	mu       sync.Mutex
+	opID     int64
+	cursorID int64
+	ns       string
+}
+
+// NewInterrupter constructs an Interrupter.
+func NewInterrupter() *Interrupter {
+	return &Interrupter{killed: make(chan struct{})}
+}
+
+// Kill interrupts every in-flight operation using this Interrupter, as if their contexts had been
+// cancelled. Operations that start after Kill is called fail immediately.
+func (i *Interrupter) Kill() {
+	i.killOnce.Do(func() { close(i.killed) })
+}
+
+// Killed returns a channel that is closed when Kill is called.
+func (i *Interrupter) Killed() <-chan struct{} { return i.killed }
+
+// SetOpID sets the server operation id used for killOp, e.g. one found by running CurrentOp.
+func (i *Interrupter) SetOpID(id int64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.opID = id
+}
+
+// SetCursor sets the cursor used for killCursors. The namespace is of the form database.collection.
+// An id of 0 means there is no open cursor.
+func (i *Interrupter) SetCursor(ns string, id int64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.ns, i.cursorID = ns, id
+}
+
+// context returns a child of ctx that is cancelled when Kill is called.
+func (i *Interrupter) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-i.killed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// observe records the cursor from a command reply, if it has one.
+func (i *Interrupter) observe(response bsoncore.Document) {
+	id, ok := response.Lookup("cursor", "id").Int64OK()
+	if !ok {
+		return
+	}
+	ns, _ := response.Lookup("cursor", "ns").StringValueOK()
+	i.SetCursor(ns, id)
+}
+
+// interrupt sends the command that stops the server-side work started by interrupted to srvr.
+// Aborting a transaction is waited for; killCursors and killOp are not. Errors are ignored because
+// the operation has already failed with its context's error.
+func (i *Interrupter) interrupt(srvr Server, interrupted Operation) {
+	if interrupted.Client.TransactionRunning() {
+		ctx, cancel := context.WithTimeout(context.Background(), killTimeout)
+		defer cancel()
+		_ = AbortTransaction(interrupted.Client).
+			Clock(interrupted.Clock).
+			Deployment(SingleServerDeployment{Server: srvr}).
+			Execute(ctx)
+		return
+	}
+
+	i.mu.Lock()
+	opID, cursorID, ns := i.opID, i.cursorID, i.ns
+	i.mu.Unlock()
+
+	op := Operation{Deployment: SingleServerDeployment{Server: srvr}, Database: "admin", Clock: interrupted.Clock}
+	switch {
+	case cursorID != 0 && strings.Contains(ns, "."):
+		dot := strings.Index(ns, ".")
+		op.Database = ns[:dot]
+		op.CommandFn = func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+			dst = bsoncore.AppendStringElement(dst, "killCursors", ns[dot+1:])
+			var idx int32
+			idx, dst = bsoncore.AppendArrayElementStart(dst, "cursors")
+			dst = bsoncore.AppendInt64Element(dst, "0", cursorID)
+			return bsoncore.AppendArrayEnd(dst, idx)
+		}
+	case opID != 0:
+		op.CommandFn = func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+			dst = bsoncore.AppendInt32Element(dst, "killOp", 1)
+			return bsoncore.AppendInt64Element(dst, "op", opID), nil
+		}
+	default:
+		return
+	}
+
+	i.pending.Add(1)
+	go func() {
+		defer i.pending.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), killTimeout)
+		defer cancel()
+		_ = op.Execute(ctx, nil)
+	}()
+}
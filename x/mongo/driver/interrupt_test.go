@@ -0,0 +1,256 @@
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	wiremessagex "github.com/lakshay2395/mongo-go-driver/x/mongo/driver/wiremessage"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/uuid"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// blockingConnection blocks on its first read until the context is done, simulating a server that
+// is still working when the operation is cancelled. Later reads are served by the mockConnection,
+// after release is closed if it is set.
+type blockingConnection struct {
+	*mockConnection
+	reading chan struct{}
+	release chan struct{}
+}
+
+func newBlockingConnection() *blockingConnection {
+	return &blockingConnection{
+		mockConnection: &mockConnection{
+			rDesc: description.Server{
+				Kind:                  description.RSPrimary,
+				WireVersion:           &description.VersionRange{Max: 6},
+				SessionTimeoutMinutes: 30,
+			},
+			rReadWM: opMsgReply(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1))),
+		},
+		reading: make(chan struct{}),
+	}
+}
+
+func (c *blockingConnection) ReadWireMessage(ctx context.Context, dst []byte) ([]byte, error) {
+	if c.reading != nil {
+		close(c.reading)
+		c.reading = nil
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if c.release != nil {
+		<-c.release
+	}
+	return c.mockConnection.ReadWireMessage(ctx, dst)
+}
+
+// msgCommand returns the command document of an OP_MSG wire message.
+func msgCommand(t *testing.T, wm []byte) bsoncore.Document {
+	t.Helper()
+	_, _, _, _, rem, ok := wiremessagex.ReadHeader(wm)
+	if !ok {
+		t.Fatalf("Could not read wire message header")
+	}
+	_, rem, _ = wiremessagex.ReadMsgFlags(rem)
+	_, rem, _ = wiremessagex.ReadMsgSectionType(rem)
+	cmd, _, ok := wiremessagex.ReadMsgSectionSingleDocument(rem)
+	if !ok {
+		t.Fatalf("Could not read OP_MSG body")
+	}
+	return cmd
+}
+
+func TestInterrupter(t *testing.T) {
+	aggregate := func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+		return bsoncore.AppendStringElement(dst, "aggregate", "bar"), nil
+	}
+	// execute runs an operation on conn and interrupts it with stop once the server is waiting.
+	execute := func(t *testing.T, conn *blockingConnection, op Operation, stop func(context.CancelFunc)) {
+		t.Helper()
+		d := new(mockDeployment)
+		d.returns.server = mockServer{conn: conn}
+		op.CommandFn, op.Database, op.Deployment = aggregate, "foo", d
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		reading := conn.reading
+		go func() {
+			<-reading
+			stop(cancel)
+		}()
+		if err := op.Execute(ctx, nil); err == nil {
+			t.Fatalf("Expected an error from an interrupted operation")
+		}
+		op.Interrupter.pending.Wait()
+	}
+
+	t.Run("cancel sends killCursors", func(t *testing.T) {
+		conn := newBlockingConnection()
+		i := NewInterrupter()
+		i.SetCursor("foo.bar", 42)
+		execute(t, conn, Operation{Interrupter: i}, func(cancel context.CancelFunc) { cancel() })
+
+		if len(conn.pWriteWMs) != 2 {
+			t.Fatalf("Expected a kill command to be sent. got %d wire messages", len(conn.pWriteWMs))
+		}
+		cmd := msgCommand(t, conn.pWriteWMs[1])
+		if coll, ok := cmd.Lookup("killCursors").StringValueOK(); !ok || coll != "bar" {
+			t.Errorf("Expected killCursors for bar. got %v", cmd)
+		}
+		if id, ok := cmd.Lookup("cursors", "0").Int64OK(); !ok || id != 42 {
+			t.Errorf("Expected cursor 42 to be killed. got %v", cmd)
+		}
+		if db, _ := cmd.Lookup("$db").StringValueOK(); db != "foo" {
+			t.Errorf("Expected killCursors to run on foo. got %v", db)
+		}
+	})
+	t.Run("Kill sends killOp", func(t *testing.T) {
+		conn := newBlockingConnection()
+		i := NewInterrupter()
+		i.SetOpID(7)
+		execute(t, conn, Operation{Interrupter: i}, func(context.CancelFunc) { i.Kill() })
+
+		if len(conn.pWriteWMs) != 2 {
+			t.Fatalf("Expected a kill command to be sent. got %d wire messages", len(conn.pWriteWMs))
+		}
+		cmd := msgCommand(t, conn.pWriteWMs[1])
+		if op, ok := cmd.Lookup("op").Int64OK(); !ok || op != 7 {
+			t.Errorf("Expected killOp for operation 7. got %v", cmd)
+		}
+		if _, err := cmd.LookupErr("killOp"); err != nil {
+			t.Errorf("Expected killOp. got %v", cmd)
+		}
+		if db, _ := cmd.Lookup("$db").StringValueOK(); db != "admin" {
+			t.Errorf("Expected killOp to run on admin. got %v", db)
+		}
+		select {
+		case <-i.Killed():
+		default:
+			t.Errorf("Expected Killed channel to be closed")
+		}
+	})
+	t.Run("transaction sends abortTransaction", func(t *testing.T) {
+		id, err := uuid.New()
+		noerr(t, err)
+		sess, err := session.NewClientSession(session.NewPool(nil), id, session.Explicit)
+		noerr(t, err)
+		noerr(t, sess.StartTransaction(&session.TransactionOptions{}))
+
+		conn := newBlockingConnection()
+		i := NewInterrupter()
+		i.SetOpID(7)
+		op := Operation{Interrupter: i, Client: sess, Clock: &session.ClusterClock{}}
+		execute(t, conn, op, func(cancel context.CancelFunc) { cancel() })
+
+		if len(conn.pWriteWMs) != 2 {
+			t.Fatalf("Expected a kill command to be sent. got %d wire messages", len(conn.pWriteWMs))
+		}
+		cmd := msgCommand(t, conn.pWriteWMs[1])
+		if _, err := cmd.LookupErr("abortTransaction"); err != nil {
+			t.Errorf("Expected abortTransaction. got %v", cmd)
+		}
+		if txn, ok := cmd.Lookup("txnNumber").Int64OK(); !ok || txn != sess.TxnNumber {
+			t.Errorf("Expected txnNumber %d. got %v", sess.TxnNumber, cmd)
+		}
+		if err := sess.CheckAbortTransaction(); err != session.ErrAbortTwice {
+			t.Errorf("Expected the transaction to be aborted. got %v", err)
+		}
+	})
+	t.Run("transaction is aborted before Execute returns", func(t *testing.T) {
+		id, err := uuid.New()
+		noerr(t, err)
+		sess, err := session.NewClientSession(session.NewPool(nil), id, session.Explicit)
+		noerr(t, err)
+		noerr(t, sess.StartTransaction(&session.TransactionOptions{}))
+
+		conn := newBlockingConnection()
+		d := new(mockDeployment)
+		d.returns.server = mockServer{conn: conn}
+		op := Operation{
+			CommandFn:   aggregate,
+			Database:    "foo",
+			Deployment:  d,
+			Interrupter: NewInterrupter(),
+			Client:      sess,
+			Clock:       &session.ClusterClock{},
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		reading := conn.reading
+		go func() {
+			<-reading
+			cancel()
+		}()
+		if err := op.Execute(ctx, nil); err == nil {
+			t.Fatalf("Expected an error from an interrupted operation")
+		}
+		// Using the session as soon as Execute returns, without waiting for pending interrupts, is
+		// reported by the race detector if the abort is still running.
+		if err := sess.CheckAbortTransaction(); err != session.ErrAbortTwice {
+			t.Errorf("Expected the transaction to be aborted. got %v", err)
+		}
+		sess.ApplyCommand(description.Server{})
+		if len(conn.pWriteWMs) != 2 {
+			t.Fatalf("Expected abortTransaction to be sent. got %d wire messages", len(conn.pWriteWMs))
+		}
+		if _, err := msgCommand(t, conn.pWriteWMs[1]).LookupErr("abortTransaction"); err != nil {
+			t.Errorf("Expected abortTransaction. got %v", msgCommand(t, conn.pWriteWMs[1]))
+		}
+	})
+	t.Run("does not wait for the kill command", func(t *testing.T) {
+		conn := newBlockingConnection()
+		conn.release = make(chan struct{})
+		i := NewInterrupter()
+		i.SetOpID(7)
+		d := new(mockDeployment)
+		d.returns.server = mockServer{conn: conn}
+		op := Operation{CommandFn: aggregate, Database: "foo", Deployment: d, Interrupter: i}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		reading := conn.reading
+		go func() {
+			<-reading
+			cancel()
+		}()
+		// Execute returning while the kill command is still waiting for its reply shows that the
+		// kill runs in the background.
+		if err := op.Execute(ctx, nil); err == nil {
+			t.Errorf("Expected an error from an interrupted operation")
+		}
+		close(conn.release)
+		i.pending.Wait()
+		if len(conn.pWriteWMs) != 2 {
+			t.Errorf("Expected a kill command to be sent. got %d wire messages", len(conn.pWriteWMs))
+		}
+	})
+	t.Run("nothing to interrupt", func(t *testing.T) {
+		conn := newBlockingConnection()
+		execute(t, conn, Operation{Interrupter: NewInterrupter()}, func(cancel context.CancelFunc) { cancel() })
+		if len(conn.pWriteWMs) != 1 {
+			t.Errorf("Expected no kill command to be sent. got %d wire messages", len(conn.pWriteWMs))
+		}
+	})
+	t.Run("records cursor from reply", func(t *testing.T) {
+		conn := &mockConnection{
+			rDesc: description.Server{Kind: description.RSPrimary, WireVersion: &description.VersionRange{Max: 6}},
+			rReadWM: opMsgReply(bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+				bsoncore.AppendDocumentElement(nil, "cursor", bsoncore.BuildDocumentFromElements(nil,
+					bsoncore.AppendInt64Element(nil, "id", 99),
+					bsoncore.AppendStringElement(nil, "ns", "foo.bar"),
+				)),
+			)),
+		}
+		d := new(mockDeployment)
+		d.returns.server = mockServer{conn: conn}
+		i := NewInterrupter()
+		err := Operation{CommandFn: aggregate, Database: "foo", Deployment: d, Interrupter: i}.Execute(context.Background(), nil)
+		noerr(t, err)
+		if i.cursorID != 99 || i.ns != "foo.bar" {
+			t.Errorf("Expected cursor foo.bar 99 to be recorded. got %s %d", i.ns, i.cursorID)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"runtime"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/lakshay2395/mongo-go-driver/bson"
 	"github.com/lakshay2395/mongo-go-driver/version"
@@ -14,11 +15,26 @@ import (
 	"github.com/lakshay2395/mongo-go-driver/x/network/result"
 )
 
+// maxClientMetadataSize is the maximum size in bytes of the client metadata document sent in the
+// handshake. Servers reject handshakes with larger metadata.
+const maxClientMetadataSize = 512
+
+// DriverInfo describes a library that wraps this driver. It is appended to the driver metadata sent
+// in the handshake so that server logs identify both the wrapping library and this driver.
+type DriverInfo struct {
+	Name     string
+	Version  string
+	Platform string
+}
+
 // IsMasterOperation is used to run the isMaster handshake operation.
 type IsMasterOperation struct {
 	appname            string
 	compressors        []string
+	driverInfo         *DriverInfo
+	loadBalanced       bool
 	saslSupportedMechs string
+	speculativeAuth    bsoncore.Document
 
 	d     Deployment
 	tkind description.TopologyKind
@@ -35,12 +51,27 @@ func (imo *IsMasterOperation) AppName(appname string) *IsMasterOperation {
 	return imo
 }
 
+// DriverInfo sets information about a library wrapping this driver. Each non-empty field is
+// appended to the corresponding driver name, driver version, or platform metadata field, separated
+// by a "|".
+func (imo *IsMasterOperation) DriverInfo(info *DriverInfo) *IsMasterOperation {
+	imo.driverInfo = info
+	return imo
+}
+
 // Compressors sets the compressors that can be used.
 func (imo *IsMasterOperation) Compressors(compressors []string) *IsMasterOperation {
 	imo.compressors = compressors
 	return imo
 }
 
+// LoadBalanced sets whether the client is connecting through a load balancer. When set, the server
+// includes the serviceId of the service behind the load balancer in its reply.
+func (imo *IsMasterOperation) LoadBalanced(loadBalanced bool) *IsMasterOperation {
+	imo.loadBalanced = loadBalanced
+	return imo
+}
+
 // SASLSupportedMechs retrieves the supported SASL mechanism for the given user when this operation
 // is run.
 func (imo *IsMasterOperation) SASLSupportedMechs(username string) *IsMasterOperation {
@@ -48,6 +79,14 @@ func (imo *IsMasterOperation) SASLSupportedMechs(username string) *IsMasterOpera
 	return imo
 }
 
+// SpeculativeAuthenticate sets the document to be used for speculative authentication. When set,
+// the server may begin authentication as part of this operation and include its reply in the
+// speculativeAuthenticate field of the result.
+func (imo *IsMasterOperation) SpeculativeAuthenticate(doc bsoncore.Document) *IsMasterOperation {
+	imo.speculativeAuth = doc
+	return imo
+}
+
 // Deployment sets the Deployment for this operation.
 func (imo *IsMasterOperation) Deployment(d Deployment) *IsMasterOperation {
 	imo.d = d
@@ -74,37 +113,103 @@ func (imo *IsMasterOperation) processResponse(response bsoncore.Document, _ Serv
 func (imo *IsMasterOperation) command(dst []byte, _ description.SelectedServer) ([]byte, error) {
 	dst = bsoncore.AppendInt32Element(dst, "isMaster", 1)
 
-	idx, dst := bsoncore.AppendDocumentElementStart(dst, "client")
+	if client := imo.clientMetadata(); client != nil {
+		dst = bsoncore.AppendDocumentElement(dst, "client", client)
+	}
+
+	if imo.loadBalanced {
+		dst = bsoncore.AppendBooleanElement(dst, "loadBalanced", true)
+	}
+	if imo.saslSupportedMechs != "" {
+		dst = bsoncore.AppendStringElement(dst, "saslSupportedMechs", imo.saslSupportedMechs)
+	}
+	if imo.speculativeAuth != nil {
+		dst = bsoncore.AppendDocumentElement(dst, "speculativeAuthenticate", imo.speculativeAuth)
+	}
+
+	idx, dst := bsoncore.AppendArrayElementStart(dst, "compression")
+	for i, compressor := range imo.compressors {
+		dst = bsoncore.AppendStringElement(dst, strconv.Itoa(i), compressor)
+	}
+	dst, _ = bsoncore.AppendArrayEnd(dst, idx)
+
+	return dst, nil
+}
+
+// clientMetadata builds the client metadata document. If the document is larger than
+// maxClientMetadataSize, the platform is truncated first, then the OS architecture is omitted. If
+// the document is still too large, nil is returned and no metadata is sent.
+func (imo *IsMasterOperation) clientMetadata() bsoncore.Document {
+	name, driverVersion, platform := "mongo-go-driver", version.Driver, runtime.Version()
+	if info := imo.driverInfo; info != nil {
+		name = appendDriverInfo(name, info.Name)
+		driverVersion = appendDriverInfo(driverVersion, info.Version)
+		platform = appendDriverInfo(platform, info.Platform)
+	}
+
+	arch := runtime.GOARCH
+	doc := imo.encodeClientMetadata(name, driverVersion, platform, arch)
+	if excess := len(doc) - maxClientMetadataSize; excess > 0 {
+		platform = truncateString(platform, len(platform)-excess)
+		doc = imo.encodeClientMetadata(name, driverVersion, platform, arch)
+	}
+	if len(doc) > maxClientMetadataSize {
+		doc = imo.encodeClientMetadata(name, driverVersion, platform, "")
+	}
+	if len(doc) > maxClientMetadataSize {
+		return nil
+	}
+	return doc
+}
+
+func (imo *IsMasterOperation) encodeClientMetadata(name, driverVersion, platform, arch string) bsoncore.Document {
+	idx, dst := bsoncore.AppendDocumentStart(nil)
 
 	didx, dst := bsoncore.AppendDocumentElementStart(dst, "driver")
-	dst = bsoncore.AppendStringElement(dst, "name", "mongo-go-driver")
-	dst = bsoncore.AppendStringElement(dst, "version", version.Driver)
+	dst = bsoncore.AppendStringElement(dst, "name", name)
+	dst = bsoncore.AppendStringElement(dst, "version", driverVersion)
 	dst, _ = bsoncore.AppendDocumentEnd(dst, didx)
 
 	didx, dst = bsoncore.AppendDocumentElementStart(dst, "os")
 	dst = bsoncore.AppendStringElement(dst, "type", runtime.GOOS)
-	dst = bsoncore.AppendStringElement(dst, "architecture", runtime.GOARCH)
+	if arch != "" {
+		dst = bsoncore.AppendStringElement(dst, "architecture", arch)
+	}
 	dst, _ = bsoncore.AppendDocumentEnd(dst, didx)
 
-	dst = bsoncore.AppendStringElement(dst, "platform", runtime.Version())
+	if platform != "" {
+		dst = bsoncore.AppendStringElement(dst, "platform", platform)
+	}
 	if imo.appname != "" {
 		didx, dst = bsoncore.AppendDocumentElementStart(dst, "application")
 		dst = bsoncore.AppendStringElement(dst, "name", imo.appname)
 		dst, _ = bsoncore.AppendDocumentEnd(dst, didx)
 	}
 	dst, _ = bsoncore.AppendDocumentEnd(dst, idx)
+	return dst
+}
 
-	if imo.saslSupportedMechs != "" {
-		dst = bsoncore.AppendStringElement(dst, "saslSupportedMechs", imo.saslSupportedMechs)
+// truncateString returns the longest prefix of s that is at most n bytes long without splitting a
+// UTF-8 encoded character.
+func truncateString(s string, n int) string {
+	if n >= len(s) {
+		return s
 	}
-
-	idx, dst = bsoncore.AppendArrayElementStart(dst, "compression")
-	for i, compressor := range imo.compressors {
-		dst = bsoncore.AppendStringElement(dst, strconv.Itoa(i), compressor)
+	if n <= 0 {
+		return ""
 	}
-	dst, _ = bsoncore.AppendArrayEnd(dst, idx)
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
 
-	return dst, nil
+// appendDriverInfo appends the value from a wrapping library to a driver metadata value.
+func appendDriverInfo(val, wrapper string) string {
+	if wrapper == "" {
+		return val
+	}
+	return val + "|" + wrapper
 }
 
 // Execute runs this operation.
@@ -0,0 +1,180 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/lakshay2395/mongo-go-driver/bson/primitive"
+	"github.com/lakshay2395/mongo-go-driver/version"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+func TestIsMasterAppName(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		dst, err := IsMaster().AppName("analytics-worker-1").command(nil, description.SelectedServer{})
+		noerr(t, err)
+		doc := bsoncore.Document(bsoncore.BuildDocument(nil, dst))
+		name, err := doc.LookupErr("client", "application", "name")
+		noerr(t, err)
+		if got := name.StringValue(); got != "analytics-worker-1" {
+			t.Errorf("Application names do not match. got %s; want %s", got, "analytics-worker-1")
+		}
+	})
+	t.Run("unset", func(t *testing.T) {
+		dst, err := IsMaster().command(nil, description.SelectedServer{})
+		noerr(t, err)
+		doc := bsoncore.Document(bsoncore.BuildDocument(nil, dst))
+		if _, err := doc.LookupErr("client", "application"); err == nil {
+			t.Errorf("Expected client.application to not be set")
+		}
+	})
+}
+
+func TestIsMasterClientMetadata(t *testing.T) {
+	lookup := func(t *testing.T, doc bsoncore.Document, keys ...string) (string, bool) {
+		t.Helper()
+		val, err := doc.LookupErr(keys...)
+		if err != nil {
+			return "", false
+		}
+		return val.StringValue(), true
+	}
+
+	t.Run("wrapping driver info is appended", func(t *testing.T) {
+		info := &DriverInfo{Name: "odm", Version: "1.2.3", Platform: "odm-platform"}
+		doc := IsMaster().DriverInfo(info).clientMetadata()
+		if got, _ := lookup(t, doc, "driver", "name"); got != "mongo-go-driver|odm" {
+			t.Errorf("Driver names do not match. got %s; want %s", got, "mongo-go-driver|odm")
+		}
+		if got, _ := lookup(t, doc, "driver", "version"); got != version.Driver+"|1.2.3" {
+			t.Errorf("Driver versions do not match. got %s; want %s", got, version.Driver+"|1.2.3")
+		}
+		if got, _ := lookup(t, doc, "platform"); got != runtime.Version()+"|odm-platform" {
+			t.Errorf("Platforms do not match. got %s; want %s", got, runtime.Version()+"|odm-platform")
+		}
+	})
+	t.Run("empty wrapping fields are not appended", func(t *testing.T) {
+		doc := IsMaster().DriverInfo(&DriverInfo{Name: "odm"}).clientMetadata()
+		if got, _ := lookup(t, doc, "driver", "version"); got != version.Driver {
+			t.Errorf("Driver versions do not match. got %s; want %s", got, version.Driver)
+		}
+	})
+	t.Run("platform is truncated first", func(t *testing.T) {
+		info := &DriverInfo{Platform: strings.Repeat("p", 400)}
+		doc := IsMaster().DriverInfo(info).clientMetadata()
+		if len(doc) != maxClientMetadataSize {
+			t.Errorf("Expected metadata to be truncated to the limit. got %d bytes; want %d", len(doc), maxClientMetadataSize)
+		}
+		platform, ok := lookup(t, doc, "platform")
+		if !ok || !strings.HasPrefix(platform, runtime.Version()+"|ppp") {
+			t.Errorf("Expected a truncated platform. got %q", platform)
+		}
+		if _, ok := lookup(t, doc, "os", "architecture"); !ok {
+			t.Errorf("Expected os.architecture to be kept when truncating the platform is enough")
+		}
+	})
+	t.Run("platform is truncated on a character boundary", func(t *testing.T) {
+		info := &DriverInfo{Platform: strings.Repeat("é", 200)}
+		doc := IsMaster().DriverInfo(info).clientMetadata()
+		if len(doc) > maxClientMetadataSize {
+			t.Errorf("Expected metadata to be truncated to the limit. got %d bytes; want at most %d", len(doc), maxClientMetadataSize)
+		}
+		platform, ok := lookup(t, doc, "platform")
+		if !ok || !utf8.ValidString(platform) || !strings.HasPrefix(platform, runtime.Version()+"|éé") {
+			t.Errorf("Expected a valid truncated platform. got %q", platform)
+		}
+	})
+	t.Run("truncateString", func(t *testing.T) {
+		testCases := []struct {
+			s    string
+			n    int
+			want string
+		}{
+			{"abc", 5, "abc"},
+			{"abc", 2, "ab"},
+			{"abc", -1, ""},
+			{"aé", 2, "a"},
+			{"aé", 3, "aé"},
+			{"a日", 3, "a"},
+		}
+		for _, tc := range testCases {
+			if got := truncateString(tc.s, tc.n); got != tc.want {
+				t.Errorf("truncateString(%q, %d) = %q; want %q", tc.s, tc.n, got, tc.want)
+			}
+		}
+	})
+	t.Run("os architecture is omitted after platform", func(t *testing.T) {
+		// A driver name that leaves no room for any platform and only a few bytes of slack.
+		base := len(IsMaster().DriverInfo(&DriverInfo{Platform: "x"}).encodeClientMetadata("", version.Driver, "", ""))
+		info := &DriverInfo{Name: strings.Repeat("n", maxClientMetadataSize-base-len("mongo-go-driver|")), Platform: "x"}
+		doc := IsMaster().DriverInfo(info).clientMetadata()
+		if doc == nil {
+			t.Fatal("Expected client metadata to be sent")
+		}
+		if len(doc) > maxClientMetadataSize {
+			t.Errorf("Metadata exceeds limit. got %d bytes", len(doc))
+		}
+		if _, ok := lookup(t, doc, "platform"); ok {
+			t.Errorf("Expected platform to be omitted")
+		}
+		if _, ok := lookup(t, doc, "os", "architecture"); ok {
+			t.Errorf("Expected os.architecture to be omitted")
+		}
+		if got, _ := lookup(t, doc, "os", "type"); got != runtime.GOOS {
+			t.Errorf("Expected os.type to be kept. got %s", got)
+		}
+	})
+	t.Run("metadata that cannot be truncated is not sent", func(t *testing.T) {
+		info := &DriverInfo{Name: strings.Repeat("n", maxClientMetadataSize)}
+		dst, err := IsMaster().DriverInfo(info).command(nil, description.SelectedServer{})
+		noerr(t, err)
+		doc := bsoncore.Document(bsoncore.BuildDocument(nil, dst))
+		if _, err := doc.LookupErr("client"); err == nil {
+			t.Errorf("Expected client metadata to be omitted")
+		}
+	})
+}
+
+func TestIsMasterServiceID(t *testing.T) {
+	serviceID := primitive.NewObjectID()
+	imo := IsMaster()
+	err := imo.processResponse(bsoncore.BuildDocumentFromElements(nil,
+		bsoncore.AppendInt32Element(nil, "ok", 1),
+		bsoncore.AppendObjectIDElement(nil, "serviceId", serviceID),
+	), nil)
+	noerr(t, err)
+	desc := description.NewServer("localhost:27017", imo.Result())
+	if desc.ServiceID != serviceID {
+		t.Errorf("Service ids do not match. got %v; want %v", desc.ServiceID, serviceID)
+	}
+}
+
+func TestIsMasterLoadBalanced(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		dst, err := IsMaster().LoadBalanced(true).command(nil, description.SelectedServer{})
+		noerr(t, err)
+		doc := bsoncore.Document(bsoncore.BuildDocument(nil, dst))
+		val, err := doc.LookupErr("loadBalanced")
+		noerr(t, err)
+		if lb, ok := val.BooleanOK(); !ok || !lb {
+			t.Errorf("Expected loadBalanced to be true, but got %v", val)
+		}
+	})
+	t.Run("unset", func(t *testing.T) {
+		dst, err := IsMaster().command(nil, description.SelectedServer{})
+		noerr(t, err)
+		doc := bsoncore.Document(bsoncore.BuildDocument(nil, dst))
+		if _, err := doc.LookupErr("loadBalanced"); err == nil {
+			t.Errorf("Expected loadBalanced to not be set")
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxDatabaseNameLength is the maximum length in bytes of a database name.
+const maxDatabaseNameLength = 64
+
+// externalDatabase is the virtual database used for authenticating with external credentials. It
+// is the only database name allowed to contain a '$'.
+const externalDatabase = "$external"
+
+// ValidateDatabaseName returns an error if name is not a legal MongoDB database name. Database
+// names must be non-empty, at most 64 bytes long, and must not contain any of the characters
+// /\. "$ or a null byte. The $external database used for authentication is always allowed.
+func ValidateDatabaseName(name string) error {
+	if name == externalDatabase {
+		return nil
+	}
+	if name == "" {
+		return errors.New("database name cannot be empty")
+	}
+	if len(name) > maxDatabaseNameLength {
+		return fmt.Errorf("database name %q is longer than %d bytes", name, maxDatabaseNameLength)
+	}
+	if i := strings.IndexAny(name, "/\\. \"$\x00"); i != -1 {
+		return fmt.Errorf("database name %q contains illegal character %q", name, name[i])
+	}
+	return nil
+}
+
+// ValidateCollectionName returns an error if name is not a legal name for a user collection.
+// Collection names must be non-empty, must not contain a null byte, and must not start with the
+// reserved "system." prefix.
+func ValidateCollectionName(name string) error {
+	if name == "" {
+		return errors.New("collection name cannot be empty")
+	}
+	if strings.IndexByte(name, 0x00) != -1 {
+		return fmt.Errorf("collection name %q contains a null byte", name)
+	}
+	if strings.HasPrefix(name, "system.") {
+		return fmt.Errorf("collection name %q uses the reserved system. prefix", name)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package driver
+
+import "testing"
+
+func TestValidateCollectionName(t *testing.T) {
+	testCases := []struct {
+		name  string
+		coll  string
+		valid bool
+	}{
+		{"valid", "users", true},
+		{"dotted", "users.archive", true},
+		{"empty", "", false},
+		{"null byte", "us\x00ers", false},
+		{"system prefix", "system.users", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateCollectionName(tc.coll)
+			if tc.valid && err != nil {
+				t.Errorf("Expected %q to be valid, but got error: %v", tc.coll, err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("Expected %q to be invalid, but got no error", tc.coll)
+			}
+		})
+	}
+}
@@ -2,15 +2,12 @@ package driver
 
 import (
 	"bytes"
-	"compress/zlib"
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"strconv"
 	"time"
 
-	"github.com/golang/snappy"
 	"github.com/lakshay2395/mongo-go-driver/bson"
 	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
 	"github.com/lakshay2395/mongo-go-driver/bson/primitive"
@@ -27,6 +24,25 @@ import (
 
 var dollarCmd = [...]byte{'.', '$', 'c', 'm', 'd'}
 
+// defaultLocalThreshold is the latency window used by the default server selector when
+// Operation.LocalThreshold is not set.
+const defaultLocalThreshold = 15 * time.Millisecond
+
+// defaultServerSelectionTimeout is how long server selection is retried when
+// Operation.ServerSelectionTimeout is not set.
+const defaultServerSelectionTimeout = 30 * time.Second
+
+// firstRetryBackoff is how long Execute waits before the second retry of an operation. The wait
+// doubles for each further retry, up to maxRetryBackoff. The first retry is sent immediately.
+const (
+	firstRetryBackoff = 100 * time.Millisecond
+	maxRetryBackoff   = 2 * time.Second
+)
+
+// minHeartbeatFrequency is the longest server selection waits for a topology change before trying
+// again. Deployments that cannot signal changes are polled at this interval.
+const minHeartbeatFrequency = 500 * time.Millisecond
+
 var (
 	// ErrNoDocCommandResponse occurs when the server indicated a response existed, but none was found.
 	ErrNoDocCommandResponse = errors.New("command returned no documents")
@@ -35,10 +51,19 @@ var (
 )
 
 // InvalidOperationError is returned from Validate and indicates that a required field is missing
-// from an instance of Operation.
-type InvalidOperationError struct{ MissingField string }
+// from an instance of Operation or that a field has an illegal value.
+type InvalidOperationError struct {
+	MissingField string
+
+	// InvalidField and Reason are set when a field is present but its value is illegal.
+	InvalidField string
+	Reason       string
+}
 
 func (err InvalidOperationError) Error() string {
+	if err.InvalidField != "" {
+		return "the " + err.InvalidField + " field on Operation is invalid: " + err.Reason
+	}
 	return "the " + err.MissingField + " field must be set on Operation"
 }
 
@@ -87,6 +112,12 @@ type Operation struct {
 	// the SingleConnectionDeployment type.
 	Deployment Deployment
 
+	// Server is a server chosen by an earlier operation, such as the server that created a cursor
+	// which a getMore or killCursors must be sent to. If this field is set, server selection is
+	// skipped and this server is used for every attempt, including retries. Deployment is still
+	// required and is used to determine the topology kind.
+	Server Server
+
 	// ProcessResponseFn is called after a response to the command is returned. The server is
 	// provided for types like Cursor that are required to run subsequent commands using the same
 	// server.
@@ -97,6 +128,17 @@ type Operation struct {
 	// SelectServer method may not actually be called.
 	Selector description.ServerSelector
 
+	// LocalThreshold is the size of the latency window used by the default server selector when
+	// Selector is not set. Servers whose average round trip time is within this window of the
+	// fastest suitable server are candidates for selection. If this field is zero, a default of
+	// 15 milliseconds is used.
+	LocalThreshold time.Duration
+
+	// ServerSelectionTimeout is how long server selection is retried when the Deployment returns
+	// ErrNoSuitableServer. If this field is zero, a default of 30 seconds is used. The context's
+	// deadline also bounds server selection.
+	ServerSelectionTimeout time.Duration
+
 	// ReadPreference is the read preference that will be attached to the command. If this field is
 	// not specified a default read preference of primary will be used.
 	ReadPreference *readpref.ReadPref
@@ -106,11 +148,29 @@ type Operation struct {
 	// server.
 	ReadConcern *readconcern.ReadConcern
 
+	// SuppressReadConcern prevents ReadConcern from being sent with the command, even if it is set.
+	// It is used for commands that reject a read concern. The read concern of a transaction the
+	// command starts is still sent.
+	SuppressReadConcern bool
+
 	// WriteConcern is the write concern used when running write commands. This field should not be
 	// set for read operations. If this field is set, it will be encoded onto the commands sent to
 	// the server.
 	WriteConcern *writeconcern.WriteConcern
 
+	// WTimeoutFromContext makes the wtimeout of an acknowledged write concern no longer than the
+	// time remaining before the context's deadline, so that the server does not keep waiting for
+	// replication after the client has given up. It has no effect if the context has no deadline.
+	WTimeoutFromContext bool
+
+	// StandaloneMajority determines how a majority write concern is handled when the selected
+	// server is a standalone. The default is to send it unchanged.
+	StandaloneMajority StandaloneMajority
+
+	// Interrupter, if set, interrupts the server-side work of this operation when the context is
+	// cancelled or the Interrupter is killed while waiting on the server.
+	Interrupter *Interrupter
+
 	// Client is the session used with this operation. This can be either an implicit or explicit
 	// session. If the server selected does not support sessions and Client is specified the
 	// behavior depends on the session type. If the session is implicit, the session fields will not
@@ -135,10 +195,39 @@ type Operation struct {
 	// it's definition. Both RetryType and RetryMode must be set for retryability to be enabled.
 	RetryType RetryType
 
+	// RetryWritesDisabled turns off retryable writes for this operation even when RetryType and
+	// RetryMode are set and the deployment supports them. When set, no txnNumber is attached to the
+	// command.
+	RetryWritesDisabled bool
+
+	// Comment is attached to the command so that it appears in server logs and profiling output.
+	// Commands sent using OP_QUERY carry it in the $comment query modifier, while commands sent
+	// using OP_MSG carry it in the top-level comment field. An empty Comment is not sent.
+	Comment string
+
+	// MaxRetries is the number of times a retryable operation is retried when RetryMode is RetryOnce
+	// or RetryOncePerCommand. Each retry selects a new server and reuses the transaction number of
+	// the original attempt. If this is less than 1, the operation is retried once. The first retry
+	// is sent immediately and later retries back off exponentially, for as long as the context allows.
+	MaxRetries int
+
+	// RetryObserver, if set, is called with the outcome of each retry attempt. It is not called for
+	// operations that succeed or fail without being retried.
+	RetryObserver RetryObserver
+
+	// BufferPool, if set, supplies the buffer used to build wire messages and read replies when
+	// Execute is called without a scratch buffer. The response passed to ProcessResponseFn is backed
+	// by this buffer, which is returned to the pool once Execute succeeds, so ProcessResponseFn must
+	// copy any part of the response it retains. If Execute returns an error the buffer is not
+	// returned to the pool, because the error may reference it.
+	BufferPool *BufferPool
+
 	// Batches contains the documents that are split when executing a write command that potentially
 	// has more documents than can fit in a single command. This should only be specified for
 	// commands that are batch compatible. For more information, please refer to the definition of
-	// Batches.
+	// Batches. When the command is sent using OP_MSG, the current batch is framed as a document
+	// sequence (payload type 1) section named by the Identifier field instead of an array in the
+	// command document.
 	Batches *Batches
 
 	// Legacy sets the legacy type for this operation. There are only 3 types that require legacy
@@ -149,6 +238,15 @@ type Operation struct {
 	// CommandMonitor specifies the monitor to use for APM events. If this field is not set,
 	// no events will be reported.
 	CommandMonitor *event.CommandMonitor
+
+	// ServerAPI specifies the Stable API options to attach to the command. If this field is set
+	// with a non-empty ServerAPIVersion, apiVersion, apiStrict, and apiDeprecationErrors are
+	// encoded onto the command and the command is never wrapped in a $query document when sent
+	// using OP_QUERY.
+	ServerAPI *ServerAPIOptions
+
+	// deadline is the context deadline used to compute wtimeout when WTimeoutFromContext is set.
+	deadline time.Time
 }
 
 // selectServer handles performing server selection for an operation.
@@ -163,19 +261,69 @@ func (op Operation) selectServer(ctx context.Context) (Server, error) {
 	default:
 	}
 
+	if op.Server != nil {
+		return op.Server, nil
+	}
+
 	selector := op.Selector
 	if selector == nil {
 		rp := op.ReadPreference
 		if rp == nil {
 			rp = readpref.Primary()
 		}
+		threshold := op.LocalThreshold
+		if threshold == 0 {
+			threshold = defaultLocalThreshold
+		}
 		selector = description.CompositeSelector([]description.ServerSelector{
 			description.ReadPrefSelector(rp),
-			description.LatencySelector(15 * time.Millisecond),
+			description.LatencySelector(threshold),
 		})
 	}
 
-	return op.Deployment.SelectServer(ctx, selector)
+	srvr, err := op.Deployment.SelectServer(ctx, selector)
+	if err != ErrNoSuitableServer {
+		return srvr, err
+	}
+
+	timeout := op.ServerSelectionTimeout
+	if timeout == 0 {
+		timeout = defaultServerSelectionTimeout
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	var changed <-chan struct{}
+	if notifier, ok := op.Deployment.(TopologyChangeNotifier); ok {
+		changed = notifier.TopologyChanged()
+	}
+
+	for {
+		heartbeat := time.NewTimer(minHeartbeatFrequency)
+		select {
+		case <-changed:
+		case <-heartbeat.C:
+		case <-ctx.Done():
+			heartbeat.Stop()
+			return nil, ctx.Err()
+		case <-deadline.C:
+			heartbeat.Stop()
+			return nil, ErrServerSelectionTimeout
+		}
+		heartbeat.Stop()
+
+		srvr, err = op.Deployment.SelectServer(ctx, selector)
+		if err != ErrNoSuitableServer {
+			return srvr, err
+		}
+	}
+}
+
+// selectedServer returns the description used to build commands sent over conn. The topology kind is
+// read from the deployment each time so that changes to the deployment, such as a Single topology
+// being discovered as a ReplicaSet, are reflected in the read preference that is sent.
+func (op Operation) selectedServer(conn Connection) description.SelectedServer {
+	return description.SelectedServer{Server: conn.Description(), Kind: op.Deployment.Kind()}
 }
 
 // Validate validates this operation, ensuring the fields are set properly.
@@ -189,17 +337,41 @@ func (op Operation) Validate() error {
 	if op.Database == "" {
 		return InvalidOperationError{MissingField: "Database"}
 	}
+	if err := ValidateDatabaseName(op.Database); err != nil {
+		return InvalidOperationError{InvalidField: "Database", Reason: err.Error()}
+	}
+	if op.ReadConcern != nil && op.ReadConcern.GetLevel() == "linearizable" &&
+		op.ReadPreference != nil && op.ReadPreference.Mode() != readpref.PrimaryMode {
+		return InvalidOperationError{
+			InvalidField: "ReadConcern",
+			Reason:       "linearizable read concern can only be used with a primary read preference",
+		}
+	}
 	return nil
 }
 
 // Execute runs this operation. The scratch parameter will be used and overwritten (potentially many
-// times), this should mainly be used to enable pooling of byte slices.
+// times), this should mainly be used to enable pooling of byte slices. If scratch is nil and
+// BufferPool is set, a buffer is taken from the pool instead.
 func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 	err := op.Validate()
 	if err != nil {
 		return err
 	}
 
+	// reply holds the most recently used wire message buffer so it can be returned to the pool.
+	var reply []byte
+	pooled := scratch == nil && op.BufferPool != nil
+	if pooled {
+		scratch = op.BufferPool.Get()
+	}
+
+	if op.Interrupter != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = op.Interrupter.context(ctx)
+		defer cancel()
+	}
+
 	srvr, err := op.selectServer(ctx)
 	if err != nil {
 		return err
@@ -211,7 +383,7 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 	}
 	defer conn.Close()
 
-	desc := description.SelectedServer{Server: conn.Description(), Kind: op.Deployment.Kind()}
+	desc := op.selectedServer(conn)
 
 	// TODO(GODRIVER-617): We should check the wire version here. If we're doing a find, getMore, or
 	// killCursors and the wire version is less than 4 we need to call out to legacy code here.
@@ -232,7 +404,7 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 	var res bsoncore.Document
 	var operationErr WriteCommandError
 	var original error
-	var retries int
+	var retries, attempt int
 	// TODO(GODRIVER-617): Add support for retryable reads.
 	retryable := op.retryable(desc.Server)
 	if retryable == RetryWrite && op.Client != nil && op.RetryMode != nil {
@@ -243,12 +415,16 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 
 		switch *op.RetryMode {
 		case RetryOnce, RetryOncePerCommand:
-			retries = 1
+			retries = op.maxRetries()
 		case RetryContext:
 			retries = -1
 		}
 	}
 	batching := op.Batches.Valid()
+	// sent is the number of documents in the batches that have already completed. Servers report
+	// write error indexes relative to the batch, so they are offset by this to match the caller's
+	// documents.
+	var sent int64
 	for {
 		if batching {
 			err = op.Batches.AdvanceBatch(int(desc.MaxBatchCount), int(desc.MaxDocumentSize))
@@ -262,6 +438,9 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 		if len(scratch) > 0 {
 			scratch = scratch[:0]
 		}
+		if dl, ok := ctx.Deadline(); ok && op.WTimeoutFromContext {
+			op.deadline = dl
+		}
 		wm, startedInfo, err := op.createWireMessage(scratch, desc)
 		if err != nil {
 			return err
@@ -273,7 +452,7 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 		op.publishStartedEvent(ctx, startedInfo)
 
 		// compress wiremessage if allowed
-		if compressor, ok := conn.(Compressor); ok && op.canCompress("") {
+		if compressor, ok := conn.(Compressor); ok && len(wm) > compressionThreshold && op.canCompress(startedInfo.cmdName) {
 			wm, err = compressor.CompressWireMessage(wm, nil)
 			if err != nil {
 				return err
@@ -287,28 +466,56 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 			connID:    startedInfo.connID,
 		}
 
+		// An unacknowledged write is sent with the moreToCome flag set, so the server will not send
+		// a reply and we must not wait for one.
+		if op.unacknowledgedWrite(desc) {
+			reply = wm
+			err = conn.WriteWireMessage(ctx, wm)
+			if err != nil {
+				err = newNetworkError(err)
+			}
+			if ep, ok := srvr.(ErrorProcessor); ok {
+				ep.ProcessError(err, conn)
+			}
+			finishedInfo.response = bsoncore.BuildDocument(nil, bsoncore.AppendInt32Element(nil, "ok", 1))
+			finishedInfo.cmdErr = err
+			op.publishFinishedEvent(ctx, finishedInfo)
+			if err != nil {
+				return err
+			}
+
+			if batching && len(op.Batches.Documents) > 0 {
+				op.Batches.ClearBatch()
+				continue
+			}
+			if pooled {
+				op.BufferPool.Put(reply)
+			}
+			return ErrUnacknowledgedWrite
+		}
+
 		// roundtrip
 		wm, err = op.roundTrip(ctx, conn, wm)
+		reply = wm
 		if ep, ok := srvr.(ErrorProcessor); ok {
-			ep.ProcessError(err)
+			ep.ProcessError(err, conn)
 		}
 		if err != nil {
 			// must fire a CommandFailedEvent even if an error occurred while reading from the socket
 			finishedInfo.cmdErr = err
 			op.publishFinishedEvent(ctx, finishedInfo)
-			return err
-		}
-
-		// decompress wiremessage
-		wm, err = op.decompressWireMessage(wm)
-		if err != nil {
+			op.observeRetry(attempt, original, false)
+			if op.Interrupter != nil && ctx.Err() != nil {
+				conn.Close() // Let the interrupt use the connection's slot in the pool.
+				op.Interrupter.interrupt(srvr, op)
+			}
 			return err
 		}
 
 		// decode
 		res, err = op.decodeResult(wm)
 		if ep, ok := srvr.(ErrorProcessor); ok {
-			ep.ProcessError(err)
+			ep.ProcessError(err, conn)
 		}
 
 		// send event if possible
@@ -320,31 +527,47 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 		// handling the error to ensure we are properly gossiping the cluster time.
 		op.updateClusterTimes(res)
 		op.updateOperationTime(res)
+		op.updateRecoveryToken(res)
+		if op.Interrupter != nil {
+			op.Interrupter.observe(res)
+		}
 
 		var perr error
 		if op.ProcessResponseFn != nil {
 			perr = op.ProcessResponseFn(res, srvr)
 		}
+		op.observeRetry(attempt, original, err == nil && perr == nil)
 		switch tt := err.(type) {
 		case WriteCommandError:
 			if retryable == RetryWrite && tt.Retryable() && retries != 0 {
 				retries--
+				attempt++
 				original, err = err, nil
 				conn.Close() // Avoid leaking the connection.
+				if !retryBackoff(ctx, attempt) {
+					return original
+				}
 				srvr, err = op.selectServer(ctx)
 				if err != nil {
 					return original
 				}
-				conn, err := srvr.Connection(ctx)
-				if err != nil || conn == nil || op.retryable(conn.Description()) != RetryWrite {
-					if conn != nil {
-						conn.Close()
+				newConn, err := srvr.Connection(ctx)
+				if err != nil || newConn == nil || op.retryable(newConn.Description()) != RetryWrite {
+					if newConn != nil {
+						newConn.Close()
 					}
 					return original
 				}
-				defer conn.Close() // Avoid leaking the new connection.
+				defer newConn.Close() // Avoid leaking the new connection.
+				conn = newConn
+				desc = op.selectedServer(conn)
 				continue
 			}
+			if batching {
+				for i := range tt.WriteErrors {
+					tt.WriteErrors[i].Index += sent
+				}
+			}
 			// If batching is enabled and either ordered is the default (which is true) or
 			// explicitly set to true and we have write errors, return the errors.
 			if batching && (op.Batches.Ordered == nil || *op.Batches.Ordered == true) && len(tt.WriteErrors) > 0 {
@@ -355,20 +578,26 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 		case Error:
 			if retryable == RetryWrite && tt.Retryable() && retries != 0 {
 				retries--
+				attempt++
 				original, err = err, nil
 				conn.Close() // Avoid leaking the connection.
+				if !retryBackoff(ctx, attempt) {
+					return original
+				}
 				srvr, err = op.selectServer(ctx)
 				if err != nil {
 					return original
 				}
-				conn, err := srvr.Connection(ctx)
-				if err != nil || conn == nil || op.retryable(conn.Description()) != RetryWrite {
-					if conn != nil {
-						conn.Close()
+				newConn, err := srvr.Connection(ctx)
+				if err != nil || newConn == nil || op.retryable(newConn.Description()) != RetryWrite {
+					if newConn != nil {
+						newConn.Close()
 					}
 					return original
 				}
-				defer conn.Close() // Avoid leaking the new connection.
+				defer newConn.Close() // Avoid leaking the new connection.
+				conn = newConn
+				desc = op.selectedServer(conn)
 				continue
 			}
 			return err
@@ -386,20 +615,74 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 					op.Client.IncrementTxnNumber()
 				}
 				if *op.RetryMode == RetryOncePerCommand {
-					retries = 1
+					retries = op.maxRetries()
 				}
 			}
+			attempt = 0
+			sent += int64(len(op.Batches.Current))
 			op.Batches.ClearBatch()
 			continue
 		}
 		break
 	}
+	if pooled {
+		op.BufferPool.Put(reply)
+	}
+	if len(operationErr.WriteErrors) > 0 || operationErr.WriteConcernError != nil {
+		return operationErr
+	}
 	return nil
 }
 
+// unacknowledgedWrite returns true if this operation has an unacknowledged write concern and will
+// be sent as an OP_MSG, which allows the moreToCome flag to be set.
+func (op Operation) unacknowledgedWrite(desc description.SelectedServer) bool {
+	return !writeconcern.AckWrite(op.WriteConcern) &&
+		desc.WireVersion != nil && desc.WireVersion.Max >= wiremessage.OpmsgWireVersion
+}
+
+// observeRetry reports the outcome of a retry attempt to the RetryObserver. Attempt is zero when
+// the operation has not been retried, in which case nothing is reported.
+func (op Operation) observeRetry(attempt int, err error, success bool) {
+	if attempt == 0 || op.RetryObserver == nil {
+		return
+	}
+	op.RetryObserver(attempt, err, success)
+}
+
+// retryBackoff waits before retry number attempt, returning false if ctx is done first.
+func retryBackoff(ctx context.Context, attempt int) bool {
+	if attempt < 2 {
+		return true
+	}
+	wait := maxRetryBackoff
+	if shift := uint(attempt - 2); shift < 8 && firstRetryBackoff<<shift < maxRetryBackoff {
+		wait = firstRetryBackoff << shift
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// maxRetries returns the number of retries allowed for this operation.
+func (op Operation) maxRetries() int {
+	if op.MaxRetries < 1 {
+		return 1
+	}
+	return op.MaxRetries
+}
+
 // Retryable writes are supported if the server supports sessions, the operation is not
 // within a transaction, and the write is acknowledged
 func (op Operation) retryable(desc description.Server) RetryType {
+	if op.RetryWritesDisabled {
+		return RetryTypeNone
+	}
 	switch op.RetryType {
 	case RetryWrite:
 		if op.Deployment.SupportsRetry() &&
@@ -409,22 +692,23 @@ func (op Operation) retryable(desc description.Server) RetryType {
 			return RetryWrite
 		}
 	}
-	return RetryType(0)
+	return RetryTypeNone
 }
 
 // roundTrip writes a wiremessage to the connection and then reads a wiremessage. The wm parameter
-// is reused when reading the wiremessage.
+// is reused when reading the wiremessage. If the reply is compressed, it is decompressed before
+// being returned.
 func (op Operation) roundTrip(ctx context.Context, conn Connection, wm []byte) ([]byte, error) {
 	err := conn.WriteWireMessage(ctx, wm)
 	if err != nil {
-		return nil, Error{Message: err.Error(), Labels: []string{TransientTransactionError, NetworkError}}
+		return nil, newNetworkError(err)
 	}
 
 	res, err := conn.ReadWireMessage(ctx, wm[:0])
 	if err != nil {
-		err = Error{Message: err.Error(), Labels: []string{TransientTransactionError, NetworkError}}
+		return res, newNetworkError(err)
 	}
-	return res, err
+	return op.decompressWireMessage(res)
 }
 
 // decompressWireMessage handles decompressing a wiremessage. If the wiremessage
@@ -438,6 +722,10 @@ func (Operation) decompressWireMessage(wm []byte) ([]byte, error) {
 	if opcode != wiremessage.OpCompressed {
 		return wm, nil
 	}
+	// header (16) + original opcode (4) + uncompressed size (4) + compressor ID (1)
+	if length < 25 {
+		return nil, fmt.Errorf("malformed OP_COMPRESSED: invalid message length %d", length)
+	}
 	// get the original opcode and uncompressed size
 	opcode, rem, ok = wiremessagex.ReadCompressedOriginalOpCode(rem)
 	if !ok {
@@ -447,41 +735,32 @@ func (Operation) decompressWireMessage(wm []byte) ([]byte, error) {
 	if !ok {
 		return nil, errors.New("malformed OP_COMPRESSED: missing uncompressed size")
 	}
+	// The decompressed message, including its 16 byte header, can't be larger than a server sends.
+	if uncompressedSize < 0 || uncompressedSize > maxMessageSize-16 {
+		return nil, fmt.Errorf("malformed OP_COMPRESSED: invalid uncompressed size %d", uncompressedSize)
+	}
 	// get the compressor ID and decompress the message
 	compressorID, rem, ok := wiremessagex.ReadCompressedCompressorID(rem)
 	if !ok {
 		return nil, errors.New("malformed OP_COMPRESSED: missing compressor ID")
 	}
-	compressedSize := length - 25 // header (16) + original opcode (4) + uncompressed size (4) + compressor ID (1)
+	compressedSize := length - 25
 	// return the original wiremessage
 	msg, rem, ok := wiremessagex.ReadCompressedCompressedMessage(rem, compressedSize)
 	if !ok {
 		return nil, errors.New("malformed OP_COMPRESSED: insufficient bytes for compressed wiremessage")
 	}
 
-	header := make([]byte, 0, uncompressedSize+16)
-	header = wiremessagex.AppendHeader(header, uncompressedSize, reqid, respto, opcode)
-	uncompressed := make([]byte, uncompressedSize)
-	switch compressorID {
-	case wiremessage.CompressorSnappy:
-		var err error
-		uncompressed, err = snappy.Decode(uncompressed, msg)
-		if err != nil {
-			return nil, err
-		}
-	case wiremessage.CompressorZLib:
-		decompressor, err := zlib.NewReader(bytes.NewReader(msg))
-		if err != nil {
-			return nil, err
-		}
-		_, err = io.ReadFull(decompressor, uncompressed)
-		if err != nil {
-			return nil, err
-		}
-	default:
-		return nil, fmt.Errorf("unknown compressorID %d", compressorID)
+	opts := CompressionOpts{Compressor: compressorID, UncompressedSize: uncompressedSize}
+	uncompressed, err := DecompressPayload(msg, opts)
+	if err != nil {
+		return nil, err
 	}
-	return append(header, uncompressed...), nil
+
+	// The length of the original wire message includes its 16 byte header.
+	dst := make([]byte, 0, uncompressedSize+16)
+	dst = wiremessagex.AppendHeader(dst, uncompressedSize+16, reqid, respto, opcode)
+	return append(dst, uncompressed...), nil
 }
 
 func (op Operation) createWireMessage(dst []byte, desc description.SelectedServer) ([]byte, startedInformation, error) {
@@ -502,7 +781,7 @@ func (op Operation) addBatchArray(dst []byte) []byte {
 
 func (op Operation) createQueryWireMessage(dst []byte, desc description.SelectedServer) ([]byte, startedInformation, error) {
 	var info startedInformation
-	flags := op.slaveOK(desc)
+	flags := op.secondaryOK(desc)
 	var wmindex int32
 	info.requestID = wiremessage.NextRequestID()
 	wmindex, dst = wiremessagex.AppendHeaderStart(dst, info.requestID, 0, wiremessage.OpQuery)
@@ -515,13 +794,30 @@ func (op Operation) createQueryWireMessage(dst []byte, desc description.Selected
 	dst = wiremessagex.AppendQueryNumberToReturn(dst, -1)
 
 	wrapper := int32(-1)
-	rp := op.createReadPref(desc.Server.Kind, desc.Kind, true)
-	if len(rp) > 0 {
+	rp, err := op.createReadPref(desc.Server.Kind, desc.Kind, true, desc.HeartbeatInterval)
+	if err != nil {
+		return dst, info, err
+	}
+	// Only a mongos reads $readPreference from an OP_QUERY, and only when the command is wrapped in
+	// $query. Other servers rely on the secondaryOk flag.
+	if desc.Server.Kind != description.Mongos {
+		rp = nil
+	}
+	// The Stable API does not allow legacy modifiers such as $query, so the read preference is only
+	// conveyed through the secondaryOk flag and the comment is sent in the command document.
+	stableAPI := op.ServerAPI != nil && op.ServerAPI.ServerAPIVersion != ""
+	if stableAPI {
+		rp = nil
+	}
+	// A comment is a query modifier for OP_QUERY, so it also requires the $query wrapper.
+	modifierComment := op.Comment != "" && !stableAPI
+	wrap := len(rp) > 0 || modifierComment
+	if wrap {
 		wrapper, dst = bsoncore.AppendDocumentStart(dst)
 		dst = bsoncore.AppendHeader(dst, bsontype.EmbeddedDocument, "$query")
 	}
 	idx, dst := bsoncore.AppendDocumentStart(dst)
-	dst, err := op.CommandFn(dst, desc)
+	dst, err = op.CommandFn(dst, desc)
 	if err != nil {
 		return dst, info, err
 	}
@@ -535,7 +831,7 @@ func (op Operation) createQueryWireMessage(dst []byte, desc description.Selected
 		return dst, info, err
 	}
 
-	dst, err = op.addWriteConcern(dst)
+	dst, err = op.addWriteConcern(dst, desc)
 	if err != nil {
 		return dst, info, err
 	}
@@ -549,19 +845,27 @@ func (op Operation) createQueryWireMessage(dst []byte, desc description.Selected
 	// either turn off RetryWrite when we are doing a retryable read or that we pass in RetryType to
 	// addSession. We should also only be adding this if the connection supports sessions, but I
 	// think that's a given if we've set RetryWrite to true.
-	if op.RetryType == RetryWrite && op.Client != nil && op.Client.RetryWrite {
+	if op.RetryType == RetryWrite && !op.RetryWritesDisabled && op.Client != nil && op.Client.RetryWrite {
 		dst = bsoncore.AppendInt64Element(dst, "txnNumber", op.Client.TxnNumber)
 	}
 
 	dst = op.addClusterTime(dst, desc)
+	dst = op.addServerAPI(dst)
+	if op.Comment != "" && !modifierComment {
+		dst = bsoncore.AppendStringElement(dst, "comment", op.Comment)
+	}
 
 	dst, _ = bsoncore.AppendDocumentEnd(dst, idx)
 	// Command monitoring only reports the document inside $query
 	info.cmd = dst[idx:]
 
-	if len(rp) > 0 {
-		var err error
-		dst = bsoncore.AppendDocumentElement(dst, "$readPreference", rp)
+	if wrap {
+		if len(rp) > 0 {
+			dst = bsoncore.AppendDocumentElement(dst, "$readPreference", rp)
+		}
+		if modifierComment {
+			dst = bsoncore.AppendStringElement(dst, "$comment", op.Comment)
+		}
 		dst, err = bsoncore.AppendDocumentEnd(dst, wrapper)
 		if err != nil {
 			return dst, info, err
@@ -573,9 +877,10 @@ func (op Operation) createQueryWireMessage(dst []byte, desc description.Selected
 
 func (op Operation) createMsgWireMessage(dst []byte, desc description.SelectedServer) ([]byte, startedInformation, error) {
 	var info startedInformation
-	// TODO(GODRIVER-617): We need to figure out how to include the writeconcern here so that we can
-	// set the moreToCome bit.
 	var flags wiremessage.MsgFlag
+	if op.unacknowledgedWrite(desc) {
+		flags |= wiremessage.MoreToCome
+	}
 	var wmindex int32
 	info.requestID = wiremessage.NextRequestID()
 	wmindex, dst = wiremessagex.AppendHeaderStart(dst, info.requestID, 0, wiremessage.OpMsg)
@@ -593,7 +898,7 @@ func (op Operation) createMsgWireMessage(dst []byte, desc description.SelectedSe
 	if err != nil {
 		return dst, info, err
 	}
-	dst, err = op.addWriteConcern(dst)
+	dst, err = op.addWriteConcern(dst, desc)
 	if err != nil {
 		return dst, info, err
 	}
@@ -607,14 +912,21 @@ func (op Operation) createMsgWireMessage(dst []byte, desc description.SelectedSe
 	// either turn off RetryWrite when we are doing a retryable read or that we pass in RetryType to
 	// addSession. We should also only be adding this if the connection supports sessions, but I
 	// think that's a given if we've set RetryWrite to true.
-	if op.RetryType == RetryWrite && op.Client != nil && op.Client.RetryWrite {
+	if op.RetryType == RetryWrite && !op.RetryWritesDisabled && op.Client != nil && op.Client.RetryWrite {
 		dst = bsoncore.AppendInt64Element(dst, "txnNumber", op.Client.TxnNumber)
 	}
 
 	dst = op.addClusterTime(dst, desc)
+	dst = op.addServerAPI(dst)
+	if op.Comment != "" {
+		dst = bsoncore.AppendStringElement(dst, "comment", op.Comment)
+	}
 
 	dst = bsoncore.AppendStringElement(dst, "$db", op.Database)
-	rp := op.createReadPref(desc.Server.Kind, desc.Kind, false)
+	rp, err := op.createReadPref(desc.Server.Kind, desc.Kind, false, desc.HeartbeatInterval)
+	if err != nil {
+		return dst, info, err
+	}
 	if len(rp) > 0 {
 		dst = bsoncore.AppendDocumentElement(dst, "$readPreference", rp)
 	}
@@ -626,24 +938,39 @@ func (op Operation) createMsgWireMessage(dst []byte, desc description.SelectedSe
 	if op.Batches != nil && len(op.Batches.Current) > 0 {
 		info.documentSequenceIncluded = true
 		dst = wiremessagex.AppendMsgSectionType(dst, wiremessage.DocumentSequence)
-		idx, dst = bsoncore.ReserveLength(dst)
-
-		dst = append(dst, op.Batches.Identifier...)
-		dst = append(dst, 0x00)
+		dst = wiremessagex.AppendMsgSectionDocumentSequence(dst, op.Batches.Identifier, op.Batches.Current...)
+	}
 
-		for _, doc := range op.Batches.Current {
-			dst = append(dst, doc...)
-		}
+	return bsoncore.UpdateLength(dst, wmindex, int32(len(dst[wmindex:]))), info, nil
+}
 
-		dst = bsoncore.UpdateLength(dst, idx, int32(len(dst[idx:])))
+func (op Operation) addServerAPI(dst []byte) []byte {
+	sa := op.ServerAPI
+	if sa == nil || sa.ServerAPIVersion == "" {
+		return dst
 	}
 
-	return bsoncore.UpdateLength(dst, wmindex, int32(len(dst[wmindex:]))), info, nil
+	dst = bsoncore.AppendStringElement(dst, "apiVersion", sa.ServerAPIVersion)
+	if sa.Strict != nil {
+		dst = bsoncore.AppendBooleanElement(dst, "apiStrict", *sa.Strict)
+	}
+	if sa.DeprecationErrors != nil {
+		dst = bsoncore.AppendBooleanElement(dst, "apiDeprecationErrors", *sa.DeprecationErrors)
+	}
+	return dst
 }
 
 func (op Operation) addReadConcern(dst []byte, desc description.SelectedServer) ([]byte, error) {
 	rc := op.ReadConcern
+	if op.SuppressReadConcern {
+		rc = nil
+	}
 	client := op.Client
+	// Only the first statement of a transaction may carry a read concern; the server applies it
+	// to the rest of the transaction.
+	if client != nil && client.TransactionInProgress() {
+		return dst, nil
+	}
 	// Starting transaction's read concern overrides all others
 	if client != nil && client.TransactionStarting() && client.CurrentRc != nil {
 		rc = client.CurrentRc
@@ -672,11 +999,45 @@ func (op Operation) addReadConcern(dst []byte, desc description.SelectedServer)
 	return bsoncore.AppendDocumentElement(dst, "readConcern", data), nil
 }
 
-func (op Operation) addWriteConcern(dst []byte) ([]byte, error) {
-	wc := op.WriteConcern
+// resolveWriteConcern returns the write concern to send with a command. A transaction's write
+// concern is used for the commands that end a transaction, overriding the operation's write concern.
+// Collection and client defaults are folded into the operation's write concern by the caller.
+func resolveWriteConcern(opWC, txnWC *writeconcern.WriteConcern, inTxn bool) *writeconcern.WriteConcern {
+	if inTxn && txnWC != nil {
+		return txnWC
+	}
+	return opWC
+}
+
+func (op Operation) addWriteConcern(dst []byte, desc description.SelectedServer) ([]byte, error) {
+	var txnWC *writeconcern.WriteConcern
+	client := op.Client
+	inTxn := client != nil && (client.Committing || client.Aborting)
+	if inTxn {
+		txnWC = client.CurrentWc
+	}
+	wc := resolveWriteConcern(op.WriteConcern, txnWC, inTxn)
 	if wc == nil {
 		return dst, nil
 	}
+	if w, ok := wc.GetW().(string); ok && w == "majority" && isStandalone(desc) {
+		switch op.StandaloneMajority {
+		case StandaloneMajorityDowngrade:
+			wc = writeconcern.New(writeconcern.J(wc.GetJ()), writeconcern.WTimeout(wc.GetWTimeout()))
+		case StandaloneMajorityError:
+			return dst, ErrMajorityOnStandalone
+		}
+	}
+	if !op.deadline.IsZero() && wc.Acknowledged() {
+		// A wtimeout of zero means no timeout, so never send less than a millisecond.
+		remaining := time.Until(op.deadline)
+		if remaining < time.Millisecond {
+			remaining = time.Millisecond
+		}
+		if wtimeout := wc.GetWTimeout(); wtimeout == 0 || remaining < wtimeout {
+			wc = wc.WithOptions(writeconcern.WTimeout(remaining))
+		}
+	}
 
 	t, data, err := wc.MarshalBSONValue()
 	if err == writeconcern.ErrEmptyWriteConcern {
@@ -689,6 +1050,11 @@ func (op Operation) addWriteConcern(dst []byte) ([]byte, error) {
 	return append(bsoncore.AppendHeader(dst, t, "writeConcern"), data...), nil
 }
 
+// isStandalone returns true if desc is a standalone server in a single server topology.
+func isStandalone(desc description.SelectedServer) bool {
+	return desc.Kind == description.Single && desc.Server.Kind == description.Standalone
+}
+
 func (op Operation) addSession(dst []byte, desc description.SelectedServer) ([]byte, error) {
 	client := op.Client
 	if client == nil || !description.SessionsSupported(desc.WireVersion) || desc.SessionTimeoutMinutes == 0 {
@@ -778,7 +1144,25 @@ func (op Operation) updateOperationTime(response bsoncore.Document) {
 	})
 }
 
-func (op Operation) createReadPref(serverKind description.ServerKind, topologyKind description.TopologyKind, isOpQuery bool) bsoncore.Document {
+// updateRecoveryToken stores the recoveryToken returned by a mongos during a sharded transaction on
+// the session attached to this operation, so it can be sent with commitTransaction and
+// abortTransaction.
+func (op Operation) updateRecoveryToken(response bsoncore.Document) {
+	if op.Client == nil || response == nil {
+		return
+	}
+	op.Client.UpdateRecoveryToken(bson.Raw(response))
+}
+
+// createReadPref creates the $readPreference document for this operation. The heartbeatInterval
+// is used to compute the smallest allowed maxStalenessSeconds, which is the heartbeat frequency plus
+// the idle write period. An error is returned if the read preference's max staleness is smaller.
+func (op Operation) createReadPref(
+	serverKind description.ServerKind,
+	topologyKind description.TopologyKind,
+	isOpQuery bool,
+	heartbeatInterval time.Duration,
+) (bsoncore.Document, error) {
 	idx, doc := bsoncore.AppendDocumentStart(nil)
 	rp := op.ReadPreference
 
@@ -786,28 +1170,40 @@ func (op Operation) createReadPref(serverKind description.ServerKind, topologyKi
 		if topologyKind == description.Single && serverKind != description.Mongos {
 			doc = bsoncore.AppendStringElement(doc, "mode", "primaryPreferred")
 			doc, _ = bsoncore.AppendDocumentEnd(doc, idx)
-			return doc
+			return doc, nil
+		}
+		return nil, nil
+	}
+
+	if d, ok := rp.MaxStaleness(); ok {
+		if err := description.ValidateMaxStaleness(d, heartbeatInterval); err != nil {
+			return nil, err
 		}
-		return nil
 	}
 
 	switch rp.Mode() {
 	case readpref.PrimaryMode:
 		if serverKind == description.Mongos {
-			return nil
+			return nil, nil
 		}
 		if topologyKind == description.Single {
 			doc = bsoncore.AppendStringElement(doc, "mode", "primaryPreferred")
 			doc, _ = bsoncore.AppendDocumentEnd(doc, idx)
-			return doc
+			return doc, nil
 		}
 		doc = bsoncore.AppendStringElement(doc, "mode", "primary")
 	case readpref.PrimaryPreferredMode:
 		doc = bsoncore.AppendStringElement(doc, "mode", "primaryPreferred")
 	case readpref.SecondaryPreferredMode:
+		// A mongos would fall back to the primary for secondaryPreferred, so strict tags are sent
+		// as secondary.
+		if rp.StrictTags() {
+			doc = bsoncore.AppendStringElement(doc, "mode", "secondary")
+			break
+		}
 		_, ok := rp.MaxStaleness()
 		if serverKind == description.Mongos && isOpQuery && !ok && len(rp.TagSets()) == 0 {
-			return nil
+			return nil, nil
 		}
 		doc = bsoncore.AppendStringElement(doc, "mode", "secondaryPreferred")
 	case readpref.SecondaryMode:
@@ -842,10 +1238,17 @@ func (op Operation) createReadPref(serverKind description.ServerKind, topologyKi
 	}
 
 	doc, _ = bsoncore.AppendDocumentEnd(doc, idx)
-	return doc
+	return doc, nil
 }
 
-func (op Operation) slaveOK(desc description.SelectedServer) wiremessage.QueryFlag {
+// secondaryOK returns the OP_QUERY flag that allows a command to run on a secondary, historically
+// called slaveOk. OP_MSG has no such flag because the $readPreference document decides where the
+// command may run, so no flag is returned for servers that use OP_MSG.
+func (op Operation) secondaryOK(desc description.SelectedServer) wiremessage.QueryFlag {
+	if desc.WireVersion != nil && desc.WireVersion.Max >= wiremessage.OpmsgWireVersion {
+		return 0
+	}
+
 	if desc.Kind == description.Single && desc.Server.Kind != description.Mongos {
 		return wiremessage.SlaveOK
 	}
@@ -868,11 +1271,11 @@ func (Operation) canCompress(cmd string) bool {
 func (Operation) decodeResult(wm []byte) (bsoncore.Document, error) {
 	wmLength := len(wm)
 	length, _, _, opcode, wm, ok := wiremessagex.ReadHeader(wm)
-	if !ok || int(length) > wmLength {
+	if !ok || length < 16 || int(length) > wmLength {
 		return nil, errors.New("malformed wire message: insufficient bytes")
 	}
 
-	wm = wm[:wmLength-16] // constrain to just this wiremessage, incase there are multiple in the slice
+	wm = wm[:length-16] // constrain to just this wiremessage, incase there are multiple in the slice
 
 	switch opcode {
 	case wiremessage.OpReply:
@@ -900,6 +1303,9 @@ func (Operation) decodeResult(wm []byte) (bsoncore.Document, error) {
 		if numReturned > 1 {
 			return nil, ErrMultiDocCommandResponse
 		}
+		if err := checkDocumentLength(wm); err != nil {
+			return nil, err
+		}
 		var rdr bsoncore.Document
 		rdr, rem, ok := wiremessagex.ReadReplyDocument(wm)
 		if !ok || len(rem) > 0 {
@@ -933,6 +1339,9 @@ func (Operation) decodeResult(wm []byte) (bsoncore.Document, error) {
 
 			switch stype {
 			case wiremessage.SingleDocument:
+				if err := checkDocumentLength(wm); err != nil {
+					return nil, err
+				}
 				res, wm, ok = wiremessagex.ReadMsgSectionSingleDocument(wm)
 				if !ok {
 					return nil, errors.New("malformed wire message: insufficient bytes to read single document")
@@ -959,6 +1368,22 @@ func (Operation) decodeResult(wm []byte) (bsoncore.Document, error) {
 	}
 }
 
+// checkDocumentLength validates the length prefix of the BSON document at the start of src against
+// the number of bytes remaining in the wire message. A reply that declares a document larger than
+// the message that carried it is truncated or corrupt and must not be parsed.
+func checkDocumentLength(src []byte) error {
+	length, _, ok := bsoncore.ReadLength(src)
+	if !ok {
+		return Error{Message: "malformed wire message: insufficient bytes to read document length", Origin: OriginNetwork}
+	}
+	if length < 5 || int(length) > len(src) {
+		return Error{Message: fmt.Sprintf(
+			"malformed wire message: document length %d does not match remaining message length %d", length, len(src),
+		), Origin: OriginNetwork}
+	}
+	return nil
+}
+
 // getCommandName returns the name of the command from the given BSON document.
 func (op Operation) getCommandName(doc []byte) string {
 	// skip 4 bytes for document length and 1 byte for element type
@@ -1000,21 +1425,6 @@ func (op Operation) publishStartedEvent(ctx context.Context, info startedInforma
 		ConnectionID: info.connID,
 	}
 	op.CommandMonitor.Started(ctx, started)
-
-	// unacknowledged writes must publish a CommandSucceededEvent
-	if op.WriteConcern.Acknowledged() || op.CommandMonitor.Succeeded == nil {
-		return
-	}
-
-	finished := event.CommandFinishedEvent{
-		CommandName:  info.cmdName,
-		RequestID:    started.RequestID,
-		ConnectionID: info.connID,
-	}
-	op.CommandMonitor.Succeeded(ctx, &event.CommandSucceededEvent{
-		Reply:                bsoncore.BuildDocument(nil, bsoncore.AppendInt32Element(nil, "ok", 1)),
-		CommandFinishedEvent: finished,
-	})
 }
 
 // publishFinishedEvent publishes either a CommandSucceededEvent or a CommandFailedEvent to the operation's command
@@ -3,17 +3,22 @@ package driver
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
 	"github.com/lakshay2395/mongo-go-driver/bson/primitive"
+	"github.com/lakshay2395/mongo-go-driver/event"
 	"github.com/lakshay2395/mongo-go-driver/mongo/readconcern"
 	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
 	"github.com/lakshay2395/mongo-go-driver/mongo/writeconcern"
 	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	wiremessagex "github.com/lakshay2395/mongo-go-driver/x/mongo/driver/wiremessage"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/uuid"
 	"github.com/lakshay2395/mongo-go-driver/x/network/address"
@@ -97,6 +102,114 @@ func TestOperation(t *testing.T) {
 				t.Error("The selectServer method should use a default selector when not specified on Operation, but it passed <nil>.")
 			}
 		})
+		t.Run("retries until a server is suitable", func(t *testing.T) {
+			want := mockServer{}
+			d := newChangingDeployment()
+			op := &Operation{
+				CommandFn:  func([]byte, description.SelectedServer) ([]byte, error) { return nil, nil },
+				Deployment: d,
+				Database:   "testing",
+			}
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				d.setServer(want)
+			}()
+			start := time.Now()
+			got, err := op.selectServer(context.Background())
+			noerr(t, err)
+			if got != want {
+				t.Errorf("Did not get expected server. got %v; want %v", got, want)
+			}
+			if elapsed := time.Since(start); elapsed >= minHeartbeatFrequency {
+				t.Errorf("Selection should be retried when the topology changes. took %v", elapsed)
+			}
+		})
+		t.Run("polls deployments that do not signal changes", func(t *testing.T) {
+			d := new(mockDeployment)
+			d.returns.err = ErrNoSuitableServer
+			op := &Operation{
+				CommandFn:              func([]byte, description.SelectedServer) ([]byte, error) { return nil, nil },
+				Deployment:             d,
+				Database:               "testing",
+				ServerSelectionTimeout: 2 * minHeartbeatFrequency,
+			}
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				d.mu.Lock()
+				d.returns.server, d.returns.err = mockServer{}, nil
+				d.mu.Unlock()
+			}()
+			_, err := op.selectServer(context.Background())
+			noerr(t, err)
+		})
+		t.Run("returns timeout error when no server is suitable", func(t *testing.T) {
+			d := newChangingDeployment()
+			op := &Operation{
+				CommandFn:              func([]byte, description.SelectedServer) ([]byte, error) { return nil, nil },
+				Deployment:             d,
+				Database:               "testing",
+				ServerSelectionTimeout: 20 * time.Millisecond,
+			}
+			_, err := op.selectServer(context.Background())
+			if err != ErrServerSelectionTimeout {
+				t.Errorf("Did not get expected error. got %v; want %v", err, ErrServerSelectionTimeout)
+			}
+		})
+		t.Run("returns context error when deadline passes during selection", func(t *testing.T) {
+			d := newChangingDeployment()
+			op := &Operation{
+				CommandFn:  func([]byte, description.SelectedServer) ([]byte, error) { return nil, nil },
+				Deployment: d,
+				Database:   "testing",
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			_, err := op.selectServer(ctx)
+			if err != context.DeadlineExceeded {
+				t.Errorf("Did not get expected error. got %v; want %v", err, context.DeadlineExceeded)
+			}
+		})
+		t.Run("default server selector uses LocalThreshold", func(t *testing.T) {
+			servers := []description.Server{
+				{Addr: address.Address("a"), Kind: description.RSSecondary, AverageRTT: 5 * time.Millisecond, AverageRTTSet: true},
+				{Addr: address.Address("b"), Kind: description.RSSecondary, AverageRTT: 15 * time.Millisecond, AverageRTTSet: true},
+				{Addr: address.Address("c"), Kind: description.RSSecondary, AverageRTT: 30 * time.Millisecond, AverageRTTSet: true},
+				{Addr: address.Address("d"), Kind: description.RSSecondary, AverageRTT: 60 * time.Millisecond, AverageRTTSet: true},
+			}
+			topo := description.Topology{Kind: description.ReplicaSetNoPrimary, Servers: servers}
+			testCases := []struct {
+				name      string
+				threshold time.Duration
+				want      []address.Address
+			}{
+				{"default", 0, []address.Address{"a", "b"}},
+				{"wider", 30 * time.Millisecond, []address.Address{"a", "b", "c"}},
+				{"narrower", time.Millisecond, []address.Address{"a"}},
+			}
+			for _, tc := range testCases {
+				t.Run(tc.name, func(t *testing.T) {
+					d := new(mockDeployment)
+					op := &Operation{
+						CommandFn:      func([]byte, description.SelectedServer) ([]byte, error) { return nil, nil },
+						Deployment:     d,
+						Database:       "testing",
+						ReadPreference: readpref.Nearest(),
+						LocalThreshold: tc.threshold,
+					}
+					_, err := op.selectServer(context.Background())
+					noerr(t, err)
+					selected, err := d.params.selector.SelectServer(topo, servers)
+					noerr(t, err)
+					got := make([]address.Address, 0, len(selected))
+					for _, s := range selected {
+						got = append(got, s.Addr)
+					}
+					if !cmp.Equal(got, tc.want) {
+						t.Errorf("Did not select expected servers. got %v; want %v", got, tc.want)
+					}
+				})
+			}
+		})
 	})
 	t.Run("Validate", func(t *testing.T) {
 		cmdFn := func([]byte, description.SelectedServer) ([]byte, error) { return nil, nil }
@@ -110,6 +223,44 @@ func TestOperation(t *testing.T) {
 			{"Deployment", &Operation{CommandFn: cmdFn}, InvalidOperationError{MissingField: "Deployment"}},
 			{"Database", &Operation{CommandFn: cmdFn, Deployment: d}, InvalidOperationError{MissingField: "Database"}},
 			{"<nil>", &Operation{CommandFn: cmdFn, Deployment: d, Database: "test"}, nil},
+			{"$external", &Operation{CommandFn: cmdFn, Deployment: d, Database: "$external"}, nil},
+			{
+				"Database with space",
+				&Operation{CommandFn: cmdFn, Deployment: d, Database: "my db"},
+				InvalidOperationError{InvalidField: "Database", Reason: `database name "my db" contains illegal character ' '`},
+			},
+			{
+				"Database with $",
+				&Operation{CommandFn: cmdFn, Deployment: d, Database: "my$db"},
+				InvalidOperationError{InvalidField: "Database", Reason: `database name "my$db" contains illegal character '$'`},
+			},
+			{
+				"Database too long",
+				&Operation{CommandFn: cmdFn, Deployment: d, Database: strings.Repeat("a", 65)},
+				InvalidOperationError{
+					InvalidField: "Database",
+					Reason:       `database name "` + strings.Repeat("a", 65) + `" is longer than 64 bytes`,
+				},
+			},
+			{
+				"linearizable with secondary",
+				&Operation{
+					CommandFn: cmdFn, Deployment: d, Database: "test",
+					ReadConcern: readconcern.Linearizable(), ReadPreference: readpref.Secondary(),
+				},
+				InvalidOperationError{
+					InvalidField: "ReadConcern",
+					Reason:       "linearizable read concern can only be used with a primary read preference",
+				},
+			},
+			{
+				"linearizable with primary",
+				&Operation{
+					CommandFn: cmdFn, Deployment: d, Database: "test",
+					ReadConcern: readconcern.Linearizable(), ReadPreference: readpref.Primary(),
+				},
+				nil,
+			},
 		}
 
 		for _, tc := range testCases {
@@ -161,24 +312,29 @@ func TestOperation(t *testing.T) {
 			desc description.Server
 			want RetryType
 		}{
-			{"deployment doesn't support", Operation{Deployment: deploymentNoRetry}, description.Server{}, RetryType(0)},
-			{"wire version too low", Operation{Deployment: deploymentRetry, Client: sess, WriteConcern: wcAck}, descNotRetryable, RetryType(0)},
+			{"deployment doesn't support", Operation{Deployment: deploymentNoRetry}, description.Server{}, RetryTypeNone},
+			{"wire version too low", Operation{Deployment: deploymentRetry, Client: sess, WriteConcern: wcAck}, descNotRetryable, RetryTypeNone},
 			{
 				"transaction in progress",
 				Operation{Deployment: deploymentRetry, Client: sessInProgressTransaction, WriteConcern: wcAck},
-				descRetryable, RetryType(0),
+				descRetryable, RetryTypeNone,
 			},
 			{
 				"transaction starting",
 				Operation{Deployment: deploymentRetry, Client: sessStartingTransaction, WriteConcern: wcAck},
-				descRetryable, RetryType(0),
+				descRetryable, RetryTypeNone,
 			},
-			{"unacknowledged write concern", Operation{Deployment: deploymentRetry, Client: sess, WriteConcern: wcUnack}, descRetryable, RetryType(0)},
+			{"unacknowledged write concern", Operation{Deployment: deploymentRetry, Client: sess, WriteConcern: wcUnack}, descRetryable, RetryTypeNone},
 			{
 				"acknowledged write concern",
 				Operation{Deployment: deploymentRetry, Client: sess, WriteConcern: wcAck, RetryType: RetryWrite},
 				descRetryable, RetryWrite,
 			},
+			{
+				"retry writes disabled",
+				Operation{Deployment: deploymentRetry, Client: sess, WriteConcern: wcAck, RetryType: RetryWrite, RetryWritesDisabled: true},
+				descRetryable, RetryTypeNone,
+			},
 		}
 
 		for _, tc := range testCases {
@@ -190,7 +346,88 @@ func TestOperation(t *testing.T) {
 			})
 		}
 	})
+	t.Run("unacknowledged write", func(t *testing.T) {
+		conn := &mockConnection{
+			rDesc:    description.Server{WireVersion: &description.VersionRange{Max: 6}},
+			rReadErr: errors.New("ReadWireMessage should not be called"),
+		}
+		op := Operation{
+			CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+				return bsoncore.AppendStringElement(dst, "insert", "bar"), nil
+			},
+			Database:     "foo",
+			Deployment:   SingleConnectionDeployment{C: conn},
+			WriteConcern: writeconcern.New(writeconcern.W(0)),
+		}
+		err := op.Execute(context.Background(), nil)
+		if err != ErrUnacknowledgedWrite {
+			t.Fatalf("Expected an unacknowledged write error. got %v; want %v", err, ErrUnacknowledgedWrite)
+		}
+
+		_, _, _, _, rem, ok := wiremessagex.ReadHeader(conn.pWriteWM)
+		if !ok {
+			t.Fatalf("Could not read wire message header")
+		}
+		flags, _, ok := wiremessagex.ReadMsgFlags(rem)
+		if !ok {
+			t.Fatalf("Could not read OP_MSG flags")
+		}
+		if flags&wiremessage.MoreToCome == 0 {
+			t.Errorf("Expected the moreToCome flag to be set. got %v", flags)
+		}
+	})
+	t.Run("unacknowledged write events", func(t *testing.T) {
+		testCases := []struct {
+			name                                   string
+			writeErr                               error
+			wantStarted, wantSucceeded, wantFailed int
+		}{
+			{"success", nil, 1, 1, 0},
+			{"network error", errors.New("connection reset by peer"), 1, 0, 1},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				var started, succeeded, failed []int64
+				monitor := &event.CommandMonitor{
+					Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+						started = append(started, evt.RequestID)
+					},
+					Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+						succeeded = append(succeeded, evt.RequestID)
+					},
+					Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+						failed = append(failed, evt.RequestID)
+					},
+				}
+				conn := &mockConnection{
+					rDesc:     description.Server{WireVersion: &description.VersionRange{Max: 6}},
+					rWriteErr: tc.writeErr,
+				}
+				op := Operation{
+					CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+						return bsoncore.AppendStringElement(dst, "insert", "bar"), nil
+					},
+					Database:       "foo",
+					Deployment:     SingleConnectionDeployment{C: conn},
+					WriteConcern:   writeconcern.New(writeconcern.W(0)),
+					CommandMonitor: monitor,
+				}
+				_ = op.Execute(context.Background(), nil)
+
+				if len(started) != tc.wantStarted || len(succeeded) != tc.wantSucceeded || len(failed) != tc.wantFailed {
+					t.Fatalf("Unexpected events. got %d started, %d succeeded, %d failed; want %d, %d, %d",
+						len(started), len(succeeded), len(failed), tc.wantStarted, tc.wantSucceeded, tc.wantFailed)
+				}
+				finished := append(succeeded, failed...)
+				if finished[0] != started[0] {
+					t.Errorf("Request IDs do not match. got %d; want %d", finished[0], started[0])
+				}
+			})
+		}
+	})
 	t.Run("roundTrip", func(t *testing.T) {
+		reply := opMsgReply(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1)))
+		compressedReply := compressWireMessage(t, reply, wiremessage.CompressorZLib)
 		testCases := []struct {
 			name    string
 			conn    *mockConnection
@@ -202,15 +439,22 @@ func TestOperation(t *testing.T) {
 				"returns write error",
 				&mockConnection{rWriteErr: errors.New("write error")},
 				nil, nil,
-				Error{Message: "write error", Labels: []string{TransientTransactionError, NetworkError}},
+				Error{Message: "write error", Labels: []string{TransientTransactionError, NetworkError}, Origin: OriginNetwork},
 			},
 			{
 				"returns read error",
 				&mockConnection{rReadErr: errors.New("read error")},
 				nil, nil,
-				Error{Message: "read error", Labels: []string{TransientTransactionError, NetworkError}},
+				Error{Message: "read error", Labels: []string{TransientTransactionError, NetworkError}, Origin: OriginNetwork},
 			},
-			{"success", &mockConnection{rReadWM: []byte{0x01, 0x02, 0x03, 0x04}}, nil, []byte{0x01, 0x02, 0x03, 0x04}, nil},
+			{
+				"returns read timeout",
+				&mockConnection{rReadErr: context.DeadlineExceeded},
+				nil, nil,
+				Error{Message: context.DeadlineExceeded.Error(), Labels: []string{NetworkError}, Origin: OriginTimeout},
+			},
+			{"success", &mockConnection{rReadWM: reply}, nil, reply, nil},
+			{"decompresses reply", &mockConnection{rReadWM: compressedReply}, nil, reply, nil},
 		}
 
 		for _, tc := range testCases {
@@ -222,9 +466,64 @@ func TestOperation(t *testing.T) {
 				if !cmp.Equal(gotErr, tc.wantErr, cmp.Comparer(compareErrors)) {
 					t.Errorf("Returned error is not equal to expected error. got %v; want %v", gotErr, tc.wantErr)
 				}
+				if want, ok := tc.wantErr.(Error); ok {
+					got, _ := gotErr.(Error)
+					if !cmp.Equal(got.Labels, want.Labels) {
+						t.Errorf("Returned error labels are not equal. got %v; want %v", got.Labels, want.Labels)
+					}
+					if got.Origin != want.Origin {
+						t.Errorf("Returned error origins are not equal. got %v; want %v", got.Origin, want.Origin)
+					}
+				}
 			})
 		}
 	})
+	t.Run("decodeResult", func(t *testing.T) {
+		doc := bsoncore.BuildDocument(nil, bsoncore.AppendInt32Element(nil, "ok", 1))
+		// overflow sets the document's length prefix past the end of the wire message.
+		overflow := func(wm []byte, docStart int) []byte {
+			wm = append([]byte(nil), wm...)
+			binary.LittleEndian.PutUint32(wm[docStart:], uint32(len(wm)))
+			return wm
+		}
+		opReply := func(doc bsoncore.Document) []byte {
+			idx, wm := wiremessagex.AppendHeaderStart(nil, 0, 0, wiremessage.OpReply)
+			wm = wiremessagex.AppendReplyFlags(wm, 0)
+			wm = wiremessagex.AppendReplyCursorID(wm, 0)
+			wm = wiremessagex.AppendReplyStartingFrom(wm, 0)
+			wm = wiremessagex.AppendReplyNumberReturned(wm, 1)
+			wm = append(wm, doc...)
+			return bsoncore.UpdateLength(wm, idx, int32(len(wm[idx:])))
+		}
+
+		t.Run("OP_MSG", func(t *testing.T) {
+			got, err := Operation{}.decodeResult(opMsgReply(doc))
+			noerr(t, err)
+			if !bytes.Equal(got, doc) {
+				t.Errorf("Documents do not match. got %v; want %v", got, doc)
+			}
+		})
+		t.Run("OP_MSG document length overflows message", func(t *testing.T) {
+			_, err := Operation{}.decodeResult(overflow(opMsgReply(doc), 21))
+			if _, ok := err.(Error); !ok {
+				t.Fatalf("Expected a driver.Error. got %T: %v", err, err)
+			}
+		})
+		t.Run("OP_REPLY document length overflows message", func(t *testing.T) {
+			_, err := Operation{}.decodeResult(overflow(opReply(doc), 36))
+			if _, ok := err.(Error); !ok {
+				t.Fatalf("Expected a driver.Error. got %T: %v", err, err)
+			}
+		})
+		t.Run("negative document length", func(t *testing.T) {
+			wm := opMsgReply(doc)
+			binary.LittleEndian.PutUint32(wm[21:], 0xFFFFFFFF)
+			_, err := Operation{}.decodeResult(wm)
+			if _, ok := err.(Error); !ok {
+				t.Fatalf("Expected a driver.Error. got %T: %v", err, err)
+			}
+		})
+	})
 	t.Run("addReadConcern", func(t *testing.T) {
 		want := bsoncore.AppendDocumentElement(nil, "readConcern", bsoncore.BuildDocument(nil,
 			bsoncore.AppendStringElement(nil, "level", "majority"),
@@ -235,15 +534,367 @@ func TestOperation(t *testing.T) {
 			t.Errorf("ReadConcern elements do not match. got %v; want %v", got, want)
 		}
 	})
+	t.Run("addReadConcern suppression", func(t *testing.T) {
+		local := bsoncore.AppendDocumentElement(nil, "readConcern", bsoncore.BuildDocument(nil,
+			bsoncore.AppendStringElement(nil, "level", "local"),
+		))
+		testCases := []struct {
+			name string
+			op   Operation
+			want []byte
+		}{
+			{"explicit", Operation{ReadConcern: readconcern.Local()}, local},
+			{"suppress ignores explicit", Operation{ReadConcern: readconcern.Local(), SuppressReadConcern: true}, nil},
+			{"neither set", Operation{}, nil},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				got, err := tc.op.addReadConcern(nil, description.SelectedServer{})
+				noerr(t, err)
+				if !bytes.Equal(got, tc.want) {
+					t.Errorf("ReadConcern elements do not match. got %v; want %v", bsoncore.Document(got), bsoncore.Document(tc.want))
+				}
+			})
+		}
+		t.Run("suppress keeps transaction read concern", func(t *testing.T) {
+			id, err := uuid.New()
+			noerr(t, err)
+			sess, err := session.NewClientSession(session.NewPool(nil), id, session.Explicit)
+			noerr(t, err)
+			noerr(t, sess.StartTransaction(&session.TransactionOptions{ReadConcern: readconcern.Snapshot()}))
+			sess.OperationTime = &primitive.Timestamp{T: 1, I: 2}
+			desc := description.SelectedServer{Server: description.Server{WireVersion: &description.VersionRange{Max: 7}}}
+
+			op := Operation{Client: sess, ReadConcern: readconcern.Local(), SuppressReadConcern: true}
+			want := bsoncore.AppendDocumentElement(nil, "readConcern", bsoncore.BuildDocument(nil, bsoncore.AppendTimestampElement(
+				bsoncore.AppendStringElement(nil, "level", "snapshot"), "afterClusterTime", 1, 2,
+			)))
+			got, err := op.addReadConcern(nil, desc)
+			noerr(t, err)
+			if !bytes.Equal(got, want) {
+				t.Errorf("ReadConcern elements do not match. got %v; want %v", bsoncore.Document(got), bsoncore.Document(want))
+			}
+		})
+	})
+	t.Run("addReadConcern in transaction", func(t *testing.T) {
+		sessPool := session.NewPool(nil)
+		id, err := uuid.New()
+		noerr(t, err)
+		sess, err := session.NewClientSession(sessPool, id, session.Explicit)
+		noerr(t, err)
+		err = sess.StartTransaction(&session.TransactionOptions{ReadConcern: readconcern.Snapshot()})
+		noerr(t, err)
+		op := Operation{Client: sess, ReadConcern: readconcern.Majority()}
+
+		want := bsoncore.AppendDocumentElement(nil, "readConcern", bsoncore.BuildDocument(nil,
+			bsoncore.AppendStringElement(nil, "level", "snapshot"),
+		))
+		got, err := op.addReadConcern(nil, description.SelectedServer{})
+		noerr(t, err)
+		if !bytes.Equal(got, want) {
+			t.Errorf("First statement should use the transaction read concern. got %v; want %v", got, want)
+		}
+
+		sess.ApplyCommand(description.Server{})
+		got, err = op.addReadConcern(nil, description.SelectedServer{})
+		noerr(t, err)
+		if len(got) != 0 {
+			t.Errorf("Subsequent statements should not include a read concern. got %v", bsoncore.Document(got))
+		}
+	})
 	t.Run("addWriteConcern", func(t *testing.T) {
 		want := bsoncore.AppendDocumentElement(nil, "writeConcern", bsoncore.BuildDocumentFromElements(
 			nil, bsoncore.AppendStringElement(nil, "w", "majority"),
 		))
-		got, err := Operation{WriteConcern: writeconcern.New(writeconcern.WMajority())}.addWriteConcern(nil)
+		got, err := Operation{WriteConcern: writeconcern.New(writeconcern.WMajority())}.addWriteConcern(nil, description.SelectedServer{})
 		noerr(t, err)
 		if !bytes.Equal(got, want) {
 			t.Errorf("WriteConcern elements do not match. got %v; want %v", got, want)
 		}
+		t.Run("majority on standalone", func(t *testing.T) {
+			standalone := description.SelectedServer{
+				Server: description.Server{Kind: description.Standalone},
+				Kind:   description.Single,
+			}
+			primary := description.SelectedServer{
+				Server: description.Server{Kind: description.RSPrimary},
+				Kind:   description.ReplicaSetWithPrimary,
+			}
+			majority := writeconcern.New(writeconcern.WMajority())
+			majorityJ := writeconcern.New(writeconcern.WMajority(), writeconcern.J(true))
+			jOnly := bsoncore.AppendDocumentElement(nil, "writeConcern", bsoncore.BuildDocumentFromElements(
+				nil, bsoncore.AppendBooleanElement(nil, "j", true),
+			))
+			testCases := []struct {
+				name string
+				mode StandaloneMajority
+				wc   *writeconcern.WriteConcern
+				desc description.SelectedServer
+				want []byte
+				err  error
+			}{
+				{"pass through", StandaloneMajorityPassThrough, majority, standalone, want, nil},
+				{"downgrade", StandaloneMajorityDowngrade, majority, standalone, nil, nil},
+				{"downgrade keeps j", StandaloneMajorityDowngrade, majorityJ, standalone, jOnly, nil},
+				{"error", StandaloneMajorityError, majority, standalone, nil, ErrMajorityOnStandalone},
+				{"replica set unchanged", StandaloneMajorityError, majority, primary, want, nil},
+			}
+			for _, tc := range testCases {
+				t.Run(tc.name, func(t *testing.T) {
+					got, err := Operation{WriteConcern: tc.wc, StandaloneMajority: tc.mode}.addWriteConcern(nil, tc.desc)
+					if err != tc.err {
+						t.Fatalf("Errors do not match. got %v; want %v", err, tc.err)
+					}
+					if !bytes.Equal(got, tc.want) {
+						t.Errorf("WriteConcern elements do not match. got %v; want %v", got, tc.want)
+					}
+				})
+			}
+		})
+	})
+	t.Run("resolveWriteConcern", func(t *testing.T) {
+		opWC := writeconcern.New(writeconcern.W(1))
+		txnWC := writeconcern.New(writeconcern.WMajority())
+		testCases := []struct {
+			name        string
+			opWC, txnWC *writeconcern.WriteConcern
+			inTxn       bool
+			want        *writeconcern.WriteConcern
+		}{
+			{"transaction overrides operation", opWC, txnWC, true, txnWC},
+			{"transaction without write concern", opWC, nil, true, opWC},
+			{"transaction ignored outside transaction", opWC, txnWC, false, opWC},
+			{"operation", opWC, nil, false, opWC},
+			{"none", nil, nil, false, nil},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				got := resolveWriteConcern(tc.opWC, tc.txnWC, tc.inTxn)
+				if got != tc.want {
+					t.Errorf("Write concerns do not match. got %v; want %v", got, tc.want)
+				}
+			})
+		}
+		t.Run("commit uses transaction write concern", func(t *testing.T) {
+			sessPool := session.NewPool(nil)
+			id, err := uuid.New()
+			noerr(t, err)
+			sess, err := session.NewClientSession(sessPool, id, session.Explicit)
+			noerr(t, err)
+			err = sess.StartTransaction(&session.TransactionOptions{WriteConcern: txnWC})
+			noerr(t, err)
+			sess.Committing = true
+
+			want, err := Operation{WriteConcern: txnWC}.addWriteConcern(nil, description.SelectedServer{})
+			noerr(t, err)
+			got, err := Operation{Client: sess, WriteConcern: opWC}.addWriteConcern(nil, description.SelectedServer{})
+			noerr(t, err)
+			if !bytes.Equal(got, want) {
+				t.Errorf("Commit should use the transaction write concern. got %v; want %v", got, want)
+			}
+		})
+	})
+	t.Run("addServerAPI", func(t *testing.T) {
+		strict, deprecationErrors := true, false
+		testCases := []struct {
+			name      string
+			serverAPI *ServerAPIOptions
+			want      bsoncore.Document
+		}{
+			{"not set", nil, nil},
+			{"empty version", &ServerAPIOptions{Strict: &strict}, nil},
+			{
+				"all fields",
+				&ServerAPIOptions{ServerAPIVersion: "1", Strict: &strict, DeprecationErrors: &deprecationErrors},
+				bsoncore.BuildDocumentFromElements(nil,
+					bsoncore.AppendStringElement(nil, "apiVersion", "1"),
+					bsoncore.AppendBooleanElement(nil, "apiStrict", true),
+					bsoncore.AppendBooleanElement(nil, "apiDeprecationErrors", false),
+				),
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				var want []byte
+				if tc.want != nil {
+					want = tc.want[4 : len(tc.want)-1]
+				}
+				got := Operation{ServerAPI: tc.serverAPI}.addServerAPI(nil)
+				if !bytes.Equal(got, want) {
+					t.Errorf("ServerAPI elements do not match. got %v; want %v", got, want)
+				}
+			})
+		}
+		t.Run("strict and deprecationErrors combinations", func(t *testing.T) {
+			for _, strict := range []bool{false, true} {
+				for _, deprecationErrors := range []bool{false, true} {
+					sa := NewServerAPIOptions("1").SetStrict(strict).SetDeprecationErrors(deprecationErrors)
+					got := bsoncore.Document(bsoncore.BuildDocument(nil, Operation{ServerAPI: sa}.addServerAPI(nil)))
+					if v, err := got.LookupErr("apiStrict"); err != nil || v.Boolean() != strict {
+						t.Errorf("apiStrict does not match for strict=%v, deprecationErrors=%v. got %v; error %v", strict, deprecationErrors, v, err)
+					}
+					if v, err := got.LookupErr("apiDeprecationErrors"); err != nil || v.Boolean() != deprecationErrors {
+						t.Errorf("apiDeprecationErrors does not match for strict=%v, deprecationErrors=%v. got %v; error %v", strict, deprecationErrors, v, err)
+					}
+
+					unversioned := NewServerAPIOptions("").SetStrict(strict).SetDeprecationErrors(deprecationErrors)
+					if got := (Operation{ServerAPI: unversioned}).addServerAPI(nil); len(got) != 0 {
+						t.Errorf("Expected no ServerAPI elements without an apiVersion. got %v", got)
+					}
+				}
+			}
+			got := bsoncore.Document(bsoncore.BuildDocument(nil, Operation{ServerAPI: NewServerAPIOptions("1").SetDeprecationErrors(true)}.addServerAPI(nil)))
+			if _, err := got.LookupErr("apiStrict"); err == nil {
+				t.Errorf("Expected apiStrict to not be set when only deprecationErrors is set")
+			}
+		})
+		t.Run("appended to commands", func(t *testing.T) {
+			strict := true
+			op := Operation{
+				CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+					return bsoncore.AppendInt32Element(dst, "ping", 1), nil
+				},
+				Database:       "admin",
+				ReadPreference: readpref.Secondary(),
+				ServerAPI:      &ServerAPIOptions{ServerAPIVersion: "1", Strict: &strict},
+			}
+			for _, wv := range []int32{5, 6} {
+				desc := description.SelectedServer{
+					Server: description.Server{Kind: description.Mongos, WireVersion: &description.VersionRange{Max: wv}},
+					Kind:   description.Sharded,
+				}
+				wm, info, err := op.createWireMessage(nil, desc)
+				noerr(t, err)
+				if got, err := info.cmd.LookupErr("apiVersion"); err != nil || got.StringValue() != "1" {
+					t.Errorf("Expected apiVersion to be 1. got %v; error %v", got, err)
+				}
+				if got, err := info.cmd.LookupErr("apiStrict"); err != nil || !got.Boolean() {
+					t.Errorf("Expected apiStrict to be true. got %v; error %v", got, err)
+				}
+				if _, err := info.cmd.LookupErr("apiDeprecationErrors"); err == nil {
+					t.Errorf("Expected apiDeprecationErrors to not be set")
+				}
+				if bytes.Contains(wm, []byte("$query")) {
+					t.Errorf("Expected command to not be wrapped in $query for wire version %d", wv)
+				}
+			}
+		})
+	})
+	t.Run("comment", func(t *testing.T) {
+		op := Operation{
+			CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+				return bsoncore.AppendStringElement(dst, "find", "bar"), nil
+			},
+			Database: "foo",
+			Comment:  "hello",
+		}
+		t.Run("OP_QUERY", func(t *testing.T) {
+			desc := description.SelectedServer{
+				Server: description.Server{WireVersion: &description.VersionRange{Max: 5}},
+				Kind:   description.ReplicaSetWithPrimary,
+			}
+			wm, info, err := op.createWireMessage(nil, desc)
+			noerr(t, err)
+			_, _, _, _, rem, _ := wiremessagex.ReadHeader(wm)
+			_, rem, _ = wiremessagex.ReadQueryFlags(rem)
+			_, rem, _ = wiremessagex.ReadQueryFullCollectionName(rem)
+			_, rem, _ = wiremessagex.ReadQueryNumberToSkip(rem)
+			_, rem, _ = wiremessagex.ReadQueryNumberToReturn(rem)
+			query, _, ok := wiremessagex.ReadQueryQuery(rem)
+			if !ok {
+				t.Fatalf("Could not read query document")
+			}
+			if got, ok := query.Lookup("$comment").StringValueOK(); !ok || got != "hello" {
+				t.Errorf("Expected $comment query modifier. got %v", query)
+			}
+			if _, err := query.LookupErr("$query", "find"); err != nil {
+				t.Errorf("Expected command to be wrapped in $query. got %v", query)
+			}
+			if _, err := info.cmd.LookupErr("comment"); err == nil {
+				t.Errorf("Expected no top-level comment for OP_QUERY. got %v", info.cmd)
+			}
+		})
+		t.Run("OP_MSG", func(t *testing.T) {
+			desc := description.SelectedServer{
+				Server: description.Server{WireVersion: &description.VersionRange{Max: 6}},
+				Kind:   description.ReplicaSetWithPrimary,
+			}
+			_, info, err := op.createWireMessage(nil, desc)
+			noerr(t, err)
+			if got, ok := info.cmd.Lookup("comment").StringValueOK(); !ok || got != "hello" {
+				t.Errorf("Expected top-level comment. got %v", info.cmd)
+			}
+			if _, err := info.cmd.LookupErr("$comment"); err == nil {
+				t.Errorf("Expected no $comment for OP_MSG. got %v", info.cmd)
+			}
+		})
+	})
+	t.Run("document sequence", func(t *testing.T) {
+		docs := []bsoncore.Document{
+			bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "_id", 1)),
+			bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "_id", 2)),
+			bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendStringElement(nil, "_id", "three")),
+		}
+		op := Operation{
+			CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+				return bsoncore.AppendStringElement(dst, "insert", "coll"), nil
+			},
+			Database: "testing",
+			Batches:  &Batches{Identifier: "documents", Current: docs},
+		}
+		desc := description.SelectedServer{
+			Server: description.Server{WireVersion: &description.VersionRange{Max: 6}},
+			Kind:   description.ReplicaSetWithPrimary,
+		}
+		wm, info, err := op.createWireMessage(nil, desc)
+		noerr(t, err)
+		if !info.documentSequenceIncluded {
+			t.Error("Expected documentSequenceIncluded to be true")
+		}
+
+		length, _, _, opcode, rem, ok := wiremessagex.ReadHeader(wm)
+		if !ok || opcode != wiremessage.OpMsg || int(length) != len(wm) {
+			t.Fatalf("Invalid header. length %d, opcode %v, ok %v; message length %d", length, opcode, ok, len(wm))
+		}
+		_, rem, ok = wiremessagex.ReadMsgFlags(rem)
+		if !ok {
+			t.Fatal("Could not read flags")
+		}
+		stype, rem, ok := wiremessagex.ReadMsgSectionType(rem)
+		if !ok || stype != wiremessage.SingleDocument {
+			t.Fatalf("Expected a body section first. got %v", stype)
+		}
+		body, rem, ok := wiremessagex.ReadMsgSectionSingleDocument(rem)
+		if !ok {
+			t.Fatal("Could not read body section")
+		}
+		if _, err := body.LookupErr("documents"); err == nil {
+			t.Error("Documents should not be included in the body section")
+		}
+		stype, rem, ok = wiremessagex.ReadMsgSectionType(rem)
+		if !ok || stype != wiremessage.DocumentSequence {
+			t.Fatalf("Expected a document sequence section. got %v", stype)
+		}
+		wantLen := 4 + len("documents") + 1
+		for _, doc := range docs {
+			wantLen += len(doc)
+		}
+		if got := int(int32(rem[0]) | int32(rem[1])<<8 | int32(rem[2])<<16 | int32(rem[3])<<24); got != wantLen {
+			t.Errorf("Section length does not match. got %d; want %d", got, wantLen)
+		}
+		identifier, got, rem, ok := wiremessagex.ReadMsgSectionDocumentSequence(rem)
+		if !ok {
+			t.Fatal("Could not read document sequence section")
+		}
+		if identifier != "documents" {
+			t.Errorf("Identifier does not match. got %s; want %s", identifier, "documents")
+		}
+		if !cmp.Equal(got, docs) {
+			t.Errorf("Documents do not match. got %v; want %v", got, docs)
+		}
+		if len(rem) != 0 {
+			t.Errorf("Expected no bytes after the document sequence, got %d", len(rem))
+		}
 	})
 	t.Run("addSession", func(t *testing.T) { t.Skip("These tests should be covered by spec tests.") })
 	t.Run("addClusterTime", func(t *testing.T) {
@@ -304,6 +955,28 @@ func TestOperation(t *testing.T) {
 
 		Operation{}.updateClusterTimes(bsoncore.BuildDocumentFromElements(nil)) // should do nothing
 	})
+	t.Run("cluster time signature round trip", func(t *testing.T) {
+		clusterTime := bsoncore.AppendDocumentElement(nil, "$clusterTime", bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendTimestampElement(nil, "clusterTime", 1234, 5678),
+			bsoncore.AppendDocumentElement(nil, "signature", bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendBinaryElement(nil, "hash", 0x00, []byte{0x01, 0x02, 0x03}),
+				bsoncore.AppendInt64Element(nil, "keyId", 42),
+			)),
+		))
+		response := bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1), clusterTime)
+
+		sess, err := session.NewClientSession(session.NewPool(nil), uuid.UUID{}, session.Explicit)
+		noerr(t, err)
+		op := Operation{Client: sess, Clock: new(session.ClusterClock)}
+		op.updateClusterTimes(response)
+
+		got := op.addClusterTime(nil, description.SelectedServer{
+			Server: description.Server{WireVersion: &description.VersionRange{Min: 0, Max: 7}},
+		})
+		if !bytes.Equal(got, clusterTime) {
+			t.Errorf("$clusterTime was not gossiped unchanged. got %v; want %v", got, clusterTime)
+		}
+	})
 	t.Run("updateOperationTime", func(t *testing.T) {
 		want := primitive.Timestamp{T: 1234, I: 4567}
 
@@ -332,6 +1005,350 @@ func TestOperation(t *testing.T) {
 
 		Operation{}.updateOperationTime(response) // should do nothing
 	})
+	t.Run("updateRecoveryToken", func(t *testing.T) {
+		sessPool := session.NewPool(nil)
+		id, err := uuid.New()
+		noerr(t, err)
+
+		sess, err := session.NewClientSession(sessPool, id, session.Explicit)
+		noerr(t, err)
+		if sess.RecoveryToken != nil {
+			t.Fatal("RecoveryToken should not be set on new session.")
+		}
+		token := bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendStringElement(nil, "shard", "sh01"))
+		response := bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt32Element(nil, "ok", 1),
+			bsoncore.AppendDocumentElement(nil, "recoveryToken", token),
+		)
+		Operation{Client: sess}.updateRecoveryToken(response)
+		if !bytes.Equal(sess.RecoveryToken, token) {
+			t.Errorf("RecoveryTokens do not match. got %v; want %v", sess.RecoveryToken, token)
+		}
+
+		Operation{}.updateRecoveryToken(response) // should do nothing
+	})
+	t.Run("read preference uses live topology kind", func(t *testing.T) {
+		conn := &mockConnection{
+			rDesc:   description.Server{Kind: description.RSPrimary, WireVersion: &description.VersionRange{Max: 6}},
+			rReadWM: opMsgReply(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1))),
+		}
+		d := new(mockDeployment)
+		d.returns.server = SingleConnectionDeployment{C: conn}
+		op := Operation{
+			CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+				return bsoncore.AppendStringElement(dst, "find", "bar"), nil
+			},
+			Database:   "foo",
+			Deployment: d,
+		}
+		readPref := func(t *testing.T) bsoncore.Value {
+			t.Helper()
+			_, _, _, _, rem, ok := wiremessagex.ReadHeader(conn.pWriteWM)
+			if !ok {
+				t.Fatalf("Could not read wire message header")
+			}
+			_, rem, _ = wiremessagex.ReadMsgFlags(rem)
+			_, rem, _ = wiremessagex.ReadMsgSectionType(rem)
+			body, _, ok := wiremessagex.ReadMsgSectionSingleDocument(rem)
+			if !ok {
+				t.Fatalf("Could not read OP_MSG body")
+			}
+			return body.Lookup("$readPreference")
+		}
+
+		d.returns.kind = description.Single
+		err := op.Execute(context.Background(), nil)
+		noerr(t, err)
+		rp, ok := readPref(t).DocumentOK()
+		if mode, _ := rp.Lookup("mode").StringValueOK(); !ok || mode != "primaryPreferred" {
+			t.Errorf("Expected primaryPreferred for a Single topology. got %v", rp)
+		}
+
+		d.returns.kind = description.ReplicaSetWithPrimary
+		err = op.Execute(context.Background(), nil)
+		noerr(t, err)
+		if rp := readPref(t); rp.Type != 0 {
+			t.Errorf("Expected no read preference for a ReplicaSet topology. got %v", rp)
+		}
+	})
+	t.Run("MaxRetries", func(t *testing.T) {
+		sessPool := session.NewPool(nil)
+		id, err := uuid.New()
+		noerr(t, err)
+		sess, err := session.NewClientSession(sessPool, id, session.Explicit)
+		noerr(t, err)
+
+		failure := opMsgReply(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt32Element(nil, "ok", 0),
+			bsoncore.AppendInt32Element(nil, "code", 91),
+			bsoncore.AppendStringElement(nil, "errmsg", "shutdown in progress"),
+		))
+		success := opMsgReply(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1)))
+		conn := &mockConnection{
+			rDesc:    description.Server{Kind: description.RSPrimary, WireVersion: &description.VersionRange{Max: 6}},
+			rReadWMs: [][]byte{failure, failure, success},
+		}
+		d := new(mockDeployment)
+		d.returns.server = SingleConnectionDeployment{C: conn}
+		d.returns.retry = true
+		d.returns.kind = description.ReplicaSetWithPrimary
+		retryMode := RetryOnce
+		op := Operation{
+			CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+				return bsoncore.AppendStringElement(dst, "insert", "bar"), nil
+			},
+			Database:   "foo",
+			Deployment: d,
+			Client:     sess,
+			Clock:      new(session.ClusterClock),
+			RetryMode:  &retryMode,
+			RetryType:  RetryWrite,
+			MaxRetries: 2,
+		}
+		start := time.Now()
+		err = op.Execute(context.Background(), nil)
+		noerr(t, err)
+		if elapsed := time.Since(start); elapsed < firstRetryBackoff {
+			t.Errorf("Expected the second retry to back off for %v. took %v", firstRetryBackoff, elapsed)
+		}
+		if len(conn.pWriteWMs) != 3 {
+			t.Fatalf("Expected the write to be attempted 3 times. got %d; want %d", len(conn.pWriteWMs), 3)
+		}
+		for i, wm := range conn.pWriteWMs {
+			_, _, _, _, rem, _ := wiremessagex.ReadHeader(wm)
+			_, rem, _ = wiremessagex.ReadMsgFlags(rem)
+			_, rem, _ = wiremessagex.ReadMsgSectionType(rem)
+			body, _, _ := wiremessagex.ReadMsgSectionSingleDocument(rem)
+			if txn, ok := body.Lookup("txnNumber").Int64OK(); !ok || txn != 1 {
+				t.Errorf("Attempt %d should reuse the original transaction number. got %v; want %d", i+1, body.Lookup("txnNumber"), 1)
+			}
+		}
+
+		conn.rReadWMs = [][]byte{failure, failure, success}
+		conn.pWriteWMs = nil
+		op.MaxRetries = 0
+		err = op.Execute(context.Background(), nil)
+		if derr, ok := err.(Error); !ok || derr.Code != 91 {
+			t.Errorf("Expected the second failure to be returned with the default of one retry. got %v", err)
+		}
+
+		conn.rReadWMs = [][]byte{failure, failure, success}
+		conn.pWriteWMs = nil
+		op.MaxRetries = 2
+		ctx, cancel := context.WithTimeout(context.Background(), firstRetryBackoff/10)
+		defer cancel()
+		err = op.Execute(ctx, nil)
+		if derr, ok := err.(Error); !ok || derr.Code != 91 {
+			t.Errorf("Expected the failure to be returned when the context ends during the backoff. got %v", err)
+		}
+		if len(conn.pWriteWMs) != 2 {
+			t.Errorf("Expected no attempt after the context ends. got %d; want %d", len(conn.pWriteWMs), 2)
+		}
+	})
+	t.Run("pre-selected server", func(t *testing.T) {
+		cmdFn := func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+			return bsoncore.AppendInt64Element(dst, "getMore", 42), nil
+		}
+		t.Run("skips server selection", func(t *testing.T) {
+			conn := &mockConnection{
+				rDesc:   description.Server{WireVersion: &description.VersionRange{Max: 6}},
+				rReadWM: opMsgReply(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1))),
+			}
+			d := new(mockDeployment)
+			var processed Server
+			op := Operation{
+				CommandFn:  cmdFn,
+				Database:   "foo",
+				Deployment: d,
+				Server:     mockServer{conn: conn},
+				ProcessResponseFn: func(_ bsoncore.Document, srvr Server) error {
+					processed = srvr
+					return nil
+				},
+			}
+			err := op.Execute(context.Background(), nil)
+			noerr(t, err)
+			if d.params.selector != nil {
+				t.Error("SelectServer should not be called when a server is pre-selected")
+			}
+			if processed != op.Server {
+				t.Errorf("The response should be processed with the pre-selected server. got %v; want %v", processed, op.Server)
+			}
+			if len(conn.pWriteWMs) != 1 {
+				t.Errorf("Expected the command to be sent to the pre-selected server. got %d writes; want %d", len(conn.pWriteWMs), 1)
+			}
+		})
+		t.Run("returns an error if the server is unusable", func(t *testing.T) {
+			want := errors.New("server is closed")
+			d := new(mockDeployment)
+			op := Operation{
+				CommandFn:  cmdFn,
+				Database:   "foo",
+				Deployment: d,
+				Server:     mockServer{err: want},
+			}
+			err := op.Execute(context.Background(), nil)
+			if err != want {
+				t.Errorf("Expected the server's error to be returned. got %v; want %v", err, want)
+			}
+			if d.params.selector != nil {
+				t.Error("SelectServer should not be called when a server is pre-selected")
+			}
+		})
+	})
+	t.Run("WTimeoutFromContext", func(t *testing.T) {
+		writeConcern := func(t *testing.T, fromContext bool) bsoncore.Document {
+			t.Helper()
+			success := opMsgReply(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1)))
+			conn := &mockConnection{
+				rDesc:    description.Server{WireVersion: &description.VersionRange{Max: 6}},
+				rReadWMs: [][]byte{success},
+			}
+			op := Operation{
+				CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+					return bsoncore.AppendStringElement(dst, "insert", "bar"), nil
+				},
+				Database:            "foo",
+				Deployment:          SingleConnectionDeployment{C: conn},
+				WriteConcern:        writeconcern.New(writeconcern.WMajority()),
+				WTimeoutFromContext: fromContext,
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+			noerr(t, op.Execute(ctx, nil))
+
+			_, _, _, _, rem, _ := wiremessagex.ReadHeader(conn.pWriteWM)
+			_, rem, _ = wiremessagex.ReadMsgFlags(rem)
+			_, rem, _ = wiremessagex.ReadMsgSectionType(rem)
+			body, _, _ := wiremessagex.ReadMsgSectionSingleDocument(rem)
+			wc, err := body.LookupErr("writeConcern")
+			noerr(t, err)
+			return wc.Document()
+		}
+
+		t.Run("derived from deadline", func(t *testing.T) {
+			wtimeout, err := writeConcern(t, true).LookupErr("wtimeout")
+			noerr(t, err)
+			if ms := wtimeout.Int64(); ms > 3000 || ms < 2900 {
+				t.Errorf("wtimeout should be close to the remaining time. got %dms; want about 3000ms", ms)
+			}
+		})
+		t.Run("not set by default", func(t *testing.T) {
+			if _, err := writeConcern(t, false).LookupErr("wtimeout"); err == nil {
+				t.Error("wtimeout should not be derived from the context unless WTimeoutFromContext is set")
+			}
+		})
+	})
+	t.Run("RetryWritesDisabled", func(t *testing.T) {
+		sessPool := session.NewPool(nil)
+		id, err := uuid.New()
+		noerr(t, err)
+		sess, err := session.NewClientSession(sessPool, id, session.Explicit)
+		noerr(t, err)
+		// Simulate a session that was used for a retryable write by an earlier operation.
+		sess.RetryWrite = true
+
+		success := opMsgReply(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1)))
+		conn := &mockConnection{
+			rDesc:    description.Server{Kind: description.RSPrimary, WireVersion: &description.VersionRange{Max: 6}},
+			rReadWMs: [][]byte{success},
+		}
+		d := new(mockDeployment)
+		d.returns.server = SingleConnectionDeployment{C: conn}
+		d.returns.retry = true
+		d.returns.kind = description.ReplicaSetWithPrimary
+		retryMode := RetryOnce
+		op := Operation{
+			CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+				return bsoncore.AppendStringElement(dst, "insert", "bar"), nil
+			},
+			Database:            "foo",
+			Deployment:          d,
+			Client:              sess,
+			Clock:               new(session.ClusterClock),
+			RetryMode:           &retryMode,
+			RetryType:           RetryWrite,
+			RetryWritesDisabled: true,
+		}
+		err = op.Execute(context.Background(), nil)
+		noerr(t, err)
+		if len(conn.pWriteWMs) != 1 {
+			t.Fatalf("Expected a single write. got %d; want %d", len(conn.pWriteWMs), 1)
+		}
+		_, _, _, _, rem, _ := wiremessagex.ReadHeader(conn.pWriteWMs[0])
+		_, rem, _ = wiremessagex.ReadMsgFlags(rem)
+		_, rem, _ = wiremessagex.ReadMsgSectionType(rem)
+		body, _, _ := wiremessagex.ReadMsgSectionSingleDocument(rem)
+		if _, err := body.LookupErr("txnNumber"); err == nil {
+			t.Errorf("txnNumber should not be sent when retryable writes are disabled. got %v", body)
+		}
+		if sess.TxnNumber != 0 {
+			t.Errorf("Transaction number should not be incremented. got %d; want %d", sess.TxnNumber, 0)
+		}
+	})
+	t.Run("RetryObserver", func(t *testing.T) {
+		sessPool := session.NewPool(nil)
+		id, err := uuid.New()
+		noerr(t, err)
+		sess, err := session.NewClientSession(sessPool, id, session.Explicit)
+		noerr(t, err)
+
+		failure := opMsgReply(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt32Element(nil, "ok", 0),
+			bsoncore.AppendInt32Element(nil, "code", 91),
+			bsoncore.AppendStringElement(nil, "errmsg", "shutdown in progress"),
+		))
+		success := opMsgReply(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1)))
+		conn := &mockConnection{
+			rDesc:    description.Server{Kind: description.RSPrimary, WireVersion: &description.VersionRange{Max: 6}},
+			rReadWMs: [][]byte{failure, success},
+		}
+		d := new(mockDeployment)
+		d.returns.server = SingleConnectionDeployment{C: conn}
+		d.returns.retry = true
+		d.returns.kind = description.ReplicaSetWithPrimary
+
+		type observation struct {
+			attempt int
+			err     error
+			success bool
+		}
+		var observed []observation
+		retryMode := RetryOnce
+		op := Operation{
+			CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+				return bsoncore.AppendStringElement(dst, "insert", "bar"), nil
+			},
+			Database:   "foo",
+			Deployment: d,
+			Client:     sess,
+			Clock:      new(session.ClusterClock),
+			RetryMode:  &retryMode,
+			RetryType:  RetryWrite,
+			RetryObserver: func(attempt int, err error, success bool) {
+				observed = append(observed, observation{attempt, err, success})
+			},
+		}
+		err = op.Execute(context.Background(), nil)
+		noerr(t, err)
+		if len(observed) != 1 {
+			t.Fatalf("Expected the observer to be called once. got %d; want %d", len(observed), 1)
+		}
+		if got := observed[0]; got.attempt != 1 || !got.success {
+			t.Errorf("Unexpected observation. got attempt %d, success %v; want attempt 1, success true", got.attempt, got.success)
+		}
+		if derr, ok := observed[0].err.(Error); !ok || derr.Code != 91 {
+			t.Errorf("Expected the triggering error to be reported. got %v", observed[0].err)
+		}
+
+		conn.rReadWMs = [][]byte{success}
+		observed = nil
+		err = op.Execute(context.Background(), nil)
+		noerr(t, err)
+		if len(observed) != 0 {
+			t.Errorf("Expected the observer not to be called without a retry. got %d calls", len(observed))
+		}
+	})
 	t.Run("createReadPref", func(t *testing.T) {
 		rpWithTags := bsoncore.BuildDocumentFromElements(nil,
 			bsoncore.AppendStringElement(nil, "mode", "secondaryPreferred"),
@@ -344,6 +1361,16 @@ func TestOperation(t *testing.T) {
 				},
 			),
 		)
+		rpWithStrictTags := bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendStringElement(nil, "mode", "secondary"),
+			bsoncore.BuildArrayElement(nil, "tags",
+				bsoncore.Value{Type: bsontype.EmbeddedDocument,
+					Data: bsoncore.BuildDocumentFromElements(nil,
+						bsoncore.AppendStringElement(nil, "workload", "analytics"),
+					),
+				},
+			),
+		)
 		rpWithMaxStaleness := bsoncore.BuildDocumentFromElements(nil,
 			bsoncore.AppendStringElement(nil, "mode", "secondaryPreferred"),
 			bsoncore.AppendInt32Element(nil, "maxStalenessSeconds", 25),
@@ -378,6 +1405,11 @@ func TestOperation(t *testing.T) {
 				readpref.SecondaryPreferred(readpref.WithTags("disk", "ssd", "use", "reporting")),
 				description.RSSecondary, description.ReplicaSet, false, rpWithTags,
 			},
+			{
+				"secondaryPreferred/withStrictTags/mongos",
+				readpref.SecondaryPreferred(readpref.WithTags("workload", "analytics"), readpref.WithStrictTags()),
+				description.Mongos, description.Sharded, true, rpWithStrictTags,
+			},
 			{
 				"secondaryPreferred/withMaxStaleness",
 				readpref.SecondaryPreferred(readpref.WithMaxStaleness(25 * time.Second)),
@@ -388,43 +1420,181 @@ func TestOperation(t *testing.T) {
 		for _, tc := range testCases {
 			tc := tc
 			t.Run(tc.name, func(t *testing.T) {
-				got := Operation{ReadPreference: tc.rp}.createReadPref(tc.serverKind, tc.topoKind, tc.opQuery)
+				got, err := Operation{ReadPreference: tc.rp}.createReadPref(tc.serverKind, tc.topoKind, tc.opQuery, 0)
+				noerr(t, err)
 				if !bytes.Equal(got, tc.want) {
 					t.Errorf("Returned documents do not match. got %v; want %v", got, tc.want)
 				}
 			})
 		}
+		t.Run("maxStaleness minimum", func(t *testing.T) {
+			testCases := []struct {
+				name         string
+				heartbeat    time.Duration
+				maxStaleness time.Duration
+				wantErr      bool
+			}{
+				{"10s heartbeat/below minimum", 10 * time.Second, 19 * time.Second, true},
+				{"10s heartbeat/at minimum", 10 * time.Second, 20 * time.Second, false},
+				{"30s heartbeat/below minimum", 30 * time.Second, 39 * time.Second, true},
+				{"30s heartbeat/at minimum", 30 * time.Second, 40 * time.Second, false},
+			}
+
+			for _, tc := range testCases {
+				tc := tc
+				t.Run(tc.name, func(t *testing.T) {
+					rp := readpref.Secondary(readpref.WithMaxStaleness(tc.maxStaleness))
+					_, err := Operation{ReadPreference: rp}.createReadPref(
+						description.RSSecondary, description.ReplicaSet, false, tc.heartbeat,
+					)
+					if gotErr := err != nil; gotErr != tc.wantErr {
+						t.Errorf("Unexpected error result. got %v; want error %t", err, tc.wantErr)
+					}
+				})
+			}
+		})
 	})
-	t.Run("slaveOK", func(t *testing.T) {
+	t.Run("secondaryOK", func(t *testing.T) {
 		t.Run("description.SelectedServer", func(t *testing.T) {
 			want := wiremessage.SlaveOK
 			desc := description.SelectedServer{
 				Kind:   description.Single,
 				Server: description.Server{Kind: description.RSSecondary},
 			}
-			got := Operation{}.slaveOK(desc)
+			got := Operation{}.secondaryOK(desc)
 			if got != want {
 				t.Errorf("Did not receive expected query flags. got %v; want %v", got, want)
 			}
 		})
 		t.Run("readPreference", func(t *testing.T) {
 			want := wiremessage.SlaveOK
-			got := Operation{ReadPreference: readpref.Secondary()}.slaveOK(description.SelectedServer{})
+			got := Operation{ReadPreference: readpref.Secondary()}.secondaryOK(description.SelectedServer{})
 			if got != want {
 				t.Errorf("Did not receive expected query flags. got %v; want %v", got, want)
 			}
 		})
-		t.Run("not slaveOK", func(t *testing.T) {
+		t.Run("not secondaryOK", func(t *testing.T) {
 			var want wiremessage.QueryFlag
-			got := Operation{}.slaveOK(description.SelectedServer{})
+			got := Operation{}.secondaryOK(description.SelectedServer{})
 			if got != want {
 				t.Errorf("Did not receive expected query flags. got %v; want %v", got, want)
 			}
 		})
+		t.Run("OP_QUERY secondary read", func(t *testing.T) {
+			op := Operation{
+				CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+					return bsoncore.AppendInt32Element(dst, "find", 1), nil
+				},
+				Database:       "foo",
+				ReadPreference: readpref.Secondary(),
+			}
+			desc := description.SelectedServer{
+				Kind:   description.ReplicaSetWithPrimary,
+				Server: description.Server{Kind: description.RSSecondary, WireVersion: &description.VersionRange{Max: 5}},
+			}
+			wm, _, err := op.createWireMessage(nil, desc)
+			noerr(t, err)
+			_, _, _, _, rem, _ := wiremessagex.ReadHeader(wm)
+			flags, _, _ := wiremessagex.ReadQueryFlags(rem)
+			if flags&wiremessage.SlaveOK != wiremessage.SlaveOK {
+				t.Errorf("The secondaryOk flag should be set for OP_QUERY secondary reads. got flags %v", flags)
+			}
+		})
+		t.Run("OP_QUERY read preference", func(t *testing.T) {
+			op := Operation{
+				CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+					return bsoncore.AppendStringElement(dst, "find", "bar"), nil
+				},
+				Database: "foo",
+			}
+			mongos := description.SelectedServer{
+				Kind:   description.Sharded,
+				Server: description.Server{Kind: description.Mongos, WireVersion: &description.VersionRange{Max: 5}},
+			}
+			secondary := description.SelectedServer{
+				Kind:   description.ReplicaSetWithPrimary,
+				Server: description.Server{Kind: description.RSSecondary, WireVersion: &description.VersionRange{Max: 5}},
+			}
+			query := func(t *testing.T, op Operation, desc description.SelectedServer) bsoncore.Document {
+				t.Helper()
+				wm, _, err := op.createWireMessage(nil, desc)
+				noerr(t, err)
+				_, _, _, _, rem, _ := wiremessagex.ReadHeader(wm)
+				_, rem, _ = wiremessagex.ReadQueryFlags(rem)
+				_, rem, _ = wiremessagex.ReadQueryFullCollectionName(rem)
+				_, rem, _ = wiremessagex.ReadQueryNumberToSkip(rem)
+				_, rem, _ = wiremessagex.ReadQueryNumberToReturn(rem)
+				query, _, ok := wiremessagex.ReadQueryQuery(rem)
+				if !ok {
+					t.Fatalf("Could not read query document")
+				}
+				return query
+			}
+
+			t.Run("tagged secondaryPreferred to mongos", func(t *testing.T) {
+				op := op
+				op.ReadPreference = readpref.SecondaryPreferred(readpref.WithTags("dc", "ny"))
+				query := query(t, op, mongos)
+				if _, err := query.LookupErr("$query", "find"); err != nil {
+					t.Errorf("Expected command to be wrapped in $query. got %v", query)
+				}
+				if mode, ok := query.Lookup("$readPreference", "mode").StringValueOK(); !ok || mode != "secondaryPreferred" {
+					t.Errorf("Expected $readPreference mode secondaryPreferred. got %v", query)
+				}
+				if dc, ok := query.Lookup("$readPreference", "tags", "0", "dc").StringValueOK(); !ok || dc != "ny" {
+					t.Errorf("Expected $readPreference to carry tags. got %v", query)
+				}
+			})
+			t.Run("untagged secondaryPreferred to mongos", func(t *testing.T) {
+				op := op
+				op.ReadPreference = readpref.SecondaryPreferred()
+				query := query(t, op, mongos)
+				if _, err := query.LookupErr("$query"); err == nil {
+					t.Errorf("Expected command to not be wrapped in $query. got %v", query)
+				}
+			})
+			t.Run("tagged secondary to replica set member", func(t *testing.T) {
+				op := op
+				op.ReadPreference = readpref.Secondary(readpref.WithTags("dc", "ny"))
+				query := query(t, op, secondary)
+				if _, err := query.LookupErr("$query"); err == nil {
+					t.Errorf("Expected command to not be wrapped in $query. got %v", query)
+				}
+				if _, err := query.LookupErr("$readPreference"); err == nil {
+					t.Errorf("Expected no $readPreference for a replica set member. got %v", query)
+				}
+			})
+		})
+		t.Run("OP_MSG secondary read", func(t *testing.T) {
+			desc := description.SelectedServer{
+				Kind:   description.ReplicaSetWithPrimary,
+				Server: description.Server{Kind: description.RSSecondary, WireVersion: &description.VersionRange{Max: 6}},
+			}
+			op := Operation{
+				CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+					return bsoncore.AppendInt32Element(dst, "find", 1), nil
+				},
+				Database:       "foo",
+				ReadPreference: readpref.Secondary(),
+			}
+			if got := op.secondaryOK(desc); got != 0 {
+				t.Errorf("The secondaryOk flag should not be used with OP_MSG. got %v", got)
+			}
+			wm, _, err := op.createWireMessage(nil, desc)
+			noerr(t, err)
+			_, _, _, _, rem, _ := wiremessagex.ReadHeader(wm)
+			_, rem, _ = wiremessagex.ReadMsgFlags(rem)
+			_, rem, _ = wiremessagex.ReadMsgSectionType(rem)
+			body, _, _ := wiremessagex.ReadMsgSectionSingleDocument(rem)
+			if _, err := body.LookupErr("$readPreference"); err != nil {
+				t.Errorf("OP_MSG secondary reads should send $readPreference, but it is missing: %v", err)
+			}
+		})
 	})
 }
 
 type mockDeployment struct {
+	mu     sync.Mutex
 	params struct {
 		selector description.ServerSelector
 	}
@@ -437,6 +1607,8 @@ type mockDeployment struct {
 }
 
 func (m *mockDeployment) SelectServer(ctx context.Context, desc description.ServerSelector) (Server, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.params.selector = desc
 	return m.returns.server, m.returns.err
 }
@@ -444,6 +1616,35 @@ func (m *mockDeployment) SelectServer(ctx context.Context, desc description.Serv
 func (m *mockDeployment) SupportsRetry() bool            { return m.returns.retry }
 func (m *mockDeployment) Kind() description.TopologyKind { return m.returns.kind }
 
+// changingDeployment is a Deployment that has no suitable server until one is set with setServer,
+// which also signals a topology change.
+type changingDeployment struct {
+	mockDeployment
+	changed chan struct{}
+}
+
+func newChangingDeployment() *changingDeployment {
+	d := &changingDeployment{changed: make(chan struct{}, 1)}
+	d.returns.err = ErrNoSuitableServer
+	return d
+}
+
+func (d *changingDeployment) setServer(srvr Server) {
+	d.mu.Lock()
+	d.returns.server, d.returns.err = srvr, nil
+	d.mu.Unlock()
+	d.changed <- struct{}{}
+}
+
+func (d *changingDeployment) TopologyChanged() <-chan struct{} { return d.changed }
+
+type mockServer struct {
+	conn Connection
+	err  error
+}
+
+func (m mockServer) Connection(context.Context) (Connection, error) { return m.conn, m.err }
+
 type mockServerSelector struct{}
 
 func (m *mockServerSelector) SelectServer(description.Topology, []description.Server) ([]description.Server, error) {
@@ -452,12 +1653,14 @@ func (m *mockServerSelector) SelectServer(description.Topology, []description.Se
 
 type mockConnection struct {
 	// parameters
-	pWriteWM []byte
-	pReadDst []byte
+	pWriteWM  []byte
+	pWriteWMs [][]byte // every wire message written, in order
+	pReadDst  []byte
 
 	// returns
 	rWriteErr error
 	rReadWM   []byte
+	rReadWMs  [][]byte // if set, returned in order by successive reads instead of rReadWM
 	rReadErr  error
 	rDesc     description.Server
 	rCloseErr error
@@ -472,10 +1675,60 @@ func (m *mockConnection) Address() address.Address        { return m.rAddr }
 
 func (m *mockConnection) WriteWireMessage(_ context.Context, wm []byte) error {
 	m.pWriteWM = wm
+	m.pWriteWMs = append(m.pWriteWMs, append([]byte(nil), wm...))
 	return m.rWriteErr
 }
 
 func (m *mockConnection) ReadWireMessage(_ context.Context, dst []byte) ([]byte, error) {
 	m.pReadDst = dst
+	if len(m.rReadWMs) > 0 {
+		wm := m.rReadWMs[0]
+		m.rReadWMs = m.rReadWMs[1:]
+		return wm, m.rReadErr
+	}
 	return m.rReadWM, m.rReadErr
 }
+
+// replicaSetTopology returns a replica set with a primary on localhost:27017 and a secondary on
+// localhost:27018.
+func replicaSetTopology() (topo description.Topology, primary, secondary description.Server) {
+	primary = description.Server{Addr: address.Address("localhost:27017"), Kind: description.RSPrimary}
+	secondary = description.Server{Addr: address.Address("localhost:27018"), Kind: description.RSSecondary}
+	topo = description.Topology{
+		Kind:    description.ReplicaSetWithPrimary,
+		Servers: []description.Server{primary, secondary},
+	}
+	return topo, primary, secondary
+}
+
+// replyDeployment returns a replica set deployment with a single connection to a server of the given
+// kind that replies to every command with reply.
+func replyDeployment(kind description.ServerKind, reply bsoncore.Document) (*mockDeployment, *mockConnection) {
+	conn := &mockConnection{
+		rDesc:   description.Server{Kind: kind, WireVersion: &description.VersionRange{Max: 6}},
+		rReadWM: opMsgReply(reply),
+	}
+	d := new(mockDeployment)
+	d.returns.server = SingleConnectionDeployment{C: conn}
+	d.returns.kind = description.ReplicaSetWithPrimary
+	return d, conn
+}
+
+// requireRoutedTo checks that the selector last passed to d only selects addr from topo.
+func requireRoutedTo(t *testing.T, d *mockDeployment, topo description.Topology, addr address.Address) {
+	t.Helper()
+	selected, err := d.params.selector.SelectServer(topo, topo.Servers)
+	noerr(t, err)
+	if len(selected) != 1 || selected[0].Addr != addr {
+		t.Errorf("Expected the command to be routed to %s. got %v", addr, selected)
+	}
+}
+
+// opMsgReply wraps doc in an OP_MSG wire message.
+func opMsgReply(doc bsoncore.Document) []byte {
+	idx, wm := wiremessagex.AppendHeaderStart(nil, 0, 0, wiremessage.OpMsg)
+	wm = wiremessagex.AppendMsgFlags(wm, 0)
+	wm = wiremessagex.AppendMsgSectionType(wm, wiremessage.SingleDocument)
+	wm = append(wm, doc...)
+	return bsoncore.UpdateLength(wm, idx, int32(len(wm[idx:])))
+}
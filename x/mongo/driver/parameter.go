@@ -0,0 +1,156 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// GetParameterOperation runs the getParameter command against a single server. Server parameters
+// are per-node, so the operation targets the server at an address rather than one chosen by read
+// preference.
+type GetParameterOperation struct {
+	name  string
+	addr  address.Address
+	clock *session.ClusterClock
+
+	d Deployment
+
+	value bsoncore.Value
+}
+
+// GetParameter constructs a GetParameterOperation that reads the parameter name from the server at
+// addr.
+func GetParameter(name string, addr address.Address) *GetParameterOperation {
+	return &GetParameterOperation{name: name, addr: addr}
+}
+
+// Clock sets the cluster clock for this operation.
+func (gpo *GetParameterOperation) Clock(clock *session.ClusterClock) *GetParameterOperation {
+	gpo.clock = clock
+	return gpo
+}
+
+// Deployment sets the Deployment for this operation.
+func (gpo *GetParameterOperation) Deployment(d Deployment) *GetParameterOperation {
+	gpo.d = d
+	return gpo
+}
+
+// Value returns the value of the parameter read by the last successful Execute.
+func (gpo *GetParameterOperation) Value() bsoncore.Value { return gpo.value }
+
+func (gpo *GetParameterOperation) command(dst []byte, _ description.SelectedServer) ([]byte, error) {
+	dst = bsoncore.AppendInt32Element(dst, "getParameter", 1)
+	return bsoncore.AppendInt32Element(dst, gpo.name, 1), nil
+}
+
+func (gpo *GetParameterOperation) processResponse(response bsoncore.Document, _ Server) error {
+	val, err := response.LookupErr(gpo.name)
+	if err != nil {
+		return fmt.Errorf("getParameter reply does not contain the parameter %q", gpo.name)
+	}
+	gpo.value = val
+	return nil
+}
+
+// Execute runs this operation.
+func (gpo *GetParameterOperation) Execute(ctx context.Context) error {
+	if gpo.d == nil {
+		return errors.New("a GetParameterOperation must have a Deployment set before Execute can be called")
+	}
+	if gpo.name == "" {
+		return errors.New("the parameter name must be of non-zero length")
+	}
+
+	gpo.value = bsoncore.Value{}
+	return runOnServer(ctx, gpo.d, "admin", gpo.addr, gpo.clock, gpo.command, gpo.processResponse)
+}
+
+// SetParameterOperation runs the setParameter command against a single server. Like
+// GetParameterOperation it targets the server at an address, so each node of a deployment must be
+// changed separately.
+type SetParameterOperation struct {
+	name  string
+	value bsoncore.Value
+	addr  address.Address
+	clock *session.ClusterClock
+
+	d Deployment
+
+	was bsoncore.Value
+}
+
+// SetParameter constructs a SetParameterOperation that sets the parameter name to value on the
+// server at addr.
+func SetParameter(name string, value bsoncore.Value, addr address.Address) *SetParameterOperation {
+	return &SetParameterOperation{name: name, value: value, addr: addr}
+}
+
+// Clock sets the cluster clock for this operation.
+func (spo *SetParameterOperation) Clock(clock *session.ClusterClock) *SetParameterOperation {
+	spo.clock = clock
+	return spo
+}
+
+// Deployment sets the Deployment for this operation.
+func (spo *SetParameterOperation) Deployment(d Deployment) *SetParameterOperation {
+	spo.d = d
+	return spo
+}
+
+// Was returns the value the parameter had before the last successful Execute.
+func (spo *SetParameterOperation) Was() bsoncore.Value { return spo.was }
+
+func (spo *SetParameterOperation) command(dst []byte, _ description.SelectedServer) ([]byte, error) {
+	dst = bsoncore.AppendInt32Element(dst, "setParameter", 1)
+	return bsoncore.AppendValueElement(dst, spo.name, spo.value), nil
+}
+
+func (spo *SetParameterOperation) processResponse(response bsoncore.Document, _ Server) error {
+	if was, err := response.LookupErr("was"); err == nil {
+		spo.was = was
+	}
+	return nil
+}
+
+// Execute runs this operation.
+func (spo *SetParameterOperation) Execute(ctx context.Context) error {
+	if spo.d == nil {
+		return errors.New("a SetParameterOperation must have a Deployment set before Execute can be called")
+	}
+	if spo.name == "" {
+		return errors.New("the parameter name must be of non-zero length")
+	}
+	if spo.value.Type == 0 {
+		return errors.New("a SetParameterOperation must have a value to set")
+	}
+
+	spo.was = bsoncore.Value{}
+	return runOnServer(ctx, spo.d, "admin", spo.addr, spo.clock, spo.command, spo.processResponse)
+}
+
+// runOnServer runs a command on database against the server at addr. The read preference is set
+// to nearest so that the command can be run against any member of a replica set, as with
+// RunCommandOnServer.
+func runOnServer(
+	ctx context.Context, d Deployment, database string, addr address.Address, clock *session.ClusterClock,
+	cmdFn func([]byte, description.SelectedServer) ([]byte, error),
+	processFn func(bsoncore.Document, Server) error,
+) error {
+	return Operation{
+		CommandFn:         cmdFn,
+		Database:          database,
+		Deployment:        d,
+		Selector:          description.AddressSelector(addr),
+		ReadPreference:    readpref.Nearest(),
+		Clock:             clock,
+		ProcessResponseFn: processFn,
+	}.Execute(ctx, nil)
+}
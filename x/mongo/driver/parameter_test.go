@@ -0,0 +1,86 @@
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+func TestParameter(t *testing.T) {
+	topo, _, secondary := replicaSetTopology()
+	deployment := func(reply bsoncore.Document) (*mockDeployment, *mockConnection) {
+		return replyDeployment(description.RSSecondary, reply)
+	}
+
+	t.Run("GetParameter", func(t *testing.T) {
+		d, conn := deployment(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt32Element(nil, "logLevel", 2),
+			bsoncore.AppendDoubleElement(nil, "ok", 1),
+		))
+		op := GetParameter("logLevel", secondary.Addr).Deployment(d)
+		noerr(t, op.Execute(context.Background()))
+
+		cmd := msgCommand(t, conn.pWriteWM)
+		elems, err := cmd.Elements()
+		noerr(t, err)
+		if v, ok := elems[0].Value().Int32OK(); elems[0].Key() != "getParameter" || !ok || v != 1 {
+			t.Errorf("Expected the command to start with getParameter: 1. got %v", cmd)
+		}
+		if v, ok := cmd.Lookup("logLevel").Int32OK(); !ok || v != 1 {
+			t.Errorf("Expected logLevel: 1. got %v", cmd)
+		}
+		if db, _ := cmd.Lookup("$db").StringValueOK(); db != "admin" {
+			t.Errorf("Expected the command to run on admin. got %q", db)
+		}
+		requireRoutedTo(t, d, topo, secondary.Addr)
+		if v, ok := op.Value().Int32OK(); !ok || v != 2 {
+			t.Errorf("Unexpected parameter value. got %v; want %d", op.Value(), 2)
+		}
+	})
+	t.Run("GetParameter missing from reply", func(t *testing.T) {
+		d, _ := deployment(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendDoubleElement(nil, "ok", 1)))
+		if err := GetParameter("logLevel", secondary.Addr).Deployment(d).Execute(context.Background()); err == nil {
+			t.Error("Expected an error when the reply does not contain the parameter")
+		}
+	})
+	t.Run("GetParameter command error", func(t *testing.T) {
+		d, _ := deployment(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendDoubleElement(nil, "ok", 0),
+			bsoncore.AppendStringElement(nil, "errmsg", "no option found to get"),
+		))
+		err := GetParameter("bogus", secondary.Addr).Deployment(d).Execute(context.Background())
+		if derr, ok := err.(Error); !ok || derr.Message != "no option found to get" {
+			t.Errorf("Expected the server error to be returned. got %v", err)
+		}
+	})
+	t.Run("SetParameter", func(t *testing.T) {
+		d, conn := deployment(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt32Element(nil, "was", 0),
+			bsoncore.AppendDoubleElement(nil, "ok", 1),
+		))
+		value := bsoncore.Value{Type: bsontype.Int32, Data: bsoncore.AppendInt32(nil, 1)}
+		op := SetParameter("logLevel", value, secondary.Addr).Deployment(d)
+		noerr(t, op.Execute(context.Background()))
+
+		cmd := msgCommand(t, conn.pWriteWM)
+		if v, ok := cmd.Lookup("setParameter").Int32OK(); !ok || v != 1 {
+			t.Errorf("Expected setParameter: 1. got %v", cmd)
+		}
+		if v, ok := cmd.Lookup("logLevel").Int32OK(); !ok || v != 1 {
+			t.Errorf("Expected logLevel: 1. got %v", cmd)
+		}
+		requireRoutedTo(t, d, topo, secondary.Addr)
+		if was, ok := op.Was().Int32OK(); !ok || was != 0 {
+			t.Errorf("Unexpected previous value. got %v; want %d", op.Was(), 0)
+		}
+	})
+	t.Run("SetParameter requires a value", func(t *testing.T) {
+		d, _ := deployment(nil)
+		if err := SetParameter("logLevel", bsoncore.Value{}, secondary.Addr).Deployment(d).Execute(context.Background()); err == nil {
+			t.Error("Expected an error when no value is set")
+		}
+	})
+}
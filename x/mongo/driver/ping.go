@@ -0,0 +1,103 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// defaultPingTimeout is the maximum amount of time a PingOperation waits when no timeout is set
+// and the context has no earlier deadline.
+const defaultPingTimeout = 5 * time.Second
+
+// PingOperation runs the ping command against a server and measures the round trip time.
+type PingOperation struct {
+	readPref *readpref.ReadPref
+	selector description.ServerSelector
+	clock    *session.ClusterClock
+	timeout  time.Duration
+
+	d Deployment
+
+	start time.Time
+	rtt   time.Duration
+}
+
+// Ping constructs a PingOperation.
+func Ping() *PingOperation { return &PingOperation{} }
+
+// ReadPreference sets the read preference used to select the server to ping. This allows
+// secondaries to be pinged. If not set, the primary is pinged.
+func (po *PingOperation) ReadPreference(rp *readpref.ReadPref) *PingOperation {
+	po.readPref = rp
+	return po
+}
+
+// ServerSelector sets the selector used to select the server to ping.
+func (po *PingOperation) ServerSelector(selector description.ServerSelector) *PingOperation {
+	po.selector = selector
+	return po
+}
+
+// Clock sets the cluster clock for this operation.
+func (po *PingOperation) Clock(clock *session.ClusterClock) *PingOperation {
+	po.clock = clock
+	return po
+}
+
+// Timeout sets the maximum amount of time to wait for the ping to complete, including server
+// selection. If not set, a default of 5 seconds is used.
+func (po *PingOperation) Timeout(timeout time.Duration) *PingOperation {
+	po.timeout = timeout
+	return po
+}
+
+// Deployment sets the Deployment for this operation.
+func (po *PingOperation) Deployment(d Deployment) *PingOperation {
+	po.d = d
+	return po
+}
+
+// RTT returns the round trip time of the ping command measured by the last successful Execute. It
+// does not include server selection or connection checkout.
+func (po *PingOperation) RTT() time.Duration { return po.rtt }
+
+func (po *PingOperation) command(dst []byte, _ description.SelectedServer) ([]byte, error) {
+	po.start = time.Now()
+	return bsoncore.AppendInt32Element(dst, "ping", 1), nil
+}
+
+func (po *PingOperation) processResponse(bsoncore.Document, Server) error {
+	po.rtt = time.Since(po.start)
+	return nil
+}
+
+// Execute runs this operation. A nil error indicates that the server responded successfully.
+func (po *PingOperation) Execute(ctx context.Context) error {
+	if po.d == nil {
+		return errors.New("a PingOperation must have a Deployment set before Execute can be called")
+	}
+
+	timeout := po.timeout
+	if timeout <= 0 {
+		timeout = defaultPingTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	po.rtt = 0
+	return Operation{
+		CommandFn:         po.command,
+		Database:          "admin",
+		Deployment:        po.d,
+		Selector:          po.selector,
+		ReadPreference:    po.readPref,
+		Clock:             po.clock,
+		ProcessResponseFn: po.processResponse,
+	}.Execute(ctx, nil)
+}
@@ -0,0 +1,63 @@
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+func TestPing(t *testing.T) {
+	t.Run("requires a Deployment", func(t *testing.T) {
+		if err := Ping().Execute(context.Background()); err == nil {
+			t.Error("Expected an error when no Deployment is set")
+		}
+	})
+	t.Run("success", func(t *testing.T) {
+		conn := &mockConnection{
+			rDesc:   description.Server{Kind: description.RSSecondary, WireVersion: &description.VersionRange{Max: 6}},
+			rReadWM: opMsgReply(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1))),
+		}
+		d := new(mockDeployment)
+		d.returns.server = SingleConnectionDeployment{C: conn}
+		d.returns.kind = description.ReplicaSetWithPrimary
+
+		op := Ping().ReadPreference(readpref.Secondary()).Deployment(d)
+		noerr(t, op.Execute(context.Background()))
+		if op.RTT() < 0 {
+			t.Errorf("Expected a non-negative round trip time. got %v", op.RTT())
+		}
+
+		secondary := description.Server{Addr: address.Address("localhost:27018"), Kind: description.RSSecondary}
+		topo := description.Topology{
+			Kind: description.ReplicaSetWithPrimary,
+			Servers: []description.Server{
+				{Addr: address.Address("localhost:27017"), Kind: description.RSPrimary},
+				secondary,
+			},
+		}
+		selected, err := d.params.selector.SelectServer(topo, topo.Servers)
+		noerr(t, err)
+		if len(selected) != 1 || selected[0].Addr != secondary.Addr {
+			t.Errorf("Expected the read preference to select the secondary. got %v", selected)
+		}
+	})
+	t.Run("command error", func(t *testing.T) {
+		conn := &mockConnection{
+			rDesc: description.Server{Kind: description.RSPrimary, WireVersion: &description.VersionRange{Max: 6}},
+			rReadWM: opMsgReply(bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 0),
+				bsoncore.AppendStringElement(nil, "errmsg", "ping failed"),
+			)),
+		}
+		d := new(mockDeployment)
+		d.returns.server = SingleConnectionDeployment{C: conn}
+
+		if err := Ping().Deployment(d).Execute(context.Background()); err == nil {
+			t.Error("Expected an error for a failed ping")
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package driver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// ProfilingLevel is a level of the database profiler.
+type ProfilingLevel int32
+
+// These are the levels of the database profiler.
+const (
+	ProfilingOff  ProfilingLevel = 0 // Collects no data.
+	ProfilingSlow ProfilingLevel = 1 // Collects data for operations slower than slowms.
+	ProfilingAll  ProfilingLevel = 2 // Collects data for all operations.
+)
+
+// ProfileOperation runs the profile command to set the profiling level of a database. The profiler
+// writes to the database's system.profile collection, which can be read with ReadProfile.
+type ProfileOperation struct {
+	level    ProfilingLevel
+	slowMS   int32
+	database string
+	clock    *session.ClusterClock
+	client   *session.Client
+
+	d Deployment
+
+	was        ProfilingLevel
+	prevSlowMS int32
+}
+
+// SetProfilingLevel constructs a ProfileOperation that sets the profiling level to level. Operations
+// slower than slowMS milliseconds are considered slow. If slowMS is negative the threshold is left
+// unchanged.
+func SetProfilingLevel(level ProfilingLevel, slowMS int32) *ProfileOperation {
+	return &ProfileOperation{level: level, slowMS: slowMS}
+}
+
+// Database sets the database to profile.
+func (po *ProfileOperation) Database(database string) *ProfileOperation {
+	po.database = database
+	return po
+}
+
+// Clock sets the cluster clock for this operation.
+func (po *ProfileOperation) Clock(clock *session.ClusterClock) *ProfileOperation {
+	po.clock = clock
+	return po
+}
+
+// Session sets the session for this operation.
+func (po *ProfileOperation) Session(client *session.Client) *ProfileOperation {
+	po.client = client
+	return po
+}
+
+// Deployment sets the Deployment for this operation.
+func (po *ProfileOperation) Deployment(d Deployment) *ProfileOperation {
+	po.d = d
+	return po
+}
+
+// Was returns the profiling level before the last successful Execute.
+func (po *ProfileOperation) Was() ProfilingLevel { return po.was }
+
+// PreviousSlowMS returns the slow operation threshold before the last successful Execute.
+func (po *ProfileOperation) PreviousSlowMS() int32 { return po.prevSlowMS }
+
+func (po *ProfileOperation) command(dst []byte, _ description.SelectedServer) ([]byte, error) {
+	dst = bsoncore.AppendInt32Element(dst, "profile", int32(po.level))
+	if po.slowMS >= 0 {
+		dst = bsoncore.AppendInt32Element(dst, "slowms", po.slowMS)
+	}
+	return dst, nil
+}
+
+func (po *ProfileOperation) processResponse(response bsoncore.Document, _ Server) error {
+	if was, ok := response.Lookup("was").AsInt64OK(); ok {
+		po.was = ProfilingLevel(was)
+	}
+	if slowMS, ok := response.Lookup("slowms").AsInt64OK(); ok {
+		po.prevSlowMS = int32(slowMS)
+	}
+	return nil
+}
+
+// Execute runs this operation against the primary, since changing the profiling level is a write.
+func (po *ProfileOperation) Execute(ctx context.Context) error {
+	if po.d == nil {
+		return errors.New("a ProfileOperation must have a Deployment set before Execute can be called")
+	}
+	if po.database == "" {
+		return errors.New("Database must be of non-zero length")
+	}
+	if po.level < ProfilingOff || po.level > ProfilingAll {
+		return errors.New("the profiling level must be ProfilingOff, ProfilingSlow or ProfilingAll")
+	}
+
+	return Operation{
+		CommandFn:         po.command,
+		Database:          po.database,
+		Deployment:        po.d,
+		Selector:          description.WriteSelector(),
+		Clock:             po.clock,
+		Client:            po.client,
+		ProcessResponseFn: po.processResponse,
+	}.Execute(ctx, nil)
+}
+
+// ReadProfileOperation reads entries from a database's system.profile collection, newest first.
+// Only a single batch is read, so Limit should be set to the number of entries wanted.
+type ReadProfileOperation struct {
+	filter   bsoncore.Document
+	limit    int64
+	database string
+	clock    *session.ClusterClock
+	client   *session.Client
+
+	d Deployment
+
+	entries []bsoncore.Document
+}
+
+// ReadProfile constructs a ReadProfileOperation.
+func ReadProfile() *ReadProfileOperation { return &ReadProfileOperation{} }
+
+// Filter sets the filter used to select profile entries, e.g. {op: "query"}.
+func (rpo *ReadProfileOperation) Filter(filter bsoncore.Document) *ReadProfileOperation {
+	rpo.filter = filter
+	return rpo
+}
+
+// Limit sets the maximum number of entries to read.
+func (rpo *ReadProfileOperation) Limit(limit int64) *ReadProfileOperation {
+	rpo.limit = limit
+	return rpo
+}
+
+// Database sets the database whose profile is read.
+func (rpo *ReadProfileOperation) Database(database string) *ReadProfileOperation {
+	rpo.database = database
+	return rpo
+}
+
+// Clock sets the cluster clock for this operation.
+func (rpo *ReadProfileOperation) Clock(clock *session.ClusterClock) *ReadProfileOperation {
+	rpo.clock = clock
+	return rpo
+}
+
+// Session sets the session for this operation.
+func (rpo *ReadProfileOperation) Session(client *session.Client) *ReadProfileOperation {
+	rpo.client = client
+	return rpo
+}
+
+// Deployment sets the Deployment for this operation.
+func (rpo *ReadProfileOperation) Deployment(d Deployment) *ReadProfileOperation {
+	rpo.d = d
+	return rpo
+}
+
+// Entries returns the profile entries read by the last successful Execute.
+func (rpo *ReadProfileOperation) Entries() []bsoncore.Document { return rpo.entries }
+
+func (rpo *ReadProfileOperation) command(dst []byte, _ description.SelectedServer) ([]byte, error) {
+	dst = bsoncore.AppendStringElement(dst, "find", "system.profile")
+	if rpo.filter != nil {
+		dst = bsoncore.AppendDocumentElement(dst, "filter", rpo.filter)
+	}
+	dst = bsoncore.AppendDocumentElement(dst, "sort", bsoncore.BuildDocumentFromElements(nil,
+		bsoncore.AppendInt32Element(nil, "ts", -1),
+	))
+	if rpo.limit > 0 {
+		dst = bsoncore.AppendInt64Element(dst, "limit", rpo.limit)
+	}
+	return bsoncore.AppendBooleanElement(dst, "singleBatch", true), nil
+}
+
+func (rpo *ReadProfileOperation) processResponse(response bsoncore.Document, _ Server) error {
+	batch, ok := response.Lookup("cursor", "firstBatch").ArrayOK()
+	if !ok {
+		return errors.New("profile reply does not contain a cursor.firstBatch array")
+	}
+	values, err := batch.Values()
+	if err != nil {
+		return err
+	}
+	for _, val := range values {
+		if doc, ok := val.DocumentOK(); ok {
+			rpo.entries = append(rpo.entries, doc)
+		}
+	}
+	return nil
+}
+
+// Execute runs this operation.
+func (rpo *ReadProfileOperation) Execute(ctx context.Context) error {
+	if rpo.d == nil {
+		return errors.New("a ReadProfileOperation must have a Deployment set before Execute can be called")
+	}
+	if rpo.database == "" {
+		return errors.New("Database must be of non-zero length")
+	}
+
+	rpo.entries = nil
+	return Operation{
+		CommandFn:         rpo.command,
+		Database:          rpo.database,
+		Deployment:        rpo.d,
+		Clock:             rpo.clock,
+		Client:            rpo.client,
+		ProcessResponseFn: rpo.processResponse,
+	}.Execute(ctx, nil)
+}
@@ -0,0 +1,87 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+func TestProfile(t *testing.T) {
+	topo, primary, _ := replicaSetTopology()
+	deployment := func(reply bsoncore.Document) (*mockDeployment, *mockConnection) {
+		return replyDeployment(description.RSPrimary, reply)
+	}
+
+	t.Run("SetProfilingLevel", func(t *testing.T) {
+		d, conn := deployment(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt32Element(nil, "was", 0),
+			bsoncore.AppendInt32Element(nil, "slowms", 100),
+			bsoncore.AppendDoubleElement(nil, "ok", 1),
+		))
+		op := SetProfilingLevel(ProfilingSlow, 50).Database("foo").Deployment(d)
+		noerr(t, op.Execute(context.Background()))
+
+		cmd := msgCommand(t, conn.pWriteWM)
+		if level, ok := cmd.Lookup("profile").Int32OK(); !ok || level != 1 {
+			t.Errorf("Expected profile level 1. got %v", cmd)
+		}
+		if slowMS, ok := cmd.Lookup("slowms").Int32OK(); !ok || slowMS != 50 {
+			t.Errorf("Expected slowms 50. got %v", cmd)
+		}
+		if db, _ := cmd.Lookup("$db").StringValueOK(); db != "foo" {
+			t.Errorf("Expected the command to run on foo. got %q", db)
+		}
+		requireRoutedTo(t, d, topo, primary.Addr)
+		if op.Was() != ProfilingOff || op.PreviousSlowMS() != 100 {
+			t.Errorf("Unexpected previous settings. got %v, %d; want %v, %d", op.Was(), op.PreviousSlowMS(), ProfilingOff, 100)
+		}
+	})
+	t.Run("SetProfilingLevel without slowms", func(t *testing.T) {
+		d, conn := deployment(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendDoubleElement(nil, "ok", 1)))
+		noerr(t, SetProfilingLevel(ProfilingOff, -1).Database("foo").Deployment(d).Execute(context.Background()))
+		if _, err := msgCommand(t, conn.pWriteWM).LookupErr("slowms"); err == nil {
+			t.Error("slowms should not be sent when negative")
+		}
+	})
+	t.Run("SetProfilingLevel invalid level", func(t *testing.T) {
+		d, _ := deployment(nil)
+		if err := SetProfilingLevel(3, 0).Database("foo").Deployment(d).Execute(context.Background()); err == nil {
+			t.Error("Expected an error for an invalid profiling level")
+		}
+	})
+	t.Run("ReadProfile", func(t *testing.T) {
+		entry := bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendStringElement(nil, "op", "query"),
+			bsoncore.AppendStringElement(nil, "ns", "foo.bar"),
+		)
+		d, conn := deployment(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendDocumentElement(nil, "cursor", bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt64Element(nil, "id", 0),
+				bsoncore.AppendStringElement(nil, "ns", "foo.system.profile"),
+				bsoncore.AppendArrayElement(nil, "firstBatch", bsoncore.BuildArray(nil, bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: entry})),
+			)),
+			bsoncore.AppendDoubleElement(nil, "ok", 1),
+		))
+		op := ReadProfile().Limit(10).Database("foo").Deployment(d)
+		noerr(t, op.Execute(context.Background()))
+
+		cmd := msgCommand(t, conn.pWriteWM)
+		if coll, _ := cmd.Lookup("find").StringValueOK(); coll != "system.profile" {
+			t.Errorf("Expected find on system.profile. got %v", cmd)
+		}
+		if ts, ok := cmd.Lookup("sort", "ts").Int32OK(); !ok || ts != -1 {
+			t.Errorf("Expected entries to be sorted newest first. got %v", cmd)
+		}
+		if limit, ok := cmd.Lookup("limit").Int64OK(); !ok || limit != 10 {
+			t.Errorf("Expected limit 10. got %v", cmd)
+		}
+		entries := op.Entries()
+		if len(entries) != 1 || !bytes.Equal(entries[0], entry) {
+			t.Errorf("Unexpected entries. got %v; want [%v]", entries, entry)
+		}
+	})
+}
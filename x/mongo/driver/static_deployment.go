@@ -0,0 +1,81 @@
+package driver
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// StaticDeployment is an implementation of Deployment backed by a fixed list of servers. The servers
+// are not monitored, so there is no heartbeat overhead, which makes it suitable for short-lived tools
+// that talk to a known mongos or set of hosts.
+//
+// Because nothing is learned about the servers, each one is described only by its address and a kind
+// inferred from the topology kind: mongos for a sharded deployment, standalone for a single server,
+// and unknown otherwise. Selectors that depend on more than that, such as read preferences against a
+// replica set, will not match any server.
+type StaticDeployment struct {
+	kind    description.TopologyKind
+	descs   []description.Server
+	servers map[address.Address]Server
+	next    uint32
+}
+
+var _ Deployment = (*StaticDeployment)(nil)
+
+// NewStaticDeployment constructs a StaticDeployment of the given kind for addrs. The server for each
+// address is created once by calling newServer.
+func NewStaticDeployment(kind description.TopologyKind, newServer func(address.Address) Server, addrs ...address.Address) *StaticDeployment {
+	var serverKind description.ServerKind
+	switch kind {
+	case description.Sharded:
+		serverKind = description.Mongos
+	case description.Single:
+		serverKind = description.Standalone
+	}
+
+	sd := &StaticDeployment{kind: kind, servers: make(map[address.Address]Server, len(addrs))}
+	for _, addr := range addrs {
+		addr = addr.Canonicalize()
+		if _, ok := sd.servers[addr]; ok {
+			continue
+		}
+		sd.descs = append(sd.descs, description.Server{Addr: addr, Kind: serverKind})
+		sd.servers[addr] = newServer(addr)
+	}
+	return sd
+}
+
+// SelectServer implements the Deployment interface. The servers matching selector are used in turn,
+// and all servers are used in turn if selector is nil. ErrNoStaticServer is returned if no server
+// matches.
+func (sd *StaticDeployment) SelectServer(_ context.Context, selector description.ServerSelector) (Server, error) {
+	candidates := sd.descs
+	if selector != nil {
+		var err error
+		candidates, err = selector.SelectServer(description.Topology{Kind: sd.kind, Servers: sd.descs}, sd.descs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoStaticServer
+	}
+
+	next := atomic.AddUint32(&sd.next, 1) - 1
+	srvr, ok := sd.servers[candidates[next%uint32(len(candidates))].Addr]
+	if !ok {
+		return nil, ErrNoStaticServer
+	}
+	return srvr, nil
+}
+
+// SupportsRetry implements the Deployment interface. It always returns false, because without
+// monitoring there is nothing to tell whether the servers support sessions.
+func (*StaticDeployment) SupportsRetry() bool { return false }
+
+// Kind implements the Deployment interface. It returns the topology kind the StaticDeployment was
+// constructed with.
+func (sd *StaticDeployment) Kind() description.TopologyKind { return sd.kind }
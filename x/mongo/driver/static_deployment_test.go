@@ -0,0 +1,66 @@
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// addrServer is a Server that only records the address it was created for.
+type addrServer struct{ addr address.Address }
+
+func (addrServer) Connection(context.Context) (Connection, error) { return nil, nil }
+
+func TestStaticDeployment(t *testing.T) {
+	newServer := func(addr address.Address) Server { return addrServer{addr: addr} }
+	selectAddr := func(t *testing.T, sd *StaticDeployment, selector description.ServerSelector) address.Address {
+		t.Helper()
+		srvr, err := sd.SelectServer(context.Background(), selector)
+		noerr(t, err)
+		return srvr.(addrServer).addr
+	}
+
+	t.Run("round-robins over the static list", func(t *testing.T) {
+		sd := NewStaticDeployment(description.Sharded, newServer, "a:27017", "b:27017", "A:27017")
+		want := []address.Address{"a:27017", "b:27017", "a:27017", "b:27017"}
+		for i, addr := range want {
+			if got := selectAddr(t, sd, nil); got != addr {
+				t.Errorf("Unexpected server for selection %d. got %s; want %s", i, got, addr)
+			}
+		}
+	})
+	t.Run("uses the selector", func(t *testing.T) {
+		sd := NewStaticDeployment(description.Sharded, newServer, "a:27017", "b:27017")
+		for i := 0; i < 3; i++ {
+			if got := selectAddr(t, sd, description.AddressSelector("b:27017")); got != "b:27017" {
+				t.Errorf("Unexpected server. got %s; want %s", got, "b:27017")
+			}
+		}
+		if got := selectAddr(t, sd, description.WriteSelector()); got != "a:27017" && got != "b:27017" {
+			t.Errorf("Expected a mongos to be selected for writes. got %s", got)
+		}
+	})
+	t.Run("no matching server", func(t *testing.T) {
+		sd := NewStaticDeployment(description.Single, newServer, "a:27017")
+		none := description.ServerSelectorFunc(func(description.Topology, []description.Server) ([]description.Server, error) {
+			return nil, nil
+		})
+		_, err := sd.SelectServer(context.Background(), none)
+		if err != ErrNoStaticServer {
+			t.Errorf("Unexpected error. got %v; want %v", err, ErrNoStaticServer)
+		}
+	})
+	t.Run("reports the configured kind", func(t *testing.T) {
+		for _, kind := range []description.TopologyKind{description.Single, description.Sharded, description.ReplicaSet} {
+			sd := NewStaticDeployment(kind, newServer, "a:27017")
+			if sd.Kind() != kind {
+				t.Errorf("Unexpected kind. got %v; want %v", sd.Kind(), kind)
+			}
+			if sd.SupportsRetry() {
+				t.Errorf("Expected a static deployment not to support retry")
+			}
+		}
+	})
+}
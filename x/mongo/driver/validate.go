@@ -0,0 +1,85 @@
+package driver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// ValidateOperation runs the validate command against a single server. Validation checks the data
+// and indexes of one node, so the operation targets the server at an address rather than one chosen
+// by read preference. A full validation is considerably more expensive and may block the
+// collection while it runs.
+type ValidateOperation struct {
+	collection string
+	full       bool
+	addr       address.Address
+	database   string
+	clock      *session.ClusterClock
+
+	d Deployment
+
+	result bsoncore.Document
+}
+
+// Validate constructs a ValidateOperation that validates collection on the server at addr. If full
+// is true a full validation is performed.
+func Validate(collection string, full bool, addr address.Address) *ValidateOperation {
+	return &ValidateOperation{collection: collection, full: full, addr: addr}
+}
+
+// Database sets the database of the collection to validate.
+func (vo *ValidateOperation) Database(database string) *ValidateOperation {
+	vo.database = database
+	return vo
+}
+
+// Clock sets the cluster clock for this operation.
+func (vo *ValidateOperation) Clock(clock *session.ClusterClock) *ValidateOperation {
+	vo.clock = clock
+	return vo
+}
+
+// Deployment sets the Deployment for this operation.
+func (vo *ValidateOperation) Deployment(d Deployment) *ValidateOperation {
+	vo.d = d
+	return vo
+}
+
+// Result returns the validation result document from the last successful Execute. Whether the
+// collection is valid is reported by the document's valid field; an invalid collection is not an
+// error.
+func (vo *ValidateOperation) Result() bsoncore.Document { return vo.result }
+
+func (vo *ValidateOperation) command(dst []byte, _ description.SelectedServer) ([]byte, error) {
+	dst = bsoncore.AppendStringElement(dst, "validate", vo.collection)
+	if vo.full {
+		dst = bsoncore.AppendBooleanElement(dst, "full", true)
+	}
+	return dst, nil
+}
+
+func (vo *ValidateOperation) processResponse(response bsoncore.Document, _ Server) error {
+	vo.result = response
+	return nil
+}
+
+// Execute runs this operation.
+func (vo *ValidateOperation) Execute(ctx context.Context) error {
+	if vo.d == nil {
+		return errors.New("a ValidateOperation must have a Deployment set before Execute can be called")
+	}
+	if vo.database == "" {
+		return errors.New("Database must be of non-zero length")
+	}
+	if vo.collection == "" {
+		return errors.New("Collection must be of non-zero length")
+	}
+
+	vo.result = nil
+	return runOnServer(ctx, vo.d, vo.database, vo.addr, vo.clock, vo.command, vo.processResponse)
+}
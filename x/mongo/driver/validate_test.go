@@ -0,0 +1,55 @@
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+func TestValidate(t *testing.T) {
+	topo, _, secondary := replicaSetTopology()
+	reply := bsoncore.BuildDocumentFromElements(nil,
+		bsoncore.AppendStringElement(nil, "ns", "db.coll"),
+		bsoncore.AppendBooleanElement(nil, "valid", false),
+		bsoncore.AppendDoubleElement(nil, "ok", 1),
+	)
+	deployment := func() (*mockDeployment, *mockConnection) {
+		return replyDeployment(description.RSSecondary, reply)
+	}
+
+	t.Run("full", func(t *testing.T) {
+		d, conn := deployment()
+		op := Validate("coll", true, secondary.Addr).Database("db").Deployment(d)
+		noerr(t, op.Execute(context.Background()))
+
+		cmd := msgCommand(t, conn.pWriteWM)
+		if coll, _ := cmd.Lookup("validate").StringValueOK(); coll != "coll" {
+			t.Errorf("Expected validate: %q. got %v", "coll", cmd)
+		}
+		if full, ok := cmd.Lookup("full").BooleanOK(); !ok || !full {
+			t.Errorf("Expected full: true. got %v", cmd)
+		}
+		if db, _ := cmd.Lookup("$db").StringValueOK(); db != "db" {
+			t.Errorf("Expected the command to run on db. got %q", db)
+		}
+		requireRoutedTo(t, d, topo, secondary.Addr)
+		if valid, ok := op.Result().Lookup("valid").BooleanOK(); !ok || valid {
+			t.Errorf("Expected the result document to be returned. got %v", op.Result())
+		}
+	})
+	t.Run("not full", func(t *testing.T) {
+		d, conn := deployment()
+		noerr(t, Validate("coll", false, secondary.Addr).Database("db").Deployment(d).Execute(context.Background()))
+		if _, err := msgCommand(t, conn.pWriteWM).LookupErr("full"); err == nil {
+			t.Error("Expected full to be omitted")
+		}
+	})
+	t.Run("requires a collection", func(t *testing.T) {
+		d, _ := deployment()
+		if err := Validate("", false, secondary.Addr).Database("db").Deployment(d).Execute(context.Background()); err == nil {
+			t.Error("Expected an error when no collection is set")
+		}
+	})
+}
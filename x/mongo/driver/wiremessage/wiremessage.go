@@ -64,6 +64,18 @@ func AppendMsgSectionType(dst []byte, stype wiremessage.SectionType) []byte {
 	return append(dst, byte(stype))
 }
 
+// AppendMsgSectionDocumentSequence appends a document sequence (payload type 1) section to dst.
+// The section type must be appended separately using AppendMsgSectionType. The length of the
+// section is computed from the identifier and the documents.
+func AppendMsgSectionDocumentSequence(dst []byte, identifier string, docs ...bsoncore.Document) []byte {
+	idx, dst := bsoncore.ReserveLength(dst)
+	dst = appendCString(dst, identifier)
+	for _, doc := range docs {
+		dst = append(dst, doc...)
+	}
+	return bsoncore.UpdateLength(dst, idx, int32(len(dst[idx:])))
+}
+
 // AppendQueryFullCollectionName appends the full collection name to dst.
 func AppendQueryFullCollectionName(dst []byte, ns string) []byte {
 	return appendCString(dst, ns)
@@ -133,7 +145,7 @@ func ReadMsgSectionSingleDocument(src []byte) (doc bsoncore.Document, rem []byte
 // ReadMsgSectionDocumentSequence reads an identifier and document sequence from src.
 func ReadMsgSectionDocumentSequence(src []byte) (identifier string, docs []bsoncore.Document, rem []byte, ok bool) {
 	length, rem, ok := readi32(src)
-	if !ok || int(length) > len(src) {
+	if !ok || length < 4 || int(length) > len(src) {
 		return "", nil, rem, false
 	}
 
@@ -40,10 +40,7 @@ func abortTransaction(
 	selector description.ServerSelector,
 	oldErr error,
 ) (result.TransactionResult, error) {
-	if cmd.Session != nil && cmd.Session.PinnedServer != nil {
-		selector = cmd.Session.PinnedServer
-	}
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		// If retrying server selection, return the original error if it fails
 		if oldErr != nil {
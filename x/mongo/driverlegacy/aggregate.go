@@ -34,25 +34,22 @@ func Aggregate(
 	clientID uuid.UUID,
 	pool *session.Pool,
 	registry *bsoncodec.Registry,
+	retryWrite bool,
 	opts ...*options.AggregateOptions,
 ) (*BatchCursor, error) {
 
-	dollarOut := cmd.HasDollarOut()
+	writeStage := cmd.HasWriteStage()
 
 	var ss *topology.SelectedServer
 	var err error
-	if cmd.Session != nil && cmd.Session.PinnedServer != nil {
-		writeSelector = cmd.Session.PinnedServer
-		readSelector = cmd.Session.PinnedServer
-	}
-	switch dollarOut {
+	switch writeStage {
 	case true:
-		ss, err = topo.SelectServerLegacy(ctx, writeSelector)
+		ss, err = selectServer(ctx, topo, cmd.Session, writeSelector)
 		if err != nil {
 			return nil, err
 		}
 	case false:
-		ss, err = topo.SelectServerLegacy(ctx, readSelector)
+		ss, err = selectServer(ctx, topo, cmd.Session, readSelector)
 		if err != nil {
 			return nil, err
 		}
@@ -73,8 +70,8 @@ func Aggregate(
 	cmd.ReadPref = rp
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return nil, err
 		}
@@ -86,24 +83,14 @@ func Aggregate(
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"allowDiskUse", bsonx.Boolean(*aggOpts.AllowDiskUse)})
 	}
 	var batchSize int32
+	cmd.Opts, cmd.CursorOpts = appendBatchSize(cmd.Opts, cmd.CursorOpts, aggOpts.BatchSize)
 	if aggOpts.BatchSize != nil {
-		elem := bsonx.Elem{"batchSize", bsonx.Int32(*aggOpts.BatchSize)}
-		cmd.Opts = append(cmd.Opts, elem)
-		cmd.CursorOpts = append(cmd.CursorOpts, elem)
 		batchSize = *aggOpts.BatchSize
 	}
-	if aggOpts.BypassDocumentValidation != nil && desc.WireVersion.Includes(4) {
-		cmd.Opts = append(cmd.Opts, bsonx.Elem{"bypassDocumentValidation", bsonx.Boolean(*aggOpts.BypassDocumentValidation)})
-	}
-	if aggOpts.Collation != nil {
-		if desc.WireVersion.Max < 5 {
-			return nil, ErrCollation
-		}
-		collDoc, err := bsonx.ReadDoc(aggOpts.Collation.ToDocument())
-		if err != nil {
-			return nil, err
-		}
-		cmd.Opts = append(cmd.Opts, bsonx.Elem{"collation", bsonx.Document(collDoc)})
+	cmd.Opts = appendBypassDocumentValidation(cmd.Opts, aggOpts.BypassDocumentValidation, desc)
+	cmd.Opts, err = appendCollation(cmd.Opts, aggOpts.Collation, desc)
+	if err != nil {
+		return nil, err
 	}
 	if aggOpts.MaxTime != nil {
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"maxTimeMS", bsonx.Int64(int64(*aggOpts.MaxTime / time.Millisecond))})
@@ -118,7 +105,7 @@ func Aggregate(
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"comment", bsonx.String(*aggOpts.Comment)})
 	}
 	if aggOpts.Hint != nil {
-		hintElem, err := interfaceToElement("hint", aggOpts.Hint, registry)
+		hintElem, err := hintToElement("hint", aggOpts.Hint, registry)
 		if err != nil {
 			return nil, err
 		}
@@ -127,10 +114,27 @@ func Aggregate(
 	}
 
 	res, err := cmd.RoundTrip(ctx, desc, conn)
-	if err != nil {
-		if wce, ok := err.(result.WriteConcernError); ok {
-			ss.ProcessWriteConcernError(&wce)
+	if wce, ok := err.(result.WriteConcernError); ok {
+		ss.ProcessWriteConcernError(&wce)
+	}
+	// A pipeline ending in $merge is retried once, like a retryable write, on another server that
+	// accepts writes.
+	if retryWrite && cmd.RetryableWrite() && aggregateRetryable(err) &&
+		retrySupported(topo, desc, cmd.Session, cmd.WriteConcern) {
+		retrySS, serr := selectServer(ctx, topo, cmd.Session, writeSelector)
+		if serr == nil && retrySupported(topo, retrySS.Description(), cmd.Session, cmd.WriteConcern) {
+			retryConn, cerr := retrySS.ConnectionLegacy(ctx)
+			if cerr == nil {
+				defer retryConn.Close()
+				ss, desc = retrySS, retrySS.Description()
+				res, err = cmd.RoundTrip(ctx, desc, retryConn)
+				if wce, ok := err.(result.WriteConcernError); ok {
+					ss.ProcessWriteConcernError(&wce)
+				}
+			}
 		}
+	}
+	if err != nil {
 		closeImplicitSession(cmd.Session)
 		return nil, err
 	}
@@ -139,7 +143,15 @@ func Aggregate(
 		return buildLegacyCommandBatchCursor(res, batchSize, ss.Server)
 	}
 
-	return NewBatchCursor(bsoncore.Document(res), cmd.Session, cmd.Clock, ss.Server, cmd.CursorOpts...)
+	bc, err := NewBatchCursor(bsoncore.Document(res), cmd.Session, cmd.Clock, ss.Server, cmd.CursorOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if aggOpts.Collation != nil {
+		bc.collation = bsoncore.Document(aggOpts.Collation.ToDocument())
+	}
+	bc.comment = aggOpts.Comment
+	return bc, nil
 }
 
 func buildLegacyCommandBatchCursor(rdr bson.Raw, batchSize int32, server *topology.Server) (*BatchCursor, error) {
@@ -202,3 +214,14 @@ func getCursorValues(result bson.Raw) (*bsoncore.DocumentSequence, command.Names
 
 	return batch, namespace, cursorID, nil
 }
+
+// aggregateRetryable returns true if err is a retryable command error or write concern error.
+func aggregateRetryable(err error) bool {
+	switch tt := err.(type) {
+	case command.Error:
+		return tt.Retryable()
+	case result.WriteConcernError:
+		return command.IsWriteConcernErrorRetryable(&tt)
+	}
+	return false
+}
@@ -10,6 +10,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driver"
 	"github.com/lakshay2395/mongo-go-driver/x/network/address"
 	"github.com/lakshay2395/mongo-go-driver/x/network/description"
@@ -52,18 +53,38 @@ type HandshakeOptions struct {
 	Authenticator         Authenticator
 	Compressors           []string
 	DBUser                string
+	DriverInfo            *driver.DriverInfo
+	LoadBalanced          bool
 	PerformAuthentication func(description.Server) bool
 }
 
 // Handshaker creates a connection handshaker for the given authenticator.
 func Handshaker(h driver.Handshaker, options *HandshakeOptions) driver.Handshaker {
 	return driver.HandshakerFunc(func(ctx context.Context, addr address.Address, conn driver.Connection) (description.Server, error) {
-		desc, err := driver.IsMaster().
+		op := driver.IsMaster().
 			AppName(options.AppName).
 			Compressors(options.Compressors).
-			SASLSupportedMechs(options.DBUser).
-			Handshake(ctx, addr, conn)
+			DriverInfo(options.DriverInfo).
+			LoadBalanced(options.LoadBalanced).
+			SASLSupportedMechs(options.DBUser)
+
+		// If the authenticator supports it, begin authentication as part of the handshake to save a
+		// round trip. Servers that don't support speculative authentication ignore the field.
+		var conversation SpeculativeConversation
+		if sa, ok := options.Authenticator.(SpeculativeAuthenticator); ok {
+			var err error
+			conversation, err = sa.CreateSpeculativeConversation()
+			if err != nil {
+				return description.Server{}, newAuthError("failed to create speculative authentication message", err)
+			}
+			firstMsg, err := conversation.FirstMessage()
+			if err != nil {
+				return description.Server{}, newAuthError("failed to create speculative authentication message", err)
+			}
+			op = op.SpeculativeAuthenticate(firstMsg)
+		}
 
+		desc, err := op.Handshake(ctx, addr, conn)
 		if err != nil {
 			return description.Server{}, newAuthError("handshake failure", err)
 		}
@@ -78,7 +99,14 @@ func Handshaker(h driver.Handshaker, options *HandshakeOptions) driver.Handshake
 			}
 		}
 		if performAuth(desc) && options.Authenticator != nil {
-			err = options.Authenticator.Auth(ctx, desc, conn)
+			// Fall back to the full authentication exchange if the server didn't respond to the
+			// speculative authentication attempt.
+			speculativeResponse := op.Result().SpeculativeAuthenticate
+			if conversation != nil && len(speculativeResponse) > 0 {
+				err = conversation.Finish(ctx, conn, bsoncore.Document(speculativeResponse))
+			} else {
+				err = options.Authenticator.Auth(ctx, desc, conn)
+			}
 			if err != nil {
 				return description.Server{}, newAuthError("auth error", err)
 			}
@@ -97,6 +125,26 @@ type Authenticator interface {
 	Auth(context.Context, description.Server, driver.Connection) error
 }
 
+// SpeculativeAuthenticator is an Authenticator that can begin authentication as part of the
+// isMaster handshake, which is supported by MongoDB 4.4+.
+type SpeculativeAuthenticator interface {
+	Authenticator
+
+	// CreateSpeculativeConversation creates a conversation for a single connection.
+	CreateSpeculativeConversation() (SpeculativeConversation, error)
+}
+
+// SpeculativeConversation is an authentication conversation that can be started during the
+// isMaster handshake.
+type SpeculativeConversation interface {
+	// FirstMessage returns the document to send in the speculativeAuthenticate field of the
+	// isMaster command.
+	FirstMessage() (bsoncore.Document, error)
+
+	// Finish completes the conversation using the server's speculativeAuthenticate reply.
+	Finish(ctx context.Context, conn driver.Connection, firstResponse bsoncore.Document) error
+}
+
 func newAuthError(msg string, inner error) error {
 	return &Error{
 		message: msg,
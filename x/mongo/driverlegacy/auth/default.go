@@ -8,6 +8,7 @@ package auth
 
 import (
 	"context"
+	"sync"
 
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driver"
 	"github.com/lakshay2395/mongo-go-driver/x/network/description"
@@ -23,6 +24,9 @@ func newDefaultAuthenticator(cred *Cred) (Authenticator, error) {
 // on the server version.
 type DefaultAuthenticator struct {
 	Cred *Cred
+
+	mu    sync.Mutex
+	scram map[string]Authenticator
 }
 
 // Auth authenticates the connection.
@@ -32,9 +36,9 @@ func (a *DefaultAuthenticator) Auth(ctx context.Context, desc description.Server
 
 	switch chooseAuthMechanism(desc) {
 	case SCRAMSHA256:
-		actual, err = newScramSHA256Authenticator(a.Cred)
+		actual, err = a.scramAuthenticator(SCRAMSHA256, newScramSHA256Authenticator)
 	case SCRAMSHA1:
-		actual, err = newScramSHA1Authenticator(a.Cred)
+		actual, err = a.scramAuthenticator(SCRAMSHA1, newScramSHA1Authenticator)
 	default:
 		actual, err = newMongoDBCRAuthenticator(a.Cred)
 	}
@@ -46,6 +50,47 @@ func (a *DefaultAuthenticator) Auth(ctx context.Context, desc description.Server
 	return actual.Auth(ctx, desc, conn)
 }
 
+// CreateSpeculativeConversation creates a SCRAM-SHA-256 conversation to send during the handshake,
+// because servers that support speculative authentication also support SCRAM-SHA-256. If the server
+// does not reply to it, for example because the user only has SCRAM-SHA-1 credentials, Auth
+// negotiates the mechanism instead. This implements the SpeculativeAuthenticator interface.
+func (a *DefaultAuthenticator) CreateSpeculativeConversation() (SpeculativeConversation, error) {
+	actual, err := a.scramAuthenticator(SCRAMSHA256, newScramSHA256Authenticator)
+	if err != nil {
+		return nil, newAuthError("error creating authenticator", err)
+	}
+	return actual.(SpeculativeAuthenticator).CreateSpeculativeConversation()
+}
+
+// scramAuthenticator returns the SCRAM authenticator for mechanism, creating it with newAuth on first
+// use. The SCRAM client it holds caches the keys derived from the password, which is the expensive
+// part of authentication, so reusing it lets every connection after the first skip that derivation.
+// The authenticator, and so the derived keys, are only shared by connections using a.
+//
+// At most one authenticator is kept per mechanism, and its SCRAM client keeps one set of keys per
+// salt and iteration count presented by the servers, so the cache does not grow with the number of
+// connections. a.mu guards the authenticators and the SCRAM client guards its keys with its own lock,
+// so connections can authenticate concurrently; each connection uses its own SCRAM conversation.
+// An authenticator created by CreateAuthenticator for an explicit SCRAM mechanism does not go
+// through this cache, but it holds a single SCRAM client for its lifetime and so caches in the same
+// way.
+func (a *DefaultAuthenticator) scramAuthenticator(mechanism string, newAuth func(*Cred) (Authenticator, error)) (Authenticator, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if actual, ok := a.scram[mechanism]; ok {
+		return actual, nil
+	}
+	actual, err := newAuth(a.Cred)
+	if err != nil {
+		return nil, err
+	}
+	if a.scram == nil {
+		a.scram = make(map[string]Authenticator)
+	}
+	a.scram[mechanism] = actual
+	return actual, nil
+}
+
 // If a server provides a list of supported mechanisms, we choose
 // SCRAM-SHA-256 if it exists or else MUST use SCRAM-SHA-1.
 // Otherwise, we decide based on what is supported.
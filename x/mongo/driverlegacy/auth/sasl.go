@@ -27,66 +27,84 @@ type SaslClientCloser interface {
 	Close()
 }
 
-// ConductSaslConversation handles running a sasl conversation with MongoDB.
-func ConductSaslConversation(ctx context.Context, conn driver.Connection, db string, client SaslClient) error {
+// saslConversation represents a SASL conversation. It can be used either as a standalone exchange
+// of saslStart and saslContinue commands or speculatively, where the saslStart payload is embedded
+// in the isMaster handshake and the conversation is continued from the server's reply.
+type saslConversation struct {
+	client      SaslClient
+	source      string
+	mechanism   string
+	speculative bool
+}
 
-	if db == "" {
-		db = defaultAuthDB
-	}
+var _ SpeculativeConversation = (*saslConversation)(nil)
 
-	if closer, ok := client.(SaslClientCloser); ok {
-		defer closer.Close()
+func newSaslConversation(client SaslClient, source string, speculative bool) *saslConversation {
+	if source == "" {
+		source = defaultAuthDB
+	}
+	return &saslConversation{
+		client:      client,
+		source:      source,
+		speculative: speculative,
 	}
+}
 
-	mech, payload, err := client.Start()
+// FirstMessage returns the saslStart command for this conversation. If the conversation is
+// speculative, the command also includes the authentication database.
+func (sc *saslConversation) FirstMessage() (bsoncore.Document, error) {
+	var payload []byte
+	var err error
+	sc.mechanism, payload, err = sc.client.Start()
 	if err != nil {
-		return newError(err, mech)
+		return nil, err
 	}
 
-	doc := bsoncore.BuildDocumentFromElements(nil,
+	elems := [][]byte{
 		bsoncore.AppendInt32Element(nil, "saslStart", 1),
-		bsoncore.AppendStringElement(nil, "mechanism", mech),
+		bsoncore.AppendStringElement(nil, "mechanism", sc.mechanism),
 		bsoncore.AppendBinaryElement(nil, "payload", 0x00, payload),
-	)
-	saslStartCmd := driver.Command(doc).Database(db).Deployment(driver.SingleConnectionDeployment{conn})
-
-	type saslResponse struct {
-		ConversationID int    `bson:"conversationId"`
-		Code           int    `bson:"code"`
-		Done           bool   `bson:"done"`
-		Payload        []byte `bson:"payload"`
 	}
-
-	var saslResp saslResponse
-
-	err = saslStartCmd.Execute(ctx)
-	if err != nil {
-		return newError(err, mech)
+	if sc.speculative {
+		elems = append(elems, bsoncore.AppendStringElement(nil, "db", sc.source))
 	}
-	rdr := saslStartCmd.Result()
+	return bsoncore.BuildDocumentFromElements(nil, elems...), nil
+}
+
+type saslResponse struct {
+	ConversationID int    `bson:"conversationId"`
+	Code           int    `bson:"code"`
+	Done           bool   `bson:"done"`
+	Payload        []byte `bson:"payload"`
+}
 
-	err = bson.Unmarshal(rdr, &saslResp)
+// Finish completes the conversation using the provided response to the saslStart command, running
+// saslContinue commands on the connection until the conversation is done.
+func (sc *saslConversation) Finish(ctx context.Context, conn driver.Connection, firstResponse bsoncore.Document) error {
+	var saslResp saslResponse
+	err := bson.Unmarshal(firstResponse, &saslResp)
 	if err != nil {
 		return newAuthError("unmarshall error", err)
 	}
 
 	cid := saslResp.ConversationID
-
+	var payload []byte
+	var rdr bsoncore.Document
 	for {
 		if saslResp.Code != 0 {
-			return newError(err, mech)
+			return newError(err, sc.mechanism)
 		}
 
-		if saslResp.Done && client.Completed() {
+		if saslResp.Done && sc.client.Completed() {
 			return nil
 		}
 
-		payload, err = client.Next(saslResp.Payload)
+		payload, err = sc.client.Next(saslResp.Payload)
 		if err != nil {
-			return newError(err, mech)
+			return newError(err, sc.mechanism)
 		}
 
-		if saslResp.Done && client.Completed() {
+		if saslResp.Done && sc.client.Completed() {
 			return nil
 		}
 
@@ -95,11 +113,11 @@ func ConductSaslConversation(ctx context.Context, conn driver.Connection, db str
 			bsoncore.AppendInt32Element(nil, "conversationId", int32(cid)),
 			bsoncore.AppendBinaryElement(nil, "payload", 0x00, payload),
 		)
-		saslContinueCmd := driver.Command(doc).Database(db).Deployment(driver.SingleConnectionDeployment{conn})
+		saslContinueCmd := driver.Command(doc).Database(sc.source).Deployment(driver.SingleConnectionDeployment{conn})
 
 		err = saslContinueCmd.Execute(ctx)
 		if err != nil {
-			return newError(err, mech)
+			return newError(err, sc.mechanism)
 		}
 		rdr = saslContinueCmd.Result()
 
@@ -109,3 +127,25 @@ func ConductSaslConversation(ctx context.Context, conn driver.Connection, db str
 		}
 	}
 }
+
+// ConductSaslConversation handles running a sasl conversation with MongoDB.
+func ConductSaslConversation(ctx context.Context, conn driver.Connection, db string, client SaslClient) error {
+	if closer, ok := client.(SaslClientCloser); ok {
+		defer closer.Close()
+	}
+
+	conversation := newSaslConversation(client, db, false)
+
+	doc, err := conversation.FirstMessage()
+	if err != nil {
+		return newError(err, conversation.mechanism)
+	}
+	saslStartCmd := driver.Command(doc).Database(conversation.source).Deployment(driver.SingleConnectionDeployment{conn})
+
+	err = saslStartCmd.Execute(ctx)
+	if err != nil {
+		return newError(err, conversation.mechanism)
+	}
+
+	return conversation.Finish(ctx, conn, saslStartCmd.Result())
+}
@@ -76,6 +76,13 @@ func (a *ScramAuthenticator) Auth(ctx context.Context, _ description.Server, con
 	return nil
 }
 
+// CreateSpeculativeConversation creates a SASL conversation whose saslStart command can be sent as
+// part of the isMaster handshake. This implements the SpeculativeAuthenticator interface.
+func (a *ScramAuthenticator) CreateSpeculativeConversation() (SpeculativeConversation, error) {
+	adapter := &scramSaslAdapter{conversation: a.client.NewConversation(), mechanism: a.mechanism}
+	return newSaslConversation(adapter, a.source, true), nil
+}
+
 type scramSaslAdapter struct {
 	mechanism    string
 	conversation *scram.ClientConversation
@@ -0,0 +1,413 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/xdg/scram"
+	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driver/drivertest"
+	wiremessagex "github.com/lakshay2395/mongo-go-driver/x/mongo/driver/wiremessage"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+// These values are the SCRAM-SHA-256 test vectors from RFC 7677.
+const (
+	scramClientNonce = "rOprNGfwEbeRWgbNEkqO"
+	scramServerFirst = "r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"
+	scramClientFinal = "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="
+	scramServerFinal = "v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="
+)
+
+func newTestScramSHA256Authenticator(t *testing.T) *ScramAuthenticator {
+	t.Helper()
+	client, err := scram.SHA256.NewClientUnprepped("user", "pencil", "")
+	if err != nil {
+		t.Fatalf("error initializing SCRAM-SHA-256 client: %v", err)
+	}
+	client.WithNonceGenerator(func() string { return scramClientNonce })
+	return &ScramAuthenticator{
+		mechanism: SCRAMSHA256,
+		source:    "admin",
+		client:    client,
+	}
+}
+
+// readCommand returns the command document from an OP_MSG or OP_QUERY wire message.
+func readCommand(t *testing.T, wm []byte) bsoncore.Document {
+	t.Helper()
+	_, _, _, opcode, wm, ok := wiremessagex.ReadHeader(wm)
+	if !ok {
+		t.Fatalf("wiremessage is too short to unmarshal")
+	}
+	switch opcode {
+	case wiremessage.OpQuery:
+		_, wm, _ = wiremessagex.ReadQueryFlags(wm)
+		_, wm, _ = wiremessagex.ReadQueryFullCollectionName(wm)
+		_, wm, _ = wiremessagex.ReadQueryNumberToSkip(wm)
+		_, wm, _ = wiremessagex.ReadQueryNumberToReturn(wm)
+		doc, _, ok := wiremessagex.ReadQueryQuery(wm)
+		if !ok {
+			t.Fatalf("wiremessage is too short to unmarshal")
+		}
+		if query, err := doc.LookupErr("$query"); err == nil {
+			return query.Document()
+		}
+		return doc
+	default:
+		t.Fatalf("unexpected opcode %v", opcode)
+	}
+	return nil
+}
+
+func TestScramSpeculativeAuthentication(t *testing.T) {
+	t.Run("FirstMessage", func(t *testing.T) {
+		conversation, err := newTestScramSHA256Authenticator(t).CreateSpeculativeConversation()
+		noerr(t, err)
+		doc, err := conversation.FirstMessage()
+		noerr(t, err)
+
+		if v, ok := doc.Lookup("saslStart").Int32OK(); !ok || v != 1 {
+			t.Errorf("expected saslStart to be 1, got %v", doc.Lookup("saslStart"))
+		}
+		if mech := doc.Lookup("mechanism").StringValue(); mech != SCRAMSHA256 {
+			t.Errorf("mechanisms do not match. got %s; want %s", mech, SCRAMSHA256)
+		}
+		if db := doc.Lookup("db").StringValue(); db != "admin" {
+			t.Errorf("databases do not match. got %s; want %s", db, "admin")
+		}
+		_, payload := doc.Lookup("payload").Binary()
+		want := "n,,n=user,r=" + scramClientNonce
+		if string(payload) != want {
+			t.Errorf("payloads do not match. got %s; want %s", payload, want)
+		}
+	})
+	t.Run("Handshake uses speculative reply", func(t *testing.T) {
+		resps := make(chan []byte, 2)
+		writeReplies(resps,
+			bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+				bsoncore.AppendBooleanElement(nil, "ismaster", true),
+				bsoncore.AppendInt32Element(nil, "maxWireVersion", 9),
+				bsoncore.AppendDocumentElement(nil, "speculativeAuthenticate", bsoncore.BuildDocumentFromElements(nil,
+					bsoncore.AppendInt32Element(nil, "conversationId", 1),
+					bsoncore.AppendBinaryElement(nil, "payload", 0x00, []byte(scramServerFirst)),
+					bsoncore.AppendBooleanElement(nil, "done", false),
+				)),
+			),
+			bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+				bsoncore.AppendInt32Element(nil, "conversationId", 1),
+				bsoncore.AppendBinaryElement(nil, "payload", 0x00, []byte(scramServerFinal)),
+				bsoncore.AppendBooleanElement(nil, "done", true),
+			),
+		)
+		c := &drivertest.ChannelConn{
+			Written:  make(chan []byte, 3),
+			ReadResp: resps,
+		}
+
+		handshaker := Handshaker(nil, &HandshakeOptions{Authenticator: newTestScramSHA256Authenticator(t)})
+		desc, err := handshaker.Handshake(context.Background(), address.Address("localhost:27017"), c)
+		noerr(t, err)
+		if desc.Kind != description.Standalone {
+			t.Errorf("server kinds do not match. got %v; want %v", desc.Kind, description.Standalone)
+		}
+
+		if len(c.Written) != 2 {
+			t.Fatalf("expected 2 messages to be sent but got %d", len(c.Written))
+		}
+		isMaster := readCommand(t, <-c.Written)
+		if _, err := isMaster.LookupErr("speculativeAuthenticate"); err != nil {
+			t.Errorf("expected isMaster to contain speculativeAuthenticate, but it didn't: %v", isMaster)
+		}
+		saslContinue := readCommand(t, <-c.Written)
+		if _, err := saslContinue.LookupErr("saslContinue"); err != nil {
+			t.Fatalf("expected a saslContinue command, got %v", saslContinue)
+		}
+		_, payload := saslContinue.Lookup("payload").Binary()
+		if string(payload) != scramClientFinal {
+			t.Errorf("payloads do not match. got %s; want %s", payload, scramClientFinal)
+		}
+	})
+	t.Run("Handshake falls back without speculative reply", func(t *testing.T) {
+		resps := make(chan []byte, 3)
+		writeReplies(resps,
+			bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+				bsoncore.AppendBooleanElement(nil, "ismaster", true),
+				bsoncore.AppendInt32Element(nil, "maxWireVersion", 7),
+			),
+			bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+				bsoncore.AppendInt32Element(nil, "conversationId", 1),
+				bsoncore.AppendBinaryElement(nil, "payload", 0x00, []byte(scramServerFirst)),
+				bsoncore.AppendBooleanElement(nil, "done", false),
+			),
+			bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+				bsoncore.AppendInt32Element(nil, "conversationId", 1),
+				bsoncore.AppendBinaryElement(nil, "payload", 0x00, []byte(scramServerFinal)),
+				bsoncore.AppendBooleanElement(nil, "done", true),
+			),
+		)
+		c := &drivertest.ChannelConn{
+			Written:  make(chan []byte, 4),
+			ReadResp: resps,
+		}
+
+		handshaker := Handshaker(nil, &HandshakeOptions{Authenticator: newTestScramSHA256Authenticator(t)})
+		_, err := handshaker.Handshake(context.Background(), address.Address("localhost:27017"), c)
+		noerr(t, err)
+
+		if len(c.Written) != 3 {
+			t.Fatalf("expected 3 messages to be sent but got %d", len(c.Written))
+		}
+		<-c.Written // isMaster
+		saslStart := readCommand(t, <-c.Written)
+		if _, err := saslStart.LookupErr("saslStart"); err != nil {
+			t.Errorf("expected a saslStart command, got %v", saslStart)
+		}
+	})
+}
+
+func writeReplies(c chan []byte, docs ...bsoncore.Document) {
+	for _, doc := range docs {
+		c <- drivertest.MakeReply(doc)
+	}
+}
+
+func noerr(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+// scramClientFinalMessage runs the client side of a SCRAM conversation against the RFC 7677 server
+// first message and returns the client final message, which contains the client proof.
+func scramClientFinalMessage(t testing.TB, client *scram.Client) string {
+	t.Helper()
+	conv := client.NewConversation()
+	if _, err := conv.Step(""); err != nil {
+		t.Fatalf("error starting SCRAM conversation: %v", err)
+	}
+	final, err := conv.Step(scramServerFirst)
+	if err != nil {
+		t.Fatalf("error computing SCRAM client final message: %v", err)
+	}
+	return final
+}
+
+func TestDefaultAuthenticatorReusesScramClient(t *testing.T) {
+	cred := &Cred{Source: "admin", Username: "user", Password: "pencil"}
+	a := &DefaultAuthenticator{Cred: cred}
+	first, err := a.scramAuthenticator(SCRAMSHA256, newScramSHA256Authenticator)
+	noerr(t, err)
+	second, err := a.scramAuthenticator(SCRAMSHA256, newScramSHA256Authenticator)
+	noerr(t, err)
+	if first.(*ScramAuthenticator).client != second.(*ScramAuthenticator).client {
+		t.Error("expected connections using the same authenticator to share a SCRAM client")
+	}
+	sha1, err := a.scramAuthenticator(SCRAMSHA1, newScramSHA1Authenticator)
+	noerr(t, err)
+	if sha1.(*ScramAuthenticator).mechanism != SCRAMSHA1 {
+		t.Errorf("expected a SCRAM-SHA-1 authenticator, got %s", sha1.(*ScramAuthenticator).mechanism)
+	}
+
+	other, err := (&DefaultAuthenticator{Cred: cred}).scramAuthenticator(SCRAMSHA256, newScramSHA256Authenticator)
+	noerr(t, err)
+	if other.(*ScramAuthenticator).client == first.(*ScramAuthenticator).client {
+		t.Error("expected authenticators of different clients not to share a SCRAM client")
+	}
+}
+
+func TestDefaultAuthenticatorCachedScramProof(t *testing.T) {
+	cred := &Cred{Source: "admin", Username: "user", Password: "pencil"}
+	a := &DefaultAuthenticator{Cred: cred}
+	actual, err := a.scramAuthenticator(SCRAMSHA256, newScramSHA256Authenticator)
+	noerr(t, err)
+	client := actual.(*ScramAuthenticator).client
+	client.WithNonceGenerator(func() string { return scramClientNonce })
+
+	// The first conversation derives the keys and the second uses the cached ones, so both must
+	// produce the client proof of the RFC 7677 test vector.
+	for i := 0; i < 2; i++ {
+		if final := scramClientFinalMessage(t, client); final != scramClientFinal {
+			t.Errorf("unexpected client final message in conversation %d. got %s; want %s", i, final, scramClientFinal)
+		}
+	}
+}
+
+func TestDefaultAuthenticatorConcurrentScram(t *testing.T) {
+	cred := &Cred{Source: "admin", Username: "user", Password: "pencil"}
+	a := &DefaultAuthenticator{Cred: cred}
+	first, err := a.scramAuthenticator(SCRAMSHA256, newScramSHA256Authenticator)
+	noerr(t, err)
+	first.(*ScramAuthenticator).client.WithNonceGenerator(func() string { return scramClientNonce })
+
+	// No keys have been derived yet, so the connections race to derive and cache them.
+	const connections = 8
+	var wg sync.WaitGroup
+	clients := make([]*scram.Client, connections)
+	finals := make([]string, connections)
+	errs := make([]error, connections)
+	for i := 0; i < connections; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			actual, err := a.scramAuthenticator(SCRAMSHA256, newScramSHA256Authenticator)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			clients[i] = actual.(*ScramAuthenticator).client
+			conv := clients[i].NewConversation()
+			if _, err = conv.Step(""); err != nil {
+				errs[i] = err
+				return
+			}
+			finals[i], errs[i] = conv.Step(scramServerFirst)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < connections; i++ {
+		noerr(t, errs[i])
+		if clients[i] != first.(*ScramAuthenticator).client {
+			t.Errorf("expected concurrent connections to share a SCRAM client")
+		}
+		if finals[i] != scramClientFinal {
+			t.Errorf("unexpected client final message in conversation %d. got %s; want %s", i, finals[i], scramClientFinal)
+		}
+	}
+	if len(a.scram) != 1 {
+		t.Errorf("expected a single cached authenticator. got %d", len(a.scram))
+	}
+}
+
+func TestDefaultAuthenticatorSpeculativeAuthentication(t *testing.T) {
+	newDefault := func(t *testing.T) *DefaultAuthenticator {
+		a := &DefaultAuthenticator{Cred: &Cred{Source: "admin", Username: "user", Password: "pencil"}}
+		actual, err := a.scramAuthenticator(SCRAMSHA256, newScramSHA256Authenticator)
+		noerr(t, err)
+		actual.(*ScramAuthenticator).client.WithNonceGenerator(func() string { return scramClientNonce })
+		return a
+	}
+
+	t.Run("Handshake uses speculative reply", func(t *testing.T) {
+		resps := make(chan []byte, 2)
+		writeReplies(resps,
+			bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+				bsoncore.AppendBooleanElement(nil, "ismaster", true),
+				bsoncore.AppendInt32Element(nil, "maxWireVersion", 9),
+				bsoncore.AppendDocumentElement(nil, "speculativeAuthenticate", bsoncore.BuildDocumentFromElements(nil,
+					bsoncore.AppendInt32Element(nil, "conversationId", 1),
+					bsoncore.AppendBinaryElement(nil, "payload", 0x00, []byte(scramServerFirst)),
+					bsoncore.AppendBooleanElement(nil, "done", false),
+				)),
+			),
+			bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+				bsoncore.AppendInt32Element(nil, "conversationId", 1),
+				bsoncore.AppendBinaryElement(nil, "payload", 0x00, []byte(scramServerFinal)),
+				bsoncore.AppendBooleanElement(nil, "done", true),
+			),
+		)
+		c := &drivertest.ChannelConn{
+			Written:  make(chan []byte, 3),
+			ReadResp: resps,
+		}
+
+		handshaker := Handshaker(nil, &HandshakeOptions{Authenticator: newDefault(t), DBUser: "admin.user"})
+		_, err := handshaker.Handshake(context.Background(), address.Address("localhost:27017"), c)
+		noerr(t, err)
+
+		if len(c.Written) != 2 {
+			t.Fatalf("expected 2 messages to be sent but got %d", len(c.Written))
+		}
+		isMaster := readCommand(t, <-c.Written)
+		if mech, _ := isMaster.Lookup("speculativeAuthenticate", "mechanism").StringValueOK(); mech != SCRAMSHA256 {
+			t.Errorf("expected isMaster to speculatively authenticate with %s, got %v", SCRAMSHA256, isMaster)
+		}
+		saslContinue := readCommand(t, <-c.Written)
+		if _, err := saslContinue.LookupErr("saslContinue"); err != nil {
+			t.Fatalf("expected a saslContinue command, got %v", saslContinue)
+		}
+		_, payload := saslContinue.Lookup("payload").Binary()
+		if string(payload) != scramClientFinal {
+			t.Errorf("payloads do not match. got %s; want %s", payload, scramClientFinal)
+		}
+	})
+	t.Run("Handshake negotiates the mechanism without speculative reply", func(t *testing.T) {
+		resps := make(chan []byte, 2)
+		writeReplies(resps,
+			bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+				bsoncore.AppendBooleanElement(nil, "ismaster", true),
+				bsoncore.AppendInt32Element(nil, "maxWireVersion", 9),
+				bsoncore.AppendArrayElement(nil, "saslSupportedMechs", bsoncore.BuildArray(nil,
+					bsoncore.Value{Type: bsontype.String, Data: bsoncore.AppendString(nil, SCRAMSHA1)},
+				)),
+			),
+			bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 0),
+				bsoncore.AppendStringElement(nil, "errmsg", "Authentication failed."),
+			),
+		)
+		c := &drivertest.ChannelConn{
+			Written:  make(chan []byte, 3),
+			ReadResp: resps,
+		}
+
+		handshaker := Handshaker(nil, &HandshakeOptions{Authenticator: newDefault(t), DBUser: "admin.user"})
+		_, err := handshaker.Handshake(context.Background(), address.Address("localhost:27017"), c)
+		if err == nil {
+			t.Fatalf("expected the failed saslStart to be returned")
+		}
+
+		if len(c.Written) != 2 {
+			t.Fatalf("expected 2 messages to be sent but got %d", len(c.Written))
+		}
+		<-c.Written // isMaster
+		saslStart := readCommand(t, <-c.Written)
+		if mech, _ := saslStart.Lookup("mechanism").StringValueOK(); mech != SCRAMSHA1 {
+			t.Errorf("expected a %s saslStart command, got %v", SCRAMSHA1, saslStart)
+		}
+	})
+}
+
+func BenchmarkScramAuth(b *testing.B) {
+	newClient := func() *scram.Client {
+		client, err := scram.SHA256.NewClientUnprepped("user", "pencil", "")
+		if err != nil {
+			b.Fatalf("error initializing SCRAM-SHA-256 client: %v", err)
+		}
+		return client.WithNonceGenerator(func() string { return scramClientNonce })
+	}
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			scramClientFinalMessage(b, newClient())
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		client := newClient()
+		scramClientFinalMessage(b, client)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			scramClientFinalMessage(b, client)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/lakshay2395/mongo-go-driver/bson"
 	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
@@ -14,6 +15,10 @@ import (
 	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
 )
 
+// ErrCursorTimeLimitExceeded is returned from Err when the cumulative time limit set with
+// SetCumulativeTimeout has been spent by earlier getMores.
+var ErrCursorTimeLimitExceeded = errors.New("cursor cumulative time limit exceeded")
+
 // BatchCursor is a batch implementation of a cursor. It returns documents in entire batches instead
 // of one at a time. An individual document cursor can be built on top of this batch cursor.
 type BatchCursor struct {
@@ -28,6 +33,13 @@ type BatchCursor struct {
 	firstBatch           bool
 	batchNumber          int
 	postBatchResumeToken bsoncore.Document
+	collation            bsoncore.Document
+	comment              *string
+
+	// cumulativeTimeout is the total time all getMores may take. It is disabled when zero.
+	cumulativeTimeout time.Duration
+	elapsed           time.Duration
+	now               func() time.Time
 
 	// legacy server (< 3.2) fields
 	batchSize   int32
@@ -162,6 +174,28 @@ func (bc *BatchCursor) Next(ctx context.Context) bool {
 		return false
 	}
 
+	if bc.cumulativeTimeout > 0 {
+		remaining := bc.cumulativeTimeout - bc.elapsed
+		if remaining <= 0 {
+			bc.clearBatch()
+			bc.err = ErrCursorTimeLimitExceeded
+			return false
+		}
+
+		parent := ctx
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, remaining)
+		defer cancel()
+
+		start := bc.now()
+		defer func() {
+			bc.elapsed += bc.now().Sub(start)
+			if ctx.Err() == context.DeadlineExceeded && parent.Err() == nil {
+				bc.err = ErrCursorTimeLimitExceeded
+			}
+		}()
+	}
+
 	if bc.legacy() {
 		bc.legacyGetMore(ctx)
 	} else {
@@ -178,6 +212,16 @@ func (bc *BatchCursor) Next(ctx context.Context) bool {
 	}
 }
 
+// SetCumulativeTimeout limits the total time spent running getMores for this cursor to d. The time
+// left is used as the deadline for each getMore, and once it has been spent Next returns false and
+// Err returns ErrCursorTimeLimitExceeded. A d of zero removes the limit.
+func (bc *BatchCursor) SetCumulativeTimeout(d time.Duration) {
+	bc.cumulativeTimeout = d
+	if bc.now == nil {
+		bc.now = time.Now
+	}
+}
+
 // Batch will return a DocumentSequence for the current batch of documents. The returned
 // DocumentSequence is only valid until the next call to Next or Close.
 func (bc *BatchCursor) Batch() *bsoncore.DocumentSequence { return bc.currentBatch }
@@ -209,9 +253,10 @@ func (bc *BatchCursor) Close(ctx context.Context) error {
 	}
 
 	_, err = (&command.KillCursors{
-		Clock: bc.clock,
-		NS:    bc.namespace,
-		IDs:   []int64{bc.id},
+		Clock:   bc.clock,
+		NS:      bc.namespace,
+		IDs:     []int64{bc.id},
+		Comment: bc.comment,
 	}).RoundTrip(ctx, bc.server.SelectedDescription(), conn)
 	if err != nil {
 		_ = conn.Close() // The command response error is more important here
@@ -226,6 +271,13 @@ func (bc *BatchCursor) Close(ctx context.Context) error {
 	return conn.Close()
 }
 
+// Collation returns the collation of the command that created this cursor, or nil if it had none.
+// The collation is not sent with getMore commands because the server applies the cursor's collation
+// to every batch.
+func (bc *BatchCursor) Collation() bsoncore.Document {
+	return bc.collation
+}
+
 // PostBatchResumeToken returns the latest seen post batch resume token.
 func (bc *BatchCursor) PostBatchResumeToken() bsoncore.Document {
 	return bc.postBatchResumeToken
@@ -241,6 +293,19 @@ func (bc *BatchCursor) clearBatch() {
 	bc.currentBatch.Data = bc.currentBatch.Data[:0]
 }
 
+// getMoreCommand returns the getMore command for the next batch. The comment of the command that
+// created the cursor is sent with it so every batch can be traced back to that command.
+func (bc *BatchCursor) getMoreCommand() *command.GetMore {
+	return &command.GetMore{
+		Clock:   bc.clock,
+		ID:      bc.id,
+		NS:      bc.namespace,
+		Opts:    bc.opts,
+		Session: bc.clientSession,
+		Comment: bc.comment,
+	}
+}
+
 func (bc *BatchCursor) getMore(ctx context.Context) {
 	bc.clearBatch()
 	if bc.id == 0 {
@@ -253,13 +318,7 @@ func (bc *BatchCursor) getMore(ctx context.Context) {
 		return
 	}
 
-	response, err := (&command.GetMore{
-		Clock:   bc.clock,
-		ID:      bc.id,
-		NS:      bc.namespace,
-		Opts:    bc.opts,
-		Session: bc.clientSession,
-	}).RoundTrip(ctx, bc.server.SelectedDescription(), conn)
+	response, err := bc.getMoreCommand().RoundTrip(ctx, bc.server.SelectedDescription(), conn)
 	if err != nil {
 		_ = conn.Close() // The command response error is more important here
 		bc.err = err
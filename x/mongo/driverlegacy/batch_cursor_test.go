@@ -2,6 +2,12 @@ package driverlegacy
 
 import (
 	"testing"
+	"time"
+
+	"github.com/lakshay2395/mongo-go-driver/mongo/options"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/topology"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
 )
 
 func TestBatchCursor(t *testing.T) {
@@ -24,4 +30,53 @@ func TestBatchCursor(t *testing.T) {
 			t.Errorf("Expect next to return false, but returned true")
 		}
 	})
+	t.Run("cumulative timeout", func(t *testing.T) {
+		// The server is never connected, so each getMore fails immediately. The fake clock charges
+		// every getMore 25ms, which spends the 50ms limit after two of them.
+		srvr, err := topology.NewServer(address.Address("localhost:27017"))
+		if err != nil {
+			t.Fatalf("Unexpected error creating server: %v", err)
+		}
+		bc := &BatchCursor{id: 1, server: srvr, currentBatch: new(bsoncore.DocumentSequence)}
+		bc.SetCumulativeTimeout(50 * time.Millisecond)
+		var clock time.Time
+		bc.now = func() time.Time {
+			clock = clock.Add(25 * time.Millisecond)
+			return clock
+		}
+
+		for i := 1; i <= 2; i++ {
+			if bc.Next(nil) {
+				t.Fatalf("Expected getMore %d to fail", i)
+			}
+			if bc.Err() == ErrCursorTimeLimitExceeded {
+				t.Fatalf("getMore %d should run within the time limit, but got %v", i, bc.Err())
+			}
+		}
+		if bc.Next(nil) {
+			t.Fatal("Expected the third getMore to fail")
+		}
+		if bc.Err() != ErrCursorTimeLimitExceeded {
+			t.Errorf("Did not get expected error. got %v; want %v", bc.Err(), ErrCursorTimeLimitExceeded)
+		}
+	})
+	t.Run("getMore has the comment of the command that created the cursor", func(t *testing.T) {
+		result := bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendDocumentElement(nil, "cursor", bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt64Element(nil, "id", 1),
+				bsoncore.AppendStringElement(nil, "ns", "foo.bar"),
+				bsoncore.AppendArrayElement(nil, "firstBatch", bsoncore.BuildArray(nil)),
+			)),
+		)
+		bc, err := NewBatchCursor(result, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error creating cursor: %v", err)
+		}
+		bc.comment = options.Find().SetComment("trace me").Comment
+
+		gm := bc.getMoreCommand()
+		if gm.Comment == nil || *gm.Comment != "trace me" {
+			t.Errorf("Expected getMore to have the cursor's comment. got %v; want %q", gm.Comment, "trace me")
+		}
+	})
 }
@@ -58,10 +58,7 @@ func BulkWrite(
 	registry *bsoncodec.Registry,
 	opts ...*options.BulkWriteOptions,
 ) (result.BulkWrite, error) {
-	if sess != nil && sess.PinnedServer != nil {
-		selector = sess.PinnedServer
-	}
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, sess, selector)
 	if err != nil {
 		return result.BulkWrite{}, err
 	}
@@ -72,8 +69,8 @@ func BulkWrite(
 	}
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if sess == nil && topo.SupportsSessions() {
-		sess, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if sess == nil {
+		sess, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return result.BulkWrite{}, err
 		}
@@ -243,9 +240,7 @@ func runInsert(
 	}
 
 	cmd.Opts = []bsonx.Elem{{"ordered", bsonx.Boolean(!continueOnError)}}
-	if bypassDocValidation != nil {
-		cmd.Opts = append(cmd.Opts, bsonx.Elem{"bypassDocumentValidation", bsonx.Boolean(*bypassDocValidation)})
-	}
+	cmd.Opts = appendBypassDocumentValidation(cmd.Opts, bypassDocValidation, ss.Description())
 
 	if !retrySupported(topo, ss.Description(), cmd.Session, cmd.WriteConcern) || !retryWrite || !batch.canRetry {
 		if cmd.Session != nil {
@@ -259,7 +254,7 @@ func runInsert(
 
 	res, origErr := insert(ctx, &cmd, ss, nil)
 	if shouldRetry(origErr, res.WriteConcernError) {
-		newServer, err := topo.SelectServerLegacy(ctx, selector)
+		newServer, err := selectServer(ctx, topo, cmd.Session, selector)
 		if err != nil || !retrySupported(topo, ss.Description(), cmd.Session, cmd.WriteConcern) {
 			return res, origErr
 		}
@@ -327,7 +322,7 @@ func runDelete(
 
 	res, origErr := delete(ctx, &cmd, ss, nil)
 	if shouldRetry(origErr, res.WriteConcernError) {
-		newServer, err := topo.SelectServerLegacy(ctx, selector)
+		newServer, err := selectServer(ctx, topo, cmd.Session, selector)
 		if err != nil || !retrySupported(topo, ss.Description(), cmd.Session, cmd.WriteConcern) {
 			return res, origErr
 		}
@@ -387,11 +382,7 @@ func runUpdate(
 	}
 
 	cmd.Opts = []bsonx.Elem{{"ordered", bsonx.Boolean(!continueOnError)}}
-	if bypassDocValidation != nil {
-		// TODO this is temporary!
-		cmd.Opts = append(cmd.Opts, bsonx.Elem{"bypassDocumentValidation", bsonx.Boolean(*bypassDocValidation)})
-		//cmd.Opts = []option.UpdateOptioner{option.OptBypassDocumentValidation(bypassDocValidation)}
-	}
+	cmd.Opts = appendBypassDocumentValidation(cmd.Opts, bypassDocValidation, ss.Description())
 
 	if !retrySupported(topo, ss.Description(), cmd.Session, cmd.WriteConcern) || !retryWrite || !batch.canRetry {
 		if cmd.Session != nil {
@@ -405,7 +396,7 @@ func runUpdate(
 
 	res, origErr := update(ctx, &cmd, ss, nil)
 	if shouldRetry(origErr, res.WriteConcernError) {
-		newServer, err := topo.SelectServerLegacy(ctx, selector)
+		newServer, err := selectServer(ctx, topo, cmd.Session, selector)
 		if err != nil || !retrySupported(topo, ss.Description(), cmd.Session, cmd.WriteConcern) {
 			return res, origErr
 		}
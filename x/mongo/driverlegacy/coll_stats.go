@@ -0,0 +1,58 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverlegacy
+
+import (
+	"context"
+
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/topology"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/uuid"
+	"github.com/lakshay2395/mongo-go-driver/x/network/command"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/result"
+)
+
+// CollStats handles the full cycle dispatch and execution of a collStats command against the provided
+// topology.
+func CollStats(
+	ctx context.Context,
+	cmd command.CollStats,
+	topo *topology.Topology,
+	selector description.ServerSelector,
+	clientID uuid.UUID,
+	pool *session.Pool,
+) (result.CollStats, error) {
+
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
+	if err != nil {
+		return result.CollStats{}, err
+	}
+
+	conn, err := ss.ConnectionLegacy(ctx)
+	if err != nil {
+		return result.CollStats{}, err
+	}
+	defer conn.Close()
+
+	rp, err := getReadPrefBasedOnTransaction(cmd.ReadPref, cmd.Session)
+	if err != nil {
+		return result.CollStats{}, err
+	}
+	cmd.ReadPref = rp
+
+	// If no explicit session and deployment supports sessions, start implicit session.
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
+		if err != nil {
+			return result.CollStats{}, err
+		}
+		defer cmd.Session.EndSession()
+	}
+
+	return cmd.RoundTrip(ctx, ss.Description(), conn)
+}
@@ -34,7 +34,7 @@ func Count(
 	opts ...*options.CountOptions,
 ) (int64, error) {
 
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return 0, err
 	}
@@ -53,8 +53,8 @@ func Count(
 	cmd.ReadPref = rp
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return 0, err
 		}
@@ -85,13 +85,27 @@ func Count(
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"collation", bsonx.Document(collDoc)})
 	}
 	if countOpts.Hint != nil {
-		hintElem, err := interfaceToElement("hint", countOpts.Hint, registry)
+		cmd.Opts, err = appendCountHint(cmd.Opts, countOpts.Hint, registry, desc)
 		if err != nil {
 			return 0, err
 		}
-
-		cmd.Opts = append(cmd.Opts, hintElem)
 	}
 
 	return cmd.RoundTrip(ctx, desc, conn)
 }
+
+// appendCountHint appends hint to opts. Servers older than 3.6 (wire version 6) only accept a hint
+// document for count, so a string hint other than "$natural", which is sent as a document, returns
+// ErrCountStringHint for them.
+func appendCountHint(opts []bsonx.Elem, hint interface{}, registry *bsoncodec.Registry, desc description.SelectedServer) ([]bsonx.Elem, error) {
+	if name, ok := hint.(string); ok && name != naturalHint && (desc.WireVersion == nil || desc.WireVersion.Max < 6) {
+		return opts, ErrCountStringHint
+	}
+
+	hintElem, err := hintToElement("hint", hint, registry)
+	if err != nil {
+		return opts, err
+	}
+
+	return append(opts, hintElem), nil
+}
@@ -33,10 +33,7 @@ func CountDocuments(
 	opts ...*options.CountOptions,
 ) (int64, error) {
 
-	if cmd.Session != nil && cmd.Session.PinnedServer != nil {
-		selector = cmd.Session.PinnedServer
-	}
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return 0, err
 	}
@@ -55,8 +52,8 @@ func CountDocuments(
 	cmd.ReadPref = rp
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), cmd.NoImplicitSession, pool, clientID)
 		if err != nil {
 			return 0, err
 		}
@@ -82,12 +79,10 @@ func CountDocuments(
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"collation", bsonx.Document(collDoc)})
 	}
 	if countOpts.Hint != nil {
-		hintElem, err := interfaceToElement("hint", countOpts.Hint, registry)
+		cmd.Opts, err = appendCountHint(cmd.Opts, countOpts.Hint, registry, desc)
 		if err != nil {
 			return 0, err
 		}
-
-		cmd.Opts = append(cmd.Opts, hintElem)
 	}
 
 	return cmd.RoundTrip(ctx, desc, conn)
@@ -0,0 +1,43 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverlegacy
+
+import (
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendCountHint(t *testing.T) {
+	selected := func(max int32) description.SelectedServer {
+		return description.SelectedServer{Server: description.Server{WireVersion: &description.VersionRange{Max: max}}}
+	}
+
+	t.Run("document hint", func(t *testing.T) {
+		opts, err := appendCountHint(nil, bsonx.Doc{{"a", bsonx.Int32(1)}}, bson.DefaultRegistry, selected(6))
+		require.NoError(t, err)
+		require.Equal(t, []bsonx.Elem{{"hint", bsonx.Document(bsonx.Doc{{"a", bsonx.Int32(1)}})}}, opts)
+	})
+	t.Run("string hint", func(t *testing.T) {
+		opts, err := appendCountHint(nil, "a_1", bson.DefaultRegistry, selected(6))
+		require.NoError(t, err)
+		require.Equal(t, []bsonx.Elem{{"hint", bsonx.String("a_1")}}, opts)
+	})
+	t.Run("string hint on old server", func(t *testing.T) {
+		opts, err := appendCountHint(nil, "a_1", bson.DefaultRegistry, selected(4))
+		require.Equal(t, ErrCountStringHint, err)
+		require.Empty(t, opts)
+	})
+	t.Run("$natural hint on old server", func(t *testing.T) {
+		opts, err := appendCountHint(nil, "$natural", bson.DefaultRegistry, selected(4))
+		require.NoError(t, err)
+		require.Equal(t, []bsonx.Elem{{"hint", bsonx.Document(bsonx.Doc{{"$natural", bsonx.Int32(1)}})}}, opts)
+	})
+}
@@ -33,17 +33,14 @@ func Delete(
 	retryWrite bool,
 	opts ...*options.DeleteOptions,
 ) (result.Delete, error) {
-	if cmd.Session != nil && cmd.Session.PinnedServer != nil {
-		selector = cmd.Session.PinnedServer
-	}
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return result.Delete{}, err
 	}
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() && writeconcern.AckWrite(cmd.WriteConcern) {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil && writeconcern.AckWrite(cmd.WriteConcern) {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return result.Delete{}, err
 		}
@@ -61,6 +58,10 @@ func Delete(
 		}
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"collation", bsonx.Document(collDoc)})
 	}
+	cmd.Opts, err = appendWriteHint(cmd.Opts, deleteOpts.Hint, cmd.WriteConcern, ss.Description(), 9)
+	if err != nil {
+		return result.Delete{}, err
+	}
 
 	// Execute in a single trip if retry writes not supported, or retry not enabled
 	if !retrySupported(topo, ss.Description(), cmd.Session, cmd.WriteConcern) || !retryWrite {
@@ -78,7 +79,7 @@ func Delete(
 	// Retry if appropriate
 	if cerr, ok := originalErr.(command.Error); (ok && cerr.Retryable()) ||
 		(res.WriteConcernError != nil && command.IsWriteConcernErrorRetryable(res.WriteConcernError)) {
-		ss, err := topo.SelectServerLegacy(ctx, selector)
+		ss, err := selectServer(ctx, topo, cmd.Session, selector)
 
 		// Return original error if server selection fails or new server does not support retryable writes
 		if err != nil || !retrySupported(topo, ss.Description(), cmd.Session, cmd.WriteConcern) {
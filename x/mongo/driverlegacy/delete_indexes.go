@@ -33,7 +33,7 @@ func DropIndexes(
 	opts ...*options.DropIndexesOptions,
 ) (bson.Raw, error) {
 
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return nil, err
 	}
@@ -50,8 +50,8 @@ func DropIndexes(
 	}
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return nil, err
 		}
@@ -7,12 +7,18 @@
 package driverlegacy // import "github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy"
 
 import (
+	"context"
 	"errors"
 
 	"github.com/lakshay2395/mongo-go-driver/bson"
 	"github.com/lakshay2395/mongo-go-driver/bson/bsoncodec"
+	"github.com/lakshay2395/mongo-go-driver/mongo/options"
+	"github.com/lakshay2395/mongo-go-driver/mongo/writeconcern"
 	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/topology"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/uuid"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
 )
 
 // ErrCollation is caused if a collation is given for an invalid server version.
@@ -21,6 +27,103 @@ var ErrCollation = errors.New("collation cannot be set for server versions < 3.4
 // ErrArrayFilters is caused if array filters are given for an invalid server version.
 var ErrArrayFilters = errors.New("array filters cannot be set for server versions < 3.6")
 
+// ErrCountStringHint is caused if a string hint is provided to a count for a server that does not support it.
+var ErrCountStringHint = errors.New("string hint cannot be used with count for server versions < 3.6")
+
+// ErrHint is caused if a hint is given to an update or delete for an invalid server version.
+var ErrHint = errors.New("hint cannot be set for server versions < 3.4")
+
+// ErrUnacknowledgedHint is caused if a hint is given to an unacknowledged update or delete for a
+// server that cannot validate it.
+var ErrUnacknowledgedHint = errors.New("hint cannot be set for unacknowledged writes on this server version")
+
+// selectServer selects the server that sess is pinned to, or a server matching selector if sess is
+// nil or not pinned. topology.ErrPinnedServerNotFound is returned if the pinned server has left the
+// topology.
+func selectServer(ctx context.Context, topo *topology.Topology, sess *session.Client, selector description.ServerSelector) (*topology.SelectedServer, error) {
+	var pinned *description.Server
+	if sess != nil {
+		pinned = sess.PinnedServer
+	}
+	return topo.SelectPinnedServerLegacy(ctx, pinned, selector)
+}
+
+// appendBypassDocumentValidation appends a bypassDocumentValidation element to opts if bypass is set
+// and the server supports it. Support was added in MongoDB 3.2 (wire version 4).
+func appendBypassDocumentValidation(opts []bsonx.Elem, bypass *bool, desc description.SelectedServer) []bsonx.Elem {
+	if bypass == nil || desc.WireVersion == nil || desc.WireVersion.Max < 4 {
+		return opts
+	}
+	return append(opts, bsonx.Elem{"bypassDocumentValidation", bsonx.Boolean(*bypass)})
+}
+
+// appendCollation appends a collation element to opts if collation is set. Collation was added in
+// MongoDB 3.4 (wire version 5), so ErrCollation is returned for older servers.
+func appendCollation(opts []bsonx.Elem, collation *options.Collation, desc description.SelectedServer) ([]bsonx.Elem, error) {
+	if collation == nil {
+		return opts, nil
+	}
+	if desc.WireVersion == nil || desc.WireVersion.Max < 5 {
+		return opts, ErrCollation
+	}
+	collDoc, err := bsonx.ReadDoc(collation.ToDocument())
+	if err != nil {
+		return opts, err
+	}
+	return append(opts, bsonx.Elem{"collation", bsonx.Document(collDoc)}), nil
+}
+
+// appendArrayFilters appends an arrayFilters element to opts if filters is set. Array filters were
+// added in MongoDB 3.6 (wire version 6), so ErrArrayFilters is returned for older servers.
+func appendArrayFilters(opts []bsonx.Elem, filters *options.ArrayFilters, desc description.SelectedServer) ([]bsonx.Elem, error) {
+	if filters == nil {
+		return opts, nil
+	}
+	if desc.WireVersion == nil || desc.WireVersion.Max < 6 {
+		return opts, ErrArrayFilters
+	}
+	raws, err := filters.ToArray()
+	if err != nil {
+		return opts, err
+	}
+	arr := make(bsonx.Arr, 0, len(raws))
+	for _, raw := range raws {
+		doc, err := bsonx.ReadDoc(raw)
+		if err != nil {
+			return opts, err
+		}
+		arr = append(arr, bsonx.Document(doc))
+	}
+	return append(opts, bsonx.Elem{"arrayFilters", bsonx.Array(arr)}), nil
+}
+
+// appendWriteHint appends a hint element to opts if hint is set. Update and delete statements accept
+// a hint on MongoDB 3.4 (wire version 5) and above. Because older servers cannot report an invalid
+// hint for an unacknowledged write, unacknowledged writes also require unackMinWire.
+func appendWriteHint(
+	opts []bsonx.Elem,
+	hint interface{},
+	wc *writeconcern.WriteConcern,
+	desc description.SelectedServer,
+	unackMinWire int32,
+) ([]bsonx.Elem, error) {
+	if hint == nil {
+		return opts, nil
+	}
+	if desc.WireVersion == nil || desc.WireVersion.Max < 5 {
+		return opts, ErrHint
+	}
+	if !writeconcern.AckWrite(wc) && desc.WireVersion.Max < unackMinWire {
+		return opts, ErrUnacknowledgedHint
+	}
+
+	hintElem, err := hintToElement("hint", hint, nil)
+	if err != nil {
+		return opts, err
+	}
+	return append(opts, hintElem), nil
+}
+
 func interfaceToDocument(val interface{}, registry *bsoncodec.Registry) (bsonx.Doc, error) {
 	if val == nil {
 		return bsonx.Doc{}, nil
@@ -60,6 +163,29 @@ func interfaceToElement(key string, i interface{}, registry *bsoncodec.Registry)
 	}
 }
 
+// naturalHint is the hint name that forces a collection scan in natural (insertion) order.
+const naturalHint = "$natural"
+
+// hintToElement converts hint into an element named key. Hints are either index names or index
+// specification documents, except for "$natural", which the server only accepts as the document
+// {$natural: 1}, so a "$natural" string hint is translated into that document.
+func hintToElement(key string, hint interface{}, registry *bsoncodec.Registry) (bsonx.Elem, error) {
+	if name, ok := hint.(string); ok && name == naturalHint {
+		return bsonx.Elem{key, bsonx.Document(bsonx.Doc{{naturalHint, bsonx.Int32(1)}})}, nil
+	}
+	return interfaceToElement(key, hint, registry)
+}
+
+// startImplicitSession starts an implicit session if the deployment supports sessions and the caller
+// has not opted out of implicit sessions. If no session is started, a nil *session.Client is
+// returned.
+func startImplicitSession(supportsSessions, optOut bool, pool *session.Pool, clientID uuid.UUID) (*session.Client, error) {
+	if !supportsSessions || optOut {
+		return nil, nil
+	}
+	return session.NewClientSession(pool, clientID, session.Implicit)
+}
+
 func closeImplicitSession(sess *session.Client) {
 	if sess != nil && sess.SessionType == session.Implicit {
 		sess.EndSession()
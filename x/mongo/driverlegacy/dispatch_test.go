@@ -0,0 +1,176 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverlegacy
+
+import (
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/mongo/options"
+	"github.com/lakshay2395/mongo-go-driver/mongo/writeconcern"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/uuid"
+	"github.com/lakshay2395/mongo-go-driver/x/network/command"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartImplicitSession(t *testing.T) {
+	clientID, err := uuid.New()
+	require.NoError(t, err)
+	desc := description.SelectedServer{
+		Server: description.Server{
+			WireVersion:           &description.VersionRange{Max: 6},
+			SessionTimeoutMinutes: 30,
+		},
+		Kind: description.ReplicaSetWithPrimary,
+	}
+
+	encode := func(t *testing.T, sess *session.Client) bson.Raw {
+		cmd := command.Read{DB: "foo", Command: bsonx.Doc{{"find", bsonx.String("bar")}}, Session: sess}
+		wm, err := cmd.Encode(desc)
+		require.NoError(t, err)
+		msg, ok := wm.(wiremessage.Msg)
+		require.True(t, ok, "expected an OP_MSG, got %T", wm)
+		body, ok := msg.Sections[0].(wiremessage.SectionBody)
+		require.True(t, ok, "expected a body section, got %T", msg.Sections[0])
+		return body.Document
+	}
+
+	t.Run("starts session by default", func(t *testing.T) {
+		sess, err := startImplicitSession(true, false, session.NewPool(nil), clientID)
+		require.NoError(t, err)
+		require.NotNil(t, sess)
+		defer sess.EndSession()
+		require.Equal(t, session.Implicit, sess.SessionType)
+
+		_, err = encode(t, sess).LookupErr("lsid")
+		require.NoError(t, err, "expected command to contain lsid")
+	})
+	t.Run("no session when opted out", func(t *testing.T) {
+		sess, err := startImplicitSession(true, true, session.NewPool(nil), clientID)
+		require.NoError(t, err)
+		require.Nil(t, sess)
+
+		_, err = encode(t, sess).LookupErr("lsid")
+		require.Error(t, err, "expected command to not contain lsid")
+	})
+	t.Run("no session when sessions are unsupported", func(t *testing.T) {
+		sess, err := startImplicitSession(false, false, session.NewPool(nil), clientID)
+		require.NoError(t, err)
+		require.Nil(t, sess)
+	})
+}
+
+func TestAppendBypassDocumentValidation(t *testing.T) {
+	bypass := true
+	selected := func(max int32) description.SelectedServer {
+		return description.SelectedServer{Server: description.Server{WireVersion: &description.VersionRange{Max: max}}}
+	}
+
+	t.Run("set", func(t *testing.T) {
+		opts := appendBypassDocumentValidation(nil, &bypass, selected(6))
+		require.Equal(t, []bsonx.Elem{{"bypassDocumentValidation", bsonx.Boolean(true)}}, opts)
+	})
+	t.Run("unset", func(t *testing.T) {
+		opts := appendBypassDocumentValidation(nil, nil, selected(6))
+		require.Empty(t, opts)
+	})
+	t.Run("unsupported wire version", func(t *testing.T) {
+		opts := appendBypassDocumentValidation(nil, &bypass, selected(3))
+		require.Empty(t, opts)
+	})
+	t.Run("minimum wire version above 4", func(t *testing.T) {
+		desc := selected(7)
+		desc.WireVersion.Min = 6
+		opts := appendBypassDocumentValidation(nil, &bypass, desc)
+		require.Len(t, opts, 1)
+	})
+}
+
+func TestAppendCollation(t *testing.T) {
+	collation := &options.Collation{Locale: "en_US"}
+	selected := func(max int32) description.SelectedServer {
+		return description.SelectedServer{Server: description.Server{WireVersion: &description.VersionRange{Max: max}}}
+	}
+
+	t.Run("unset", func(t *testing.T) {
+		opts, err := appendCollation(nil, nil, selected(4))
+		require.NoError(t, err)
+		require.Empty(t, opts)
+	})
+	t.Run("unsupported wire version", func(t *testing.T) {
+		_, err := appendCollation(nil, collation, selected(4))
+		require.Equal(t, ErrCollation, err)
+	})
+	t.Run("supported wire version", func(t *testing.T) {
+		opts, err := appendCollation(nil, collation, selected(5))
+		require.NoError(t, err)
+		require.Equal(t, []bsonx.Elem{{"collation", bsonx.Document(bsonx.Doc{{"locale", bsonx.String("en_US")}})}}, opts)
+	})
+}
+
+func TestAppendArrayFilters(t *testing.T) {
+	filters := &options.ArrayFilters{Filters: []interface{}{bsonx.Doc{{"x.a", bsonx.Int32(1)}}}}
+	selected := func(max int32) description.SelectedServer {
+		return description.SelectedServer{Server: description.Server{WireVersion: &description.VersionRange{Max: max}}}
+	}
+
+	t.Run("unset", func(t *testing.T) {
+		opts, err := appendArrayFilters(nil, nil, selected(5))
+		require.NoError(t, err)
+		require.Empty(t, opts)
+	})
+	t.Run("unsupported wire version", func(t *testing.T) {
+		_, err := appendArrayFilters(nil, filters, selected(5))
+		require.Equal(t, ErrArrayFilters, err)
+	})
+	t.Run("supported wire version", func(t *testing.T) {
+		opts, err := appendArrayFilters(nil, filters, selected(6))
+		require.NoError(t, err)
+		want := []bsonx.Elem{{"arrayFilters", bsonx.Array(bsonx.Arr{bsonx.Document(bsonx.Doc{{"x.a", bsonx.Int32(1)}})})}}
+		require.Equal(t, want, opts)
+	})
+}
+
+func TestAppendWriteHint(t *testing.T) {
+	selected := func(max int32) description.SelectedServer {
+		return description.SelectedServer{Server: description.Server{WireVersion: &description.VersionRange{Max: max}}}
+	}
+	unack := writeconcern.New(writeconcern.W(0))
+
+	t.Run("unset", func(t *testing.T) {
+		opts, err := appendWriteHint(nil, nil, unack, selected(4), 8)
+		require.NoError(t, err)
+		require.Empty(t, opts)
+	})
+	t.Run("hinted update on wire version 6", func(t *testing.T) {
+		opts, err := appendWriteHint(nil, "a_1", nil, selected(6), 8)
+		require.NoError(t, err)
+		require.Equal(t, []bsonx.Elem{{"hint", bsonx.String("a_1")}}, opts)
+	})
+	t.Run("hinted update on wire version 4", func(t *testing.T) {
+		_, err := appendWriteHint(nil, "a_1", nil, selected(4), 8)
+		require.Equal(t, ErrHint, err)
+	})
+	t.Run("document hint", func(t *testing.T) {
+		opts, err := appendWriteHint(nil, bsonx.Doc{{"a", bsonx.Int32(1)}}, nil, selected(6), 8)
+		require.NoError(t, err)
+		require.Equal(t, []bsonx.Elem{{"hint", bsonx.Document(bsonx.Doc{{"a", bsonx.Int32(1)}})}}, opts)
+	})
+	t.Run("unacknowledged on old server", func(t *testing.T) {
+		_, err := appendWriteHint(nil, "a_1", unack, selected(6), 8)
+		require.Equal(t, ErrUnacknowledgedHint, err)
+	})
+	t.Run("unacknowledged on new server", func(t *testing.T) {
+		opts, err := appendWriteHint(nil, "a_1", unack, selected(8), 8)
+		require.NoError(t, err)
+		require.Equal(t, []bsonx.Elem{{"hint", bsonx.String("a_1")}}, opts)
+	})
+}
@@ -32,10 +32,7 @@ func Distinct(
 	opts ...*options.DistinctOptions,
 ) (result.Distinct, error) {
 
-	if cmd.Session != nil && cmd.Session.PinnedServer != nil {
-		selector = cmd.Session.PinnedServer
-	}
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return result.Distinct{}, err
 	}
@@ -54,8 +51,8 @@ func Distinct(
 	cmd.ReadPref = rp
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return result.Distinct{}, err
 		}
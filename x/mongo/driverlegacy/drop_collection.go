@@ -28,7 +28,7 @@ func DropCollection(
 	pool *session.Pool,
 ) (bson.Raw, error) {
 
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return nil, err
 	}
@@ -40,8 +40,8 @@ func DropCollection(
 	defer conn.Close()
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return nil, err
 		}
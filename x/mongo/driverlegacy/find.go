@@ -14,6 +14,7 @@ import (
 	"errors"
 
 	"github.com/lakshay2395/mongo-go-driver/bson/bsoncodec"
+	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
 	"github.com/lakshay2395/mongo-go-driver/mongo/options"
 	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
 	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
@@ -27,6 +28,10 @@ import (
 	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
 )
 
+// ErrMixedProjection is returned by Find when projection validation is enabled and the projection
+// both includes and excludes fields. Only the _id field may be excluded in an inclusion projection.
+var ErrMixedProjection = errors.New("projection cannot mix inclusion and exclusion of fields other than _id")
+
 // Find handles the full cycle dispatch and execution of a find command against the provided
 // topology.
 func Find(
@@ -40,10 +45,7 @@ func Find(
 	opts ...*options.FindOptions,
 ) (*BatchCursor, error) {
 
-	if cmd.Session != nil && cmd.Session.PinnedServer != nil {
-		selector = cmd.Session.PinnedServer
-	}
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return nil, err
 	}
@@ -66,35 +68,22 @@ func Find(
 	cmd.ReadPref = rp
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	fo := options.MergeFindOptions(opts...)
+	fo.BatchSize = clampBatchSize(fo.BatchSize, desc)
 	if fo.AllowPartialResults != nil {
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"allowPartialResults", bsonx.Boolean(*fo.AllowPartialResults)})
 	}
-	if fo.BatchSize != nil {
-		elem := bsonx.Elem{"batchSize", bsonx.Int32(*fo.BatchSize)}
-		cmd.Opts = append(cmd.Opts, elem)
-		cmd.CursorOpts = append(cmd.CursorOpts, elem)
-
-		if fo.Limit != nil && *fo.BatchSize != 0 && *fo.Limit <= int64(*fo.BatchSize) {
-			cmd.Opts = append(cmd.Opts, bsonx.Elem{"singleBatch", bsonx.Boolean(true)})
-		}
-	}
-	if fo.Collation != nil {
-		if desc.WireVersion.Max < 5 {
-			return nil, ErrCollation
-		}
-		collDoc, err := bsonx.ReadDoc(fo.Collation.ToDocument())
-		if err != nil {
-			return nil, err
-		}
-		cmd.Opts = append(cmd.Opts, bsonx.Elem{"collation", bsonx.Document(collDoc)})
+	cmd.Opts, cmd.CursorOpts = appendBatchSize(cmd.Opts, cmd.CursorOpts, fo.BatchSize)
+	cmd.Opts, err = appendCollation(cmd.Opts, fo.Collation, desc)
+	if err != nil {
+		return nil, err
 	}
 	if fo.Comment != nil {
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"comment", bsonx.String(*fo.Comment)})
@@ -108,22 +97,14 @@ func Find(
 		}
 	}
 	if fo.Hint != nil {
-		hintElem, err := interfaceToElement("hint", fo.Hint, registry)
+		hintElem, err := hintToElement("hint", fo.Hint, registry)
 		if err != nil {
 			return nil, err
 		}
 
 		cmd.Opts = append(cmd.Opts, hintElem)
 	}
-	if fo.Limit != nil {
-		limit := *fo.Limit
-		if limit < 0 {
-			cmd.Opts = append(cmd.Opts, bsonx.Elem{"singleBatch", bsonx.Boolean(true)})
-			limit = -1 * limit
-		}
-
-		cmd.Opts = append(cmd.Opts, bsonx.Elem{"limit", bsonx.Int64(limit)})
-	}
+	cmd.Opts = appendFindLimit(cmd.Opts, fo.Limit, fo.BatchSize)
 	if fo.Max != nil {
 		maxElem, err := interfaceToElement("max", fo.Max, registry)
 		if err != nil {
@@ -132,10 +113,7 @@ func Find(
 
 		cmd.Opts = append(cmd.Opts, maxElem)
 	}
-	if fo.MaxAwaitTime != nil {
-		// Specified as maxTimeMS on the in the getMore command and not given in initial find command.
-		cmd.CursorOpts = append(cmd.CursorOpts, bsonx.Elem{"maxTimeMS", bsonx.Int64(int64(*fo.MaxAwaitTime / time.Millisecond))})
-	}
+	cmd.CursorOpts = appendMaxAwaitTime(cmd.CursorOpts, fo.MaxAwaitTime, fo.CursorType)
 	if fo.MaxTime != nil {
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"maxTimeMS", bsonx.Int64(int64(*fo.MaxTime / time.Millisecond))})
 	}
@@ -158,6 +136,11 @@ func Find(
 		if err != nil {
 			return nil, err
 		}
+		if fo.ValidateProjection != nil && *fo.ValidateProjection {
+			if err = validateProjection(projElem.Value); err != nil {
+				return nil, err
+			}
+		}
 
 		cmd.Opts = append(cmd.Opts, projElem)
 	}
@@ -188,7 +171,15 @@ func Find(
 		return nil, err
 	}
 
-	return NewBatchCursor(bsoncore.Document(res), cmd.Session, cmd.Clock, ss.Server, cmd.CursorOpts...)
+	bc, err := NewBatchCursor(bsoncore.Document(res), cmd.Session, cmd.Clock, ss.Server, cmd.CursorOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if fo.Collation != nil {
+		bc.collation = bsoncore.Document(fo.Collation.ToDocument())
+	}
+	bc.comment = fo.Comment
+	return bc, nil
 }
 
 // legacyFind handles the dispatch and execution of a find operation against a pre-3.2 server.
@@ -205,6 +196,7 @@ func legacyFind(
 	}
 
 	fo := options.MergeFindOptions(opts...)
+	fo.BatchSize = clampBatchSize(fo.BatchSize, ss.Description())
 	optsDoc, err := createLegacyOptionsDoc(fo, registry)
 	if err != nil {
 		return nil, err
@@ -214,6 +206,11 @@ func legacyFind(
 		if err != nil {
 			return nil, err
 		}
+		if fo.ValidateProjection != nil && *fo.ValidateProjection {
+			if err = validateProjection(bsonx.Document(projDoc)); err != nil {
+				return nil, err
+			}
+		}
 
 		projRaw, err := projDoc.MarshalBSON()
 		if err != nil {
@@ -306,7 +303,7 @@ func createLegacyOptionsDoc(fo *options.FindOptions, registry *bsoncodec.Registr
 		optsDoc = append(optsDoc, bsonx.Elem{"$comment", bsonx.String(*fo.Comment)})
 	}
 	if fo.Hint != nil {
-		hintElem, err := interfaceToElement("$hint", fo.Hint, registry)
+		hintElem, err := hintToElement("$hint", fo.Hint, registry)
 		if err != nil {
 			return nil, err
 		}
@@ -378,6 +375,44 @@ func calculateLegacyFlags(fo *options.FindOptions) wiremessage.QueryFlag {
 	return flags
 }
 
+// validateProjection returns ErrMixedProjection if the projection document both includes and
+// excludes fields. Fields other than _id with a numeric or boolean value are inclusions unless the
+// value is 0 or false; other values, such as $slice and $elemMatch documents, are not checked.
+func validateProjection(projection bsonx.Val) error {
+	doc, ok := projection.DocumentOK()
+	if !ok {
+		return nil
+	}
+
+	var include, exclude bool
+	for _, elem := range doc {
+		if elem.Key == "_id" {
+			continue
+		}
+
+		var included bool
+		switch elem.Value.Type() {
+		case bsontype.Int32:
+			included = elem.Value.Int32() != 0
+		case bsontype.Int64:
+			included = elem.Value.Int64() != 0
+		case bsontype.Double:
+			included = elem.Value.Double() != 0
+		case bsontype.Boolean:
+			included = elem.Value.Boolean()
+		default:
+			continue
+		}
+
+		include = include || included
+		exclude = exclude || !included
+		if include && exclude {
+			return ErrMixedProjection
+		}
+	}
+	return nil
+}
+
 // calculate the number to return for the first find query
 func calculateNumberToReturn(opts *options.FindOptions) int32 {
 	var numReturn int32
@@ -444,6 +479,80 @@ func readPrefNeeded(rp *readpref.ReadPref, kind description.ServerKind) bool {
 	return true
 }
 
+// defaultMaxMessageSize is the maxMessageSizeBytes assumed for servers that have not reported one.
+const defaultMaxMessageSize = 48000000
+
+// minDocumentSize is the size of the smallest possible BSON document, {}.
+const minDocumentSize = 5
+
+// clampBatchSize limits batchSize to the number of documents that could fit in a single reply. A
+// reply cannot be larger than the server's maxMessageSizeBytes and no document is smaller than
+// minDocumentSize, so a larger batch size can never be satisfied. The batch size is also used for
+// subsequent getMore commands.
+func clampBatchSize(batchSize *int32, desc description.SelectedServer) *int32 {
+	if batchSize == nil {
+		return nil
+	}
+	maxMessageSize := desc.MaxMessageSize
+	if maxMessageSize == 0 {
+		maxMessageSize = defaultMaxMessageSize
+	}
+	max := int32(maxMessageSize / minDocumentSize)
+	if *batchSize <= max {
+		return batchSize
+	}
+	return &max
+}
+
+// appendBatchSize appends a batchSize element to the options of the initial command and the cursor
+// options used for getMore commands. An explicit batch size of zero is distinct from an unset one:
+// it is sent on the initial command so that the server establishes the cursor without returning any
+// documents, but it is not used for getMore because the server requires a positive batch size there.
+func appendBatchSize(opts, cursorOpts []bsonx.Elem, batchSize *int32) ([]bsonx.Elem, []bsonx.Elem) {
+	if batchSize == nil {
+		return opts, cursorOpts
+	}
+	elem := bsonx.Elem{"batchSize", bsonx.Int32(*batchSize)}
+	opts = append(opts, elem)
+	if *batchSize > 0 {
+		cursorOpts = append(cursorOpts, elem)
+	}
+	return opts, cursorOpts
+}
+
+// appendFindLimit appends the limit and singleBatch elements of a find command to opts. A negative
+// limit is the OP_QUERY convention for returning a single batch, so it is sent as singleBatch:true
+// with the absolute value as the limit. singleBatch is also set when a positive limit fits within
+// the first batch.
+func appendFindLimit(opts []bsonx.Elem, limit *int64, batchSize *int32) []bsonx.Elem {
+	if limit == nil {
+		return opts
+	}
+
+	l := *limit
+	singleBatch := l < 0
+	if singleBatch {
+		l = -l
+	} else if l > 0 && batchSize != nil && *batchSize != 0 && l <= int64(*batchSize) {
+		singleBatch = true
+	}
+
+	if singleBatch {
+		opts = append(opts, bsonx.Elem{"singleBatch", bsonx.Boolean(true)})
+	}
+	return append(opts, bsonx.Elem{"limit", bsonx.Int64(l)})
+}
+
+// appendMaxAwaitTime appends a maxAwaitTimeMS element to the cursor options used for getMore
+// commands. The server only waits for new data on tailable awaitData cursors, so the option is
+// dropped for other cursor types. It is never sent on the initial find.
+func appendMaxAwaitTime(cursorOpts []bsonx.Elem, maxAwaitTime *time.Duration, cursorType *options.CursorType) []bsonx.Elem {
+	if maxAwaitTime == nil || cursorType == nil || *cursorType != options.TailableAwait {
+		return cursorOpts
+	}
+	return append(cursorOpts, bsonx.Elem{"maxAwaitTimeMS", bsonx.Int64(int64(*maxAwaitTime / time.Millisecond))})
+}
+
 func createReadPref(rp *readpref.ReadPref) bsonx.Doc {
 	if rp == nil {
 		return nil
@@ -457,6 +566,10 @@ func createReadPref(rp *readpref.ReadPref) bsonx.Doc {
 	case readpref.PrimaryPreferredMode:
 		doc = append(doc, bsonx.Elem{"mode", bsonx.String("primaryPreferred")})
 	case readpref.SecondaryPreferredMode:
+		if rp.StrictTags() {
+			doc = append(doc, bsonx.Elem{"mode", bsonx.String("secondary")})
+			break
+		}
 		doc = append(doc, bsonx.Elem{"mode", bsonx.String("secondaryPreferred")})
 	case readpref.SecondaryMode:
 		doc = append(doc, bsonx.Elem{"mode", bsonx.String("secondary")})
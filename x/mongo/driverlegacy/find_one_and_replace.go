@@ -37,17 +37,14 @@ func FindOneAndReplace(
 	opts ...*options.FindOneAndReplaceOptions,
 ) (result.FindAndModify, error) {
 
-	if cmd.Session != nil && cmd.Session.PinnedServer != nil {
-		selector = cmd.Session.PinnedServer
-	}
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return result.FindAndModify{}, err
 	}
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return result.FindAndModify{}, err
 		}
@@ -55,9 +52,7 @@ func FindOneAndReplace(
 	}
 
 	ro := options.MergeFindOneAndReplaceOptions(opts...)
-	if ro.BypassDocumentValidation != nil {
-		cmd.Opts = append(cmd.Opts, bsonx.Elem{"byapssDocumentValidation", bsonx.Boolean(*ro.BypassDocumentValidation)})
-	}
+	cmd.Opts = appendBypassDocumentValidation(cmd.Opts, ro.BypassDocumentValidation, ss.Description())
 	if ro.Collation != nil {
 		if ss.Description().WireVersion.Max < 5 {
 			return result.FindAndModify{}, ErrCollation
@@ -110,7 +105,7 @@ func FindOneAndReplace(
 	// Retry if appropriate
 	if cerr, ok := originalErr.(command.Error); (ok && cerr.Retryable()) ||
 		(res.WriteConcernError != nil && command.IsWriteConcernErrorRetryable(res.WriteConcernError)) {
-		ss, err := topo.SelectServerLegacy(ctx, selector)
+		ss, err := selectServer(ctx, topo, cmd.Session, selector)
 
 		// Return original error if server selection fails or new server does not support retryable writes
 		if err != nil || !retrySupported(topo, ss.Description(), cmd.Session, cmd.WriteConcern) {
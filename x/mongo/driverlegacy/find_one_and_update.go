@@ -37,17 +37,14 @@ func FindOneAndUpdate(
 	opts ...*options.FindOneAndUpdateOptions,
 ) (result.FindAndModify, error) {
 
-	if cmd.Session != nil && cmd.Session.PinnedServer != nil {
-		selector = cmd.Session.PinnedServer
-	}
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return result.FindAndModify{}, err
 	}
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return result.FindAndModify{}, err
 		}
@@ -55,25 +52,11 @@ func FindOneAndUpdate(
 	}
 
 	uo := options.MergeFindOneAndUpdateOptions(opts...)
-	if uo.ArrayFilters != nil {
-		filters, err := uo.ArrayFilters.ToArray()
-		if err != nil {
-			return result.FindAndModify{}, err
-		}
-
-		arr := make(bsonx.Arr, 0, len(filters))
-		for _, filter := range filters {
-			doc, err := bsonx.ReadDoc(filter)
-			if err != nil {
-				return result.FindAndModify{}, err
-			}
-			arr = append(arr, bsonx.Document(doc))
-		}
-		cmd.Opts = append(cmd.Opts, bsonx.Elem{"arrayFilters", bsonx.Array(arr)})
-	}
-	if uo.BypassDocumentValidation != nil {
-		cmd.Opts = append(cmd.Opts, bsonx.Elem{"bypassDocumentValidation", bsonx.Boolean(*uo.BypassDocumentValidation)})
+	cmd.Opts, err = appendArrayFilters(cmd.Opts, uo.ArrayFilters, ss.Description())
+	if err != nil {
+		return result.FindAndModify{}, err
 	}
+	cmd.Opts = appendBypassDocumentValidation(cmd.Opts, uo.BypassDocumentValidation, ss.Description())
 	if uo.Collation != nil {
 		if ss.Description().WireVersion.Max < 5 {
 			return result.FindAndModify{}, ErrCollation
@@ -126,7 +109,7 @@ func FindOneAndUpdate(
 	// Retry if appropriate
 	if cerr, ok := originalErr.(command.Error); (ok && cerr.Retryable()) ||
 		(res.WriteConcernError != nil && command.IsWriteConcernErrorRetryable(res.WriteConcernError)) {
-		ss, err := topo.SelectServerLegacy(ctx, selector)
+		ss, err := selectServer(ctx, topo, cmd.Session, selector)
 
 		// Return original error if server selection fails or new server does not support retryable writes
 		if err != nil || !retrySupported(topo, ss.Description(), cmd.Session, cmd.WriteConcern) {
@@ -0,0 +1,180 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverlegacy
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/mongo/options"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendFindLimit(t *testing.T) {
+	int64Ptr := func(i int64) *int64 { return &i }
+	int32Ptr := func(i int32) *int32 { return &i }
+
+	testCases := []struct {
+		name      string
+		limit     *int64
+		batchSize *int32
+		want      []bsonx.Elem
+	}{
+		{"unset", nil, nil, nil},
+		{"positive", int64Ptr(5), nil, []bsonx.Elem{{"limit", bsonx.Int64(5)}}},
+		{
+			"negative",
+			int64Ptr(-5), nil,
+			[]bsonx.Elem{{"singleBatch", bsonx.Boolean(true)}, {"limit", bsonx.Int64(5)}},
+		},
+		{
+			"negative with batch size",
+			int64Ptr(-5), int32Ptr(10),
+			[]bsonx.Elem{{"singleBatch", bsonx.Boolean(true)}, {"limit", bsonx.Int64(5)}},
+		},
+		{
+			"fits in first batch",
+			int64Ptr(5), int32Ptr(10),
+			[]bsonx.Elem{{"singleBatch", bsonx.Boolean(true)}, {"limit", bsonx.Int64(5)}},
+		},
+		{"larger than batch size", int64Ptr(20), int32Ptr(10), []bsonx.Elem{{"limit", bsonx.Int64(20)}}},
+		{"zero", int64Ptr(0), int32Ptr(10), []bsonx.Elem{{"limit", bsonx.Int64(0)}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, appendFindLimit(nil, tc.limit, tc.batchSize))
+		})
+	}
+}
+
+func TestAppendBatchSize(t *testing.T) {
+	int32Ptr := func(i int32) *int32 { return &i }
+
+	testCases := []struct {
+		name           string
+		batchSize      *int32
+		wantOpts       []bsonx.Elem
+		wantCursorOpts []bsonx.Elem
+	}{
+		{"unset", nil, nil, nil},
+		{"zero", int32Ptr(0), []bsonx.Elem{{"batchSize", bsonx.Int32(0)}}, nil},
+		{
+			"positive",
+			int32Ptr(10),
+			[]bsonx.Elem{{"batchSize", bsonx.Int32(10)}},
+			[]bsonx.Elem{{"batchSize", bsonx.Int32(10)}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, cursorOpts := appendBatchSize(nil, nil, tc.batchSize)
+			require.Equal(t, tc.wantOpts, opts)
+			require.Equal(t, tc.wantCursorOpts, cursorOpts)
+		})
+	}
+}
+
+func TestAppendMaxAwaitTime(t *testing.T) {
+	maxAwait := 500 * time.Millisecond
+	cursorType := func(ct options.CursorType) *options.CursorType { return &ct }
+
+	t.Run("awaitData cursor", func(t *testing.T) {
+		fo := options.Find().SetCursorType(options.TailableAwait).SetMaxAwaitTime(maxAwait)
+		got := appendMaxAwaitTime(nil, fo.MaxAwaitTime, fo.CursorType)
+		require.Equal(t, []bsonx.Elem{{"maxAwaitTimeMS", bsonx.Int64(500)}}, got)
+	})
+	t.Run("tailable cursor without awaitData", func(t *testing.T) {
+		require.Empty(t, appendMaxAwaitTime(nil, &maxAwait, cursorType(options.Tailable)))
+	})
+	t.Run("non-tailable cursor", func(t *testing.T) {
+		require.Empty(t, appendMaxAwaitTime(nil, &maxAwait, nil))
+		require.Empty(t, appendMaxAwaitTime(nil, &maxAwait, cursorType(options.NonTailable)))
+	})
+}
+
+func TestFindNaturalHint(t *testing.T) {
+	natural := bsonx.Document(bsonx.Doc{{"$natural", bsonx.Int32(1)}})
+	testCases := []struct {
+		name string
+		hint interface{}
+		want bsonx.Val
+	}{
+		{"$natural string", "$natural", natural},
+		{"$natural document", bson.D{{"$natural", 1}}, natural},
+		{"reverse $natural document", bson.D{{"$natural", -1}}, bsonx.Document(bsonx.Doc{{"$natural", bsonx.Int32(-1)}})},
+		{"index name", "a_1", bsonx.String("a_1")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("find command", func(t *testing.T) {
+				got, err := hintToElement("hint", tc.hint, bson.DefaultRegistry)
+				require.NoError(t, err)
+				require.Equal(t, bsonx.Elem{"hint", tc.want}, got)
+			})
+			t.Run("OP_QUERY", func(t *testing.T) {
+				got, err := createLegacyOptionsDoc(options.Find().SetHint(tc.hint), bson.DefaultRegistry)
+				require.NoError(t, err)
+				require.Equal(t, bsonx.Doc{{"$hint", tc.want}}, got)
+			})
+		})
+	}
+}
+
+func TestClampBatchSize(t *testing.T) {
+	selected := func(maxMessageSize uint32) description.SelectedServer {
+		return description.SelectedServer{Server: description.Server{MaxMessageSize: maxMessageSize}}
+	}
+
+	t.Run("unset", func(t *testing.T) {
+		require.Nil(t, clampBatchSize(nil, selected(1000)))
+	})
+	t.Run("within server limits", func(t *testing.T) {
+		fo := options.Find().SetBatchSize(100)
+		require.Equal(t, int32(100), *clampBatchSize(fo.BatchSize, selected(1000)))
+	})
+	t.Run("enormous batch size", func(t *testing.T) {
+		fo := options.Find().SetBatchSize(math.MaxInt32)
+		fo.BatchSize = clampBatchSize(fo.BatchSize, selected(1000))
+		require.Equal(t, int32(200), *fo.BatchSize)
+		require.Equal(t, int32(200), calculateNumberToReturn(fo))
+		require.Equal(t,
+			[]bsonx.Elem{{"singleBatch", bsonx.Boolean(true)}, {"limit", bsonx.Int64(150)}},
+			appendFindLimit(nil, options.Find().SetLimit(150).Limit, fo.BatchSize),
+		)
+	})
+	t.Run("server without maxMessageSizeBytes", func(t *testing.T) {
+		fo := options.Find().SetBatchSize(math.MaxInt32)
+		require.Equal(t, int32(defaultMaxMessageSize/minDocumentSize), *clampBatchSize(fo.BatchSize, selected(0)))
+	})
+}
+
+func TestValidateProjection(t *testing.T) {
+	testCases := []struct {
+		name       string
+		projection bsonx.Doc
+		want       error
+	}{
+		{"inclusion with _id excluded", bsonx.Doc{{"a", bsonx.Int32(1)}, {"_id", bsonx.Int32(0)}}, nil},
+		{"mixed", bsonx.Doc{{"a", bsonx.Int32(1)}, {"b", bsonx.Int32(0)}}, ErrMixedProjection},
+		{"mixed types", bsonx.Doc{{"a", bsonx.Boolean(true)}, {"b", bsonx.Double(0)}}, ErrMixedProjection},
+		{"exclusion", bsonx.Doc{{"a", bsonx.Int32(0)}, {"b", bsonx.Boolean(false)}}, nil},
+		{"operators", bsonx.Doc{{"a", bsonx.Int64(0)}, {"b", bsonx.Document(bsonx.Doc{{"$slice", bsonx.Int32(1)}})}}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, validateProjection(bsonx.Document(tc.projection)))
+		})
+	}
+}
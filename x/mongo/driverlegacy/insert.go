@@ -34,17 +34,14 @@ func Insert(
 	opts ...*options.InsertManyOptions,
 ) (result.Insert, error) {
 
-	if cmd.Session != nil && cmd.Session.PinnedServer != nil {
-		selector = cmd.Session.PinnedServer
-	}
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return result.Insert{}, err
 	}
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return result.Insert{}, err
 		}
@@ -53,9 +50,7 @@ func Insert(
 
 	insertOpts := options.MergeInsertManyOptions(opts...)
 
-	if insertOpts.BypassDocumentValidation != nil && ss.Description().WireVersion.Includes(4) {
-		cmd.Opts = append(cmd.Opts, bsonx.Elem{"bypassDocumentValidation", bsonx.Boolean(*insertOpts.BypassDocumentValidation)})
-	}
+	cmd.Opts = appendBypassDocumentValidation(cmd.Opts, insertOpts.BypassDocumentValidation, ss.Description())
 	if insertOpts.Ordered != nil {
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"ordered", bsonx.Boolean(*insertOpts.Ordered)})
 	}
@@ -77,7 +72,7 @@ func Insert(
 	// Retry if appropriate
 	if cerr, ok := originalErr.(command.Error); (ok && cerr.Retryable()) ||
 		(res.WriteConcernError != nil && command.IsWriteConcernErrorRetryable(res.WriteConcernError)) {
-		ss, err := topo.SelectServerLegacy(ctx, selector)
+		ss, err := selectServer(ctx, topo, cmd.Session, selector)
 
 		// Return original error if server selection fails or new server does not support retryable writes
 		if err != nil || !retrySupported(topo, ss.Description(), cmd.Session, cmd.WriteConcern) {
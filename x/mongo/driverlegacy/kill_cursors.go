@@ -17,14 +17,18 @@ import (
 	"github.com/lakshay2395/mongo-go-driver/x/network/result"
 )
 
-// KillCursors handles the full cycle dispatch and execution of an aggregate command against the provided
-// topology.
+// KillCursors handles the full cycle dispatch and execution of a killCursors command against the
+// provided server. All of the cursor ids, which must belong to ns, are killed with a single command.
 func KillCursors(
 	ctx context.Context,
 	ns command.Namespace,
 	server *topology.Server,
-	cursorID int64,
+	cursorIDs ...int64,
 ) (result.KillCursors, error) {
+	if len(cursorIDs) == 0 {
+		return result.KillCursors{}, nil
+	}
+
 	desc := server.SelectedDescription()
 	conn, err := server.ConnectionLegacy(ctx)
 	if err != nil {
@@ -33,21 +37,21 @@ func KillCursors(
 	defer conn.Close()
 
 	if desc.WireVersion.Max < 4 {
-		return result.KillCursors{}, legacyKillCursors(ctx, ns, cursorID, conn)
+		return result.KillCursors{}, legacyKillCursors(ctx, ns, cursorIDs, conn)
 	}
 
 	cmd := command.KillCursors{
 		NS:  ns,
-		IDs: []int64{cursorID},
+		IDs: cursorIDs,
 	}
 
 	return cmd.RoundTrip(ctx, desc, conn)
 }
 
-func legacyKillCursors(ctx context.Context, ns command.Namespace, cursorID int64, conn connection.Connection) error {
+func legacyKillCursors(ctx context.Context, ns command.Namespace, cursorIDs []int64, conn connection.Connection) error {
 	kc := wiremessage.KillCursors{
-		NumberOfCursorIDs: 1,
-		CursorIDs:         []int64{cursorID},
+		NumberOfCursorIDs: int32(len(cursorIDs)),
+		CursorIDs:         cursorIDs,
 		CollectionName:    ns.Collection,
 		DatabaseName:      ns.DB,
 	}
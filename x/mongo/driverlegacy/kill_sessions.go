@@ -0,0 +1,55 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverlegacy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/topology"
+	"github.com/lakshay2395/mongo-go-driver/x/network/command"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+// ErrKillSessionsNotSupported is returned by KillSessions if the deployment does not support sessions.
+var ErrKillSessionsNotSupported = errors.New("killing sessions requires a deployment that supports sessions")
+
+// killSessionsSelector selects the server that killSessions commands are sent to. Killing sessions
+// modifies server state, so the commands are routed like writes.
+var killSessionsSelector = description.WriteSelector()
+
+// KillSessions handles the full cycle dispatch and execution of a killSessions, killAllSessionsByPattern,
+// or killAllSessions command against the primary of the provided topology.
+func KillSessions(
+	ctx context.Context,
+	cmd command.KillSessions,
+	topo *topology.Topology,
+) (bson.Raw, error) {
+
+	if !topo.SupportsSessions() {
+		return nil, ErrKillSessionsNotSupported
+	}
+
+	ss, err := topo.SelectServerLegacy(ctx, killSessionsSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	desc := ss.Description()
+	if !description.SessionsSupported(desc.WireVersion) {
+		return nil, ErrKillSessionsNotSupported
+	}
+
+	conn, err := ss.ConnectionLegacy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return cmd.RoundTrip(ctx, desc, conn)
+}
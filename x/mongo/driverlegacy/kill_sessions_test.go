@@ -0,0 +1,28 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverlegacy
+
+import (
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKillSessionsSelector(t *testing.T) {
+	primary := description.Server{Addr: address.Address("localhost:27017"), Kind: description.RSPrimary}
+	secondary := description.Server{Addr: address.Address("localhost:27018"), Kind: description.RSSecondary}
+	topo := description.Topology{
+		Kind:    description.ReplicaSetWithPrimary,
+		Servers: []description.Server{secondary, primary},
+	}
+
+	selected, err := killSessionsSelector.SelectServer(topo, topo.Servers)
+	require.NoError(t, err)
+	require.Equal(t, []description.Server{primary}, selected)
+}
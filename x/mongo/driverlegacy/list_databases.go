@@ -31,7 +31,7 @@ func ListDatabases(
 	opts ...*options.ListDatabasesOptions,
 ) (result.ListDatabases, error) {
 
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return result.ListDatabases{}, err
 	}
@@ -43,8 +43,8 @@ func ListDatabases(
 	defer conn.Close()
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return result.ListDatabases{}, err
 		}
@@ -34,7 +34,7 @@ func ListIndexes(
 	opts ...*options.ListIndexesOptions,
 ) (*BatchCursor, error) {
 
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return nil, err
 	}
@@ -50,18 +50,14 @@ func ListIndexes(
 	}
 
 	lio := options.MergeListIndexesOptions(opts...)
-	if lio.BatchSize != nil {
-		elem := bsonx.Elem{"batchSize", bsonx.Int32(*lio.BatchSize)}
-		cmd.Opts = append(cmd.Opts, elem)
-		cmd.CursorOpts = append(cmd.CursorOpts, elem)
-	}
+	cmd.Opts, cmd.CursorOpts = appendBatchSize(cmd.Opts, cmd.CursorOpts, lio.BatchSize)
 	if lio.MaxTime != nil {
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"maxTimeMS", bsonx.Int64(int64(*lio.MaxTime / time.Millisecond))})
 	}
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return nil, err
 		}
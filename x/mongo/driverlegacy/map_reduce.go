@@ -0,0 +1,89 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverlegacy
+
+import (
+	"context"
+	"time"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/mongo/options"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/topology"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/uuid"
+	"github.com/lakshay2395/mongo-go-driver/x/network/command"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/result"
+)
+
+// MapReduce handles the full cycle dispatch and execution of a mapReduce command against the provided
+// topology. Inline output is treated as a read and uses readSelector, while output to a collection is
+// treated as a write and uses writeSelector.
+func MapReduce(
+	ctx context.Context,
+	cmd command.MapReduce,
+	topo *topology.Topology,
+	readSelector, writeSelector description.ServerSelector,
+	clientID uuid.UUID,
+	pool *session.Pool,
+	opts ...*options.MapReduceOptions,
+) (bson.Raw, error) {
+
+	selector := readSelector
+	if !cmd.Out.Inline() {
+		selector = writeSelector
+	}
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	desc := ss.Description()
+	conn, err := ss.ConnectionLegacy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rp, err := getReadPrefBasedOnTransaction(cmd.ReadPref, cmd.Session)
+	if err != nil {
+		return nil, err
+	}
+	cmd.ReadPref = rp
+
+	// If no explicit session and deployment supports sessions, start implicit session.
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
+		if err != nil {
+			return nil, err
+		}
+		defer cmd.Session.EndSession()
+	}
+
+	mrOpts := options.MergeMapReduceOptions(opts...)
+
+	if mrOpts.MaxTime != nil {
+		cmd.Opts = append(cmd.Opts, bsonx.Elem{
+			"maxTimeMS", bsonx.Int64(int64(*mrOpts.MaxTime / time.Millisecond)),
+		})
+	}
+	cmd.Opts, err = appendCollation(cmd.Opts, mrOpts.Collation, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := cmd.RoundTrip(ctx, desc, conn)
+	if err != nil {
+		if wce, ok := err.(result.WriteConcernError); ok {
+			ss.ProcessWriteConcernError(&wce)
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
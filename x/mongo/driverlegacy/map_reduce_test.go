@@ -0,0 +1,141 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverlegacy
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lakshay2395/mongo-go-driver/mongo/options"
+	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	wiremessagex "github.com/lakshay2395/mongo-go-driver/x/mongo/driver/wiremessage"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/topology"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/uuid"
+	"github.com/lakshay2395/mongo-go-driver/x/network/command"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapReduce(t *testing.T) {
+	clientID, err := uuid.New()
+	require.NoError(t, err)
+	cmd := command.MapReduce{
+		NS:     command.Namespace{DB: "foo", Collection: "bar"},
+		Map:    "function() { emit(this.a, 1) }",
+		Reduce: "function(k, vs) { return Array.sum(vs) }",
+	}
+	opts := options.MapReduce().SetCollation(&options.Collation{Locale: "en"}).SetMaxTime(time.Second)
+	selector := description.ReadPrefSelector(readpref.Primary())
+
+	t.Run("sends options", func(t *testing.T) {
+		cmds := make(chan bsoncore.Document, 1)
+		topo := newFakeTopology(t, 6, cmds)
+		defer func() { _ = topo.Disconnect(context.Background()) }()
+
+		_, err := MapReduce(context.Background(), cmd, topo, selector, selector, clientID, topo.SessionPool, opts)
+		require.NoError(t, err)
+
+		sent := <-cmds
+		require.Equal(t, "en", sent.Lookup("collation", "locale").StringValue())
+		require.Equal(t, int64(1000), sent.Lookup("maxTimeMS").Int64())
+		require.Equal(t, int32(1), sent.Lookup("out", "inline").Int32())
+	})
+	t.Run("collation on old server", func(t *testing.T) {
+		cmds := make(chan bsoncore.Document, 1)
+		topo := newFakeTopology(t, 4, cmds)
+		defer func() { _ = topo.Disconnect(context.Background()) }()
+
+		_, err := MapReduce(context.Background(), cmd, topo, selector, selector, clientID, topo.SessionPool, opts)
+		require.Equal(t, ErrCollation, err)
+		require.Len(t, cmds, 0, "no command should be sent")
+	})
+}
+
+// newFakeTopology returns a connected single server topology whose connections are served by a fake
+// standalone server with the given maximum wire version. Every OP_MSG command other than isMaster is
+// sent on cmds and answered with an empty successful reply.
+func newFakeTopology(t *testing.T, maxWireVersion int32, cmds chan<- bsoncore.Document) *topology.Topology {
+	isMaster := bsoncore.BuildDocumentFromElements(nil,
+		bsoncore.AppendBooleanElement(nil, "ismaster", true),
+		bsoncore.AppendInt32Element(nil, "maxWireVersion", maxWireVersion),
+		bsoncore.AppendDoubleElement(nil, "ok", 1),
+	)
+	d := topology.DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go serveFakeServer(server, isMaster, cmds)
+		return client, nil
+	})
+	topo, err := topology.New(
+		topology.WithMode(func(topology.MonitorMode) topology.MonitorMode { return topology.SingleMode }),
+		topology.WithServerOptions(func(opts ...topology.ServerOption) []topology.ServerOption {
+			return append(opts, topology.WithConnectionOptions(func(opts ...topology.ConnectionOption) []topology.ConnectionOption {
+				return append(opts, topology.WithDialer(func(topology.Dialer) topology.Dialer { return d }))
+			}))
+		}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, topo.Connect())
+	return topo
+}
+
+// serveFakeServer answers the wire messages read from nc until it is closed. OP_QUERY messages are
+// heartbeats and are answered with isMaster.
+func serveFakeServer(nc net.Conn, isMaster bsoncore.Document, cmds chan<- bsoncore.Document) {
+	defer nc.Close()
+	for {
+		header := make([]byte, 16)
+		if _, err := io.ReadFull(nc, header); err != nil {
+			return
+		}
+		length, requestID, _, opcode, _, _ := wiremessagex.ReadHeader(header)
+		body := make([]byte, length-16)
+		if _, err := io.ReadFull(nc, body); err != nil {
+			return
+		}
+
+		var wm []byte
+		switch opcode {
+		case wiremessage.OpQuery:
+			var idx int32
+			idx, wm = wiremessagex.AppendHeaderStart(nil, 0, requestID, wiremessage.OpReply)
+			wm = wiremessagex.AppendReplyFlags(wm, 0)
+			wm = wiremessagex.AppendReplyCursorID(wm, 0)
+			wm = wiremessagex.AppendReplyStartingFrom(wm, 0)
+			wm = wiremessagex.AppendReplyNumberReturned(wm, 1)
+			wm = append(wm, isMaster...)
+			wm = bsoncore.UpdateLength(wm, idx, int32(len(wm[idx:])))
+		case wiremessage.OpMsg:
+			_, rem, _ := wiremessagex.ReadMsgFlags(body)
+			_, rem, _ = wiremessagex.ReadMsgSectionType(rem)
+			cmd, _, _ := wiremessagex.ReadMsgSectionSingleDocument(rem)
+			reply := isMaster
+			if elem, err := cmd.IndexErr(0); err != nil || (elem.Key() != "isMaster" && elem.Key() != "ismaster") {
+				cmds <- cmd
+				reply = bsoncore.BuildDocumentFromElements(nil,
+					bsoncore.AppendArrayElement(nil, "results", bsoncore.BuildArray(nil)),
+					bsoncore.AppendDoubleElement(nil, "ok", 1),
+				)
+			}
+			var idx int32
+			idx, wm = wiremessagex.AppendHeaderStart(nil, 0, requestID, wiremessage.OpMsg)
+			wm = wiremessagex.AppendMsgFlags(wm, 0)
+			wm = wiremessagex.AppendMsgSectionType(wm, wiremessage.SingleDocument)
+			wm = append(wm, reply...)
+			wm = bsoncore.UpdateLength(wm, idx, int32(len(wm[idx:])))
+		default:
+			return
+		}
+		if _, err := nc.Write(wm); err != nil {
+			return
+		}
+	}
+}
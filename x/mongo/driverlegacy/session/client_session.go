@@ -124,9 +124,23 @@ func MaxClusterTime(ct1, ct2 bson.Raw) bson.Raw {
 		return ct2
 	}
 
+	// The times are equal, so keep whichever document is signed. Servers that validate gossiped
+	// cluster times reject a $clusterTime without its signature.
+	if !hasClusterTimeSignature(ct1) && hasClusterTimeSignature(ct2) {
+		return ct2
+	}
 	return ct1
 }
 
+// hasClusterTimeSignature returns true if clusterTime includes a $clusterTime.signature document.
+func hasClusterTimeSignature(clusterTime bson.Raw) bool {
+	if clusterTime == nil {
+		return false
+	}
+	_, err := clusterTime.LookupErr("$clusterTime", "signature")
+	return err == nil
+}
+
 // NewClientSession creates a Client.
 func NewClientSession(pool *Pool, clientID uuid.UUID, sessionType Type, opts ...*ClientOptions) (*Client, error) {
 	c := &Client{
@@ -210,7 +224,8 @@ func (c *Client) UpdateRecoveryToken(response bson.Raw) {
 		return
 	}
 
-	c.RecoveryToken = token.Document()
+	// Copy the token because the response may be backed by a buffer that is reused.
+	c.RecoveryToken = append(bson.Raw(nil), token.Document()...)
 }
 
 // ClearPinnedServer sets the PinnedServer to nil.
@@ -220,9 +235,10 @@ func (c *Client) ClearPinnedServer() {
 	}
 }
 
-// EndSession ends the session.
+// EndSession ends the session and returns its server session to the pool. It is safe to call on a
+// nil *Client and to call more than once.
 func (c *Client) EndSession() {
-	if c.Terminated {
+	if c == nil || c.Terminated {
 		return
 	}
 
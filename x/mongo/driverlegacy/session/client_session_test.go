@@ -50,6 +50,22 @@ func TestClientSession(t *testing.T) {
 		}
 	})
 
+	t.Run("TestMaxClusterTimeKeepsSignature", func(t *testing.T) {
+		signed := bsoncore.BuildDocument(nil, bsoncore.AppendDocumentElement(nil, "$clusterTime", bsoncore.BuildDocument(nil,
+			bsoncore.AppendTimestampElement(
+				bsoncore.AppendDocumentElement(nil, "signature", bsoncore.BuildDocument(nil, bsoncore.AppendInt64Element(nil, "keyId", 1))),
+				"clusterTime", 5, 5,
+			),
+		)))
+
+		if maxTime := MaxClusterTime(clusterTime2, signed); !bytes.Equal(maxTime, signed) {
+			t.Errorf("Equal cluster times should keep the signed document. got %v; want %v", maxTime, signed)
+		}
+		if maxTime := MaxClusterTime(signed, clusterTime2); !bytes.Equal(maxTime, signed) {
+			t.Errorf("Equal cluster times should keep the signed document. got %v; want %v", maxTime, signed)
+		}
+	})
+
 	t.Run("TestAdvanceClusterTime", func(t *testing.T) {
 		id, _ := uuid.New()
 		sess, err := NewClientSession(&Pool{}, id, Explicit, sessionOpts)
@@ -81,6 +97,22 @@ func TestClientSession(t *testing.T) {
 		require.NotNil(t, err, "Expected error, received nil")
 	})
 
+	t.Run("TestEndSessionNil", func(t *testing.T) {
+		var sess *Client
+		require.NotPanics(t, sess.EndSession)
+	})
+
+	t.Run("TestEndSessionTwice", func(t *testing.T) {
+		pool := NewPool(nil)
+		id, _ := uuid.New()
+		sess, err := NewClientSession(pool, id, Explicit, sessionOpts)
+		require.Nil(t, err, "Unexpected error")
+		require.Equal(t, 1, pool.CheckedOut())
+		sess.EndSession()
+		sess.EndSession()
+		require.Equal(t, 0, pool.CheckedOut(), "session should be returned to the pool exactly once")
+	})
+
 	t.Run("TestAdvanceOperationTime", func(t *testing.T) {
 		id, _ := uuid.New()
 		sess, err := NewClientSession(&Pool{}, id, Explicit, sessionOpts)
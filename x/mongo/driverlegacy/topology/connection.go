@@ -19,7 +19,10 @@ import (
 
 	"strings"
 
+	"github.com/lakshay2395/mongo-go-driver/event"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driver"
+	wiremessagex "github.com/lakshay2395/mongo-go-driver/x/mongo/driver/wiremessage"
 	"github.com/lakshay2395/mongo-go-driver/x/network/address"
 	"github.com/lakshay2395/mongo-go-driver/x/network/command"
 	connectionlegacy "github.com/lakshay2395/mongo-go-driver/x/network/connection"
@@ -27,6 +30,9 @@ import (
 	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
 )
 
+// maxMessageSize is the largest wire message a server sends.
+const maxMessageSize = 48000000
+
 var globalConnectionID uint64
 
 func nextConnectionID() uint64 { return atomic.AddUint64(&globalConnectionID, 1) }
@@ -38,16 +44,25 @@ type connection struct {
 	idleTimeout      time.Duration
 	idleDeadline     time.Time
 	lifetimeDeadline time.Time
+	now              func() time.Time
 	readTimeout      time.Duration
 	writeTimeout     time.Duration
 	desc             description.Server
+	compressor       wiremessage.CompressorID // The compressor negotiated during the handshake.
+	zliblevel        int
 
 	// pool related fields
-	pool       *pool
-	poolID     uint64
-	generation uint64
+	pool              *pool
+	poolID            uint64
+	generation        uint64
+	serviceGeneration uint64 // generation of desc.ServiceID when the connection was created
+	healthyGeneration uint64 // pool generation when a reply was last read, reset when the connection is returned to the pool
 }
 
+// setNoDelay sets TCP_NODELAY on a dialed TCP connection. It is a variable so that tests can observe
+// the value that is set.
+var setNoDelay = func(tc *net.TCPConn, noDelay bool) error { return tc.SetNoDelay(noDelay) }
+
 // newConnection handles the creation of a connection. It will dial, configure TLS, and perform
 // initialization handshakes.
 func newConnection(ctx context.Context, addr address.Address, opts ...ConnectionOption) (*connection, error) {
@@ -58,23 +73,29 @@ func newConnection(ctx context.Context, addr address.Address, opts ...Connection
 
 	nc, err := cfg.dialer.DialContext(ctx, addr.Network(), addr.String())
 	if err != nil {
-		return nil, ConnectionError{Wrapped: err, init: true}
+		return nil, ConnectionError{Addr: addr, Wrapped: err, init: true, message: "failed to dial"}
+	}
+
+	if tc, ok := nc.(*net.TCPConn); ok {
+		// Failing to set TCP_NODELAY only affects latency, so the connection is used regardless.
+		_ = setNoDelay(tc, cfg.tcpNoDelay)
 	}
 
 	if cfg.tlsConfig != nil {
 		tlsConfig := cfg.tlsConfig.Clone()
 		nc, err = configureTLS(ctx, nc, addr, tlsConfig)
 		if err != nil {
-			return nil, ConnectionError{Wrapped: err, init: true}
+			return nil, ConnectionError{Addr: addr, Wrapped: err, init: true, message: "failed to configure TLS"}
 		}
 	}
 
 	var lifetimeDeadline time.Time
 	if cfg.lifeTimeout > 0 {
-		lifetimeDeadline = time.Now().Add(cfg.lifeTimeout)
+		lifetimeDeadline = cfg.now().Add(cfg.lifeTimeout)
 	}
 
-	id := fmt.Sprintf("%s[-%d]", addr, nextConnectionID())
+	clientID := nextConnectionID()
+	id := fmt.Sprintf("%s[-%d]", addr, clientID)
 
 	c := &connection{
 		id:               id,
@@ -82,6 +103,7 @@ func newConnection(ctx context.Context, addr address.Address, opts ...Connection
 		addr:             addr,
 		idleTimeout:      cfg.idleTimeout,
 		lifetimeDeadline: lifetimeDeadline,
+		now:              cfg.now,
 		readTimeout:      cfg.readTimeout,
 		writeTimeout:     cfg.writeTimeout,
 	}
@@ -93,7 +115,18 @@ func newConnection(ctx context.Context, addr address.Address, opts ...Connection
 		c.desc, err = cfg.handshaker.Handshake(ctx, c.addr, initConnection{c})
 		if err != nil {
 			c.nc.Close()
-			return nil, ConnectionError{Wrapped: err, init: true}
+			return nil, ConnectionError{Addr: addr, Wrapped: err, init: true, message: "handshake failed"}
+		}
+		// Include the server's id for this connection so client and server logs can be correlated.
+		if c.desc.ServerConnectionID != 0 {
+			c.id = fmt.Sprintf("%s[-%d:%d]", addr, clientID, c.desc.ServerConnectionID)
+		}
+		if comp := negotiateCompressor(cfg.compressors, c.desc.Compression); comp != "" {
+			if err = validateCompressionLevel(comp, cfg.compLevel); err != nil {
+				c.nc.Close()
+				return nil, ConnectionError{Addr: addr, Wrapped: err, init: true, message: "invalid compression level"}
+			}
+			c.setCompressor(comp, cfg)
 		}
 		if cfg.descCallback != nil {
 			cfg.descCallback(c.desc)
@@ -179,6 +212,12 @@ func (c *connection) readWireMessage(ctx context.Context, dst []byte) ([]byte, e
 
 	// read the length as an int32
 	size := (int32(sizeBuf[0])) | (int32(sizeBuf[1]) << 8) | (int32(sizeBuf[2]) << 16) | (int32(sizeBuf[3]) << 24)
+	if size < 16 || size > maxMessageSize {
+		// The length includes the 16 byte header, so a smaller value means the stream is corrupt. A
+		// larger value than any server sends is rejected before allocating a buffer for it.
+		c.close()
+		return nil, ConnectionError{ConnectionID: c.id, message: fmt.Sprintf("malformed message length: %d", size)}
+	}
 
 	if int(size) > cap(dst) {
 		// Since we can't grow this slice without allocating, just allocate an entirely new slice.
@@ -197,6 +236,9 @@ func (c *connection) readWireMessage(ctx context.Context, dst []byte) ([]byte, e
 	}
 
 	c.bumpIdleDeadline()
+	if c.pool != nil {
+		c.healthyGeneration = atomic.LoadUint64(&c.pool.generation)
+	}
 	return dst, nil
 }
 
@@ -209,11 +251,56 @@ func (c *connection) close() error {
 		c.nc = nil
 		return err
 	}
-	return c.pool.close(c)
+	return c.pool.close(c, event.ReasonError)
+}
+
+// setCompressor configures the connection to compress wire messages with the named compressor.
+// Compressors that this connection cannot compress with leave wire messages uncompressed.
+func (c *connection) setCompressor(comp string, cfg *connectionConfig) {
+	switch comp {
+	case "snappy":
+		c.compressor = wiremessage.CompressorSnappy
+	case "zlib":
+		c.compressor = wiremessage.CompressorZLib
+		c.zliblevel = wiremessage.DefaultZlibLevel
+		switch {
+		case cfg.compLevel != nil:
+			c.zliblevel = *cfg.compLevel
+		case cfg.zlibLevel != nil:
+			c.zliblevel = *cfg.zlibLevel
+		}
+	}
+}
+
+// CompressWireMessage handles compressing the provided wire message using the underlying
+// compressor negotiated during the handshake. The original opcode and the uncompressed size of the
+// message are preserved in the OP_COMPRESSED header. If no compressor was negotiated, src is
+// appended to dst unchanged.
+func (c *connection) CompressWireMessage(src, dst []byte) ([]byte, error) {
+	if c.compressor == wiremessage.CompressorNoOp {
+		return append(dst, src...), nil
+	}
+	_, reqid, respto, origcode, rem, ok := wiremessagex.ReadHeader(src)
+	if !ok {
+		return dst, errors.New("wire message is too short to compress, less than 16 bytes")
+	}
+	compressed, err := driver.CompressPayload(rem, driver.CompressionOpts{Compressor: c.compressor, ZlibLevel: c.zliblevel})
+	if err != nil {
+		return dst, err
+	}
+	idx, dst := wiremessagex.AppendHeaderStart(dst, reqid, respto, wiremessage.OpCompressed)
+	dst = wiremessagex.AppendCompressedOriginalOpCode(dst, origcode)
+	dst = wiremessagex.AppendCompressedUncompressedSize(dst, int32(len(rem)))
+	dst = wiremessagex.AppendCompressedCompressorID(dst, c.compressor)
+	dst = wiremessagex.AppendCompressedCompressedMessage(dst, compressed)
+	return bsoncore.UpdateLength(dst, idx, int32(len(dst[idx:]))), nil
 }
 
+// expired returns true if the connection has been idle for longer than its idle timeout, has been
+// open for longer than its lifetime, or is closed. Connections past their lifetime are recycled even
+// when they are in constant use.
 func (c *connection) expired() bool {
-	now := time.Now()
+	now := c.currentTime()
 	if !c.idleDeadline.IsZero() && now.After(c.idleDeadline) {
 		return true
 	}
@@ -227,10 +314,18 @@ func (c *connection) expired() bool {
 
 func (c *connection) bumpIdleDeadline() {
 	if c.idleTimeout > 0 {
-		c.idleDeadline = time.Now().Add(c.idleTimeout)
+		c.idleDeadline = c.currentTime().Add(c.idleTimeout)
 	}
 }
 
+// currentTime returns the time from the connection's clock, which is only replaced in tests.
+func (c *connection) currentTime() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+	return c.now()
+}
+
 // initConnection is an adapter used during connection initialization. It has the minimum
 // functionality necessary to implement the driver.Connection interface, which is required to pass a
 // *connection to a Handshaker.
@@ -259,6 +354,7 @@ type Connection struct {
 }
 
 var _ driver.Connection = (*Connection)(nil)
+var _ driver.Compressor = (*Connection)(nil)
 
 // WriteWireMessage handles writing a wire message to the underlying connection.
 func (c *Connection) WriteWireMessage(ctx context.Context, wm []byte) error {
@@ -270,6 +366,17 @@ func (c *Connection) WriteWireMessage(ctx context.Context, wm []byte) error {
 	return c.writeWireMessage(ctx, wm)
 }
 
+// CompressWireMessage handles compressing the provided wire message using the compressor
+// negotiated with the server.
+func (c *Connection) CompressWireMessage(src, dst []byte) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.connection == nil {
+		return dst, ErrConnectionClosed
+	}
+	return c.connection.CompressWireMessage(src, dst)
+}
+
 // ReadWireMessage handles reading a wire message from the underlying connection. The dst parameter
 // will be overwritten with the new wire message.
 func (c *Connection) ReadWireMessage(ctx context.Context, dst []byte) ([]byte, error) {
@@ -341,6 +448,7 @@ type sconn struct {
 
 var notMasterCodes = []int32{10107, 13435}
 var recoveringCodes = []int32{11600, 11602, 13436, 189, 91}
+var shuttingDownCodes = []int32{11600, 91}
 
 func (sc *sconn) ReadWireMessage(ctx context.Context) (wiremessage.WireMessage, error) {
 	wm, err := sc.Connection.ReadWireMessage(ctx)
@@ -360,15 +468,21 @@ func (sc *sconn) WriteWireMessage(ctx context.Context, wm wiremessage.WireMessag
 }
 
 func (sc *sconn) processErr(err error) {
+	var c *connection
+	if cl, ok := sc.Connection.(*connectionLegacy); ok {
+		c = cl.connection
+	}
+	if c != nil && sc.s.pool.stale(c) {
+		return
+	}
+
 	// Invalidate server description if not master or node recovering error occurs
 	if cerr, ok := err.(command.Error); ok && (isRecoveringError(cerr) || isNotMasterError(cerr)) {
 		desc := sc.s.Description()
+		clearPool := isShuttingDownCode(cerr.Code) || !keepsConnectionsOnStepDown(desc)
 		desc.Kind = description.Unknown
 		desc.LastError = err
-		// updates description to unknown
-		sc.s.updateDescription(desc, false)
-		sc.s.RequestImmediateCheck()
-		sc.s.pool.drain()
+		sc.s.markUnknown(desc, c, clearPool)
 		return
 	}
 
@@ -387,8 +501,8 @@ func (sc *sconn) processErr(err error) {
 	desc := sc.s.Description()
 	desc.Kind = description.Unknown
 	desc.LastError = err
-	// updates description to unknown
-	sc.s.updateDescription(desc, false)
+	sc.s.setDescription(desc)
+	sc.s.clearPool(c, false)
 }
 
 func isRecoveringError(err command.Error) bool {
@@ -400,6 +514,15 @@ func isRecoveringError(err command.Error) bool {
 	return strings.Contains(err.Error(), "node is recovering")
 }
 
+func isShuttingDownCode(code int32) bool {
+	for _, c := range shuttingDownCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
 func isNotMasterError(err command.Error) bool {
 	for _, c := range notMasterCodes {
 		if c == err.Code {
@@ -50,7 +50,11 @@ func newConnectionLegacy(c *connection, s *Server, opts ...ConnectionOption) (*c
 			snappyComp := compressor.CreateSnappy()
 			compressorMap[snappyComp.CompressorID()] = snappyComp
 		case "zlib":
-			zlibComp, err := compressor.CreateZlib(cfg.zlibLevel)
+			level := cfg.zlibLevel
+			if cfg.compLevel != nil {
+				level = cfg.compLevel
+			}
+			zlibComp, err := compressor.CreateZlib(level)
 			if err != nil {
 				return nil, err
 			}
@@ -123,7 +127,7 @@ func (c *connectionLegacy) WriteWireMessage(ctx context.Context, wm wiremessage.
 
 	err = c.writeWireMessage(ctx, c.writeBuf)
 	if c.s != nil {
-		c.s.ProcessError(err)
+		c.s.processError(err, c.connection)
 	}
 	if err != nil {
 		// The error we got back was probably a ConnectionError already, so we don't really need to
@@ -145,7 +149,7 @@ func (c *connectionLegacy) ReadWireMessage(ctx context.Context) (wiremessage.Wir
 	var err error
 	c.readBuf, err = c.readWireMessage(ctx, c.readBuf)
 	if c.s != nil {
-		c.s.ProcessError(err)
+		c.s.processError(err, c.connection)
 	}
 	if err != nil {
 		// The error we got back was probably a ConnectionError already, so we don't really need to
@@ -223,7 +227,7 @@ func (c *connectionLegacy) ReadWireMessage(ctx context.Context) (wiremessage.Wir
 	}
 
 	if c.s != nil {
-		c.s.ProcessError(command.DecodeError(wm))
+		c.s.processError(command.DecodeError(wm), c.connection)
 	}
 
 	// TODO: do we care if monitoring fails?
@@ -3,6 +3,8 @@ package topology
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
 	"net"
 	"time"
 
@@ -52,7 +54,11 @@ type connectionConfig struct {
 	tlsConfig      *tls.Config
 	compressors    []string
 	zlibLevel      *int
+	compLevel      *int
 	descCallback   func(description.Server)
+	now            func() time.Time
+	tcpNoDelay     bool
+	tcpFastOpen    bool
 }
 
 func newConnectionConfig(opts ...ConnectionOption) (*connectionConfig, error) {
@@ -61,6 +67,8 @@ func newConnectionConfig(opts ...ConnectionOption) (*connectionConfig, error) {
 		dialer:         nil,
 		idleTimeout:    10 * time.Minute,
 		lifeTimeout:    30 * time.Minute,
+		now:            time.Now,
+		tcpNoDelay:     true,
 	}
 
 	for _, opt := range opts {
@@ -71,7 +79,11 @@ func newConnectionConfig(opts ...ConnectionOption) (*connectionConfig, error) {
 	}
 
 	if cfg.dialer == nil {
-		cfg.dialer = &net.Dialer{Timeout: cfg.connectTimeout}
+		d := &net.Dialer{Timeout: cfg.connectTimeout}
+		if cfg.tcpFastOpen {
+			enableTCPFastOpen(d)
+		}
+		cfg.dialer = d
 	}
 
 	return cfg, nil
@@ -84,14 +96,32 @@ func withServerDescriptionCallback(callback func(description.Server), opts ...Co
 	}))
 }
 
+// maxAppNameLength is the maximum length in bytes of the application name sent in the handshake.
+const maxAppNameLength = 128
+
+// ErrAppNameTooLong is returned when the configured application name is longer than 128 bytes.
+var ErrAppNameTooLong = errors.New("application name must not be longer than 128 bytes")
+
+func validateAppName(appName string) error {
+	if len(appName) > maxAppNameLength {
+		return ErrAppNameTooLong
+	}
+	return nil
+}
+
 // ConnectionOption is used to configure a connection.
 type ConnectionOption func(*connectionConfig) error
 
 // WithAppName sets the application name which gets sent to MongoDB when it
-// first connects.
+// first connects. The name is sent as client.application.name in the handshake,
+// and must not be longer than 128 bytes.
 func WithAppName(fn func(string) string) ConnectionOption {
 	return func(c *connectionConfig) error {
-		c.appName = fn(c.appName)
+		appName := fn(c.appName)
+		if err := validateAppName(appName); err != nil {
+			return err
+		}
+		c.appName = appName
 		return nil
 	}
 }
@@ -178,6 +208,25 @@ func WithMonitor(fn func(*event.CommandMonitor) *event.CommandMonitor) Connectio
 	}
 }
 
+// WithTCPNoDelay configures whether TCP_NODELAY is set on dialed TCP connections, which disables
+// Nagle's algorithm. It is enabled by default. Connections returned by a dialer that are not TCP
+// connections are left unchanged.
+func WithTCPNoDelay(fn func(bool) bool) ConnectionOption {
+	return func(c *connectionConfig) error {
+		c.tcpNoDelay = fn(c.tcpNoDelay)
+		return nil
+	}
+}
+
+// WithTCPFastOpen configures whether TCP Fast Open is requested when dialing. It only applies to the
+// default dialer on platforms that support it, and is ignored when WithDialer is used.
+func WithTCPFastOpen(fn func(bool) bool) ConnectionOption {
+	return func(c *connectionConfig) error {
+		c.tcpFastOpen = fn(c.tcpFastOpen)
+		return nil
+	}
+}
+
 // WithZlibLevel sets the zLib compression level.
 func WithZlibLevel(fn func(*int) *int) ConnectionOption {
 	return func(c *connectionConfig) error {
@@ -185,3 +234,51 @@ func WithZlibLevel(fn func(*int) *int) ConnectionOption {
 		return nil
 	}
 }
+
+// WithCompressionLevel sets the compression level used by the compressor negotiated with the
+// server. The level is validated against the negotiated compressor when a connection is
+// established: zlib accepts levels from -1 to 9 and zstd accepts levels from 1 to 22. If the level
+// is not set, the compressor's default level is used.
+func WithCompressionLevel(fn func(*int) *int) ConnectionOption {
+	return func(c *connectionConfig) error {
+		c.compLevel = fn(c.compLevel)
+		return nil
+	}
+}
+
+// validateCompressionLevel returns an error if level is outside of the range supported by the
+// named compressor. A nil level always selects the compressor's default and is valid, and the level
+// is ignored for compressors that do not support levels, such as snappy.
+func validateCompressionLevel(compressor string, level *int) error {
+	if level == nil {
+		return nil
+	}
+
+	var min, max int
+	switch compressor {
+	case "zlib":
+		min, max = -1, 9
+	case "zstd":
+		min, max = 1, 22
+	default:
+		return nil
+	}
+
+	if *level < min || *level > max {
+		return fmt.Errorf("invalid compression level %d for %s: must be between %d and %d", *level, compressor, min, max)
+	}
+	return nil
+}
+
+// negotiateCompressor returns the first of the client's compressors that is also supported by the
+// server, or an empty string if there is none.
+func negotiateCompressor(client, server []string) string {
+	for _, comp := range client {
+		for _, serverComp := range server {
+			if comp == serverComp {
+				return comp
+			}
+		}
+	}
+	return ""
+}
@@ -7,9 +7,13 @@
 package topology
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -17,9 +21,11 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driver"
+	wiremessagex "github.com/lakshay2395/mongo-go-driver/x/mongo/driver/wiremessage"
 	"github.com/lakshay2395/mongo-go-driver/x/network/address"
 	connectionlegacy "github.com/lakshay2395/mongo-go-driver/x/network/connection"
 	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/result"
 	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
 )
 
@@ -95,7 +101,7 @@ func TestConnection(t *testing.T) {
 			})
 			t.Run("dialer error", func(t *testing.T) {
 				err := errors.New("dialer error")
-				var want error = ConnectionError{Wrapped: err}
+				var want error = ConnectionError{Wrapped: err, message: "failed to dial"}
 				_, got := newConnection(context.Background(), address.Address(""), WithDialer(func(Dialer) Dialer {
 					return DialerFunc(func(context.Context, string, string) (net.Conn, error) { return nil, err })
 				}))
@@ -105,7 +111,7 @@ func TestConnection(t *testing.T) {
 			})
 			t.Run("handshaker error", func(t *testing.T) {
 				err := errors.New("handshaker error")
-				var want error = ConnectionError{Wrapped: err}
+				var want error = ConnectionError{Wrapped: err, message: "handshake failed"}
 				_, got := newConnection(context.Background(), address.Address(""),
 					WithHandshaker(func(Handshaker) Handshaker {
 						return HandshakerFunc(func(context.Context, address.Address, driver.Connection) (description.Server, error) {
@@ -144,6 +150,131 @@ func TestConnection(t *testing.T) {
 					t.Errorf("Server descriptions do not match. got %v; want %v", got, want)
 				}
 			})
+			t.Run("connection ID includes server connection ID", func(t *testing.T) {
+				addr := address.Address("localhost:27017")
+				conn, err := newConnection(context.Background(), addr,
+					WithHandshaker(func(Handshaker) Handshaker {
+						return HandshakerFunc(func(_ context.Context, addr address.Address, _ driver.Connection) (description.Server, error) {
+							return description.NewServer(addr, result.IsMaster{OK: 1, ConnectionID: 42}), nil
+						})
+					}),
+					WithDialer(func(Dialer) Dialer {
+						return DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+							return &net.TCPConn{}, nil
+						})
+					}),
+				)
+				noerr(t, err)
+				if !strings.HasPrefix(conn.id, "localhost:27017[-") || !strings.HasSuffix(conn.id, ":42]") {
+					t.Errorf("Expected connection ID to include the address and server connection ID. got %s", conn.id)
+				}
+			})
+			t.Run("dials bracketed IPv6 address", func(t *testing.T) {
+				l, err := net.Listen("tcp6", "[::1]:0")
+				if err != nil {
+					t.Skipf("IPv6 loopback is not available: %v", err)
+				}
+				defer l.Close()
+				accepted := make(chan struct{})
+				go func() {
+					defer close(accepted)
+					c, err := l.Accept()
+					if err == nil {
+						_ = c.Close()
+					}
+				}()
+
+				_, port, err := net.SplitHostPort(l.Addr().String())
+				noerr(t, err)
+				conn, err := newConnection(context.Background(), address.Address("[::1]:"+port))
+				noerr(t, err)
+				defer conn.close()
+				<-accepted
+				if got := conn.nc.RemoteAddr().String(); got != "[::1]:"+port {
+					t.Errorf("Connected to unexpected address. got %s; want %s", got, "[::1]:"+port)
+				}
+			})
+			t.Run("compression level", func(t *testing.T) {
+				testCases := []struct {
+					name        string
+					compressors []string
+					level       int
+					wantErr     bool
+				}{
+					{"zlib accepts 6", []string{"zlib"}, 6, false},
+					{"zlib accepts -1", []string{"zlib"}, -1, false},
+					{"zlib rejects 15", []string{"zlib"}, 15, true},
+					{"zstd accepts 15", []string{"zstd"}, 15, false},
+					{"zstd rejects 0", []string{"zstd"}, 0, true},
+					{"snappy ignores level", []string{"snappy"}, 15, false},
+					{"validated against negotiated compressor", []string{"snappy", "zlib"}, 15, false},
+				}
+				for _, tc := range testCases {
+					t.Run(tc.name, func(t *testing.T) {
+						_, err := newConnection(context.Background(), address.Address(""),
+							WithCompressors(func([]string) []string { return tc.compressors }),
+							WithCompressionLevel(func(*int) *int { return &tc.level }),
+							WithHandshaker(func(Handshaker) Handshaker {
+								return HandshakerFunc(func(context.Context, address.Address, driver.Connection) (description.Server, error) {
+									return description.Server{Compression: []string{"snappy", "zlib", "zstd"}}, nil
+								})
+							}),
+							WithDialer(func(Dialer) Dialer {
+								return DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+									return &net.TCPConn{}, nil
+								})
+							}),
+						)
+						if tc.wantErr {
+							if _, ok := err.(ConnectionError); !ok {
+								t.Errorf("Expected a ConnectionError for level %d, got %v", tc.level, err)
+							}
+							return
+						}
+						noerr(t, err)
+					})
+				}
+			})
+			t.Run("compresses with the negotiated compressor", func(t *testing.T) {
+				conn, err := newConnection(context.Background(), address.Address(""),
+					WithCompressors(func([]string) []string { return []string{"zstd", "zlib"} }),
+					WithHandshaker(func(Handshaker) Handshaker {
+						return HandshakerFunc(func(context.Context, address.Address, driver.Connection) (description.Server, error) {
+							return description.Server{Compression: []string{"zlib"}}, nil
+						})
+					}),
+					WithDialer(func(Dialer) Dialer {
+						return DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+							return &net.TCPConn{}, nil
+						})
+					}),
+				)
+				noerr(t, err)
+				if conn.compressor != wiremessage.CompressorZLib {
+					t.Fatalf("Expected zlib to be negotiated. got %v; want %v", conn.compressor, wiremessage.CompressorZLib)
+				}
+
+				src := wiremessagex.AppendHeader(nil, 16+64, 1, 0, wiremessage.OpMsg)
+				src = append(src, make([]byte, 64)...)
+				got, err := conn.CompressWireMessage(src, nil)
+				noerr(t, err)
+				_, reqid, _, opcode, rem, _ := wiremessagex.ReadHeader(got)
+				if opcode != wiremessage.OpCompressed || reqid != 1 {
+					t.Errorf("Unexpected header. got opcode %v, request ID %d; want %v, %d", opcode, reqid, wiremessage.OpCompressed, 1)
+				}
+				original, rem, _ := wiremessagex.ReadCompressedOriginalOpCode(rem)
+				size, rem, _ := wiremessagex.ReadCompressedUncompressedSize(rem)
+				id, rem, _ := wiremessagex.ReadCompressedCompressorID(rem)
+				if original != wiremessage.OpMsg || size != 64 || id != wiremessage.CompressorZLib {
+					t.Errorf("Unexpected OP_COMPRESSED fields. got %v, %d, %v; want %v, %d, %v",
+						original, size, id, wiremessage.OpMsg, 64, wiremessage.CompressorZLib)
+				}
+				payload, err := driver.DecompressPayload(rem, driver.CompressionOpts{Compressor: id, UncompressedSize: size})
+				noerr(t, err)
+				if !bytes.Equal(payload, src[16:]) {
+					t.Errorf("Decompressed payload does not match. got %v; want %v", payload, src[16:])
+				}
+			})
 		})
 		t.Run("writeWireMessage", func(t *testing.T) {
 			t.Run("closed connection", func(t *testing.T) {
@@ -313,7 +444,7 @@ func TestConnection(t *testing.T) {
 				}
 			})
 			t.Run("Read (success)", func(t *testing.T) {
-				want := []byte{0x0A, 0x00, 0x00, 0x00, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A}
+				want := []byte{0x12, 0x00, 0x00, 0x00, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10, 0x11, 0x12}
 				tnc := &testNetConn{buf: make([]byte, len(want))}
 				copy(tnc.buf, want)
 				conn := &connection{id: "foobar", nc: tnc}
@@ -323,6 +454,61 @@ func TestConnection(t *testing.T) {
 					t.Errorf("did not read full wire message. got %v; want %v", got, want)
 				}
 			})
+			t.Run("Read (short reads)", func(t *testing.T) {
+				want := []byte{0x12, 0x00, 0x00, 0x00, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10, 0x11, 0x12}
+				testCases := []struct {
+					name   string
+					chunks [][]byte
+				}{
+					{"two chunks", [][]byte{want[:6], want[6:]}},
+					{"split length", [][]byte{want[:2], want[2:7], want[7:]}},
+					{"byte at a time", func() [][]byte {
+						chunks := make([][]byte, len(want))
+						for i := range want {
+							chunks[i] = want[i : i+1]
+						}
+						return chunks
+					}()},
+				}
+				for _, tc := range testCases {
+					t.Run(tc.name, func(t *testing.T) {
+						cnc := &chunkedNetConn{chunks: tc.chunks}
+						conn := &connection{id: "foobar", nc: cnc}
+						got, err := conn.readWireMessage(context.Background(), nil)
+						noerr(t, err)
+						if !cmp.Equal(got, want) {
+							t.Errorf("did not reassemble wire message. got %v; want %v", got, want)
+						}
+						if cnc.closed {
+							t.Errorf("net.Conn should not be closed after a successful read.")
+						}
+					})
+				}
+			})
+			t.Run("Read (malformed length)", func(t *testing.T) {
+				testCases := []struct {
+					name string
+					size int32
+				}{
+					{"smaller than the length prefix", 3},
+					{"smaller than the header", 15},
+					{"larger than the maximum message size", maxMessageSize + 1},
+				}
+				for _, tc := range testCases {
+					t.Run(tc.name, func(t *testing.T) {
+						want := ConnectionError{ConnectionID: "foobar", message: fmt.Sprintf("malformed message length: %d", tc.size)}
+						tnc := &testNetConn{buf: []byte{byte(tc.size), byte(tc.size >> 8), byte(tc.size >> 16), byte(tc.size >> 24)}}
+						conn := &connection{id: "foobar", nc: tnc}
+						_, got := conn.readWireMessage(context.Background(), nil)
+						if !cmp.Equal(got, want, cmp.Comparer(compareErrors)) {
+							t.Errorf("errors do not match. got %v; want %v", got, want)
+						}
+						if !tnc.closed {
+							t.Errorf("failed to close net.Conn after a malformed message length.")
+						}
+					})
+				}
+			})
 		})
 	})
 	t.Run("Connection", func(t *testing.T) {
@@ -544,3 +730,94 @@ func (d *dialer) lenclosed() int {
 	defer d.Unlock()
 	return len(d.closed)
 }
+
+func TestConnectionTCPOptions(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	noerr(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			nc, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer nc.Close()
+		}
+	}()
+	addr := address.Address(l.Addr().String())
+
+	// recordNoDelay replaces the setNoDelay seam so that each value that is set is recorded. The
+	// returned function restores the original.
+	recordNoDelay := func() (*[]bool, func()) {
+		var set []bool
+		orig := setNoDelay
+		setNoDelay = func(tc *net.TCPConn, noDelay bool) error {
+			set = append(set, noDelay)
+			return orig(tc, noDelay)
+		}
+		return &set, func() { setNoDelay = orig }
+	}
+
+	t.Run("TCP_NODELAY is set by default", func(t *testing.T) {
+		set, restore := recordNoDelay()
+		defer restore()
+		c, err := newConnection(context.Background(), addr)
+		noerr(t, err)
+		defer c.close()
+		if !cmp.Equal(*set, []bool{true}) {
+			t.Errorf("Expected TCP_NODELAY to be enabled on the dialed connection. got %v", *set)
+		}
+	})
+	t.Run("WithTCPNoDelay disables TCP_NODELAY", func(t *testing.T) {
+		set, restore := recordNoDelay()
+		defer restore()
+		c, err := newConnection(context.Background(), addr, WithTCPNoDelay(func(bool) bool { return false }))
+		noerr(t, err)
+		defer c.close()
+		if !cmp.Equal(*set, []bool{false}) {
+			t.Errorf("Expected TCP_NODELAY to be disabled on the dialed connection. got %v", *set)
+		}
+	})
+	t.Run("non-TCP connections are left unchanged", func(t *testing.T) {
+		set, restore := recordNoDelay()
+		defer restore()
+		c, err := newConnection(context.Background(), addr, WithDialer(func(Dialer) Dialer {
+			return DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+				client, server := net.Pipe()
+				server.Close()
+				return client, nil
+			})
+		}))
+		noerr(t, err)
+		defer c.close()
+		if len(*set) != 0 {
+			t.Errorf("Expected TCP_NODELAY not to be set on a non-TCP connection. got %v", *set)
+		}
+	})
+	t.Run("WithTCPFastOpen", func(t *testing.T) {
+		c, err := newConnection(context.Background(), addr, WithTCPFastOpen(func(bool) bool { return true }))
+		noerr(t, err)
+		defer c.close()
+		if _, ok := c.nc.(*net.TCPConn); !ok {
+			t.Errorf("Expected a TCP connection. got %T", c.nc)
+		}
+	})
+}
+
+// chunkedNetConn is a testNetConn that delivers its data in the given chunks, one per Read, like a
+// socket that receives a wire message over several packets.
+type chunkedNetConn struct {
+	testNetConn
+	chunks [][]byte
+}
+
+func (cnc *chunkedNetConn) Read(b []byte) (int, error) {
+	if len(cnc.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(b, cnc.chunks[0])
+	if cnc.chunks[0] = cnc.chunks[0][n:]; len(cnc.chunks[0]) == 0 {
+		cnc.chunks = cnc.chunks[1:]
+	}
+	return n, nil
+}
@@ -1,12 +1,20 @@
 package topology
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+)
 
 // ConnectionError represents a connection error.
 type ConnectionError struct {
 	ConnectionID string
 	Wrapped      error
 
+	// Addr is the address of the server the connection was made to. It is set for errors that occur
+	// while establishing a connection, when there may not be a ConnectionID yet.
+	Addr address.Address
+
 	// init will be set to true if this error occured during connection initialization or
 	// during a connection handshake.
 	init    bool
@@ -15,8 +23,17 @@ type ConnectionError struct {
 
 // Error implements the error interface.
 func (e ConnectionError) Error() string {
+	id := e.ConnectionID
+	if id == "" {
+		id = e.Addr.String()
+	}
 	if e.Wrapped != nil {
-		return fmt.Sprintf("connection(%s) %s: %s", e.ConnectionID, e.message, e.Wrapped.Error())
+		return fmt.Sprintf("connection(%s) %s: %s", id, e.message, e.Wrapped.Error())
 	}
-	return fmt.Sprintf("connection(%s) %s", e.ConnectionID, e.message)
+	return fmt.Sprintf("connection(%s) %s", id, e.message)
+}
+
+// Unwrap returns the underlying error.
+func (e ConnectionError) Unwrap() error {
+	return e.Wrapped
 }
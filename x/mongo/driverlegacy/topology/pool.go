@@ -2,10 +2,13 @@ package topology
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/lakshay2395/mongo-go-driver/bson/primitive"
+	"github.com/lakshay2395/mongo-go-driver/event"
 	"github.com/lakshay2395/mongo-go-driver/x/network/address"
 )
 
@@ -22,6 +25,29 @@ var ErrConnectionClosed = ConnectionError{ConnectionID: "<closed>", message: "co
 // ErrWrongPool is return when a connection is returned to a pool it doesn't belong to.
 var ErrWrongPool = PoolError("connection does not belong to this pool")
 
+// defaultMaxConnecting is the default number of connections a pool will establish concurrently.
+const defaultMaxConnecting = 2
+
+// checkoutSamples is the number of recent checkout durations a pool keeps to compute PoolStats.
+const checkoutSamples = 256
+
+// CheckoutFunc is called each time a connection is successfully checked out of a server's pool with
+// the time spent getting the connection, including any wait and dial time, and whether an idle
+// connection was reused rather than a new one being dialed.
+type CheckoutFunc func(duration time.Duration, reused bool)
+
+// PoolStats is a point in time view of a server's connection pool. Opened counts every connection
+// the pool has established and not yet closed, which includes the Available idle connections and the
+// InUse checked out connections. CheckoutWaitP99 is the 99th percentile of the time spent getting a
+// connection over the most recent checkouts.
+type PoolStats struct {
+	Opened          int
+	Available       int
+	InUse           int
+	Generation      uint64
+	CheckoutWaitP99 time.Duration
+}
+
 // PoolError is an error returned from a Pool method.
 type PoolError string
 
@@ -33,10 +59,36 @@ type pool struct {
 	opts       []ConnectionOption
 	conns      chan *connection
 	generation uint64
+	connecting chan struct{} // connecting limits the number of connections being established at once.
+	checkoutFn CheckoutFunc
+	monitor    event.PoolMonitor
 
 	connected int32                  // Must be accessed using the sync/atomic package
 	opened    map[uint64]*connection // opened holds all of the currently open connections.
 
+	// invalidGeneration is the generation of the pool after it was last invalidated. Connections
+	// from earlier generations are never refreshed. It must be accessed using the sync/atomic package.
+	invalidGeneration uint64
+	// refreshConns enables refreshing the generation of connections that complete a round trip.
+	refreshConns bool
+	// minSize is the number of idle connections established in the background when the pool
+	// connects.
+	minSize uint64
+	// cancelWarm stops establishing the minSize connections, and warming is done once it has. Both
+	// are set when the pool connects and cancelWarm is guarded by the pool's mutex.
+	cancelWarm context.CancelFunc
+	warming    sync.WaitGroup
+
+	// serviceGenerations holds the generation of each service behind a load balancer, so that a
+	// clear for one service does not invalidate the connections to the others. It is guarded by the
+	// pool's mutex.
+	serviceGenerations map[primitive.ObjectID]uint64
+
+	// checkoutWaits holds the durations of the most recent checkouts, starting at nextWait once it
+	// is full. Both are guarded by the pool's mutex.
+	checkoutWaits []time.Duration
+	nextWait      int
+
 	sync.Mutex
 }
 
@@ -46,37 +98,212 @@ func newPool(addr address.Address, size uint64, opts ...ConnectionOption) *pool
 	return &pool{
 		address:    addr,
 		conns:      make(chan *connection, size),
+		connecting: make(chan struct{}, defaultMaxConnecting),
 		generation: 0,
 		connected:  disconnected,
 		opened:     make(map[uint64]*connection),
 		opts:       opts,
+
+		serviceGenerations: make(map[primitive.ObjectID]uint64),
 	}
 }
 
-// drain lazily drains the pool by increasing the generation ID.
-func (p *pool) drain()                         { atomic.AddUint64(&p.generation, 1) }
+// drain lazily drains the pool by increasing the generation ID. It is used for transient failures,
+// such as a network error on one connection, so connections that complete a round trip afterwards
+// may be refreshed.
+func (p *pool) drain() {
+	atomic.AddUint64(&p.generation, 1)
+	p.publish(p.monitor.PoolCleared, nil, "")
+}
+
+// invalidate lazily drains the pool like drain, but the drained connections can never be refreshed.
+// It is used when the server is known to have closed or abandoned its connections, such as when a
+// server steps down or shuts down.
+func (p *pool) invalidate() {
+	p.markInvalid(atomic.AddUint64(&p.generation, 1))
+	p.publish(p.monitor.PoolCleared, nil, "")
+}
+
+// markInvalid prevents connections from before generation from being refreshed.
+func (p *pool) markInvalid(generation uint64) {
+	for {
+		current := atomic.LoadUint64(&p.invalidGeneration)
+		if generation <= current || atomic.CompareAndSwapUint64(&p.invalidGeneration, current, generation) {
+			return
+		}
+	}
+}
+
+// refresh moves c to the current generation if the pool refreshes connections, c completed a round
+// trip since the pool was last drained, and the pool has not been invalidated since c's generation.
+// This keeps healthy connections from being discarded by a drain for a transient failure, while
+// connections from before an invalidation, or that have not been used since the drain, stay
+// discarded.
+func (p *pool) refresh(c *connection) {
+	healthyGeneration := c.healthyGeneration
+	c.healthyGeneration = 0
+	if !p.refreshConns {
+		return
+	}
+	generation := atomic.LoadUint64(&p.generation)
+	if healthyGeneration < generation || c.generation < atomic.LoadUint64(&p.invalidGeneration) {
+		return
+	}
+	c.generation = generation
+}
+
 func (p *pool) expired(generation uint64) bool { return generation < atomic.LoadUint64(&p.generation) }
 
+// stale returns true if c was created before the pool, or the service c is connected to, was last
+// cleared.
+func (p *pool) stale(c *connection) bool {
+	if p.expired(c.generation) {
+		return true
+	}
+	if c.desc.ServiceID.IsZero() {
+		return false
+	}
+	p.Lock()
+	defer p.Unlock()
+	return c.serviceGeneration < p.serviceGenerations[c.desc.ServiceID]
+}
+
+// serviceGeneration returns the current generation of the service behind a load balancer
+// identified by serviceID.
+func (p *pool) serviceGeneration(serviceID primitive.ObjectID) uint64 {
+	p.Lock()
+	defer p.Unlock()
+	return p.serviceGenerations[serviceID]
+}
+
+// clearService lazily invalidates the connections to the service behind a load balancer identified
+// by serviceID. Connections to other services are left usable.
+func (p *pool) clearService(serviceID primitive.ObjectID) {
+	p.Lock()
+	p.serviceGenerations[serviceID]++
+	p.Unlock()
+	p.publish(p.monitor.PoolCleared, nil, "")
+}
+
+// publish calls fn, if it is set, with an event for this pool and the connection c, which may be nil
+// for events about the pool as a whole.
+func (p *pool) publish(fn func(*event.PoolEvent), c *connection, reason event.ConnectionClosedReason) {
+	if fn == nil {
+		return
+	}
+	evt := &event.PoolEvent{Address: p.address.String(), Reason: reason}
+	if c != nil {
+		evt.ConnectionID = c.id
+	}
+	fn(evt)
+}
+
+// expiredReason reports whether c should be closed instead of being handed out or kept idle and, if
+// so, the reason for closing it.
+func (p *pool) expiredReason(c *connection) (event.ConnectionClosedReason, bool) {
+	switch {
+	case p.stale(c):
+		return event.ReasonStale, true
+	case c.nc == nil:
+		return event.ReasonError, true
+	case c.expired():
+		return event.ReasonIdle, true
+	}
+	return "", false
+}
+
+// clear lazily invalidates the connections that have a generation less than or equal to the
+// provided generation, which is usually the generation of a connection that encountered an error.
+// Connections created after the pool was last cleared are left usable. If the pool has already been
+// cleared past the provided generation, this method does nothing and returns false.
+func (p *pool) clear(generation uint64) bool {
+	for {
+		current := atomic.LoadUint64(&p.generation)
+		if generation < current {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&p.generation, current, generation+1) {
+			p.publish(p.monitor.PoolCleared, nil, "")
+			return true
+		}
+	}
+}
+
 // connect puts the pool into the connected state, allowing it to be used.
 func (p *pool) connect() error {
 	if !atomic.CompareAndSwapInt32(&p.connected, disconnected, connected) {
 		return ErrPoolConnected
 	}
 	atomic.AddUint64(&p.generation, 1)
+	if p.minSize > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		p.Lock()
+		p.cancelWarm = cancel
+		p.Unlock()
+		p.warming.Add(1)
+		go func() {
+			defer p.warming.Done()
+			p.warm(ctx)
+		}()
+	}
 	return nil
 }
 
-func (p *pool) disconnect(ctx context.Context) error {
+// warm establishes up to minSize idle connections until ctx is cancelled. Each dial takes a slot in
+// p.connecting, so warming shares the maxConnecting budget with checkouts instead of dialing every
+// connection at once against a server that may have just started.
+func (p *pool) warm(ctx context.Context) {
+	size := p.minSize
+	if max := uint64(cap(p.conns)); size > max {
+		size = max
+	}
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for i := uint64(0); i < size; i++ {
+		select {
+		case p.connecting <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := p.dial(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case p.conns <- c:
+			default:
+				_ = p.close(c, event.ReasonIdle) // The pool already has enough idle connections.
+			}
+		}()
+	}
+}
+
+// disconnect closes the pool's connections and returns how many were leaked. Connections that are
+// still checked out once the context's deadline passes are leaked: they are force-closed and
+// reported to the pool's monitor with event.ReasonLeaked.
+func (p *pool) disconnect(ctx context.Context) (int, error) {
 	if !atomic.CompareAndSwapInt32(&p.connected, connected, disconnecting) {
-		return ErrPoolDisconnected
+		return 0, ErrPoolDisconnected
+	}
+
+	// Warming is stopped first so that it does not open connections after they have been closed.
+	p.Lock()
+	if p.cancelWarm != nil {
+		p.cancelWarm()
+		p.cancelWarm = nil
 	}
+	p.Unlock()
+	p.warming.Wait()
 
 	// We first clear out the idle connections, then we wait until the context's deadline is hit or
 	// it's cancelled, after which we aggressively close the remaining open connections.
 	for {
 		select {
 		case pc := <-p.conns:
-			_ = p.close(pc) // We don't care about errors while closing the connection.
+			_ = p.close(pc, event.ReasonPoolClosed) // We don't care about errors while closing the connection.
 			continue
 		default:
 		}
@@ -88,20 +315,21 @@ func (p *pool) disconnect(ctx context.Context) error {
 		// until the timer is done.
 		ticker := time.NewTicker(1 * time.Second)
 		defer ticker.Stop()
-		timer := time.NewTimer(time.Now().Sub(dl))
+		timer := time.NewTimer(time.Until(dl))
 		defer timer.Stop()
+	wait:
 		for {
+			p.Lock()
+			inUse := len(p.opened)
+			p.Unlock()
+			if inUse == 0 {
+				break
+			}
 			select {
 			case <-timer.C:
+				break wait
 			case <-ticker.C: // Can we repalce this with an actual signal channel? We will know when p.inflight hits zero from the close method.
-				p.Lock()
-				if len(p.opened) > 0 {
-					p.Unlock()
-					continue
-				}
-				p.Unlock()
 			}
-			break
 		}
 	}
 
@@ -115,50 +343,138 @@ func (p *pool) disconnect(ctx context.Context) error {
 	}
 	p.Unlock()
 	for _, pc := range toClose {
-		_ = p.close(pc) // We don't care about errors while closing the connection.
+		_ = p.close(pc, event.ReasonLeaked) // We don't care about errors while closing the connection.
 	}
 	atomic.StoreInt32(&p.connected, disconnected)
-	return nil
+	return len(toClose), nil
 }
 
 func (p *pool) get(ctx context.Context) (*connection, error) {
+	start := time.Now()
+	c, reused, err := p.checkout(ctx)
+	if err != nil {
+		return c, err
+	}
+	wait := time.Since(start)
+	p.recordCheckout(wait)
+	if p.checkoutFn != nil {
+		p.checkoutFn(wait, reused)
+	}
+	p.publish(p.monitor.ConnectionCheckedOut, c, "")
+	return c, nil
+}
+
+// recordCheckout records the time spent getting a connection, replacing the oldest sample once
+// checkoutSamples have been recorded.
+func (p *pool) recordCheckout(wait time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+	if len(p.checkoutWaits) < checkoutSamples {
+		p.checkoutWaits = append(p.checkoutWaits, wait)
+		return
+	}
+	p.checkoutWaits[p.nextWait] = wait
+	p.nextWait = (p.nextWait + 1) % checkoutSamples
+}
+
+// stats returns the current PoolStats of the pool.
+func (p *pool) stats() PoolStats {
+	p.Lock()
+	opened := len(p.opened)
+	waits := append([]time.Duration(nil), p.checkoutWaits...)
+	p.Unlock()
+
+	stats := PoolStats{
+		Opened:     opened,
+		Available:  len(p.conns),
+		Generation: atomic.LoadUint64(&p.generation),
+	}
+	// Idle connections are still counted in opened while they are being closed, so never report a
+	// negative number of connections in use.
+	if stats.InUse = opened - stats.Available; stats.InUse < 0 {
+		stats.InUse = 0
+	}
+	if len(waits) > 0 {
+		sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+		stats.CheckoutWaitP99 = waits[(len(waits)*99-1)/100]
+	}
+	return stats
+}
+
+// checkout gets a connection from the pool, dialing a new one if there are no idle connections. The
+// returned bool reports whether an idle connection was reused.
+func (p *pool) checkout(ctx context.Context) (*connection, bool, error) {
 	if atomic.LoadInt32(&p.connected) != connected {
-		return nil, ErrPoolDisconnected
+		return nil, false, ErrPoolDisconnected
 	}
 	select {
 	case c := <-p.conns:
-		if c.expired() {
-			go p.close(c)
-			return p.get(ctx)
-		}
-
-		return c, nil
+		return p.reuse(ctx, c)
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return nil, false, ctx.Err()
 	default:
-		c, err := newConnection(ctx, p.address, p.opts...)
-		if err != nil {
-			return nil, err
-		}
+	}
 
-		c.pool = p
-		c.poolID = atomic.AddUint64(&p.nextid, 1)
-		c.generation = p.generation
+	// There are no idle connections, so wait until either one is returned to the pool or fewer than
+	// maxConnecting connections are being established.
+	select {
+	case c := <-p.conns:
+		return p.reuse(ctx, c)
+	case p.connecting <- struct{}{}:
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
 
-		if atomic.LoadInt32(&p.connected) != connected {
-			_ = p.close(c) // The pool is disconnected or disconnecting, ignore the error from closing the connection.
-			return nil, ErrPoolDisconnected
-		}
-		p.Lock()
-		p.opened[c.poolID] = c
-		p.Unlock()
-		return c, nil
+	c, err := p.dial(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return c, false, nil
+}
+
+// dial establishes a new connection and adds it to the pool's open connections. The caller must hold
+// a slot in p.connecting, which is released once the connection has been established.
+func (p *pool) dial(ctx context.Context) (*connection, error) {
+	c, err := newConnection(ctx, p.address, p.opts...)
+	<-p.connecting
+	if err != nil {
+		return nil, err
 	}
+
+	c.pool = p
+	c.poolID = atomic.AddUint64(&p.nextid, 1)
+	c.generation = atomic.LoadUint64(&p.generation)
+	c.healthyGeneration = 0 // The handshake does not prove the connection is healthy in this generation.
+	if !c.desc.ServiceID.IsZero() {
+		c.serviceGeneration = p.serviceGeneration(c.desc.ServiceID)
+	}
+
+	if atomic.LoadInt32(&p.connected) != connected {
+		_ = p.close(c, event.ReasonPoolClosed) // The pool is disconnected or disconnecting, ignore the error from closing the connection.
+		return nil, ErrPoolDisconnected
+	}
+	p.Lock()
+	p.opened[c.poolID] = c
+	p.Unlock()
+	p.publish(p.monitor.ConnectionCreated, c, "")
+	return c, nil
+}
+
+// reuse returns an idle connection taken from the pool, or checks out another connection if it has
+// expired.
+func (p *pool) reuse(ctx context.Context, c *connection) (*connection, bool, error) {
+	if reason, expired := p.expiredReason(c); expired {
+		go p.close(c, reason)
+		return p.checkout(ctx)
+	}
+
+	return c, true, nil
 }
 
 // close closes a connection, not the pool itself. This method will actually close the connection,
-// making it unusable, to instead return the connection to the pool, use put.
-func (p *pool) close(c *connection) error {
+// making it unusable, to instead return the connection to the pool, use put. The reason is reported
+// to the pool's monitor.
+func (p *pool) close(c *connection, reason event.ConnectionClosedReason) error {
 	if c.pool != p {
 		return ErrWrongPool
 	}
@@ -170,6 +486,7 @@ func (p *pool) close(c *connection) error {
 	}
 	err := c.nc.Close()
 	c.nc = nil
+	p.publish(p.monitor.ConnectionClosed, c, reason)
 	if err != nil {
 		return ConnectionError{ConnectionID: c.id, Wrapped: err, message: "failed to close net.Conn"}
 	}
@@ -182,14 +499,19 @@ func (p *pool) put(c *connection) error {
 	if c.pool != p {
 		return ErrWrongPool
 	}
-	if atomic.LoadInt32(&p.connected) != connected || c.expired() {
-		return p.close(c)
+	p.publish(p.monitor.ConnectionCheckedIn, c, "")
+	if atomic.LoadInt32(&p.connected) != connected {
+		return p.close(c, event.ReasonPoolClosed)
+	}
+	p.refresh(c)
+	if reason, expired := p.expiredReason(c); expired {
+		return p.close(c, reason)
 	}
 
 	select {
 	case p.conns <- c:
 		return nil
 	default:
-		return p.close(c)
+		return p.close(c, event.ReasonIdle)
 	}
 }
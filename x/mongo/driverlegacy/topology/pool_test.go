@@ -5,12 +5,17 @@ import (
 	"errors"
 	"net"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/lakshay2395/mongo-go-driver/bson/primitive"
+	"github.com/lakshay2395/mongo-go-driver/event"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driver"
 	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
 )
 
 func TestPool(t *testing.T) {
@@ -35,7 +40,7 @@ func TestPool(t *testing.T) {
 
 			c1 := &connection{pool: p1}
 			want := ErrWrongPool
-			got := p2.close(c1)
+			got := p2.close(c1, event.ReasonError)
 			if got != want {
 				t.Errorf("Errors do not match. got %v; want %v", got, want)
 			}
@@ -63,9 +68,9 @@ func TestPool(t *testing.T) {
 			p := newPool(address.Address(""), 2)
 			err := p.connect()
 			noerr(t, err)
-			err = p.disconnect(context.Background())
+			_, err = p.disconnect(context.Background())
 			noerr(t, err)
-			err = p.disconnect(context.Background())
+			_, err = p.disconnect(context.Background())
 			if err != ErrPoolDisconnected {
 				t.Errorf("Should not be able to call disconnect twice. got %v; want %v", err, ErrPoolDisconnected)
 			}
@@ -92,7 +97,7 @@ func TestPool(t *testing.T) {
 			if d.lenopened() != 3 {
 				t.Errorf("Should have opened 3 connections, but didn't. got %d; want %d", d.lenopened(), 3)
 			}
-			err = p.disconnect(context.Background())
+			_, err = p.disconnect(context.Background())
 			noerr(t, err)
 			if d.lenclosed() != 3 {
 				t.Errorf("Should have closed 3 connections, but didn't. got %d; want %d", d.lenclosed(), 3)
@@ -123,15 +128,88 @@ func TestPool(t *testing.T) {
 			}
 			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Microsecond)
 			cancel()
-			err = p.disconnect(ctx)
+			_, err = p.disconnect(ctx)
 			noerr(t, err)
 			if d.lenclosed() != 3 {
 				t.Errorf("Should have closed 3 connections, but didn't. got %d; want %d", d.lenclosed(), 3)
 			}
 			close(cleanup)
-			err = p.close(conns[2])
+			err = p.close(conns[2], event.ReasonError)
 			noerr(t, err)
 		})
+		t.Run("reports leaked connections", func(t *testing.T) {
+			cleanup := make(chan struct{})
+			addr := bootstrapConnections(t, 2, func(nc net.Conn) {
+				<-cleanup
+				nc.Close()
+			})
+			d := newdialer(&net.Dialer{})
+			p := newPool(address.Address(addr.String()), 2, WithDialer(func(Dialer) Dialer { return d }))
+			var count int32
+			p.monitor.ConnectionClosed = func(evt *event.PoolEvent) {
+				if evt.Reason == event.ReasonLeaked {
+					atomic.AddInt32(&count, 1)
+				}
+			}
+			err := p.connect()
+			noerr(t, err)
+			leaked, err := p.get(context.Background())
+			noerr(t, err)
+			returned, err := p.get(context.Background())
+			noerr(t, err)
+			noerr(t, p.put(returned))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			n, err := p.disconnect(ctx)
+			noerr(t, err)
+			if n != 1 {
+				t.Errorf("Should have returned 1 leaked connection. got %d; want %d", n, 1)
+			}
+			if count := atomic.LoadInt32(&count); count != 1 {
+				t.Errorf("Should have reported 1 leaked connection. got %d; want %d", count, 1)
+			}
+			if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+				t.Errorf("Should have waited for the deadline before force-closing, but only waited %v", elapsed)
+			}
+			if d.lenclosed() != 2 || leaked.nc != nil {
+				t.Errorf("Should have closed every connection. got %d; want %d", d.lenclosed(), 2)
+			}
+			close(cleanup)
+		})
+		t.Run("reports no leaks when connections are returned", func(t *testing.T) {
+			cleanup := make(chan struct{})
+			addr := bootstrapConnections(t, 1, func(nc net.Conn) {
+				<-cleanup
+				nc.Close()
+			})
+			d := newdialer(&net.Dialer{})
+			p := newPool(address.Address(addr.String()), 1, WithDialer(func(Dialer) Dialer { return d }))
+			var count int32
+			p.monitor.ConnectionClosed = func(evt *event.PoolEvent) {
+				if evt.Reason == event.ReasonLeaked {
+					atomic.AddInt32(&count, 1)
+				}
+			}
+			err := p.connect()
+			noerr(t, err)
+			c, err := p.get(context.Background())
+			noerr(t, err)
+			noerr(t, p.put(c))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+			n, err := p.disconnect(ctx)
+			noerr(t, err)
+			if n != 0 {
+				t.Errorf("Should not have returned leaked connections. got %d", n)
+			}
+			if count := atomic.LoadInt32(&count); count != 0 {
+				t.Errorf("Should not have reported leaked connections. got %d", count)
+			}
+			close(cleanup)
+		})
 		t.Run("properly sets the connection state on return", func(t *testing.T) {
 			cleanup := make(chan struct{})
 			addr := bootstrapConnections(t, 3, func(nc net.Conn) {
@@ -144,14 +222,14 @@ func TestPool(t *testing.T) {
 			noerr(t, err)
 			c, err := p.get(context.Background())
 			noerr(t, err)
-			err = p.close(c)
+			err = p.close(c, event.ReasonError)
 			noerr(t, err)
 			if d.lenopened() != 1 {
 				t.Errorf("Should have opened 1 connections, but didn't. got %d; want %d", d.lenopened(), 1)
 			}
 			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Microsecond)
 			defer cancel()
-			err = p.disconnect(ctx)
+			_, err = p.disconnect(ctx)
 			noerr(t, err)
 			if d.lenclosed() != 1 {
 				t.Errorf("Should have closed 1 connections, but didn't. got %d; want %d", d.lenclosed(), 1)
@@ -187,7 +265,7 @@ func TestPool(t *testing.T) {
 			}
 			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 			defer cancel()
-			err = p.disconnect(ctx)
+			_, err = p.disconnect(ctx)
 			noerr(t, err)
 			if d.lenclosed() != 1 {
 				t.Errorf("Should have closed 1 connections, but didn't. got %d; want %d", d.lenclosed(), 1)
@@ -224,7 +302,7 @@ func TestPool(t *testing.T) {
 			if err != ErrPoolConnected {
 				t.Errorf("Shouldn't be able to connect to already connected pool. got %v; want %v", err, ErrPoolConnected)
 			}
-			err = p.disconnect(context.Background())
+			_, err = p.disconnect(context.Background())
 			noerr(t, err)
 			err = p.connect()
 			if err != nil {
@@ -235,19 +313,19 @@ func TestPool(t *testing.T) {
 			p := newPool(address.Address(""), 3)
 			err := p.connect()
 			noerr(t, err)
-			err = p.disconnect(context.Background())
+			_, err = p.disconnect(context.Background())
 			noerr(t, err)
 			err = p.connect()
 			if err != nil {
 				t.Errorf("Should be able to connect to disconnected pool. got %v; want <nil>", err)
 			}
-			err = p.disconnect(context.Background())
+			_, err = p.disconnect(context.Background())
 			noerr(t, err)
 			err = p.connect()
 			if err != nil {
 				t.Errorf("Should be able to connect to disconnected pool. got %v; want <nil>", err)
 			}
-			err = p.disconnect(context.Background())
+			_, err = p.disconnect(context.Background())
 			noerr(t, err)
 			err = p.connect()
 			if err != nil {
@@ -276,7 +354,7 @@ func TestPool(t *testing.T) {
 		})
 		t.Run("return error when attempting to create new connection", func(t *testing.T) {
 			wanterr := errors.New("create new connection error")
-			var want error = ConnectionError{Wrapped: wanterr, init: true}
+			var want error = ConnectionError{Wrapped: wanterr, init: true, message: "failed to dial"}
 			var dialer DialerFunc = func(context.Context, string, string) (net.Conn, error) { return nil, wanterr }
 			p := newPool(address.Address(""), 2, WithDialer(func(Dialer) Dialer { return dialer }))
 			err := p.connect()
@@ -286,6 +364,27 @@ func TestPool(t *testing.T) {
 				t.Errorf("Should return error from calling New. got %v; want %v", got, want)
 			}
 		})
+		t.Run("dial error identifies the address", func(t *testing.T) {
+			l, err := net.Listen("tcp", "localhost:0")
+			noerr(t, err)
+			addr := address.Address(l.Addr().String())
+			_ = l.Close()
+
+			p := newPool(addr, 2)
+			err = p.connect()
+			noerr(t, err)
+			_, err = p.get(context.Background())
+			connErr, ok := err.(ConnectionError)
+			if !ok {
+				t.Fatalf("Expected a ConnectionError. got %T: %v", err, err)
+			}
+			if connErr.Addr != addr {
+				t.Errorf("Error should identify the address. got %q; want %q", connErr.Addr, addr)
+			}
+			if !strings.Contains(connErr.Error(), addr.String()) || !strings.Contains(connErr.Error(), "failed to dial") {
+				t.Errorf("Error message should name the address and the dial phase. got %q", connErr.Error())
+			}
+		})
 		t.Run("adds connection to inflight pool", func(t *testing.T) {
 			cleanup := make(chan struct{})
 			addr := bootstrapConnections(t, 1, func(nc net.Conn) {
@@ -304,7 +403,7 @@ func TestPool(t *testing.T) {
 			if inflight != 1 {
 				t.Errorf("Incorrect number of inlight connections. got %d; want %d", inflight, 1)
 			}
-			err = p.close(c)
+			err = p.close(c, event.ReasonError)
 			noerr(t, err)
 			close(cleanup)
 		})
@@ -346,6 +445,201 @@ func TestPool(t *testing.T) {
 			}
 			close(cleanup)
 		})
+		t.Run("closes connections past their lifetime", func(t *testing.T) {
+			cleanup := make(chan struct{})
+			addr := bootstrapConnections(t, 2, func(nc net.Conn) {
+				<-cleanup
+				nc.Close()
+			})
+			var mu sync.Mutex
+			now := time.Now()
+			clock := func() time.Time {
+				mu.Lock()
+				defer mu.Unlock()
+				return now
+			}
+			advance := func(d time.Duration) {
+				mu.Lock()
+				defer mu.Unlock()
+				now = now.Add(d)
+			}
+			d := newdialer(&net.Dialer{})
+			p := newPool(
+				address.Address(addr.String()), 3,
+				WithDialer(func(Dialer) Dialer { return d }),
+				WithIdleTimeout(func(time.Duration) time.Duration { return 0 }),
+				WithLifeTimeout(func(time.Duration) time.Duration { return 30 * time.Minute }),
+				func(cfg *connectionConfig) error {
+					cfg.now = clock
+					return nil
+				},
+			)
+			err := p.connect()
+			noerr(t, err)
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+			c1, err := p.get(ctx)
+			noerr(t, err)
+			noerr(t, p.put(c1))
+
+			advance(29 * time.Minute)
+			c, err := p.get(ctx)
+			noerr(t, err)
+			if c != c1 {
+				t.Errorf("Should have reused the connection before its lifetime elapsed, but didn't.")
+			}
+			noerr(t, p.put(c))
+
+			advance(2 * time.Minute)
+			c2, err := p.get(ctx)
+			noerr(t, err)
+			if c2 == c1 {
+				t.Errorf("Should not have returned a connection past its lifetime, but did.")
+			}
+			if d.lenopened() != 2 {
+				t.Errorf("Should have opened 2 connections, but didn't. got %d; want %d", d.lenopened(), 2)
+			}
+			time.Sleep(10 * time.Millisecond)
+			if d.lenclosed() != 1 {
+				t.Errorf("Should have closed 1 connection, but didn't. got %d; want %d", d.lenclosed(), 1)
+			}
+			close(cleanup)
+		})
+		t.Run("clears connections per service", func(t *testing.T) {
+			cleanup := make(chan struct{})
+			addr := bootstrapConnections(t, 3, func(nc net.Conn) {
+				<-cleanup
+				nc.Close()
+			})
+			serviceA, serviceB := primitive.NewObjectID(), primitive.NewObjectID()
+			services := []primitive.ObjectID{serviceA, serviceB, serviceA}
+			var handshakes int32
+			d := newdialer(&net.Dialer{})
+			p := newPool(
+				address.Address(addr.String()), 3,
+				WithDialer(func(Dialer) Dialer { return d }),
+				WithHandshaker(func(Handshaker) Handshaker {
+					return HandshakerFunc(func(context.Context, address.Address, driver.Connection) (description.Server, error) {
+						i := atomic.AddInt32(&handshakes, 1) - 1
+						return description.Server{ServiceID: services[i]}, nil
+					})
+				}),
+			)
+			err := p.connect()
+			noerr(t, err)
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+			a, err := p.get(ctx)
+			noerr(t, err)
+			b, err := p.get(ctx)
+			noerr(t, err)
+			if a.desc.ServiceID != serviceA || b.desc.ServiceID != serviceB {
+				t.Fatalf("Connections should have the service ids from the handshake. got %v and %v", a.desc.ServiceID, b.desc.ServiceID)
+			}
+
+			p.clearService(serviceA)
+			if !p.stale(a) {
+				t.Errorf("Connection to the cleared service should be stale, but isn't.")
+			}
+			if p.stale(b) {
+				t.Errorf("Connection to another service should not be stale, but is.")
+			}
+			noerr(t, p.put(a))
+			noerr(t, p.put(b))
+			if d.lenclosed() != 1 {
+				t.Errorf("Should have closed 1 connection, but didn't. got %d; want %d", d.lenclosed(), 1)
+			}
+
+			c, err := p.get(ctx)
+			noerr(t, err)
+			if c != b {
+				t.Errorf("Should have reused the connection to the other service, but didn't.")
+			}
+			a2, err := p.get(ctx)
+			noerr(t, err)
+			if a2.desc.ServiceID != serviceA || p.stale(a2) {
+				t.Errorf("New connection to the cleared service should be usable, but isn't.")
+			}
+			close(cleanup)
+		})
+		t.Run("refreshes healthy connections", func(t *testing.T) {
+			cleanup := make(chan struct{})
+			addr := bootstrapConnections(t, 4, func(nc net.Conn) {
+				<-cleanup
+				nc.Close()
+			})
+			d := newdialer(&net.Dialer{})
+			p := newPool(address.Address(addr.String()), 3, WithDialer(func(Dialer) Dialer { return d }))
+			p.refreshConns = true
+			err := p.connect()
+			noerr(t, err)
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+
+			// A drain for a transient failure ages the connection, but a successful round trip
+			// afterwards proves it is healthy.
+			aged, err := p.get(ctx)
+			noerr(t, err)
+			p.drain()
+			aged.healthyGeneration = atomic.LoadUint64(&p.generation)
+			noerr(t, p.put(aged))
+			if d.lenclosed() != 0 {
+				t.Errorf("Healthy connection aged by a drain should be kept, but was closed.")
+			}
+			c, err := p.get(ctx)
+			noerr(t, err)
+			if c != aged {
+				t.Errorf("Should have reused the refreshed connection, but didn't.")
+			}
+
+			// A stepdown invalidates the connection, so it is discarded even after a round trip.
+			p.invalidate()
+			c.healthyGeneration = atomic.LoadUint64(&p.generation)
+			noerr(t, p.put(c))
+			if d.lenclosed() != 1 {
+				t.Errorf("Connection cleared by a stepdown should be closed, but wasn't.")
+			}
+
+			// A connection that has not completed a round trip since the drain is not refreshed.
+			idle, err := p.get(ctx)
+			noerr(t, err)
+			p.drain()
+			noerr(t, p.put(idle))
+			if d.lenclosed() != 2 {
+				t.Errorf("Connection without a round trip since the drain should be closed, but wasn't.")
+			}
+
+			// A round trip that completed before the drain doesn't prove the connection survived it.
+			early, err := p.get(ctx)
+			noerr(t, err)
+			early.healthyGeneration = atomic.LoadUint64(&p.generation)
+			p.drain()
+			noerr(t, p.put(early))
+			if d.lenclosed() != 3 {
+				t.Errorf("Connection with a round trip only before the drain should be closed, but wasn't.")
+			}
+			close(cleanup)
+		})
+		t.Run("does not refresh connections by default", func(t *testing.T) {
+			cleanup := make(chan struct{})
+			addr := bootstrapConnections(t, 1, func(nc net.Conn) {
+				<-cleanup
+				nc.Close()
+			})
+			d := newdialer(&net.Dialer{})
+			p := newPool(address.Address(addr.String()), 1, WithDialer(func(Dialer) Dialer { return d }))
+			err := p.connect()
+			noerr(t, err)
+			c, err := p.get(context.Background())
+			noerr(t, err)
+			p.drain()
+			c.healthyGeneration = atomic.LoadUint64(&p.generation)
+			noerr(t, p.put(c))
+			if d.lenclosed() != 1 {
+				t.Errorf("Drained connection should be closed, but wasn't.")
+			}
+			close(cleanup)
+		})
 		t.Run("recycles connections", func(t *testing.T) {
 			cleanup := make(chan struct{})
 			addr := bootstrapConnections(t, 3, func(nc net.Conn) {
@@ -379,7 +673,7 @@ func TestPool(t *testing.T) {
 			noerr(t, err)
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Microsecond)
 			defer cancel()
-			err = p.disconnect(ctx)
+			_, err = p.disconnect(ctx)
 			noerr(t, err)
 			_, err = p.get(context.Background())
 			if err != ErrPoolDisconnected {
@@ -451,6 +745,70 @@ func TestPool(t *testing.T) {
 			close(cleanup)
 		})
 	})
+	t.Run("clear", func(t *testing.T) {
+		t.Run("only expires connections from older generations", func(t *testing.T) {
+			cleanup := make(chan struct{})
+			defer close(cleanup)
+			addr := bootstrapConnections(t, 2, func(nc net.Conn) {
+				<-cleanup
+				nc.Close()
+			})
+			d := newdialer(&net.Dialer{})
+			p := newPool(address.Address(addr.String()), 3, WithDialer(func(Dialer) Dialer { return d }))
+			err := p.connect()
+			noerr(t, err)
+			older, err := p.get(context.Background())
+			noerr(t, err)
+			p.clear(older.generation)
+			newer, err := p.get(context.Background())
+			noerr(t, err)
+			if newer.generation <= older.generation {
+				t.Errorf("Connection should have a newer generation. got %d; want > %d", newer.generation, older.generation)
+			}
+
+			// A second error from a connection in the older generation should not affect newer connections.
+			p.clear(older.generation)
+			if !p.expired(older.generation) {
+				t.Errorf("Connection from older generation should be expired, but isn't.")
+			}
+			if p.expired(newer.generation) {
+				t.Errorf("Connection from newer generation should not be expired, but is.")
+			}
+
+			err = p.put(older)
+			noerr(t, err)
+			err = p.put(newer)
+			noerr(t, err)
+			if d.lenclosed() != 1 {
+				t.Errorf("Should have closed 1 connection, but didn't. got %d; want %d", d.lenclosed(), 1)
+			}
+			if len(p.conns) != 1 {
+				t.Errorf("Should have returned 1 connection to the pool. got %d; want %d", len(p.conns), 1)
+			}
+		})
+		t.Run("expires all connections up to the failing generation", func(t *testing.T) {
+			cleanup := make(chan struct{})
+			defer close(cleanup)
+			addr := bootstrapConnections(t, 2, func(nc net.Conn) {
+				<-cleanup
+				nc.Close()
+			})
+			d := newdialer(&net.Dialer{})
+			p := newPool(address.Address(addr.String()), 3, WithDialer(func(Dialer) Dialer { return d }))
+			err := p.connect()
+			noerr(t, err)
+			older, err := p.get(context.Background())
+			noerr(t, err)
+			p.drain()
+			newer, err := p.get(context.Background())
+			noerr(t, err)
+
+			p.clear(newer.generation)
+			if !p.expired(older.generation) || !p.expired(newer.generation) {
+				t.Errorf("Connections from both generations should be expired, but weren't.")
+			}
+		})
+	})
 	t.Run("Connection", func(t *testing.T) {
 		t.Run("Connection Close Does Not Error After Pool Is Disconnected", func(t *testing.T) {
 			cleanup := make(chan struct{})
@@ -468,7 +826,7 @@ func TestPool(t *testing.T) {
 			c1 := &Connection{connection: c}
 			ctx, cancel := context.WithCancel(context.Background())
 			cancel()
-			err = p.disconnect(ctx)
+			_, err = p.disconnect(ctx)
 			noerr(t, err)
 			err = c1.Close()
 			if err != nil {
@@ -501,4 +859,364 @@ func TestPool(t *testing.T) {
 			}
 		})
 	})
+	t.Run("maxConnecting", func(t *testing.T) {
+		t.Run("limits concurrent connection establishment", func(t *testing.T) {
+			var inflight, maxInflight int32
+			d := DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+				n := atomic.AddInt32(&inflight, 1)
+				for {
+					max := atomic.LoadInt32(&maxInflight)
+					if n <= max || atomic.CompareAndSwapInt32(&maxInflight, max, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&inflight, -1)
+				nc, _ := net.Pipe()
+				return nc, nil
+			})
+			p := newPool(address.Address(""), 10, WithDialer(func(Dialer) Dialer { return d }))
+			err := p.connect()
+			noerr(t, err)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, 10)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := p.get(context.Background())
+					errs <- err
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				noerr(t, err)
+			}
+			if got := atomic.LoadInt32(&maxInflight); got > defaultMaxConnecting {
+				t.Errorf("Too many connections established concurrently. got %d; want at most %d", got, defaultMaxConnecting)
+			}
+			_, err = p.disconnect(context.Background())
+			noerr(t, err)
+		})
+		t.Run("warming shares the budget with checkouts", func(t *testing.T) {
+			var inflight, maxInflight, dialed int32
+			d := DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+				n := atomic.AddInt32(&inflight, 1)
+				for {
+					max := atomic.LoadInt32(&maxInflight)
+					if n <= max || atomic.CompareAndSwapInt32(&maxInflight, max, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&inflight, -1)
+				atomic.AddInt32(&dialed, 1)
+				nc, _ := net.Pipe()
+				return nc, nil
+			})
+			p := newPool(address.Address(""), 10, WithDialer(func(Dialer) Dialer { return d }))
+			p.connecting = make(chan struct{}, 2)
+			p.minSize = 10
+			err := p.connect()
+			noerr(t, err)
+
+			// Check out connections while the pool is warming; they compete for the same slots.
+			var wg sync.WaitGroup
+			for i := 0; i < 3; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					c, err := p.get(context.Background())
+					noerr(t, err)
+					noerr(t, p.put(c))
+				}()
+			}
+			wg.Wait()
+
+			deadline := time.Now().Add(5 * time.Second)
+			for len(p.conns) < 10 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if len(p.conns) != 10 {
+				t.Errorf("Pool should have warmed to its minimum size. got %d idle connections; want %d", len(p.conns), 10)
+			}
+			if got := atomic.LoadInt32(&maxInflight); got > 2 {
+				t.Errorf("Too many connections established concurrently. got %d; want at most %d", got, 2)
+			}
+			if got := atomic.LoadInt32(&dialed); got > 13 {
+				t.Errorf("Too many connections dialed. got %d; want at most %d", got, 13)
+			}
+			_, err = p.disconnect(context.Background())
+			noerr(t, err)
+		})
+		t.Run("warming stops when the pool disconnects", func(t *testing.T) {
+			var dialed int32
+			d := DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+				atomic.AddInt32(&dialed, 1)
+				time.Sleep(10 * time.Millisecond)
+				nc, _ := net.Pipe()
+				return nc, nil
+			})
+			p := newPool(address.Address(""), 10, WithDialer(func(Dialer) Dialer { return d }))
+			p.connecting = make(chan struct{}, 1)
+			p.minSize = 10
+			err := p.connect()
+			noerr(t, err)
+			_, err = p.disconnect(context.Background())
+			noerr(t, err)
+
+			time.Sleep(50 * time.Millisecond)
+			if got := atomic.LoadInt32(&dialed); got >= 10 {
+				t.Errorf("Warming should stop once the pool disconnects. got %d dials", got)
+			}
+			if len(p.conns) != 0 {
+				t.Errorf("No connections should be kept after the pool disconnects. got %d", len(p.conns))
+			}
+		})
+		// disconnectWithin fails the test if disconnecting p takes longer than a second.
+		disconnectWithin := func(t *testing.T, p *pool) {
+			t.Helper()
+			errs := make(chan error, 1)
+			go func() {
+				_, err := p.disconnect(context.Background())
+				errs <- err
+			}()
+			select {
+			case err := <-errs:
+				noerr(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("Disconnect should not wait for warming to acquire a connecting slot or finish dialing")
+			}
+		}
+		t.Run("disconnect stops warming waiting for a connecting slot", func(t *testing.T) {
+			var dialed int32
+			d := DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+				atomic.AddInt32(&dialed, 1)
+				nc, _ := net.Pipe()
+				return nc, nil
+			})
+			p := newPool(address.Address(""), 2, WithDialer(func(Dialer) Dialer { return d }))
+			p.connecting = make(chan struct{}, 1)
+			p.connecting <- struct{}{} // A checkout is establishing a connection.
+			p.minSize = 2
+			err := p.connect()
+			noerr(t, err)
+			time.Sleep(20 * time.Millisecond) // Let warming block on the connecting slot.
+
+			disconnectWithin(t, p)
+			<-p.connecting // The checkout finishes, which would let warming dial if it were still running.
+			time.Sleep(20 * time.Millisecond)
+			if got := atomic.LoadInt32(&dialed); got != 0 {
+				t.Errorf("Warming should stop once the pool disconnects. got %d dials", got)
+			}
+		})
+		t.Run("disconnect cancels warming dials", func(t *testing.T) {
+			dialing := make(chan struct{}, 1)
+			cancelled := make(chan struct{}, 1)
+			d := DialerFunc(func(ctx context.Context, _, _ string) (net.Conn, error) {
+				dialing <- struct{}{}
+				<-ctx.Done()
+				cancelled <- struct{}{}
+				return nil, ctx.Err()
+			})
+			p := newPool(address.Address(""), 1, WithDialer(func(Dialer) Dialer { return d }))
+			p.minSize = 1
+			err := p.connect()
+			noerr(t, err)
+			<-dialing
+
+			disconnectWithin(t, p)
+			select {
+			case <-cancelled:
+			default:
+				t.Error("The warming dial should be cancelled before disconnect returns")
+			}
+			if len(p.opened) != 0 {
+				t.Errorf("No connections should be opened by a cancelled dial. got %d", len(p.opened))
+			}
+		})
+	})
+	t.Run("monitor", func(t *testing.T) {
+		newMonitoredPool := func(t *testing.T, size uint64) (*pool, *[]string) {
+			d := DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+				nc, _ := net.Pipe()
+				return nc, nil
+			})
+			p := newPool(address.Address("localhost:27017"), size, WithDialer(func(Dialer) Dialer { return d }))
+			var mu sync.Mutex
+			var events []string
+			record := func(kind string) func(*event.PoolEvent) {
+				return func(evt *event.PoolEvent) {
+					if evt.Address != "localhost:27017" {
+						t.Errorf("Unexpected address. got %s; want %s", evt.Address, "localhost:27017")
+					}
+					if kind != "cleared" && evt.ConnectionID == "" {
+						t.Errorf("%s event should carry the connection ID", kind)
+					}
+					mu.Lock()
+					defer mu.Unlock()
+					if evt.Reason != "" {
+						kind += ":" + string(evt.Reason)
+					}
+					events = append(events, kind)
+				}
+			}
+			p.monitor = event.PoolMonitor{
+				ConnectionCreated:    record("created"),
+				ConnectionCheckedOut: record("checkedOut"),
+				ConnectionCheckedIn:  record("checkedIn"),
+				ConnectionClosed:     record("closed"),
+				PoolCleared:          record("cleared"),
+			}
+			err := p.connect()
+			noerr(t, err)
+			return p, &events
+		}
+		assertEvents := func(t *testing.T, got []string, want ...string) {
+			t.Helper()
+			if len(got) != len(want) {
+				t.Fatalf("Unexpected events. got %v; want %v", got, want)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("Unexpected events. got %v; want %v", got, want)
+				}
+			}
+		}
+
+		t.Run("checkout and checkin", func(t *testing.T) {
+			p, events := newMonitoredPool(t, 1)
+			c, err := p.get(context.Background())
+			noerr(t, err)
+			err = p.put(c)
+			noerr(t, err)
+			_, err = p.get(context.Background())
+			noerr(t, err)
+			assertEvents(t, *events, "created", "checkedOut", "checkedIn", "checkedOut")
+		})
+		t.Run("closed after an error", func(t *testing.T) {
+			p, events := newMonitoredPool(t, 1)
+			c, err := p.get(context.Background())
+			noerr(t, err)
+			c.close()
+			err = p.put(c)
+			noerr(t, err)
+			assertEvents(t, *events, "created", "checkedOut", "closed:error", "checkedIn")
+		})
+		t.Run("closed when stale", func(t *testing.T) {
+			p, events := newMonitoredPool(t, 1)
+			c, err := p.get(context.Background())
+			noerr(t, err)
+			p.drain()
+			err = p.put(c)
+			noerr(t, err)
+			assertEvents(t, *events, "created", "checkedOut", "cleared", "checkedIn", "closed:stale")
+		})
+		t.Run("closed when idle", func(t *testing.T) {
+			p, events := newMonitoredPool(t, 0)
+			c, err := p.get(context.Background())
+			noerr(t, err)
+			err = p.put(c)
+			noerr(t, err)
+			assertEvents(t, *events, "created", "checkedOut", "checkedIn", "closed:idle")
+		})
+		t.Run("closed when the pool is disconnected", func(t *testing.T) {
+			p, events := newMonitoredPool(t, 1)
+			c, err := p.get(context.Background())
+			noerr(t, err)
+			err = p.put(c)
+			noerr(t, err)
+			_, err = p.disconnect(context.Background())
+			noerr(t, err)
+			assertEvents(t, *events, "created", "checkedOut", "checkedIn", "closed:poolClosed")
+		})
+	})
+	t.Run("checkoutFn", func(t *testing.T) {
+		t.Run("reports checkout duration and reuse", func(t *testing.T) {
+			delay := 50 * time.Millisecond
+			d := DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+				time.Sleep(delay)
+				nc, _ := net.Pipe()
+				return nc, nil
+			})
+			p := newPool(address.Address(""), 1, WithDialer(func(Dialer) Dialer { return d }))
+			var durations []time.Duration
+			var reuses []bool
+			p.checkoutFn = func(duration time.Duration, reused bool) {
+				durations = append(durations, duration)
+				reuses = append(reuses, reused)
+			}
+			err := p.connect()
+			noerr(t, err)
+
+			c, err := p.get(context.Background())
+			noerr(t, err)
+			err = p.put(c)
+			noerr(t, err)
+			_, err = p.get(context.Background())
+			noerr(t, err)
+
+			if len(durations) != 2 {
+				t.Fatalf("Expected checkoutFn to be called twice. got %d; want %d", len(durations), 2)
+			}
+			if durations[0] < delay {
+				t.Errorf("Checkout duration should include dial time. got %v; want at least %v", durations[0], delay)
+			}
+			if reuses[0] {
+				t.Errorf("First checkout should dial a new connection")
+			}
+			if !reuses[1] {
+				t.Errorf("Second checkout should reuse the idle connection")
+			}
+			if durations[1] >= delay {
+				t.Errorf("Reusing a connection should not include dial time. got %v", durations[1])
+			}
+		})
+	})
+	t.Run("stats", func(t *testing.T) {
+		delay := 20 * time.Millisecond
+		d := DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+			time.Sleep(delay)
+			nc, _ := net.Pipe()
+			return nc, nil
+		})
+		p := newPool(address.Address(""), 2, WithDialer(func(Dialer) Dialer { return d }))
+		err := p.connect()
+		noerr(t, err)
+
+		generation := p.stats().Generation
+		if stats := p.stats(); stats != (PoolStats{Generation: generation}) {
+			t.Errorf("Expected empty stats before any checkout. got %+v", stats)
+		}
+		c1, err := p.get(context.Background())
+		noerr(t, err)
+		_, err = p.get(context.Background())
+		noerr(t, err)
+		err = p.put(c1)
+		noerr(t, err)
+		p.drain()
+
+		stats := p.stats()
+		if stats.Opened != 2 || stats.Available != 1 || stats.InUse != 1 || stats.Generation != generation+1 {
+			t.Errorf("Unexpected pool stats. got %+v", stats)
+		}
+		if stats.CheckoutWaitP99 < delay {
+			t.Errorf("Checkout wait should include dial time. got %v; want at least %v", stats.CheckoutWaitP99, delay)
+		}
+	})
+	t.Run("stats keep the most recent checkouts", func(t *testing.T) {
+		p := newPool(address.Address(""), 1)
+		p.recordCheckout(time.Hour)
+		for i := 0; i < checkoutSamples; i++ {
+			p.recordCheckout(time.Millisecond)
+		}
+		if len(p.checkoutWaits) != checkoutSamples {
+			t.Errorf("Unexpected number of samples. got %d; want %d", len(p.checkoutWaits), checkoutSamples)
+		}
+		if wait := p.stats().CheckoutWaitP99; wait != time.Millisecond {
+			t.Errorf("The oldest sample should have been replaced. got %v; want %v", wait, time.Millisecond)
+		}
+	})
 }
@@ -105,8 +105,23 @@ type Server struct {
 	subscribers         map[uint64]chan description.Server
 	currentSubscriberID uint64
 	subscriptionsClosed bool
+
+	// monitor related fields, which replace s.heartbeat and time.NewTimer and are only set in tests
+	heartbeatFn func(*connection) (description.Server, *connection)
+	newTimer    func(time.Duration) timer
+}
+
+// timer is the part of a *time.Timer used by a server's monitor.
+type timer interface {
+	Chan() <-chan time.Time
+	Stop() bool
+	Reset(time.Duration) bool
 }
 
+type stdTimer struct{ *time.Timer }
+
+func (t stdTimer) Chan() <-chan time.Time { return t.C }
+
 // ConnectServer creates a new Server and then initializes it using the
 // Connect method.
 func ConnectServer(addr address.Address, updateCallback func(description.Server), opts ...ServerOption) (*Server, error) {
@@ -149,6 +164,15 @@ func NewServer(addr address.Address, opts ...ServerOption) (*Server, error) {
 
 	callback := func(desc description.Server) { s.updateDescription(desc, false) }
 	s.pool = newPool(addr, uint64(cfg.maxIdleConns), withServerDescriptionCallback(callback, cfg.connectionOpts...)...)
+	if cfg.maxConnecting > 0 {
+		s.pool.connecting = make(chan struct{}, cfg.maxConnecting)
+	}
+	s.pool.checkoutFn = cfg.checkoutFn
+	s.pool.refreshConns = cfg.refreshConns
+	s.pool.minSize = uint64(cfg.minConns)
+	if cfg.poolMonitor != nil {
+		s.pool.monitor = *cfg.poolMonitor
+	}
 
 	return s, nil
 }
@@ -185,7 +209,7 @@ func (s *Server) Disconnect(ctx context.Context) error {
 	// For every call to Connect there must be at least 1 goroutine that is
 	// waiting on the done channel.
 	s.done <- struct{}{}
-	err := s.pool.disconnect(ctx)
+	_, err := s.pool.disconnect(ctx)
 	if err != nil {
 		return err
 	}
@@ -266,6 +290,14 @@ func (s *Server) Description() description.Server {
 	return s.desc.Load().(description.Server)
 }
 
+// AverageRTT returns the exponentially-weighted moving average of the round trip times of this
+// server's heartbeats as of the last heartbeat. The returned bool is false if no heartbeat has
+// succeeded yet.
+func (s *Server) AverageRTT() (time.Duration, bool) {
+	desc := s.Description()
+	return desc.AverageRTT, desc.AverageRTTSet
+}
+
 // SelectedDescription returns a description.SelectedServer with a Kind of
 // Single. This can be used when performing tasks like monitoring a batch
 // of servers and you want to run one off commands against those servers.
@@ -314,17 +346,46 @@ func (s *Server) RequestImmediateCheck() {
 	}
 }
 
-// ProcessError handles SDAM error handling and implements driver.ErrorProcessor.
-func (s *Server) ProcessError(err error) {
+// ProcessError handles SDAM error handling and implements driver.ErrorProcessor. The conn parameter
+// is the connection the error occurred on and can be nil.
+func (s *Server) ProcessError(err error, conn driver.Connection) {
+	var c *connection
+	if tc, ok := conn.(*Connection); ok {
+		tc.mu.RLock()
+		c = tc.connection
+		tc.mu.RUnlock()
+	}
+	s.processError(err, c)
+}
+
+// processError handles an error that occurred on the pooled connection c. If c is nil, the error is
+// treated as if it occurred on a connection from the current pool generation.
+func (s *Server) processError(err error, c *connection) {
+	// An error on a connection from before the pool was last cleared doesn't reflect the current
+	// state of the server, and the pool has already been cleared for it.
+	if c != nil && s.pool.stale(c) {
+		return
+	}
 	// Invalidate server description if not master or node recovering error occurs
 	if cerr, ok := err.(driver.Error); ok && (cerr.NetworkError() || cerr.NodeIsRecovering() || cerr.NotMaster()) {
 		desc := s.Description()
+		// Ignore errors that don't reflect newer information than the current description, since
+		// applying them could flap the server's state.
+		if cerr.TopologyVersion != nil && desc.TopologyVersion.CompareToIncoming(cerr.TopologyVersion) >= 0 {
+			return
+		}
+		// A network error is transient, so the pool is drained rather than invalidated and
+		// connections that keep working can be refreshed.
+		clearPool := !cerr.NetworkError() && (cerr.NodeIsShuttingDown() || !keepsConnectionsOnStepDown(desc))
 		desc.Kind = description.Unknown
 		desc.LastError = err
-		// updates description to unknown
-		s.updateDescription(desc, false)
-		s.RequestImmediateCheck()
-		s.pool.drain()
+		if cerr.TopologyVersion != nil {
+			desc.TopologyVersion = cerr.TopologyVersion
+		}
+		s.markUnknown(desc, c, clearPool)
+		if cerr.NetworkError() {
+			s.clearPool(c, false)
+		}
 		return
 	}
 
@@ -343,8 +404,8 @@ func (s *Server) ProcessError(err error) {
 	desc := s.Description()
 	desc.Kind = description.Unknown
 	desc.LastError = err
-	// updates description to unknown
-	s.updateDescription(desc, false)
+	s.setDescription(desc)
+	s.clearPool(c, false)
 }
 
 // ProcessWriteConcernError checks if a WriteConcernError is an isNotMaster or
@@ -354,11 +415,10 @@ func (s *Server) ProcessWriteConcernError(err *result.WriteConcernError) {
 		return
 	}
 	desc := s.Description()
+	clearPool := isShuttingDownCode(int32(err.Code)) || !keepsConnectionsOnStepDown(desc)
 	desc.Kind = description.Unknown
 	desc.LastError = err
-	// updates description to unknown
-	s.updateDescription(desc, false)
-	s.RequestImmediateCheck()
+	s.markUnknown(desc, nil, clearPool)
 }
 
 func wceIsNotMasterOrRecovering(wce *result.WriteConcernError) bool {
@@ -377,9 +437,16 @@ func wceIsNotMasterOrRecovering(wce *result.WriteConcernError) bool {
 // newest description.Server retrieved.
 func (s *Server) update() {
 	defer s.closewg.Done()
-	heartbeatTicker := time.NewTicker(s.cfg.heartbeatInterval)
-	rateLimiter := time.NewTicker(minHeartbeatInterval)
-	defer heartbeatTicker.Stop()
+	heartbeat, newTimer := s.heartbeat, func(d time.Duration) timer { return stdTimer{time.NewTimer(d)} }
+	if s.heartbeatFn != nil {
+		heartbeat = s.heartbeatFn
+	}
+	if s.newTimer != nil {
+		newTimer = s.newTimer
+	}
+	// The rate limiter allows at most one heartbeat every minHeartbeatInterval, however often an
+	// immediate check is requested.
+	rateLimiter := newTimer(minHeartbeatInterval)
 	defer rateLimiter.Stop()
 	checkNow := s.checkNow
 	done := s.done
@@ -398,9 +465,13 @@ func (s *Server) update() {
 	var conn *connection
 	var desc description.Server
 
-	desc, conn = s.heartbeat(nil)
+	desc, conn = heartbeat(nil)
 	s.updateDescription(desc, true)
 
+	backoff := heartbeatBackoff{min: minHeartbeatInterval, max: s.cfg.heartbeatInterval}
+	heartbeatTimer := newTimer(backoff.next(desc.LastError != nil))
+	defer heartbeatTimer.Stop()
+
 	closeServer := func() {
 		doneOnce = true
 		s.subLock.Lock()
@@ -417,22 +488,95 @@ func (s *Server) update() {
 	}
 	for {
 		select {
-		case <-heartbeatTicker.C:
+		case <-heartbeatTimer.Chan():
 		case <-checkNow:
+			if !heartbeatTimer.Stop() {
+				<-heartbeatTimer.Chan()
+			}
 		case <-done:
 			closeServer()
 			return
 		}
 
 		select {
-		case <-rateLimiter.C:
+		case <-rateLimiter.Chan():
 		case <-done:
 			closeServer()
 			return
 		}
 
-		desc, conn = s.heartbeat(conn)
-		s.updateDescription(desc, false)
+		generation := atomic.LoadUint64(&s.pool.generation)
+		desc, conn = heartbeat(conn)
+		s.setDescription(desc)
+		if desc.Kind == description.Unknown {
+			s.pool.clear(generation)
+		}
+		rateLimiter.Reset(minHeartbeatInterval)
+		heartbeatTimer.Reset(backoff.next(desc.LastError != nil))
+	}
+}
+
+// heartbeatBackoff computes how long a server's monitor waits before its next heartbeat. After a
+// successful heartbeat the monitor waits the full heartbeat interval. After a failed heartbeat it
+// waits min, doubling the wait for every consecutive failure up to max, so that a server that is
+// down is probed quickly at first without the monitor hot-looping while it stays down. A successful
+// heartbeat resets the backoff.
+type heartbeatBackoff struct {
+	min, max time.Duration
+	failures uint
+}
+
+// next records whether the latest heartbeat failed and returns how long to wait before the next one.
+func (b *heartbeatBackoff) next(failed bool) time.Duration {
+	if !failed {
+		b.failures = 0
+		return b.max
+	}
+	wait := b.min
+	for i := uint(0); i < b.failures && wait < b.max; i++ {
+		wait *= 2
+	}
+	b.failures++
+	if wait > b.max {
+		wait = b.max
+	}
+	return wait
+}
+
+// keepsConnectionsOnStepDown returns true if the server described by desc is MongoDB 4.2 (wire
+// version 8) or newer. Older servers close all of their connections when they step down, so their
+// pools must be cleared, while newer servers keep connections open across a stepdown.
+func keepsConnectionsOnStepDown(desc description.Server) bool {
+	return desc.WireVersion != nil && desc.WireVersion.Max >= 8
+}
+
+// markUnknown handles a "not master" or "node is recovering" error from the server by updating the
+// description to desc, which must have a Kind of Unknown, and requesting an immediate check. The
+// connection pool is only invalidated if clearPool is true, and then only up to the generation of c,
+// the connection the error occurred on, which can be nil.
+func (s *Server) markUnknown(desc description.Server, c *connection, clearPool bool) {
+	s.setDescription(desc)
+	s.RequestImmediateCheck()
+	if clearPool {
+		s.clearPool(c, true)
+	}
+}
+
+// clearPool clears the connection pool because of an error on c. If c is nil, the whole pool is
+// cleared. Otherwise only connections from c's generation or earlier are cleared, so connections
+// established since the pool was last cleared stay usable. If invalidate is true, the cleared
+// connections can never be refreshed. If the server is a load balancer, only the connections to the
+// service c is connected to are cleared.
+func (s *Server) clearPool(c *connection, invalidate bool) {
+	switch {
+	case s.cfg.loadBalanced && c != nil && !c.desc.ServiceID.IsZero():
+		s.pool.clearService(c.desc.ServiceID)
+	case c == nil && invalidate:
+		s.pool.invalidate()
+	case c == nil:
+		s.pool.drain()
+	case s.pool.clear(c.generation) && invalidate:
+		s.pool.markInvalid(c.generation + 1)
 	}
 }
 
@@ -441,6 +585,22 @@ func (s *Server) update() {
 // parameter is used to determine if this is the first description from the
 // server.
 func (s *Server) updateDescription(desc description.Server, initial bool) {
+	s.setDescription(desc)
+
+	if initial {
+		// We don't clear the pool on the first update on the description.
+		return
+	}
+
+	switch desc.Kind {
+	case description.Unknown:
+		s.pool.drain()
+	}
+}
+
+// setDescription stores desc as the Server's description and notifies the topology and
+// subscribers, without draining the connection pool.
+func (s *Server) setDescription(desc description.Server) {
 	defer func() {
 		//  ¯\_(ツ)_/¯
 		_ = recover()
@@ -462,16 +622,6 @@ func (s *Server) updateDescription(desc description.Server, initial bool) {
 		c <- desc
 	}
 	s.subLock.Unlock()
-
-	if initial {
-		// We don't clear the pool on the first update on the description.
-		return
-	}
-
-	switch desc.Kind {
-	case description.Unknown:
-		s.pool.drain()
-	}
 }
 
 // heartbeat sends a heartbeat to the server using the given connection. The connection can be nil.
@@ -482,6 +632,8 @@ func (s *Server) heartbeat(conn *connection) (description.Server, *connection) {
 	var set bool
 	var err error
 	ctx := context.Background()
+	// Only clear the pool if it hasn't been cleared since this heartbeat started.
+	generation := atomic.LoadUint64(&s.pool.generation)
 
 	for i := 1; i <= maxRetry; i++ {
 		if conn != nil && conn.expired() {
@@ -516,7 +668,7 @@ func (s *Server) heartbeat(conn *connection) (description.Server, *connection) {
 				}
 				conn = nil
 				if _, ok := err.(ConnectionError); ok {
-					s.pool.drain()
+					s.pool.clear(generation)
 					// If the server is not connected, give up and exit loop
 					if s.Description().Kind == description.Unknown {
 						break
@@ -542,7 +694,7 @@ func (s *Server) heartbeat(conn *connection) (description.Server, *connection) {
 			}
 			conn = nil
 			if _, ok := err.(ConnectionError); ok {
-				s.pool.drain()
+				s.pool.clear(generation)
 				// If the server is not connected, give up and exit loop
 				if s.Description().Kind == description.Unknown {
 					break
@@ -577,9 +729,12 @@ func (s *Server) heartbeat(conn *connection) (description.Server, *connection) {
 	return desc, conn
 }
 
+// updateAverageRTT folds delay into the server's exponentially-weighted moving average round trip
+// time, using an alpha of 0.2. The first sample becomes the average.
 func (s *Server) updateAverageRTT(delay time.Duration) time.Duration {
 	if !s.averageRTTSet {
 		s.averageRTT = delay
+		s.averageRTTSet = true
 	} else {
 		alpha := 0.2
 		s.averageRTT = time.Duration(alpha*float64(delay) + (1-alpha)*float64(s.averageRTT))
@@ -601,6 +756,9 @@ func (s *Server) Drain() error {
 	return nil
 }
 
+// PoolStats returns the current PoolStats of this server's connection pool.
+func (s *Server) PoolStats() PoolStats { return s.pool.stats() }
+
 // String implements the Stringer interface.
 func (s *Server) String() string {
 	desc := s.Description()
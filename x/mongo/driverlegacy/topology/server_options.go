@@ -11,20 +11,27 @@ import (
 
 	"github.com/lakshay2395/mongo-go-driver/bson"
 	"github.com/lakshay2395/mongo-go-driver/bson/bsoncodec"
+	"github.com/lakshay2395/mongo-go-driver/event"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
 )
 
 var defaultRegistry = bson.NewRegistryBuilder().Build()
 
 type serverConfig struct {
+	checkoutFn        CheckoutFunc
 	clock             *session.ClusterClock
 	compressionOpts   []string
 	connectionOpts    []ConnectionOption
 	appname           string
 	heartbeatInterval time.Duration
 	heartbeatTimeout  time.Duration
+	loadBalanced      bool
 	maxConns          uint16
+	maxConnecting     uint16
 	maxIdleConns      uint16
+	minConns          uint16
+	poolMonitor       *event.PoolMonitor
+	refreshConns      bool
 	registry          *bsoncodec.Registry
 }
 
@@ -33,6 +40,7 @@ func newServerConfig(opts ...ServerOption) (*serverConfig, error) {
 		heartbeatInterval: 10 * time.Second,
 		heartbeatTimeout:  10 * time.Second,
 		maxConns:          100,
+		maxConnecting:     defaultMaxConnecting,
 		maxIdleConns:      100,
 		registry:          defaultRegistry,
 	}
@@ -58,6 +66,37 @@ func WithConnectionOptions(fn func(...ConnectionOption) []ConnectionOption) Serv
 	}
 }
 
+// WithServerAppName sets the application name which gets sent to MongoDB by the server's
+// monitoring connection. The name must not be longer than 128 bytes.
+func WithServerAppName(fn func(string) string) ServerOption {
+	return func(cfg *serverConfig) error {
+		appname := fn(cfg.appname)
+		if err := validateAppName(appname); err != nil {
+			return err
+		}
+		cfg.appname = appname
+		return nil
+	}
+}
+
+// WithCheckoutFunc configures a function that is called each time a connection is checked out of
+// the server's pool.
+func WithCheckoutFunc(fn func(CheckoutFunc) CheckoutFunc) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.checkoutFn = fn(cfg.checkoutFn)
+		return nil
+	}
+}
+
+// WithPoolMonitor configures a monitor that is notified of connection lifecycle events in the
+// server's pool.
+func WithPoolMonitor(fn func(*event.PoolMonitor) *event.PoolMonitor) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.poolMonitor = fn(cfg.poolMonitor)
+		return nil
+	}
+}
+
 // WithCompressionOptions configures the server's compressors.
 func WithCompressionOptions(fn func(...string) []string) ServerOption {
 	return func(cfg *serverConfig) error {
@@ -93,6 +132,15 @@ func WithMaxConnections(fn func(uint16) uint16) ServerOption {
 	}
 }
 
+// WithMaxConnecting configures the maximum number of connections that a server's pool will
+// establish concurrently. If max is 0, the default of 2 is used.
+func WithMaxConnecting(fn func(uint16) uint16) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.maxConnecting = fn(cfg.maxConnecting)
+		return nil
+	}
+}
+
 // WithMaxIdleConnections configures the maximum number of idle connections
 // allowed for the server.
 func WithMaxIdleConnections(fn func(uint16) uint16) ServerOption {
@@ -102,6 +150,36 @@ func WithMaxIdleConnections(fn func(uint16) uint16) ServerOption {
 	}
 }
 
+// WithMinConnections configures the number of idle connections that a server's pool establishes in
+// the background when the server connects. The connections are dialed no more than maxConnecting at
+// a time, and no more than the maximum number of idle connections are kept.
+func WithMinConnections(fn func(uint16) uint16) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.minConns = fn(cfg.minConns)
+		return nil
+	}
+}
+
+// WithLoadBalanced configures whether the server is a load balancer in front of one or more
+// services. Errors on a connection to a load balancer only clear the connections to the service the
+// failing connection is connected to.
+func WithLoadBalanced(fn func(bool) bool) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.loadBalanced = fn(cfg.loadBalanced)
+		return nil
+	}
+}
+
+// WithRefreshConnections configures whether a connection that completes a round trip after the
+// server's pool was drained because of a transient failure is kept instead of being discarded.
+// Connections drained because the server stepped down or shut down are always discarded.
+func WithRefreshConnections(fn func(bool) bool) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.refreshConns = fn(cfg.refreshConns)
+		return nil
+	}
+}
+
 // WithClock configures the ClusterClock for the server to use.
 func WithClock(fn func(clock *session.ClusterClock) *session.ClusterClock) ServerOption {
 	return func(cfg *serverConfig) error {
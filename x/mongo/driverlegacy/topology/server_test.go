@@ -8,15 +8,19 @@ package topology
 
 import (
 	"context"
+	"errors"
 	"net"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+	"github.com/lakshay2395/mongo-go-driver/bson/primitive"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driver"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/auth"
 	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/command"
 	connectionlegacy "github.com/lakshay2395/mongo-go-driver/x/network/connection"
 	"github.com/lakshay2395/mongo-go-driver/x/network/description"
 	"github.com/lakshay2395/mongo-go-driver/x/network/result"
@@ -75,8 +79,8 @@ func TestServer(t *testing.T) {
 		{"network_error_desc", false, true, true},
 	}
 
-	authErr := ConnectionError{Wrapped: &auth.Error{}}
-	netErr := ConnectionError{Wrapped: &net.AddrError{}}
+	authErr := ConnectionError{Addr: "localhost", Wrapped: &auth.Error{}, message: "handshake failed"}
+	netErr := ConnectionError{Addr: "localhost", Wrapped: &net.AddrError{}, message: "failed to dial"}
 	for _, tt := range serverTestTable {
 		t.Run(tt.name, func(t *testing.T) {
 			s, err := NewServer(
@@ -184,6 +188,158 @@ func TestServer(t *testing.T) {
 			t.Errorf("Expected pool to not be drained. got %d; want %d", s.pool.generation, 0)
 		}
 	})
+	t.Run("stepdown", func(t *testing.T) {
+		testCases := []struct {
+			name      string
+			maxWire   int32
+			err       error
+			clearPool bool
+		}{
+			{"not master on 4.0", 7, driver.Error{Code: 10107, Message: "not master"}, true},
+			{"not master on 4.2", 8, driver.Error{Code: 10107, Message: "not master"}, false},
+			{"legacy not master on 4.0", 7, command.Error{Code: 10107, Message: "not master"}, true},
+			{"legacy not master on 4.2", 8, command.Error{Code: 10107, Message: "not master"}, false},
+			{"shutdown in progress on 4.2", 8, driver.Error{Code: 91, Message: "shutdown in progress"}, true},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				s, err := NewServer(address.Address("localhost"))
+				require.NoError(t, err)
+				s.connectionstate = connected
+				s.pool.connected = connected
+				s.desc.Store(description.Server{
+					Addr:        s.address,
+					Kind:        description.RSPrimary,
+					WireVersion: &description.VersionRange{Max: tc.maxWire},
+				})
+
+				if cerr, ok := tc.err.(command.Error); ok {
+					(&sconn{s: s}).processErr(cerr)
+				} else {
+					s.ProcessError(tc.err, nil)
+				}
+
+				desc := s.Description()
+				require.Equal(t, description.ServerKind(description.Unknown), desc.Kind)
+				require.Equal(t, tc.err, desc.LastError)
+				cleared := s.pool.generation != 0
+				require.Equal(t, tc.clearPool, cleared, "unexpected pool clear")
+			})
+		}
+	})
+	t.Run("stale topologyVersion errors are ignored", func(t *testing.T) {
+		s, err := NewServer(address.Address("localhost"))
+		require.NoError(t, err)
+		s.connectionstate = connected
+		s.pool.connected = connected
+
+		pid := primitive.NewObjectID()
+		newer := driver.Error{
+			Code:            10107,
+			Message:         "not master",
+			TopologyVersion: &description.TopologyVersion{ProcessID: pid, Counter: 5},
+		}
+		stale := driver.Error{
+			Code:            10107,
+			Message:         "not master (stale)",
+			TopologyVersion: &description.TopologyVersion{ProcessID: pid, Counter: 3},
+		}
+
+		s.ProcessError(newer, nil)
+		desc := s.Description()
+		require.Equal(t, description.ServerKind(description.Unknown), desc.Kind)
+		require.Equal(t, newer, desc.LastError)
+		require.Equal(t, newer.TopologyVersion, desc.TopologyVersion)
+		generation := s.pool.generation
+
+		s.ProcessError(stale, nil)
+		desc = s.Description()
+		require.Equal(t, newer, desc.LastError, "stale error should not replace the newer one")
+		require.Equal(t, newer.TopologyVersion, desc.TopologyVersion)
+		require.Equal(t, generation, s.pool.generation, "stale error should not drain the pool")
+	})
+	t.Run("errors only clear the failing connection's generation", func(t *testing.T) {
+		cleanup := make(chan struct{})
+		defer close(cleanup)
+		addr := bootstrapConnections(t, 2, func(nc net.Conn) {
+			<-cleanup
+			nc.Close()
+		})
+		s, err := NewServer(address.Address(addr.String()))
+		require.NoError(t, err)
+		s.connectionstate = connected
+		err = s.pool.connect()
+		require.NoError(t, err)
+		primary := description.Server{Addr: s.address, Kind: description.RSPrimary}
+		s.desc.Store(primary)
+
+		older, err := s.pool.get(context.Background())
+		require.NoError(t, err)
+		networkErr := driver.Error{Message: "connection reset", Labels: []string{driver.NetworkError}}
+		s.ProcessError(networkErr, &Connection{connection: older, s: s})
+		require.Equal(t, description.ServerKind(description.Unknown), s.Description().Kind)
+		require.True(t, s.pool.expired(older.generation), "failing connection should be cleared")
+
+		newer, err := s.pool.get(context.Background())
+		require.NoError(t, err)
+		s.desc.Store(primary)
+		generation := s.pool.generation
+
+		// A second error from the already cleared connection must not clear connections established
+		// since, or mark the server unknown again.
+		s.ProcessError(networkErr, &Connection{connection: older, s: s})
+		require.Equal(t, generation, s.pool.generation, "stale connection error should not clear the pool")
+		require.False(t, s.pool.expired(newer.generation), "newer connection should stay usable")
+		require.Equal(t, description.ServerKind(description.RSPrimary), s.Description().Kind)
+	})
+	t.Run("load balanced errors only clear the failing service", func(t *testing.T) {
+		cleanup := make(chan struct{})
+		defer close(cleanup)
+		addr := bootstrapConnections(t, 2, func(nc net.Conn) {
+			<-cleanup
+			nc.Close()
+		})
+		s, err := NewServer(address.Address(addr.String()), WithLoadBalanced(func(bool) bool { return true }))
+		require.NoError(t, err)
+		s.connectionstate = connected
+		err = s.pool.connect()
+		require.NoError(t, err)
+
+		a, err := s.pool.get(context.Background())
+		require.NoError(t, err)
+		b, err := s.pool.get(context.Background())
+		require.NoError(t, err)
+		a.desc.ServiceID = primitive.NewObjectID()
+		b.desc.ServiceID = primitive.NewObjectID()
+		generation := s.pool.generation
+
+		networkErr := driver.Error{Message: "connection reset", Labels: []string{driver.NetworkError}}
+		s.ProcessError(networkErr, &Connection{connection: a, s: s})
+		require.True(t, s.pool.stale(a), "connection to the failing service should be cleared")
+		require.False(t, s.pool.stale(b), "connection to another service should stay usable")
+		require.Equal(t, generation, s.pool.generation, "pool generation should not change")
+	})
+	t.Run("average RTT", func(t *testing.T) {
+		var s Server
+		samples := []struct {
+			rtt  time.Duration
+			want time.Duration
+		}{
+			{100 * time.Millisecond, 100 * time.Millisecond},
+			{200 * time.Millisecond, 120 * time.Millisecond},
+			{50 * time.Millisecond, 106 * time.Millisecond},
+			{106 * time.Millisecond, 106 * time.Millisecond},
+		}
+		for _, sample := range samples {
+			got := s.updateAverageRTT(sample.rtt)
+			require.Equal(t, sample.want, got, "unexpected average after a %v sample", sample.rtt)
+		}
+
+		s.desc.Store(description.Server{}.SetAverageRTT(s.averageRTT))
+		rtt, ok := s.AverageRTT()
+		require.True(t, ok)
+		require.Equal(t, 106*time.Millisecond, rtt)
+	})
 	t.Run("update topology", func(t *testing.T) {
 		var updated atomic.Value // bool
 		updated.Store(false)
@@ -193,3 +349,106 @@ func TestServer(t *testing.T) {
 		require.True(t, updated.Load().(bool))
 	})
 }
+
+func TestHeartbeatBackoff(t *testing.T) {
+	// heartbeat fails three times, succeeds, and then fails again.
+	results := []bool{false, false, false, true, false}
+	var calls int
+	heartbeat := func() bool {
+		ok := results[calls]
+		calls++
+		return ok
+	}
+
+	backoff := heartbeatBackoff{min: minHeartbeatInterval, max: 2 * time.Second}
+	var clock time.Duration
+	var heartbeats []time.Duration
+	for range results {
+		heartbeats = append(heartbeats, clock)
+		clock += backoff.next(!heartbeat())
+	}
+
+	want := []time.Duration{
+		0,
+		500 * time.Millisecond,
+		1500 * time.Millisecond,
+		3500 * time.Millisecond, // capped at the heartbeat interval
+		5500 * time.Millisecond, // full heartbeat interval after recovery
+	}
+	require.Equal(t, want, heartbeats)
+	require.Equal(t, 5500*time.Millisecond+minHeartbeatInterval, clock, "backoff should reset after recovery")
+}
+
+// fakeTimer is a timer that only fires when fire is called. The duration it is armed with, when it
+// is created and every time it is reset, is sent on armed.
+type fakeTimer struct {
+	c     chan time.Time
+	armed chan time.Duration
+}
+
+func newFakeTimer(d time.Duration) *fakeTimer {
+	t := &fakeTimer{c: make(chan time.Time, 1), armed: make(chan time.Duration, 1)}
+	t.armed <- d
+	return t
+}
+
+func (t *fakeTimer) Chan() <-chan time.Time { return t.c }
+func (t *fakeTimer) Stop() bool             { return len(t.c) == 0 }
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.armed <- d
+	return true
+}
+
+func (t *fakeTimer) fire() { t.c <- time.Time{} }
+
+func TestServerMonitorHeartbeatBackoff(t *testing.T) {
+	s, err := NewServer(
+		address.Address("localhost:27017"),
+		WithHeartbeatInterval(func(time.Duration) time.Duration { return 10 * time.Second }),
+	)
+	require.NoError(t, err)
+
+	failed := description.Server{Addr: s.address, Kind: description.Unknown, LastError: errors.New("connection refused")}
+	healthy := description.Server{Addr: s.address, Kind: description.Standalone}
+	// The initial heartbeat and the two after it fail, the server recovers, and then fails again.
+	results := []description.Server{failed, failed, failed, healthy, failed}
+	var probes int32
+	s.heartbeatFn = func(conn *connection) (description.Server, *connection) {
+		desc := results[atomic.LoadInt32(&probes)]
+		atomic.AddInt32(&probes, 1)
+		return desc, conn
+	}
+	timers := make(chan *fakeTimer, 2)
+	s.newTimer = func(d time.Duration) timer {
+		t := newFakeTimer(d)
+		timers <- t
+		return t
+	}
+	require.NoError(t, s.Connect(nil))
+	defer func() { _ = s.Disconnect(context.Background()) }()
+
+	rateLimiter, heartbeatTimer := <-timers, <-timers
+	want := []time.Duration{
+		minHeartbeatInterval,
+		2 * minHeartbeatInterval,
+		4 * minHeartbeatInterval,
+		10 * time.Second,     // full heartbeat interval after recovery
+		minHeartbeatInterval, // backoff starts over
+	}
+	for i, wait := range want {
+		require.Equal(t, minHeartbeatInterval, <-rateLimiter.armed, "rate limit after heartbeat %d", i)
+		require.Equal(t, wait, <-heartbeatTimer.armed, "wait after heartbeat %d", i)
+		require.Equal(t, int32(i+1), atomic.LoadInt32(&probes), "heartbeats sent")
+		desc := s.Description()
+		require.Equal(t, results[i].Kind, desc.Kind, "description after heartbeat %d", i)
+		require.Equal(t, results[i].LastError, desc.LastError, "description after heartbeat %d", i)
+		if i == len(want)-1 {
+			break
+		}
+
+		// The next heartbeat waits for both the backoff and the rate limiter.
+		heartbeatTimer.fire()
+		rateLimiter.fire()
+	}
+}
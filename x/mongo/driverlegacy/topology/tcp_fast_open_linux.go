@@ -0,0 +1,29 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build go1.11
+// +build go1.11
+
+package topology
+
+import (
+	"net"
+	"syscall"
+)
+
+// tcpFastOpenConnect is the Linux TCP_FASTOPEN_CONNECT socket option, which sends the first write of
+// a connection in the SYN when the kernel has a fast open cookie for the server.
+const tcpFastOpenConnect = 30
+
+// enableTCPFastOpen sets TCP_FASTOPEN_CONNECT on sockets created by d. Kernels that do not support
+// the option reject it, in which case the connection is made without fast open.
+func enableTCPFastOpen(d *net.Dialer) {
+	d.Control = func(network, address string, c syscall.RawConn) error {
+		return c.Control(func(fd uintptr) {
+			_ = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, tcpFastOpenConnect, 1)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build !linux || !go1.11
+// +build !linux !go1.11
+
+package topology
+
+import "net"
+
+// enableTCPFastOpen does nothing on platforms where requesting TCP Fast Open is not supported.
+func enableTCPFastOpen(*net.Dialer) {}
@@ -13,7 +13,9 @@ package topology // import "github.com/lakshay2395/mongo-go-driver/x/mongo/drive
 import (
 	"context"
 	"errors"
+	"expvar"
 	"math/rand"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -22,6 +24,7 @@ import (
 	"fmt"
 
 	"github.com/lakshay2395/mongo-go-driver/bson/bsoncodec"
+	"github.com/lakshay2395/mongo-go-driver/tag"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driver"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/dns"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
@@ -45,6 +48,60 @@ var ErrTopologyConnected = errors.New("topology is connected or connecting")
 // selection process took longer than allowed by the timeout.
 var ErrServerSelectionTimeout = errors.New("server selection timeout")
 
+// ErrPinnedServerNotFound is returned when a session is pinned to a server that is no longer part of
+// the topology.
+var ErrPinnedServerNotFound = errors.New("pinned server is no longer part of the topology")
+
+// PoolStatsExpvarName is the name of the expvar variable under which topologies configured with
+// WithPoolStatsExpvar publish their pool metrics, keyed by the name each topology was configured
+// with. The variable is only published once such a topology connects.
+const PoolStatsExpvarName = "mongo-go-driver.pools"
+
+// The pool stats of connected topologies are kept in a map of our own rather than in an expvar.Map
+// because entries cannot be removed from an expvar.Map before Go 1.12.
+var (
+	poolStatsOnce sync.Once
+	poolStatsLock sync.Mutex
+	poolStats     = make(map[string]func() interface{})
+)
+
+// publishPoolStats adds the pool stats returned by fn under name, publishing the expvar variable
+// first if necessary.
+func publishPoolStats(name string, fn func() interface{}) error {
+	poolStatsOnce.Do(func() { expvar.Publish(PoolStatsExpvarName, expvar.Func(allPoolStats)) })
+
+	poolStatsLock.Lock()
+	defer poolStatsLock.Unlock()
+	if _, ok := poolStats[name]; ok {
+		return fmt.Errorf("pool stats named %q are already published", name)
+	}
+	poolStats[name] = fn
+	return nil
+}
+
+// unpublishPoolStats removes the pool stats published under name.
+func unpublishPoolStats(name string) {
+	poolStatsLock.Lock()
+	delete(poolStats, name)
+	poolStatsLock.Unlock()
+}
+
+// allPoolStats returns the published pool stats keyed by name.
+func allPoolStats() interface{} {
+	poolStatsLock.Lock()
+	fns := make(map[string]func() interface{}, len(poolStats))
+	for name, fn := range poolStats {
+		fns[name] = fn
+	}
+	poolStatsLock.Unlock()
+
+	stats := make(map[string]interface{}, len(fns))
+	for name, fn := range fns {
+		stats[name] = fn()
+	}
+	return stats
+}
+
 // MonitorMode represents the way in which a server is monitored.
 type MonitorMode uint8
 
@@ -132,6 +189,13 @@ func (t *Topology) Connect() error {
 		return ErrTopologyConnected
 	}
 
+	if t.cfg.expvarNamespace != "" {
+		if err := publishPoolStats(t.cfg.expvarNamespace, t.poolMetrics); err != nil {
+			atomic.StoreInt32(&t.connectionstate, disconnected)
+			return err
+		}
+	}
+
 	t.desc.Store(description.Topology{})
 	var err error
 	t.serversLock.Lock()
@@ -191,6 +255,10 @@ func (t *Topology) Disconnect(ctx context.Context) error {
 
 	t.desc.Store(description.Topology{})
 
+	if t.cfg.expvarNamespace != "" {
+		unpublishPoolStats(t.cfg.expvarNamespace)
+	}
+
 	atomic.StoreInt32(&t.connectionstate, disconnected)
 	return nil
 }
@@ -348,6 +416,24 @@ func (t *Topology) SelectServerLegacy(ctx context.Context, ss description.Server
 	}
 }
 
+// SelectPinnedServerLegacy returns the server described by pinned, which is the server a session is
+// pinned to. If pinned is nil, a server is selected with ss instead. If the pinned server has left the
+// topology, ErrPinnedServerNotFound is returned rather than waiting for the server selection timeout.
+func (t *Topology) SelectPinnedServerLegacy(ctx context.Context, pinned *description.Server, ss description.ServerSelector) (*SelectedServer, error) {
+	if pinned == nil {
+		return t.SelectServerLegacy(ctx, ss)
+	}
+
+	selected, err := t.FindServer(*pinned)
+	if err != nil {
+		return nil, err
+	}
+	if selected == nil {
+		return nil, ErrPinnedServerNotFound
+	}
+	return selected, nil
+}
+
 // FindServer will attempt to find a server that fits the given server description.
 // This method will return nil, nil if a matching server could not be found.
 func (t *Topology) FindServer(selected description.Server) (*SelectedServer, error) {
@@ -590,10 +676,105 @@ func (t *Topology) addServer(addr address.Address) error {
 
 // String implements the Stringer interface
 func (t *Topology) String() string {
+	return t.Describe().String()
+}
+
+// poolMetrics returns the PoolStats of each server's connection pool keyed by address, in the form
+// published with expvar. Durations are reported in milliseconds.
+func (t *Topology) poolMetrics() interface{} {
+	t.serversLock.Lock()
+	stats := make(map[address.Address]PoolStats, len(t.servers))
+	for addr, s := range t.servers {
+		if s != nil {
+			stats[addr] = s.PoolStats()
+		}
+	}
+	t.serversLock.Unlock()
+
+	metrics := make(map[string]map[string]interface{}, len(stats))
+	for addr, ps := range stats {
+		metrics[addr.String()] = map[string]interface{}{
+			"opened":            ps.Opened,
+			"available":         ps.Available,
+			"inUse":             ps.InUse,
+			"generation":        ps.Generation,
+			"checkoutWaitP99Ms": float64(ps.CheckoutWaitP99) / float64(time.Millisecond),
+		}
+	}
+	return metrics
+}
+
+// Snapshot is a point in time view of a Topology intended for debugging, for example to explain why
+// server selection failed.
+type Snapshot struct {
+	Kind    description.TopologyKind
+	Servers []ServerSnapshot
+}
+
+// ServerSnapshot is a point in time view of a single server in a Topology. State is the connection
+// state of the server's monitor and is empty if the Topology is not monitoring the server.
+type ServerSnapshot struct {
+	Addr        address.Address
+	Kind        description.ServerKind
+	State       string
+	WireVersion *description.VersionRange
+	AverageRTT  time.Duration
+	Tags        tag.Set
+	LastError   error
+}
+
+// Describe returns a Snapshot of the current topology description. Servers are sorted by address.
+func (t *Topology) Describe() Snapshot {
 	desc := t.Description()
-	str := fmt.Sprintf("Type: %s\nServers:\n", desc.Kind)
-	for _, s := range t.servers {
-		str += s.String() + "\n"
+	snapshot := Snapshot{Kind: desc.Kind, Servers: make([]ServerSnapshot, 0, len(desc.Servers))}
+
+	t.serversLock.Lock()
+	for _, server := range desc.Servers {
+		ss := ServerSnapshot{
+			Addr:        server.Addr,
+			Kind:        server.Kind,
+			WireVersion: server.WireVersion,
+			AverageRTT:  server.AverageRTT,
+			Tags:        server.Tags,
+			LastError:   server.LastError,
+		}
+		if s, ok := t.servers[server.Addr]; ok {
+			ss.State = connectionStateString(atomic.LoadInt32(&s.connectionstate))
+		}
+		snapshot.Servers = append(snapshot.Servers, ss)
+	}
+	t.serversLock.Unlock()
+
+	sort.Slice(snapshot.Servers, func(i, j int) bool {
+		return snapshot.Servers[i].Addr < snapshot.Servers[j].Addr
+	})
+	return snapshot
+}
+
+// String formats the snapshot with one line per server.
+func (s Snapshot) String() string {
+	str := fmt.Sprintf("Type: %s\nServers:\n", s.Kind)
+	for _, server := range s.Servers {
+		str += server.String() + "\n"
+	}
+	return str
+}
+
+// String formats the server snapshot, omitting fields that are not set.
+func (s ServerSnapshot) String() string {
+	str := fmt.Sprintf("Addr: %s, Type: %s", s.Addr, s.Kind)
+	if s.State != "" {
+		str += fmt.Sprintf(", State: %s", s.State)
+	}
+	if s.WireVersion != nil {
+		str += fmt.Sprintf(", Wire version: %s", s.WireVersion)
+	}
+	if len(s.Tags) != 0 {
+		str += fmt.Sprintf(", Tag sets: %s", s.Tags)
+	}
+	str += fmt.Sprintf(", Average RTT: %s", s.AverageRTT)
+	if s.LastError != nil {
+		str += fmt.Sprintf(", Last error: %s", s.LastError)
 	}
 	return str
 }
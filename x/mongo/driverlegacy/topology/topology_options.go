@@ -28,6 +28,7 @@ type config struct {
 	serverOpts             []ServerOption
 	cs                     connstring.ConnString
 	serverSelectionTimeout time.Duration
+	expvarNamespace        string
 }
 
 func newConfig(opts ...Option) (*config, error) {
@@ -59,7 +60,11 @@ func WithConnString(fn func(connstring.ConnString) connstring.ConnString) Option
 		var connOpts []ConnectionOption
 
 		if cs.AppName != "" {
+			if err := validateAppName(cs.AppName); err != nil {
+				return err
+			}
 			connOpts = append(connOpts, WithAppName(func(string) string { return cs.AppName }))
+			c.serverOpts = append(c.serverOpts, WithServerAppName(func(string) string { return cs.AppName }))
 		}
 
 		switch cs.Connect {
@@ -86,6 +91,10 @@ func WithConnString(fn func(connstring.ConnString) connstring.ConnString) Option
 			c.serverOpts = append(c.serverOpts, WithHeartbeatInterval(func(time.Duration) time.Duration { return cs.HeartbeatInterval }))
 		}
 
+		if cs.LoadBalanced {
+			c.serverOpts = append(c.serverOpts, WithLoadBalanced(func(bool) bool { return true }))
+		}
+
 		if cs.MaxConnIdleTime > 0 {
 			connOpts = append(connOpts, WithIdleTimeout(func(time.Duration) time.Duration { return cs.MaxConnIdleTime }))
 		}
@@ -176,6 +185,7 @@ func WithConnString(fn func(connstring.ConnString) connstring.ConnString) Option
 					AppName:       cs.AppName,
 					Authenticator: authenticator,
 					Compressors:   cs.Compressors,
+					LoadBalanced:  cs.LoadBalanced,
 				}
 				if cs.AuthMechanism == "" {
 					// Required for SASL mechanism negotiation during handshake
@@ -186,7 +196,7 @@ func WithConnString(fn func(connstring.ConnString) connstring.ConnString) Option
 		} else {
 			// We need to add a non-auth Handshaker to the connection options
 			connOpts = append(connOpts, WithHandshaker(func(h driver.Handshaker) driver.Handshaker {
-				return driver.IsMaster().AppName(cs.AppName).Compressors(cs.Compressors)
+				return driver.IsMaster().AppName(cs.AppName).Compressors(cs.Compressors).LoadBalanced(cs.LoadBalanced)
 			}))
 		}
 
@@ -251,6 +261,17 @@ func WithServerOptions(fn func(...ServerOption) []ServerOption) Option {
 	}
 }
 
+// WithPoolStatsExpvar configures the topology to publish the PoolStats of each server's connection
+// pool under the given key of the PoolStatsExpvarName expvar variable while it is connected.
+// Publishing is disabled by default and when the name is empty. Connected topologies must use
+// different names.
+func WithPoolStatsExpvar(fn func(string) string) Option {
+	return func(cfg *config) error {
+		cfg.expvarNamespace = fn(cfg.expvarNamespace)
+		return nil
+	}
+}
+
 // WithServerSelectionTimeout configures a topology's server selection timeout.
 // A server selection timeout of 0 means there is no timeout for server selection.
 func WithServerSelectionTimeout(fn func(time.Duration) time.Duration) Option {
@@ -7,6 +7,7 @@
 package topology
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -31,3 +32,33 @@ func TestOptionsSetting(t *testing.T) {
 
 	assert.Equal(t, ssts, conf.serverSelectionTimeout)
 }
+
+func TestOptionsAppName(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		cfg, err := newConfig(WithConnString(func(connstring.ConnString) connstring.ConnString {
+			return connstring.ConnString{AppName: "analytics-worker-1"}
+		}))
+		assert.NoError(t, err)
+
+		srvCfg, err := newServerConfig(cfg.serverOpts...)
+		assert.NoError(t, err)
+		assert.Equal(t, "analytics-worker-1", srvCfg.appname)
+	})
+	t.Run("too long", func(t *testing.T) {
+		_, err := newConfig(WithConnString(func(connstring.ConnString) connstring.ConnString {
+			return connstring.ConnString{AppName: strings.Repeat("a", 129)}
+		}))
+		assert.Equal(t, ErrAppNameTooLong, err)
+
+		_, err = newConnectionConfig(WithAppName(func(string) string { return strings.Repeat("a", 129) }))
+		assert.Equal(t, ErrAppNameTooLong, err)
+
+		_, err = newServerConfig(WithServerAppName(func(string) string { return strings.Repeat("a", 129) }))
+		assert.Equal(t, ErrAppNameTooLong, err)
+	})
+	t.Run("128 bytes", func(t *testing.T) {
+		cfg, err := newConnectionConfig(WithAppName(func(string) string { return strings.Repeat("a", 128) }))
+		assert.NoError(t, err)
+		assert.Len(t, cfg.appName, 128)
+	})
+}
@@ -8,7 +8,11 @@ package topology
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"expvar"
+	"net"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -241,6 +245,46 @@ func TestServerSelection(t *testing.T) {
 			t.Errorf("findServer does not properly set the topology description kind. got %v; want %v", ss.Kind, description.Single)
 		}
 	})
+	t.Run("SelectPinnedServerLegacy", func(t *testing.T) {
+		topo, err := New()
+		noerr(t, err)
+		atomic.StoreInt32(&topo.connectionstate, connected)
+		for _, addr := range []address.Address{"one", "two"} {
+			srvr, err := ConnectServer(addr, func(desc description.Server) { topo.apply(context.Background(), desc) })
+			noerr(t, err)
+			topo.servers[addr] = srvr
+		}
+		topo.desc.Store(description.Topology{
+			Kind: description.Sharded,
+			Servers: []description.Server{
+				{Addr: address.Address("one"), Kind: description.Mongos},
+				{Addr: address.Address("two"), Kind: description.Mongos},
+			},
+		})
+
+		t.Run("selects the pinned server", func(t *testing.T) {
+			pinned := &description.Server{Addr: address.Address("two")}
+			ss, err := topo.SelectPinnedServerLegacy(context.Background(), pinned, selectFirst)
+			noerr(t, err)
+			if ss.Description().Addr != pinned.Addr {
+				t.Errorf("Incorrect server selected. got %s; want %s", ss.Description().Addr, pinned.Addr)
+			}
+		})
+		t.Run("uses the selector when not pinned", func(t *testing.T) {
+			ss, err := topo.SelectPinnedServerLegacy(context.Background(), nil, selectFirst)
+			noerr(t, err)
+			if ss.Description().Addr != address.Address("one") {
+				t.Errorf("Incorrect server selected. got %s; want %s", ss.Description().Addr, "one")
+			}
+		})
+		t.Run("errors when the pinned server has left", func(t *testing.T) {
+			pinned := &description.Server{Addr: address.Address("three")}
+			_, err := topo.SelectPinnedServerLegacy(context.Background(), pinned, selectFirst)
+			if err != ErrPinnedServerNotFound {
+				t.Errorf("Did not get expected error. got %v; want %v", err, ErrPinnedServerNotFound)
+			}
+		})
+	})
 	t.Run("Update on not master error", func(t *testing.T) {
 		topo, err := New()
 		noerr(t, err)
@@ -439,3 +483,126 @@ func TestSessionTimeout(t *testing.T) {
 		}
 	})
 }
+
+func TestTopologyDescribe(t *testing.T) {
+	topo, err := New()
+	noerr(t, err)
+	lastErr := errors.New("connection refused")
+	topo.desc.Store(description.Topology{
+		Kind: description.ReplicaSetWithPrimary,
+		Servers: []description.Server{
+			{Addr: address.Address("c:27017"), Kind: description.RSSecondary, LastError: lastErr},
+			{Addr: address.Address("a:27017"), Kind: description.RSPrimary, WireVersion: &description.VersionRange{Max: 7}},
+			{Addr: address.Address("b:27017"), Kind: description.RSSecondary, AverageRTT: 5 * time.Millisecond},
+		},
+	})
+
+	snapshot := topo.Describe()
+	if snapshot.Kind != description.ReplicaSetWithPrimary {
+		t.Errorf("Incorrect topology kind. got %s; want %s", snapshot.Kind, description.ReplicaSetWithPrimary)
+	}
+	want := []struct {
+		addr address.Address
+		kind description.ServerKind
+	}{
+		{"a:27017", description.RSPrimary},
+		{"b:27017", description.RSSecondary},
+		{"c:27017", description.RSSecondary},
+	}
+	if len(snapshot.Servers) != len(want) {
+		t.Fatalf("Incorrect number of servers. got %d; want %d", len(snapshot.Servers), len(want))
+	}
+	for i, w := range want {
+		if got := snapshot.Servers[i]; got.Addr != w.addr || got.Kind != w.kind {
+			t.Errorf("Incorrect server %d. got %s (%s); want %s (%s)", i, got.Addr, got.Kind, w.addr, w.kind)
+		}
+	}
+
+	str := topo.String()
+	for _, line := range []string{
+		"Type: ReplicaSetWithPrimary",
+		"Addr: a:27017, Type: RSPrimary, Wire version: [0, 7]",
+		"Addr: b:27017, Type: RSSecondary, Average RTT: 5ms",
+		"Addr: c:27017, Type: RSSecondary, Average RTT: 0s, Last error: connection refused",
+	} {
+		if !strings.Contains(str, line) {
+			t.Errorf("Expected the topology string to contain %q. got %q", line, str)
+		}
+	}
+}
+
+func TestTopologyPoolStatsExpvar(t *testing.T) {
+	name := "pool-stats-test"
+	noSeeds := WithSeedList(func(...string) []string { return nil })
+	topo, err := New(noSeeds, WithPoolStatsExpvar(func(string) string { return name }))
+	noerr(t, err)
+	noerr(t, topo.Connect())
+
+	d := DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+		nc, _ := net.Pipe()
+		return nc, nil
+	})
+	addr := address.Address("localhost:27017")
+	srvr, err := NewServer(addr, WithConnectionOptions(func(opts ...ConnectionOption) []ConnectionOption {
+		return append(opts, WithDialer(func(Dialer) Dialer { return d }))
+	}))
+	noerr(t, err)
+	noerr(t, srvr.pool.connect())
+	topo.servers[addr] = srvr
+
+	c1, err := srvr.pool.get(context.Background())
+	noerr(t, err)
+	_, err = srvr.pool.get(context.Background())
+	noerr(t, err)
+	noerr(t, srvr.pool.put(c1))
+	generation := srvr.PoolStats().Generation
+	srvr.pool.drain()
+
+	v := expvar.Get(PoolStatsExpvarName)
+	if v == nil {
+		t.Fatalf("Expected pool stats to be published as %q", PoolStatsExpvarName)
+	}
+	type metrics map[string]struct {
+		Opened            int     `json:"opened"`
+		Available         int     `json:"available"`
+		InUse             int     `json:"inUse"`
+		Generation        uint64  `json:"generation"`
+		CheckoutWaitP99Ms float64 `json:"checkoutWaitP99Ms"`
+	}
+	var pools map[string]metrics
+	noerr(t, json.Unmarshal([]byte(v.String()), &pools))
+	got, ok := pools[name][addr.String()]
+	if !ok {
+		t.Fatalf("Expected metrics for %s under %q. got %v", addr, name, v)
+	}
+	if got.Opened != 2 || got.Available != 1 || got.InUse != 1 || got.Generation != generation+1 {
+		t.Errorf("Unexpected pool metrics. got %+v", got)
+	}
+
+	other, err := New(noSeeds, WithPoolStatsExpvar(func(string) string { return name }))
+	noerr(t, err)
+	if err = other.Connect(); err == nil {
+		t.Error("Expected an error when publishing under a name that is already used")
+	}
+
+	noerr(t, topo.Disconnect(context.Background()))
+	pools = nil
+	noerr(t, json.Unmarshal([]byte(v.String()), &pools))
+	if _, ok := pools[name]; ok {
+		t.Errorf("Expected pool stats to be removed on disconnect. got %v", v)
+	}
+	noerr(t, other.Connect())
+	noerr(t, other.Disconnect(context.Background()))
+}
+
+func TestTopologyPoolStatsExpvarDisabled(t *testing.T) {
+	var before []string
+	expvar.Do(func(kv expvar.KeyValue) { before = append(before, kv.Key) })
+	_, err := New()
+	noerr(t, err)
+	var after []string
+	expvar.Do(func(kv expvar.KeyValue) { after = append(after, kv.Key) })
+	if len(after) != len(before) {
+		t.Errorf("Expected nothing to be published by default. got %v; had %v", after, before)
+	}
+}
@@ -34,17 +34,14 @@ func Update(
 	opts ...*options.UpdateOptions,
 ) (result.Update, error) {
 
-	if cmd.Session != nil && cmd.Session.PinnedServer != nil {
-		selector = cmd.Session.PinnedServer
-	}
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return result.Update{}, err
 	}
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), false, pool, clientID)
 		if err != nil {
 			return result.Update{}, err
 		}
@@ -53,27 +50,11 @@ func Update(
 
 	updateOpts := options.MergeUpdateOptions(opts...)
 
-	if updateOpts.ArrayFilters != nil {
-		if ss.Description().WireVersion.Max < 6 {
-			return result.Update{}, ErrArrayFilters
-		}
-		filters, err := updateOpts.ArrayFilters.ToArray()
-		if err != nil {
-			return result.Update{}, err
-		}
-		arr := make(bsonx.Arr, 0, len(filters))
-		for _, filter := range filters {
-			doc, err := bsonx.ReadDoc(filter)
-			if err != nil {
-				return result.Update{}, err
-			}
-			arr = append(arr, bsonx.Document(doc))
-		}
-		cmd.Opts = append(cmd.Opts, bsonx.Elem{"arrayFilters", bsonx.Array(arr)})
-	}
-	if updateOpts.BypassDocumentValidation != nil && ss.Description().WireVersion.Includes(4) {
-		cmd.Opts = append(cmd.Opts, bsonx.Elem{"bypassDocumentValidation", bsonx.Boolean(*updateOpts.BypassDocumentValidation)})
+	cmd.Opts, err = appendArrayFilters(cmd.Opts, updateOpts.ArrayFilters, ss.Description())
+	if err != nil {
+		return result.Update{}, err
 	}
+	cmd.Opts = appendBypassDocumentValidation(cmd.Opts, updateOpts.BypassDocumentValidation, ss.Description())
 	if updateOpts.Collation != nil {
 		if ss.Description().WireVersion.Max < 5 {
 			return result.Update{}, ErrCollation
@@ -84,6 +65,10 @@ func Update(
 		}
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"collation", bsonx.Document(collDoc)})
 	}
+	cmd.Opts, err = appendWriteHint(cmd.Opts, updateOpts.Hint, cmd.WriteConcern, ss.Description(), 8)
+	if err != nil {
+		return result.Update{}, err
+	}
 	if updateOpts.Upsert != nil {
 		cmd.Opts = append(cmd.Opts, bsonx.Elem{"upsert", bsonx.Boolean(*updateOpts.Upsert)})
 	}
@@ -104,7 +89,7 @@ func Update(
 	// Retry if appropriate
 	if cerr, ok := originalErr.(command.Error); (ok && cerr.Retryable()) ||
 		(res.WriteConcernError != nil && command.IsWriteConcernErrorRetryable(res.WriteConcernError)) {
-		ss, err := topo.SelectServerLegacy(ctx, selector)
+		ss, err := selectServer(ctx, topo, cmd.Session, selector)
 
 		// Return original error if server selection fails or new server does not support retryable writes
 		if err != nil || !retrySupported(topo, ss.Description(), cmd.Session, cmd.WriteConcern) {
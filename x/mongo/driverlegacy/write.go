@@ -8,16 +8,39 @@ package driverlegacy
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/lakshay2395/mongo-go-driver/bson"
 	"github.com/lakshay2395/mongo-go-driver/mongo/writeconcern"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/topology"
 	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/uuid"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
 	"github.com/lakshay2395/mongo-go-driver/x/network/command"
 	"github.com/lakshay2395/mongo-go-driver/x/network/description"
 )
 
+// NotWritableError is returned when a write command would be sent to a server that cannot accept
+// writes, such as a replica set secondary. This usually means the selector used for the write is
+// misconfigured.
+type NotWritableError struct {
+	Address address.Address
+	Kind    description.ServerKind
+}
+
+func (e NotWritableError) Error() string {
+	return fmt.Sprintf("cannot send write to %s: server of kind %s is not writable", e.Address, e.Kind)
+}
+
+// checkWritable returns a NotWritableError if the selected server cannot accept writes.
+func checkWritable(desc description.SelectedServer) error {
+	switch desc.Server.Kind {
+	case description.RSSecondary, description.RSArbiter, description.RSMember, description.RSGhost:
+		return NotWritableError{Address: desc.Server.Addr, Kind: desc.Server.Kind}
+	}
+	return nil
+}
+
 // Write handles the full cycle dispatch and execution of a write command against the provided
 // topology.
 func Write(
@@ -29,15 +52,16 @@ func Write(
 	pool *session.Pool,
 ) (bson.Raw, error) {
 
-	if cmd.Session != nil && cmd.Session.PinnedServer != nil {
-		selector = cmd.Session.PinnedServer
-	}
-	ss, err := topo.SelectServerLegacy(ctx, selector)
+	ss, err := selectServer(ctx, topo, cmd.Session, selector)
 	if err != nil {
 		return nil, err
 	}
 
 	desc := ss.Description()
+	if err = checkWritable(desc); err != nil {
+		return nil, err
+	}
+
 	conn, err := ss.ConnectionLegacy(ctx)
 	if err != nil {
 		return nil, err
@@ -56,8 +80,8 @@ func Write(
 	defer conn.Close()
 
 	// If no explicit session and deployment supports sessions, start implicit session.
-	if cmd.Session == nil && topo.SupportsSessions() {
-		cmd.Session, err = session.NewClientSession(pool, clientID, session.Implicit)
+	if cmd.Session == nil {
+		cmd.Session, err = startImplicitSession(topo.SupportsSessions(), cmd.NoImplicitSession, pool, clientID)
 		if err != nil {
 			return nil, err
 		}
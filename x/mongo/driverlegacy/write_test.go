@@ -0,0 +1,47 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverlegacy
+
+import (
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckWritable(t *testing.T) {
+	addr := address.Address("localhost:27017")
+	testCases := []struct {
+		kind     description.ServerKind
+		writable bool
+	}{
+		{description.Standalone, true},
+		{description.RSPrimary, true},
+		{description.Mongos, true},
+		{description.RSSecondary, false},
+		{description.RSArbiter, false},
+		{description.RSMember, false},
+		{description.RSGhost, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.kind.String(), func(t *testing.T) {
+			desc := description.SelectedServer{
+				Server: description.Server{Addr: addr, Kind: tc.kind},
+				Kind:   description.ReplicaSetWithPrimary,
+			}
+			err := checkWritable(desc)
+			if tc.writable {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, NotWritableError{Address: addr, Kind: tc.kind}, err)
+			require.Contains(t, err.Error(), "not writable")
+		})
+	}
+}
@@ -26,7 +26,8 @@ func (a Address) Network() string {
 }
 
 // String is the canonical version of this address, e.g. localhost:27017,
-// 1.2.3.4:27017, example.com:27017.
+// 1.2.3.4:27017, example.com:27017, [::1]:27017. IPv6 literals are always
+// bracketed so the result can be passed directly to a dialer.
 func (a Address) String() string {
 	// TODO: unicode case folding?
 	s := strings.ToLower(string(a))
@@ -35,8 +36,13 @@ func (a Address) String() string {
 	}
 	if a.Network() != "unix" {
 		_, _, err := net.SplitHostPort(s)
-		if err != nil && strings.Contains(err.Error(), "missing port in address") {
+		switch {
+		case err == nil:
+		case strings.Contains(err.Error(), "missing port in address"):
 			s += ":" + defaultPort
+		case net.ParseIP(s) != nil:
+			// An unbracketed IPv6 literal without a port, e.g. ::1.
+			s = net.JoinHostPort(s, defaultPort)
 		}
 	}
 
@@ -22,6 +22,10 @@ func TestAddress_String(t *testing.T) {
 		{"A:27017", "a:27017"},
 		{"a:27017", "a:27017"},
 		{"a.sock", "a.sock"},
+		{"[::1]", "[::1]:27017"},
+		{"[::1]:27018", "[::1]:27018"},
+		{"::1", "[::1]:27017"},
+		{"[FE80::1]:27017", "[fe80::1]:27017"},
 	}
 
 	for _, test := range tests {
@@ -41,6 +45,10 @@ func TestAddress_Canonicalize(t *testing.T) {
 		{"A:27017", "a:27017"},
 		{"a:27017", "a:27017"},
 		{"a.sock", "a.sock"},
+		{"[::1]", "[::1]:27017"},
+		{"[::1]:27018", "[::1]:27018"},
+		{"::1", "[::1]:27017"},
+		{"[FE80::1]:27017", "[fe80::1]:27017"},
 	}
 
 	for _, test := range tests {
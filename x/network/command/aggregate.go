@@ -59,7 +59,7 @@ func (a *Aggregate) encode(desc description.SelectedServer) (*Read, error) {
 	}
 
 	cursor := bsonx.Doc{}
-	hasOutStage := a.HasDollarOut()
+	hasOutStage := a.HasWriteStage()
 
 	for _, opt := range a.Opts {
 		switch opt.Key {
@@ -99,21 +99,34 @@ func (a *Aggregate) encode(desc description.SelectedServer) (*Read, error) {
 }
 
 // HasDollarOut returns true if the Pipeline field contains a $out stage.
-func (a *Aggregate) HasDollarOut() bool {
-	if a.Pipeline == nil {
-		return false
-	}
+func (a *Aggregate) HasDollarOut() bool { return a.lastStage() == "$out" }
+
+// HasDollarMerge returns true if the Pipeline field ends with a $merge stage.
+func (a *Aggregate) HasDollarMerge() bool { return a.lastStage() == "$merge" }
+
+// HasWriteStage returns true if the Pipeline field ends with a stage that writes its results to a
+// collection, either $out or $merge. Such an aggregation is a write, so it is sent to a server that
+// accepts writes with the write concern attached.
+func (a *Aggregate) HasWriteStage() bool { return a.HasDollarOut() || a.HasDollarMerge() }
+
+// RetryableWrite returns true if the aggregation is a write that may be retried. A $merge stage can
+// be run again with the same outcome, but $out replaces the target collection and is not retried.
+func (a *Aggregate) RetryableWrite() bool { return a.HasDollarMerge() }
+
+// lastStage returns the name of the last stage of the Pipeline field, or an empty string if the
+// pipeline is empty or the last stage is malformed.
+func (a *Aggregate) lastStage() string {
 	if len(a.Pipeline) == 0 {
-		return false
+		return ""
 	}
 
 	val := a.Pipeline[len(a.Pipeline)-1]
 
 	doc, ok := val.DocumentOK()
 	if !ok || len(doc) != 1 {
-		return false
+		return ""
 	}
-	return doc[0].Key == "$out"
+	return doc[0].Key
 }
 
 // Decode will decode the wire message using the provided server description. Errors during decoding
@@ -32,6 +32,8 @@ func TestAggregate(t *testing.T) {
 	}
 	outDoc := bsonx.Doc{{"$out", bsonx.Int32(1)}}
 	outPipeline := bsonx.Arr{bsonx.Document(outDoc)}
+	mergeDoc := bsonx.Doc{{"$merge", bsonx.Document(bsonx.Doc{{"into", bsonx.String("other")}})}}
+	mergePipeline := bsonx.Arr{bsonx.Document(bsonx.Doc{{"$match", bsonx.Document(bsonx.Doc{})}}), bsonx.Document(mergeDoc)}
 
 	testCases := []struct {
 		name       string
@@ -43,6 +45,7 @@ func TestAggregate(t *testing.T) {
 		{"LegacyDescOut", legacyDesc, outPipeline, false},
 		{"NewDescNoOut", desc, bsonx.Arr{}, false},
 		{"NewDescOut", desc, outPipeline, true},
+		{"NewDescMerge", desc, mergePipeline, true},
 	}
 
 	for _, tc := range testCases {
@@ -67,4 +70,56 @@ func TestAggregate(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("write stages", func(t *testing.T) {
+		testCases := []struct {
+			name      string
+			pipeline  bsonx.Arr
+			write     bool
+			retryable bool
+		}{
+			{"empty", bsonx.Arr{}, false, false},
+			{"$out", outPipeline, true, false},
+			{"$merge", mergePipeline, true, true},
+			{"$merge not last", bsonx.Arr{bsonx.Document(mergeDoc), bsonx.Document(bsonx.Doc{{"$limit", bsonx.Int32(1)}})}, false, false},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				cmd := Aggregate{Pipeline: tc.pipeline}
+				if cmd.HasWriteStage() != tc.write {
+					t.Errorf("HasWriteStage mismatch: expected %v got %v", tc.write, cmd.HasWriteStage())
+				}
+				if cmd.RetryableWrite() != tc.retryable {
+					t.Errorf("RetryableWrite mismatch: expected %v got %v", tc.retryable, cmd.RetryableWrite())
+				}
+			})
+		}
+	})
+	t.Run("batch size", func(t *testing.T) {
+		testCases := []struct {
+			name   string
+			opts   []bsonx.Elem
+			cursor bsonx.Doc
+		}{
+			{"unset", nil, bsonx.Doc{}},
+			{"zero", []bsonx.Elem{{"batchSize", bsonx.Int32(0)}}, bsonx.Doc{{"batchSize", bsonx.Int32(0)}}},
+			{"positive", []bsonx.Elem{{"batchSize", bsonx.Int32(5)}}, bsonx.Doc{{"batchSize", bsonx.Int32(5)}}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				cmd := Aggregate{NS: Namespace{DB: "db", Collection: "coll"}, Pipeline: bsonx.Arr{}, Opts: tc.opts}
+				readCmd, err := cmd.encode(desc)
+				testhelpers.RequireNil(t, err, "error encoding: %s", err)
+
+				cursor, err := readCmd.Command.LookupErr("cursor")
+				testhelpers.RequireNil(t, err, "cursor document missing: %s", err)
+				if !cursor.Document().Equal(tc.cursor) {
+					t.Errorf("cursor mismatch: expected %v got %v", tc.cursor, cursor.Document())
+				}
+				if _, err := readCmd.Command.LookupErr("batchSize"); err == nil {
+					t.Error("batchSize should only be sent in the cursor document")
+				}
+			})
+		}
+	})
 }
@@ -0,0 +1,106 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package command
+
+import (
+	"context"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/result"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+// CollStats represents the collStats command.
+//
+// The collStats command returns storage statistics for a collection. When Scale is greater than
+// zero the size fields of the result are divided by it.
+type CollStats struct {
+	NS       Namespace
+	Scale    int32
+	ReadPref *readpref.ReadPref
+	Clock    *session.ClusterClock
+	Session  *session.Client
+
+	result result.CollStats
+	err    error
+}
+
+// Encode will encode this command into a wire message for the given server description.
+func (cs *CollStats) Encode(desc description.SelectedServer) (wiremessage.WireMessage, error) {
+	cmd, err := cs.encode(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	return cmd.Encode(desc)
+}
+
+func (cs *CollStats) encode(desc description.SelectedServer) (*Read, error) {
+	if err := cs.NS.Validate(); err != nil {
+		return nil, err
+	}
+
+	cmd := bsonx.Doc{{"collStats", bsonx.String(cs.NS.Collection)}}
+	if cs.Scale > 0 {
+		cmd = append(cmd, bsonx.Elem{"scale", bsonx.Int32(cs.Scale)})
+	}
+
+	return &Read{
+		Clock:    cs.Clock,
+		DB:       cs.NS.DB,
+		ReadPref: cs.ReadPref,
+		Command:  cmd,
+		Session:  cs.Session,
+	}, nil
+}
+
+// Decode will decode the wire message using the provided server description. Errors during decoding
+// are deferred until either the Result or Err methods are called.
+func (cs *CollStats) Decode(desc description.SelectedServer, wm wiremessage.WireMessage) *CollStats {
+	rdr, err := (&Read{}).Decode(desc, wm).Result()
+	if err != nil {
+		cs.err = err
+		return cs
+	}
+
+	return cs.decode(desc, rdr)
+}
+
+func (cs *CollStats) decode(desc description.SelectedServer, rdr bson.Raw) *CollStats {
+	cs.err = bson.Unmarshal(rdr, &cs.result)
+	return cs
+}
+
+// Result returns the result of a decoded wire message and server description.
+func (cs *CollStats) Result() (result.CollStats, error) {
+	if cs.err != nil {
+		return result.CollStats{}, cs.err
+	}
+	return cs.result, nil
+}
+
+// Err returns the error set on this command.
+func (cs *CollStats) Err() error { return cs.err }
+
+// RoundTrip handles the execution of this command using the provided wiremessage.ReadWriter.
+func (cs *CollStats) RoundTrip(ctx context.Context, desc description.SelectedServer, rw wiremessage.ReadWriter) (result.CollStats, error) {
+	cmd, err := cs.encode(desc)
+	if err != nil {
+		return result.CollStats{}, err
+	}
+
+	rdr, err := cmd.RoundTrip(ctx, desc, rw)
+	if err != nil {
+		return result.CollStats{}, err
+	}
+
+	return cs.decode(desc, rdr).Result()
+}
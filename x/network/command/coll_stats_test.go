@@ -0,0 +1,127 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+func statsReply(t *testing.T, doc bsonx.Doc) wiremessage.Msg {
+	t.Helper()
+	raw, err := doc.MarshalBSON()
+	noerr(t, err)
+	return wiremessage.Msg{Sections: []wiremessage.Section{wiremessage.SectionBody{Document: raw}}}
+}
+
+func TestCollStats(t *testing.T) {
+	t.Run("Encode scale", func(t *testing.T) {
+		rp := readpref.Secondary()
+		cmd := CollStats{NS: Namespace{DB: "foo", Collection: "bar"}, Scale: 1024, ReadPref: rp}
+		read, err := cmd.encode(description.SelectedServer{})
+		noerr(t, err)
+		if read.DB != "foo" {
+			t.Errorf("Unexpected database. got %s; want %s", read.DB, "foo")
+		}
+		if read.ReadPref != rp {
+			t.Error("Read preference should be passed through to the read command")
+		}
+		want := bsonx.Doc{{"collStats", bsonx.String("bar")}, {"scale", bsonx.Int32(1024)}}
+		if !read.Command.Equal(want) {
+			t.Errorf("Unexpected command. got %v; want %v", read.Command, want)
+		}
+	})
+	t.Run("Omit scale when unset", func(t *testing.T) {
+		cmd := CollStats{NS: Namespace{DB: "foo", Collection: "bar"}}
+		read, err := cmd.encode(description.SelectedServer{})
+		noerr(t, err)
+		if _, err := read.Command.LookupErr("scale"); err == nil {
+			t.Error("scale should be omitted from the command, but is present")
+		}
+	})
+	t.Run("Decode result", func(t *testing.T) {
+		reply := statsReply(t, bsonx.Doc{
+			{"ns", bsonx.String("foo.bar")},
+			{"size", bsonx.Int32(4096)},
+			{"count", bsonx.Int64(32)},
+			{"avgObjSize", bsonx.Double(128.5)},
+			{"storageSize", bsonx.Int32(16384)},
+			{"capped", bsonx.Boolean(false)},
+			{"nindexes", bsonx.Int32(2)},
+			{"totalIndexSize", bsonx.Int32(8192)},
+			{"indexSizes", bsonx.Document(bsonx.Doc{
+				{"_id_", bsonx.Int32(4096)},
+				{"a_1", bsonx.Int32(4096)},
+			})},
+			{"ok", bsonx.Double(1)},
+		})
+		res, err := (&CollStats{}).Decode(description.SelectedServer{}, reply).Result()
+		noerr(t, err)
+		if res.Ns != "foo.bar" {
+			t.Errorf("Unexpected namespace. got %s; want %s", res.Ns, "foo.bar")
+		}
+		if res.Size != 4096 {
+			t.Errorf("Unexpected size. got %d; want %d", res.Size, 4096)
+		}
+		if res.Count != 32 {
+			t.Errorf("Unexpected count. got %d; want %d", res.Count, 32)
+		}
+		if res.AvgObjSize != 128.5 {
+			t.Errorf("Unexpected average object size. got %v; want %v", res.AvgObjSize, 128.5)
+		}
+		if res.StorageSize != 16384 {
+			t.Errorf("Unexpected storage size. got %d; want %d", res.StorageSize, 16384)
+		}
+		if res.Indexes != 2 {
+			t.Errorf("Unexpected number of indexes. got %d; want %d", res.Indexes, 2)
+		}
+		if res.TotalIndexSize != 8192 {
+			t.Errorf("Unexpected total index size. got %d; want %d", res.TotalIndexSize, 8192)
+		}
+		if res.IndexSizes["a_1"] != 4096 {
+			t.Errorf("Unexpected index size for a_1. got %d; want %d", res.IndexSizes["a_1"], 4096)
+		}
+	})
+	t.Run("Decode error", func(t *testing.T) {
+		_, err := (&CollStats{}).Decode(description.SelectedServer{}, nsNotFoundReply(t)).Result()
+		if err == nil {
+			t.Error("Expected an error decoding a failed collStats reply")
+		}
+	})
+}
+
+func TestDbStats(t *testing.T) {
+	t.Run("Encode scale", func(t *testing.T) {
+		cmd := DbStats{DB: "foo", Scale: 1024}
+		read, err := cmd.encode(description.SelectedServer{})
+		noerr(t, err)
+		want := bsonx.Doc{{"dbStats", bsonx.Int32(1)}, {"scale", bsonx.Int32(1024)}}
+		if !read.Command.Equal(want) {
+			t.Errorf("Unexpected command. got %v; want %v", read.Command, want)
+		}
+	})
+	t.Run("Decode result", func(t *testing.T) {
+		reply := statsReply(t, bsonx.Doc{
+			{"db", bsonx.String("foo")},
+			{"collections", bsonx.Int32(3)},
+			{"views", bsonx.Int32(0)},
+			{"objects", bsonx.Int64(96)},
+			{"avgObjSize", bsonx.Double(100.25)},
+			{"dataSize", bsonx.Double(9624)},
+			{"storageSize", bsonx.Int32(49152)},
+			{"indexes", bsonx.Int32(4)},
+			{"indexSize", bsonx.Int32(32768)},
+			{"ok", bsonx.Double(1)},
+		})
+		res, err := (&DbStats{}).Decode(description.SelectedServer{}, reply).Result()
+		noerr(t, err)
+		if res.DB != "foo" || res.Collections != 3 || res.Objects != 96 || res.Indexes != 4 {
+			t.Errorf("Unexpected counts. got %+v", res)
+		}
+		if res.AvgObjSize != 100.25 || res.DataSize != 9624 || res.StorageSize != 49152 || res.IndexSize != 32768 {
+			t.Errorf("Unexpected sizes. got %+v", res)
+		}
+	})
+}
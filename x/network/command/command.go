@@ -30,6 +30,10 @@ import (
 type WriteBatch struct {
 	*Write
 	numDocs int
+	// offset is the position of the batch's first document among all of the documents of the
+	// command it was split from. The server reports write error and upsert indexes relative to the
+	// batch, so they are offset by this.
+	offset int
 }
 
 // DecodeError attempts to decode the wiremessage as an error
@@ -106,18 +110,7 @@ func extractError(rdr bson.Raw) error {
 				code = c
 			}
 		case "errorLabels":
-			if arr, okay := elem.Value().ArrayOK(); okay {
-				elems, err := arr.Elements()
-				if err != nil {
-					continue
-				}
-				for _, elem := range elems {
-					if str, ok := elem.Value().StringValueOK(); ok {
-						labels = append(labels, str)
-					}
-				}
-
-			}
+			labels, _ = getErrorLabels(&rdr)
 		}
 	}
 
@@ -262,6 +255,11 @@ func addClusterTime(cmd bsonx.Doc, desc description.SelectedServer, sess *sessio
 
 // add a read concern to a BSON doc representing a command
 func addReadConcern(cmd bsonx.Doc, desc description.SelectedServer, rc *readconcern.ReadConcern, sess *session.Client) (bsonx.Doc, error) {
+	// Only the first statement of a transaction may carry a read concern
+	if sess != nil && sess.TransactionInProgress() {
+		return cmd.Delete("readConcern"), nil
+	}
+
 	// Starting transaction's read concern overrides all others
 	if sess != nil && sess.TransactionStarting() && sess.CurrentRc != nil {
 		rc = sess.CurrentRc
@@ -324,11 +322,15 @@ func addWriteConcern(cmd bsonx.Doc, wc *writeconcern.WriteConcern) (bsonx.Doc, e
 	return append(cmd, bsonx.Elem{Key: "writeConcern", Value: xval}), nil
 }
 
-// Get the error labels from a command response
+// Get the error labels from a command response. Every command response, including those for
+// getMore and killCursors, may contain a top-level errorLabels array.
 func getErrorLabels(rdr *bson.Raw) ([]string, error) {
 	var labels []string
 	labelsElem, err := rdr.LookupErr("errorLabels")
-	if err != bsoncore.ErrElementNotFound {
+	if err == bsoncore.ErrElementNotFound {
+		return nil, nil
+	}
+	if err != nil {
 		return nil, err
 	}
 	if labelsElem.Type == bsontype.Array {
@@ -337,7 +339,9 @@ func getErrorLabels(rdr *bson.Raw) ([]string, error) {
 			return nil, err
 		}
 		for _, elem := range labelsIt {
-			labels = append(labels, elem.Value().StringValue())
+			if str, ok := elem.Value().StringValueOK(); ok {
+				labels = append(labels, str)
+			}
 		}
 	}
 	return labels, nil
@@ -496,7 +500,6 @@ func roundTripBatches(
 	cmdKind WriteCommandKind,
 ) (interface{}, []*WriteBatch, error) {
 	var res interface{}
-	var upsertIndex int64 // the operation index for the upserted IDs map
 
 	// hold onto txnNumber, reset it when loop exits to ensure reuse of same
 	// transaction number if retry is needed
@@ -527,7 +530,7 @@ func roundTripBatches(
 				return res, batches, err
 			}
 
-			conv.WriteErrors = append(conv.WriteErrors, r.WriteErrors...)
+			conv.WriteErrors = appendWriteErrors(conv.WriteErrors, r.WriteErrors, cmd.offset)
 
 			if r.WriteConcernError != nil {
 				conv.WriteConcernError = r.WriteConcernError
@@ -556,7 +559,7 @@ func roundTripBatches(
 				return conv, batches, err
 			}
 
-			conv.WriteErrors = append(conv.WriteErrors, r.WriteErrors...)
+			conv.WriteErrors = appendWriteErrors(conv.WriteErrors, r.WriteErrors, cmd.offset)
 
 			if r.WriteConcernError != nil {
 				conv.WriteConcernError = r.WriteConcernError
@@ -570,7 +573,7 @@ func roundTripBatches(
 			conv.ModifiedCount += r.ModifiedCount
 			for _, upsert := range r.Upserted {
 				conv.Upserted = append(conv.Upserted, result.Upsert{
-					Index: upsert.Index + upsertIndex,
+					Index: upsert.Index + int64(cmd.offset),
 					ID:    upsert.ID,
 				})
 			}
@@ -580,7 +583,6 @@ func roundTripBatches(
 			}
 
 			res = conv
-			upsertIndex += int64(cmd.numDocs)
 		case DeleteCommand:
 			if res == nil {
 				res = result.Delete{}
@@ -593,7 +595,7 @@ func roundTripBatches(
 				return conv, batches, err
 			}
 
-			conv.WriteErrors = append(conv.WriteErrors, r.WriteErrors...)
+			conv.WriteErrors = appendWriteErrors(conv.WriteErrors, r.WriteErrors, cmd.offset)
 
 			if r.WriteConcernError != nil {
 				conv.WriteConcernError = r.WriteConcernError
@@ -628,6 +630,16 @@ func roundTripBatches(
 	return res, batches, nil
 }
 
+// appendWriteErrors appends the write errors of a batch to dst, offsetting their indexes so that they
+// refer to the documents of the command the batch was split from.
+func appendWriteErrors(dst, batchErrs []result.WriteError, offset int) []result.WriteError {
+	for _, we := range batchErrs {
+		we.Index += offset
+		dst = append(dst, we)
+	}
+	return dst
+}
+
 // get the firstBatch, cursor ID, and namespace from a bson.Raw
 func getCursorValues(result bson.Raw) ([]bson.RawValue, Namespace, int64, error) {
 	cur, err := result.LookupErr("cursor")
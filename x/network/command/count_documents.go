@@ -31,6 +31,10 @@ type CountDocuments struct {
 	Clock       *session.ClusterClock
 	Session     *session.Client
 
+	// NoImplicitSession prevents dispatchers from starting an implicit session for this command
+	// when Session is nil, so no lsid is sent to the server.
+	NoImplicitSession bool
+
 	result int64
 	err    error
 }
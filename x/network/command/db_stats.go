@@ -0,0 +1,102 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package command
+
+import (
+	"context"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/result"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+// DbStats represents the dbStats command.
+//
+// The dbStats command returns storage statistics for a database. When Scale is greater than
+// zero the size fields of the result are divided by it.
+type DbStats struct {
+	DB       string
+	Scale    int32
+	ReadPref *readpref.ReadPref
+	Clock    *session.ClusterClock
+	Session  *session.Client
+
+	result result.DbStats
+	err    error
+}
+
+// Encode will encode this command into a wire message for the given server description.
+func (ds *DbStats) Encode(desc description.SelectedServer) (wiremessage.WireMessage, error) {
+	cmd, err := ds.encode(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	return cmd.Encode(desc)
+}
+
+func (ds *DbStats) encode(desc description.SelectedServer) (*Read, error) {
+	cmd := bsonx.Doc{{"dbStats", bsonx.Int32(1)}}
+	if ds.Scale > 0 {
+		cmd = append(cmd, bsonx.Elem{"scale", bsonx.Int32(ds.Scale)})
+	}
+
+	return &Read{
+		Clock:    ds.Clock,
+		DB:       ds.DB,
+		ReadPref: ds.ReadPref,
+		Command:  cmd,
+		Session:  ds.Session,
+	}, nil
+}
+
+// Decode will decode the wire message using the provided server description. Errors during decoding
+// are deferred until either the Result or Err methods are called.
+func (ds *DbStats) Decode(desc description.SelectedServer, wm wiremessage.WireMessage) *DbStats {
+	rdr, err := (&Read{}).Decode(desc, wm).Result()
+	if err != nil {
+		ds.err = err
+		return ds
+	}
+
+	return ds.decode(desc, rdr)
+}
+
+func (ds *DbStats) decode(desc description.SelectedServer, rdr bson.Raw) *DbStats {
+	ds.err = bson.Unmarshal(rdr, &ds.result)
+	return ds
+}
+
+// Result returns the result of a decoded wire message and server description.
+func (ds *DbStats) Result() (result.DbStats, error) {
+	if ds.err != nil {
+		return result.DbStats{}, ds.err
+	}
+	return ds.result, nil
+}
+
+// Err returns the error set on this command.
+func (ds *DbStats) Err() error { return ds.err }
+
+// RoundTrip handles the execution of this command using the provided wiremessage.ReadWriter.
+func (ds *DbStats) RoundTrip(ctx context.Context, desc description.SelectedServer, rw wiremessage.ReadWriter) (result.DbStats, error) {
+	cmd, err := ds.encode(desc)
+	if err != nil {
+		return result.DbStats{}, err
+	}
+
+	rdr, err := cmd.RoundTrip(ctx, desc, rw)
+	if err != nil {
+		return result.DbStats{}, err
+	}
+
+	return ds.decode(desc, rdr).Result()
+}
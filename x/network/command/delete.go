@@ -52,12 +52,15 @@ func (d *Delete) encode(desc description.SelectedServer) error {
 		return err
 	}
 
+	var offset int
 	for _, docs := range batches {
 		cmd, err := d.encodeBatch(docs, desc)
 		if err != nil {
 			return err
 		}
 
+		cmd.offset = offset
+		offset += len(docs)
 		d.batches = append(d.batches, cmd)
 	}
 
@@ -72,7 +75,7 @@ func (d *Delete) encodeBatch(docs []bsonx.Doc, desc description.SelectedServer)
 
 	var options []bsonx.Elem
 	for _, opt := range d.Opts {
-		if opt.Key == "collation" {
+		if opt.Key == "collation" || opt.Key == "hint" {
 			for idx := range copyDocs {
 				copyDocs[idx] = append(copyDocs[idx], opt)
 			}
@@ -87,14 +90,14 @@ func (d *Delete) encodeBatch(docs []bsonx.Doc, desc description.SelectedServer)
 	}
 
 	return &WriteBatch{
-		&Write{
+		Write: &Write{
 			Clock:        d.Clock,
 			DB:           d.NS.DB,
 			Command:      command,
 			WriteConcern: d.WriteConcern,
 			Session:      d.Session,
 		},
-		len(docs),
+		numDocs: len(docs),
 	}, nil
 }
 
@@ -27,6 +27,10 @@ type DropCollection struct {
 	Clock        *session.ClusterClock
 	Session      *session.Client
 
+	// IgnoreNotFound makes the command succeed when the namespace does not exist, so that dropping
+	// it is idempotent.
+	IgnoreNotFound bool
+
 	result bson.Raw
 	err    error
 }
@@ -61,7 +65,7 @@ func (dc *DropCollection) encode(desc description.SelectedServer) (*Write, error
 func (dc *DropCollection) Decode(desc description.SelectedServer, wm wiremessage.WireMessage) *DropCollection {
 	rdr, err := (&Write{}).Decode(desc, wm).Result()
 	if err != nil {
-		dc.err = err
+		dc.err = ignoreNotFound(err, dc.IgnoreNotFound)
 		return dc
 	}
 
@@ -94,7 +98,7 @@ func (dc *DropCollection) RoundTrip(ctx context.Context, desc description.Select
 
 	rdr, err := cmd.RoundTrip(ctx, desc, rw)
 	if err != nil {
-		return nil, err
+		return nil, ignoreNotFound(err, dc.IgnoreNotFound)
 	}
 
 	return dc.decode(desc, rdr).Result()
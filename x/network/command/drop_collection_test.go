@@ -4,9 +4,22 @@ import (
 	"testing"
 
 	"github.com/lakshay2395/mongo-go-driver/mongo/writeconcern"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
 	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
 )
 
+func nsNotFoundReply(t *testing.T) wiremessage.Msg {
+	t.Helper()
+	doc, err := bsonx.Doc{
+		{"ok", bsonx.Int32(0)},
+		{"errmsg", bsonx.String("ns not found")},
+		{"code", bsonx.Int32(26)},
+	}.MarshalBSON()
+	noerr(t, err)
+	return wiremessage.Msg{Sections: []wiremessage.Section{wiremessage.SectionBody{Document: doc}}}
+}
+
 func TestDropCollection(t *testing.T) {
 	t.Run("Encode Write Concern for MaxWireVersion >= 5", func(t *testing.T) {
 		desc := description.SelectedServer{
@@ -36,4 +49,14 @@ func TestDropCollection(t *testing.T) {
 			t.Error("write concern should be omitted from write command, but is present")
 		}
 	})
+	t.Run("Decode namespace not found", func(t *testing.T) {
+		_, err := (&DropCollection{}).Decode(description.SelectedServer{}, nsNotFoundReply(t)).Result()
+		if !IsNotFound(err) {
+			t.Errorf("Expected a namespace not found error. got %v", err)
+		}
+	})
+	t.Run("Decode namespace not found with IgnoreNotFound", func(t *testing.T) {
+		_, err := (&DropCollection{IgnoreNotFound: true}).Decode(description.SelectedServer{}, nsNotFoundReply(t)).Result()
+		noerr(t, err)
+	})
 }
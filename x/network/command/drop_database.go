@@ -26,6 +26,10 @@ type DropDatabase struct {
 	Clock        *session.ClusterClock
 	Session      *session.Client
 
+	// IgnoreNotFound makes the command succeed when the namespace does not exist, so that dropping
+	// it is idempotent.
+	IgnoreNotFound bool
+
 	result bson.Raw
 	err    error
 }
@@ -60,7 +64,7 @@ func (dd *DropDatabase) encode(desc description.SelectedServer) (*Write, error)
 func (dd *DropDatabase) Decode(desc description.SelectedServer, wm wiremessage.WireMessage) *DropDatabase {
 	rdr, err := (&Write{}).Decode(desc, wm).Result()
 	if err != nil {
-		dd.err = err
+		dd.err = ignoreNotFound(err, dd.IgnoreNotFound)
 		return dd
 	}
 
@@ -93,7 +97,7 @@ func (dd *DropDatabase) RoundTrip(ctx context.Context, desc description.Selected
 
 	rdr, err := cmd.RoundTrip(ctx, desc, rw)
 	if err != nil {
-		return nil, err
+		return nil, ignoreNotFound(err, dd.IgnoreNotFound)
 	}
 
 	return dd.decode(desc, rdr).Result()
@@ -28,6 +28,10 @@ type DropIndexes struct {
 	Clock        *session.ClusterClock
 	Session      *session.Client
 
+	// IgnoreNotFound makes the command succeed when the namespace does not exist, so that dropping
+	// it is idempotent.
+	IgnoreNotFound bool
+
 	result bson.Raw
 	err    error
 }
@@ -66,7 +70,7 @@ func (di *DropIndexes) encode(desc description.SelectedServer) (*Write, error) {
 func (di *DropIndexes) Decode(desc description.SelectedServer, wm wiremessage.WireMessage) *DropIndexes {
 	rdr, err := (&Write{}).Decode(desc, wm).Result()
 	if err != nil {
-		di.err = err
+		di.err = ignoreNotFound(err, di.IgnoreNotFound)
 		return di
 	}
 
@@ -99,7 +103,7 @@ func (di *DropIndexes) RoundTrip(ctx context.Context, desc description.SelectedS
 
 	di.result, err = cmd.RoundTrip(ctx, desc, rw)
 	if err != nil {
-		return nil, err
+		return nil, ignoreNotFound(err, di.IgnoreNotFound)
 	}
 
 	return di.Result()
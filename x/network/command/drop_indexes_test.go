@@ -3,8 +3,10 @@ package command
 import (
 	"testing"
 
+	"github.com/lakshay2395/mongo-go-driver/bson"
 	"github.com/lakshay2395/mongo-go-driver/mongo/writeconcern"
 	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
 )
 
 func TestDropIndexes(t *testing.T) {
@@ -36,4 +38,38 @@ func TestDropIndexes(t *testing.T) {
 			t.Error("write concern should be omitted from write command, but is present")
 		}
 	})
+	t.Run("Serialize Write Concern", func(t *testing.T) {
+		wc := writeconcern.New(writeconcern.WMajority())
+		testCases := []struct {
+			name    string
+			version int32
+			present bool
+		}{
+			{"included for MaxWireVersion 5", 5, true},
+			{"omitted for MaxWireVersion 4", 4, false},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				desc := description.SelectedServer{
+					Server: description.Server{
+						WireVersion: &description.VersionRange{Min: 0, Max: tc.version},
+					},
+				}
+				cmd := DropIndexes{NS: Namespace{DB: "foo", Collection: "bar"}, Index: "*", WriteConcern: wc}
+				wm, err := cmd.Encode(desc)
+				noerr(t, err)
+				query, ok := wm.(wiremessage.Query)
+				if !ok {
+					t.Fatalf("Expected an OP_QUERY wire message, but got something else. got %v", wm)
+				}
+				_, err = bson.Raw(query.Query).LookupErr("writeConcern")
+				if tc.present && err != nil {
+					t.Errorf("writeConcern should be sent, but is missing. got %v", bson.Raw(query.Query))
+				}
+				if !tc.present && err == nil {
+					t.Errorf("writeConcern should not be sent, but is present. got %v", bson.Raw(query.Query))
+				}
+			})
+		}
+	})
 }
@@ -139,3 +139,12 @@ func IsNotFound(err error) bool {
 	// need message check because legacy servers don't include the error code
 	return ok && (e.Code == 26 || e.Message == "ns not found")
 }
+
+// ignoreNotFound returns nil if ignore is set and err is a namespace not found error. Otherwise err is
+// returned unchanged. Drop commands use it so that dropping a namespace that does not exist succeeds.
+func ignoreNotFound(err error, ignore bool) error {
+	if ignore && IsNotFound(err) {
+		return nil
+	}
+	return err
+}
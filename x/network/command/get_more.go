@@ -25,6 +25,7 @@ type GetMore struct {
 	Opts    []bsonx.Elem
 	Clock   *session.ClusterClock
 	Session *session.Client
+	Comment *string
 
 	result bson.Raw
 	err    error
@@ -49,11 +50,21 @@ func (gm *GetMore) encode(desc description.SelectedServer) (*Read, error) {
 	for _, opt := range gm.Opts {
 		switch opt.Key {
 		case "maxAwaitTimeMS":
-			cmd = append(cmd, bsonx.Elem{"maxTimeMs", opt.Value})
+			// getMore has no maxAwaitTimeMS field; the server uses maxTimeMS as the time to wait
+			// for new data on an awaitData cursor.
+			cmd = append(cmd, bsonx.Elem{"maxTimeMS", opt.Value})
+		case "collation":
+			// A cursor keeps the collation of the command that created it, and the server rejects
+			// a collation on getMore.
+			continue
 		default:
 			cmd = append(cmd, opt)
 		}
 	}
+	// Servers before 4.4 reject a comment on getMore.
+	if gm.Comment != nil && desc.WireVersion != nil && desc.WireVersion.Max >= 9 {
+		cmd = append(cmd, bsonx.Elem{"comment", bsonx.String(*gm.Comment)})
+	}
 
 	return &Read{
 		Clock:   gm.Clock,
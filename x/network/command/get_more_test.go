@@ -0,0 +1,88 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+func errorLabelsReply(t *testing.T) wiremessage.Msg {
+	t.Helper()
+	doc, err := bsonx.Doc{
+		{"ok", bsonx.Int32(0)},
+		{"errmsg", bsonx.String("cursor not found")},
+		{"code", bsonx.Int32(43)},
+		{"errorLabels", bsonx.Array(bsonx.Arr{bsonx.String("TransientTransactionError")})},
+	}.MarshalBSON()
+	noerr(t, err)
+	return wiremessage.Msg{Sections: []wiremessage.Section{wiremessage.SectionBody{Document: doc}}}
+}
+
+func requireTransientTransactionError(t *testing.T, err error) {
+	t.Helper()
+	cerr, ok := err.(Error)
+	if !ok {
+		t.Fatalf("Expected a command error. got %T; want %T", err, Error{})
+	}
+	if !cerr.HasErrorLabel(TransientTransactionError) {
+		t.Errorf("Expected error to have the %s label. got %v", TransientTransactionError, cerr.Labels)
+	}
+}
+
+func TestGetMore(t *testing.T) {
+	t.Run("Decode error labels", func(t *testing.T) {
+		_, err := (&GetMore{}).Decode(description.SelectedServer{}, errorLabelsReply(t)).Result()
+		requireTransientTransactionError(t, err)
+	})
+	t.Run("maxAwaitTimeMS is sent as maxTimeMS", func(t *testing.T) {
+		gm := &GetMore{
+			ID:   1,
+			NS:   Namespace{DB: "foo", Collection: "bar"},
+			Opts: []bsonx.Elem{{"maxAwaitTimeMS", bsonx.Int64(500)}},
+		}
+		read, err := gm.encode(description.SelectedServer{})
+		noerr(t, err)
+		want := bsonx.Doc{
+			{"getMore", bsonx.Int64(1)},
+			{"collection", bsonx.String("bar")},
+			{"maxTimeMS", bsonx.Int64(500)},
+		}
+		if !read.Command.Equal(want) {
+			t.Errorf("Commands do not match. got %v; want %v", read.Command, want)
+		}
+	})
+	t.Run("collation is sent on find but not getMore", func(t *testing.T) {
+		collation := bsonx.Elem{"collation", bsonx.Document(bsonx.Doc{{"locale", bsonx.String("fr")}})}
+		ns := Namespace{DB: "foo", Collection: "bar"}
+
+		find, err := (&Find{NS: ns, Opts: []bsonx.Elem{collation}}).encode(description.SelectedServer{})
+		noerr(t, err)
+		if _, err := find.Command.LookupErr("collation"); err != nil {
+			t.Errorf("collation should be sent on find, but it is missing: %v", err)
+		}
+
+		getMore, err := (&GetMore{ID: 1, NS: ns, Opts: []bsonx.Elem{collation}}).encode(description.SelectedServer{})
+		noerr(t, err)
+		if _, err := getMore.Command.LookupErr("collation"); err == nil {
+			t.Error("collation should not be sent on getMore, but it is present")
+		}
+	})
+	t.Run("comment", func(t *testing.T) {
+		comment := "trace me"
+		gm := &GetMore{ID: 1, NS: Namespace{DB: "foo", Collection: "bar"}, Comment: &comment}
+
+		read, err := gm.encode(description.SelectedServer{Server: description.Server{WireVersion: &description.VersionRange{Max: 9}}})
+		noerr(t, err)
+		if got, _ := read.Command.Lookup("comment").StringValueOK(); got != comment {
+			t.Errorf("Expected comment to be sent. got %q; want %q", got, comment)
+		}
+
+		read, err = gm.encode(description.SelectedServer{Server: description.Server{WireVersion: &description.VersionRange{Max: 8}}})
+		noerr(t, err)
+		if _, err := read.Command.LookupErr("comment"); err == nil {
+			t.Error("comment should not be sent to servers before 4.4, but it is present")
+		}
+	})
+}
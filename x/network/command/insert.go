@@ -66,14 +66,14 @@ func (i *Insert) encodeBatch(docs []bsonx.Doc, desc description.SelectedServer)
 	}
 
 	return &WriteBatch{
-		&Write{
+		Write: &Write{
 			Clock:        i.Clock,
 			DB:           i.NS.DB,
 			Command:      command,
 			WriteConcern: i.WriteConcern,
 			Session:      i.Session,
 		},
-		len(docs),
+		numDocs: len(docs),
 	}, nil
 }
 
@@ -83,12 +83,15 @@ func (i *Insert) encode(desc description.SelectedServer) error {
 		return err
 	}
 
+	var offset int
 	for _, docs := range batches {
 		cmd, err := i.encodeBatch(docs, desc)
 		if err != nil {
 			return err
 		}
 
+		cmd.offset = offset
+		offset += len(docs)
 		i.batches = append(i.batches, cmd)
 	}
 	return nil
@@ -7,11 +7,13 @@
 package command
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
 	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
 )
 
 func TestInsertCommandSplitting(t *testing.T) {
@@ -93,3 +95,112 @@ func TestInsertCommandSplitting(t *testing.T) {
 		}
 	})
 }
+
+func TestInsertDecodeWriteErrorDetails(t *testing.T) {
+	errInfo := bsonx.Doc{{"failingDocumentId", bsonx.String("abc")}}
+	reply := statsReply(t, bsonx.Doc{
+		{"ok", bsonx.Int32(1)},
+		{"n", bsonx.Int32(0)},
+		{"writeErrors", bsonx.Array(bsonx.Arr{bsonx.Document(bsonx.Doc{
+			{"index", bsonx.Int32(0)},
+			{"code", bsonx.Int32(121)},
+			{"errmsg", bsonx.String("Document failed validation")},
+			{"errInfo", bsonx.Document(errInfo)},
+		})})},
+	})
+	res, err := (&Insert{}).Decode(description.SelectedServer{}, reply).Result()
+	noerr(t, err)
+	if len(res.WriteErrors) != 1 {
+		t.Fatalf("Expected 1 write error. got %v", res.WriteErrors)
+	}
+	want, err := errInfo.MarshalBSON()
+	noerr(t, err)
+	assert.Equal(t, []byte(want), []byte(res.WriteErrors[0].ErrInfo))
+}
+
+// batchReadWriter records the wire messages written to it and replies to each with the next of
+// replies.
+type batchReadWriter struct {
+	written []wiremessage.WireMessage
+	replies []wiremessage.WireMessage
+}
+
+func (rw *batchReadWriter) WriteWireMessage(_ context.Context, wm wiremessage.WireMessage) error {
+	rw.written = append(rw.written, wm)
+	return nil
+}
+
+func (rw *batchReadWriter) ReadWireMessage(context.Context) (wiremessage.WireMessage, error) {
+	reply := rw.replies[0]
+	rw.replies = rw.replies[1:]
+	return reply, nil
+}
+
+func TestInsertOrderedWriteErrorIndexes(t *testing.T) {
+	// Batches of two documents are sent, so a write of four statements is split in two.
+	desc := description.SelectedServer{Server: description.Server{
+		WireVersion:     &description.VersionRange{Max: 6},
+		MaxBatchCount:   2,
+		MaxDocumentSize: 16 * 1024 * 1024,
+	}}
+	docs := []bsonx.Doc{
+		{{"_id", bsonx.Int32(0)}},
+		{{"_id", bsonx.Int32(1)}},
+		{{"_id", bsonx.Int32(2)}},
+		{{"_id", bsonx.Int32(3)}},
+	}
+	// reply is the reply to a batch of n documents in which the document at errIndex, relative to
+	// the batch, failed. A negative errIndex means no document failed.
+	reply := func(n int32, errIndex int32) wiremessage.Msg {
+		doc := bsonx.Doc{{"ok", bsonx.Int32(1)}, {"n", bsonx.Int32(n)}}
+		if errIndex >= 0 {
+			doc = append(doc, bsonx.Elem{"writeErrors", bsonx.Array(bsonx.Arr{bsonx.Document(bsonx.Doc{
+				{"index", bsonx.Int32(errIndex)},
+				{"code", bsonx.Int32(11000)},
+				{"errmsg", bsonx.String("duplicate key")},
+			})})})
+		}
+		return statsReply(t, doc)
+	}
+
+	t.Run("ordered stops at the failed statement", func(t *testing.T) {
+		rw := &batchReadWriter{replies: []wiremessage.WireMessage{reply(1, 1)}}
+		res, err := (&Insert{NS: Namespace{DB: "foo", Collection: "bar"}, Docs: docs}).RoundTrip(context.Background(), desc, rw)
+		noerr(t, err)
+
+		if len(rw.written) != 1 {
+			t.Errorf("Statements after the failed statement should not be sent. got %d batches", len(rw.written))
+		}
+		if res.N != 1 {
+			t.Errorf("Only the statement before the failure should be reported. got n=%d", res.N)
+		}
+		if len(res.WriteErrors) != 1 || res.WriteErrors[0].Index != 1 {
+			t.Errorf("Expected a single write error for statement 1. got %v", res.WriteErrors)
+		}
+	})
+	t.Run("indexes in later batches refer to the statements", func(t *testing.T) {
+		rw := &batchReadWriter{replies: []wiremessage.WireMessage{reply(2, -1), reply(0, 0)}}
+		res, err := (&Insert{NS: Namespace{DB: "foo", Collection: "bar"}, Docs: docs}).RoundTrip(context.Background(), desc, rw)
+		noerr(t, err)
+
+		if len(rw.written) != 2 {
+			t.Errorf("Expected both batches to be sent. got %d batches", len(rw.written))
+		}
+		if res.N != 2 {
+			t.Errorf("Unexpected number of inserted statements. got %d; want %d", res.N, 2)
+		}
+		if len(res.WriteErrors) != 1 || res.WriteErrors[0].Index != 2 {
+			t.Errorf("Expected a single write error for statement 2. got %v", res.WriteErrors)
+		}
+	})
+	t.Run("unordered reports every batch", func(t *testing.T) {
+		rw := &batchReadWriter{replies: []wiremessage.WireMessage{reply(1, 1), reply(1, 1)}}
+		insert := &Insert{NS: Namespace{DB: "foo", Collection: "bar"}, Docs: docs, ContinueOnError: true}
+		res, err := insert.RoundTrip(context.Background(), desc, rw)
+		noerr(t, err)
+
+		if len(res.WriteErrors) != 2 || res.WriteErrors[0].Index != 1 || res.WriteErrors[1].Index != 3 {
+			t.Errorf("Expected write errors for statements 1 and 3. got %v", res.WriteErrors)
+		}
+	})
+}
@@ -21,9 +21,10 @@ import (
 //
 // The killCursors command kills a set of cursors.
 type KillCursors struct {
-	Clock *session.ClusterClock
-	NS    Namespace
-	IDs   []int64
+	Clock   *session.ClusterClock
+	NS      Namespace
+	IDs     []int64
+	Comment *string
 
 	result result.KillCursors
 	err    error
@@ -47,6 +48,10 @@ func (kc *KillCursors) encode(desc description.SelectedServer) (*Read, error) {
 		{"killCursors", bsonx.String(kc.NS.Collection)},
 		{"cursors", bsonx.Array(idVals)},
 	}
+	// Servers before 4.4 reject a comment on killCursors.
+	if kc.Comment != nil && desc.WireVersion != nil && desc.WireVersion.Max >= 9 {
+		cmd = append(cmd, bsonx.Elem{"comment", bsonx.String(*kc.Comment)})
+	}
 
 	return &Read{
 		Clock:   kc.Clock,
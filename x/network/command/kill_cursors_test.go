@@ -0,0 +1,74 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+func TestKillCursors(t *testing.T) {
+	t.Run("Decode error labels", func(t *testing.T) {
+		_, err := (&KillCursors{}).Decode(description.SelectedServer{}, errorLabelsReply(t)).Result()
+		requireTransientTransactionError(t, err)
+	})
+	t.Run("comment", func(t *testing.T) {
+		comment := "trace me"
+		kc := &KillCursors{NS: Namespace{DB: "foo", Collection: "bar"}, IDs: []int64{1}, Comment: &comment}
+
+		read, err := kc.encode(description.SelectedServer{Server: description.Server{WireVersion: &description.VersionRange{Max: 9}}})
+		noerr(t, err)
+		if got, _ := read.Command.Lookup("comment").StringValueOK(); got != comment {
+			t.Errorf("Expected comment to be sent. got %q; want %q", got, comment)
+		}
+
+		read, err = kc.encode(description.SelectedServer{Server: description.Server{WireVersion: &description.VersionRange{Max: 8}}})
+		noerr(t, err)
+		if _, err := read.Command.LookupErr("comment"); err == nil {
+			t.Error("comment should not be sent to servers before 4.4, but it is present")
+		}
+	})
+	t.Run("batches cursor ids", func(t *testing.T) {
+		kc := &KillCursors{NS: Namespace{DB: "foo", Collection: "bar"}, IDs: []int64{1, 2, 3}}
+		read, err := kc.encode(description.SelectedServer{Server: description.Server{WireVersion: &description.VersionRange{Max: 6}}})
+		noerr(t, err)
+		if read.DB != "foo" {
+			t.Errorf("Expected the command to run on the cursors' database. got %q", read.DB)
+		}
+		if got, _ := read.Command.Lookup("killCursors").StringValueOK(); got != "bar" {
+			t.Errorf("Expected killCursors: %q. got %q", "bar", got)
+		}
+		arr, ok := read.Command.Lookup("cursors").ArrayOK()
+		if !ok || len(arr) != 3 {
+			t.Fatalf("Expected all cursor ids in a single cursors array. got %v", read.Command)
+		}
+		for i, want := range kc.IDs {
+			if got, ok := arr[i].Int64OK(); !ok || got != want {
+				t.Errorf("Unexpected cursor id %d. got %v; want %d", i, arr[i], want)
+			}
+		}
+	})
+	t.Run("Decode killed and not found cursors", func(t *testing.T) {
+		doc, err := bsonx.Doc{
+			{"cursorsKilled", bsonx.Array(bsonx.Arr{bsonx.Int64(1), bsonx.Int64(3)})},
+			{"cursorsNotFound", bsonx.Array(bsonx.Arr{bsonx.Int64(2)})},
+			{"cursorsAlive", bsonx.Array(bsonx.Arr{})},
+			{"ok", bsonx.Int32(1)},
+		}.MarshalBSON()
+		noerr(t, err)
+		reply := wiremessage.Msg{Sections: []wiremessage.Section{wiremessage.SectionBody{Document: doc}}}
+
+		res, err := (&KillCursors{}).Decode(description.SelectedServer{}, reply).Result()
+		noerr(t, err)
+		if len(res.CursorsKilled) != 2 || res.CursorsKilled[0] != 1 || res.CursorsKilled[1] != 3 {
+			t.Errorf("Unexpected cursorsKilled. got %v; want %v", res.CursorsKilled, []int64{1, 3})
+		}
+		if len(res.CursorsNotFound) != 1 || res.CursorsNotFound[0] != 2 {
+			t.Errorf("Unexpected cursorsNotFound. got %v; want %v", res.CursorsNotFound, []int64{2})
+		}
+		if len(res.CursorsAlive) != 0 {
+			t.Errorf("Unexpected cursorsAlive. got %v", res.CursorsAlive)
+		}
+	})
+}
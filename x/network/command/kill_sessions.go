@@ -0,0 +1,119 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package command
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+// errKillSessionsIDsAndPatterns is returned when both session IDs and patterns are given to KillSessions.
+var errKillSessionsIDsAndPatterns = errors.New("cannot specify both session IDs and patterns to kill sessions")
+
+// must be sent to admin db
+// { killSessions: [ {id: uuid}, ... ] }
+// { killAllSessionsByPattern: [ { lsid: {id: uuid} }, { uid: ... }, ... ] }
+// { killAllSessions: [] }
+
+// KillSessions represents a killSessions, killAllSessionsByPattern, or killAllSessions command.
+//
+// If SessionIDs is set, the sessions with those lsids are killed. If Patterns is set, the sessions
+// matching any of the patterns are killed. If neither is set, all sessions are killed.
+type KillSessions struct {
+	Clock      *session.ClusterClock
+	SessionIDs []bsonx.Doc
+	Patterns   []bsonx.Doc
+
+	result bson.Raw
+	err    error
+}
+
+// Encode will encode this command into a wire message for the given server description.
+func (ks *KillSessions) Encode(desc description.SelectedServer) (wiremessage.WireMessage, error) {
+	cmd, err := ks.encode(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	return cmd.Encode(desc)
+}
+
+func (ks *KillSessions) encode(desc description.SelectedServer) (*Write, error) {
+	var name string
+	var docs []bsonx.Doc
+	switch {
+	case len(ks.SessionIDs) > 0 && len(ks.Patterns) > 0:
+		return nil, errKillSessionsIDsAndPatterns
+	case len(ks.SessionIDs) > 0:
+		name, docs = "killSessions", ks.SessionIDs
+	case len(ks.Patterns) > 0:
+		name, docs = "killAllSessionsByPattern", ks.Patterns
+	default:
+		name = "killAllSessions"
+	}
+
+	vals := make(bsonx.Arr, 0, len(docs))
+	for _, doc := range docs {
+		vals = append(vals, bsonx.Document(doc))
+	}
+
+	return &Write{
+		Clock:   ks.Clock,
+		DB:      "admin",
+		Command: bsonx.Doc{{name, bsonx.Array(vals)}},
+	}, nil
+}
+
+// Decode will decode the wire message using the provided server description. Errors during decoding
+// are deferred until either the Result or Err methods are called.
+func (ks *KillSessions) Decode(desc description.SelectedServer, wm wiremessage.WireMessage) *KillSessions {
+	rdr, err := (&Write{}).Decode(desc, wm).Result()
+	if err != nil {
+		ks.err = err
+		return ks
+	}
+
+	return ks.decode(desc, rdr)
+}
+
+func (ks *KillSessions) decode(desc description.SelectedServer, rdr bson.Raw) *KillSessions {
+	ks.result = rdr
+	return ks
+}
+
+// Result returns the result of a decoded wire message and server description.
+func (ks *KillSessions) Result() (bson.Raw, error) {
+	if ks.err != nil {
+		return nil, ks.err
+	}
+
+	return ks.result, nil
+}
+
+// Err returns the error set on this command.
+func (ks *KillSessions) Err() error { return ks.err }
+
+// RoundTrip handles the execution of this command using the provided wiremessage.ReadWriter.
+func (ks *KillSessions) RoundTrip(ctx context.Context, desc description.SelectedServer, rw wiremessage.ReadWriter) (bson.Raw, error) {
+	cmd, err := ks.encode(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	rdr, err := cmd.RoundTrip(ctx, desc, rw)
+	if err != nil {
+		return nil, err
+	}
+
+	return ks.decode(desc, rdr).Result()
+}
@@ -0,0 +1,61 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package command
+
+import (
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+func TestKillSessions(t *testing.T) {
+	desc := description.SelectedServer{
+		Server: description.Server{
+			WireVersion: &description.VersionRange{Min: 0, Max: 6},
+		},
+	}
+	ids := []bsonx.Doc{
+		{{"id", bsonx.Binary(0x04, []byte{0x01})}},
+		{{"id", bsonx.Binary(0x04, []byte{0x02})}},
+	}
+
+	t.Run("killSessions carries the session IDs", func(t *testing.T) {
+		write, err := (&KillSessions{SessionIDs: ids}).encode(desc)
+		noerr(t, err)
+		if write.DB != "admin" {
+			t.Errorf("killSessions should be run against admin. got %s", write.DB)
+		}
+		want := bsonx.Doc{{"killSessions", bsonx.Array(bsonx.Arr{bsonx.Document(ids[0]), bsonx.Document(ids[1])})}}
+		if !write.Command.Equal(want) {
+			t.Errorf("Commands do not match. got %v; want %v", write.Command, want)
+		}
+	})
+	t.Run("killAllSessionsByPattern", func(t *testing.T) {
+		patterns := []bsonx.Doc{{{"lsid", bsonx.Document(ids[0])}}}
+		write, err := (&KillSessions{Patterns: patterns}).encode(desc)
+		noerr(t, err)
+		want := bsonx.Doc{{"killAllSessionsByPattern", bsonx.Array(bsonx.Arr{bsonx.Document(patterns[0])})}}
+		if !write.Command.Equal(want) {
+			t.Errorf("Commands do not match. got %v; want %v", write.Command, want)
+		}
+	})
+	t.Run("killAllSessions", func(t *testing.T) {
+		write, err := (&KillSessions{}).encode(desc)
+		noerr(t, err)
+		want := bsonx.Doc{{"killAllSessions", bsonx.Array(bsonx.Arr{})}}
+		if !write.Command.Equal(want) {
+			t.Errorf("Commands do not match. got %v; want %v", write.Command, want)
+		}
+	})
+	t.Run("session IDs and patterns", func(t *testing.T) {
+		_, err := (&KillSessions{SessionIDs: ids, Patterns: ids}).encode(desc)
+		if err != errKillSessionsIDsAndPatterns {
+			t.Errorf("Expected error %v. got %v", errKillSessionsIDsAndPatterns, err)
+		}
+	})
+}
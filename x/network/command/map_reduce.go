@@ -0,0 +1,207 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package command
+
+import (
+	"context"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/mongo/readconcern"
+	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
+	"github.com/lakshay2395/mongo-go-driver/mongo/writeconcern"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/result"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+// MapReduceOutputAction specifies how the results of a mapReduce command are output.
+type MapReduceOutputAction string
+
+// These are the output actions supported by the mapReduce command.
+const (
+	// MapReduceInline returns the results in the command response.
+	MapReduceInline MapReduceOutputAction = "inline"
+	// MapReduceReplace replaces the contents of the output collection with the results.
+	MapReduceReplace MapReduceOutputAction = "replace"
+	// MapReduceMerge merges the results into the output collection, overwriting existing documents
+	// with the same key.
+	MapReduceMerge MapReduceOutputAction = "merge"
+	// MapReduceReduce merges the results into the output collection, applying the reduce function
+	// to documents with the same key.
+	MapReduceReduce MapReduceOutputAction = "reduce"
+)
+
+// MapReduceOutput is the out specification of a mapReduce command. The zero value outputs the
+// results inline.
+type MapReduceOutput struct {
+	Action     MapReduceOutputAction
+	Collection string
+	DB         string
+}
+
+// Inline returns true if the results are returned in the command response instead of being written
+// to a collection.
+func (mro MapReduceOutput) Inline() bool {
+	return mro.Action == "" || mro.Action == MapReduceInline
+}
+
+func (mro MapReduceOutput) value() bsonx.Val {
+	if mro.Inline() {
+		return bsonx.Document(bsonx.Doc{{"inline", bsonx.Int32(1)}})
+	}
+
+	out := bsonx.Doc{{string(mro.Action), bsonx.String(mro.Collection)}}
+	if mro.DB != "" {
+		out = append(out, bsonx.Elem{"db", bsonx.String(mro.DB)})
+	}
+	return bsonx.Document(out)
+}
+
+// MapReduce represents the mapReduce command.
+//
+// The mapReduce command runs map and reduce JavaScript functions over the documents in a
+// collection.
+type MapReduce struct {
+	NS           Namespace
+	Map          string
+	Reduce       string
+	Finalize     string
+	Query        bsonx.Doc
+	Sort         bsonx.Doc
+	Limit        int64
+	Scope        bsonx.Doc
+	Out          MapReduceOutput
+	Opts         []bsonx.Elem
+	ReadPref     *readpref.ReadPref
+	WriteConcern *writeconcern.WriteConcern
+	ReadConcern  *readconcern.ReadConcern
+	Clock        *session.ClusterClock
+	Session      *session.Client
+
+	result bson.Raw
+	err    error
+}
+
+// Encode will encode this command into a wire message for the given server description.
+func (mr *MapReduce) Encode(desc description.SelectedServer) (wiremessage.WireMessage, error) {
+	cmd, err := mr.encode(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	return cmd.Encode(desc)
+}
+
+func (mr *MapReduce) encode(desc description.SelectedServer) (*Read, error) {
+	if err := mr.NS.Validate(); err != nil {
+		return nil, err
+	}
+
+	command := bsonx.Doc{
+		{"mapReduce", bsonx.String(mr.NS.Collection)},
+		{"map", bsonx.JavaScript(mr.Map)},
+		{"reduce", bsonx.JavaScript(mr.Reduce)},
+		{"out", mr.Out.value()},
+	}
+
+	if mr.Finalize != "" {
+		command = append(command, bsonx.Elem{"finalize", bsonx.JavaScript(mr.Finalize)})
+	}
+	if mr.Query != nil {
+		command = append(command, bsonx.Elem{"query", bsonx.Document(mr.Query)})
+	}
+	if mr.Sort != nil {
+		command = append(command, bsonx.Elem{"sort", bsonx.Document(mr.Sort)})
+	}
+	if mr.Limit != 0 {
+		command = append(command, bsonx.Elem{"limit", bsonx.Int64(mr.Limit)})
+	}
+	if mr.Scope != nil {
+		command = append(command, bsonx.Elem{"scope", bsonx.Document(mr.Scope)})
+	}
+
+	command = append(command, mr.Opts...)
+
+	rp := mr.ReadPref
+	if !mr.Out.Inline() {
+		// Writing to a collection must happen on the primary, regardless of the read preference.
+		rp = readpref.Primary()
+
+		// add write concern because it won't be added by the Read command's Encode()
+		if desc.WireVersion.Max >= 5 && mr.WriteConcern != nil {
+			t, data, err := mr.WriteConcern.MarshalBSONValue()
+			if err != nil {
+				return nil, err
+			}
+			var xval bsonx.Val
+			err = xval.UnmarshalBSONValue(t, data)
+			if err != nil {
+				return nil, err
+			}
+			command = append(command, bsonx.Elem{Key: "writeConcern", Value: xval})
+		}
+	}
+
+	return &Read{
+		DB:          mr.NS.DB,
+		Command:     command,
+		ReadPref:    rp,
+		ReadConcern: mr.ReadConcern,
+		Clock:       mr.Clock,
+		Session:     mr.Session,
+	}, nil
+}
+
+// Decode will decode the wire message using the provided server description. Errors during decoding
+// are deferred until either the Result or Err methods are called.
+func (mr *MapReduce) Decode(desc description.SelectedServer, wm wiremessage.WireMessage) *MapReduce {
+	rdr, err := (&Read{}).Decode(desc, wm).Result()
+	if err != nil {
+		mr.err = err
+		return mr
+	}
+
+	return mr.decode(desc, rdr)
+}
+
+func (mr *MapReduce) decode(desc description.SelectedServer, rdr bson.Raw) *MapReduce {
+	mr.result = rdr
+	if val, err := rdr.LookupErr("writeConcernError"); err == nil {
+		var wce result.WriteConcernError
+		_ = val.Unmarshal(&wce)
+		mr.err = wce
+	}
+	return mr
+}
+
+// Result returns the result of a decoded wire message and server description.
+func (mr *MapReduce) Result() (bson.Raw, error) {
+	if mr.err != nil {
+		return nil, mr.err
+	}
+	return mr.result, nil
+}
+
+// Err returns the error set on this command.
+func (mr *MapReduce) Err() error { return mr.err }
+
+// RoundTrip handles the execution of this command using the provided wiremessage.ReadWriter.
+func (mr *MapReduce) RoundTrip(ctx context.Context, desc description.SelectedServer, rw wiremessage.ReadWriter) (bson.Raw, error) {
+	cmd, err := mr.encode(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	rdr, err := cmd.RoundTrip(ctx, desc, rw)
+	if err != nil {
+		return nil, err
+	}
+
+	return mr.decode(desc, rdr).Result()
+}
@@ -0,0 +1,81 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package command
+
+import (
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/internal/testutil/helpers"
+	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
+	"github.com/lakshay2395/mongo-go-driver/mongo/writeconcern"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+	"github.com/lakshay2395/mongo-go-driver/x/network/wiremessage"
+)
+
+func TestMapReduce(t *testing.T) {
+	desc := description.SelectedServer{
+		Server: description.Server{
+			Kind:        description.RSSecondary,
+			WireVersion: &description.VersionRange{Max: 6},
+		},
+		Kind: description.ReplicaSetWithPrimary,
+	}
+
+	testCases := []struct {
+		name   string
+		out    MapReduceOutput
+		mode   string
+		wcSent bool
+	}{
+		{"inline", MapReduceOutput{}, "secondary", false},
+		{"explicit inline", MapReduceOutput{Action: MapReduceInline}, "secondary", false},
+		{"replace", MapReduceOutput{Action: MapReduceReplace, Collection: "out"}, "primary", true},
+		{"merge", MapReduceOutput{Action: MapReduceMerge, Collection: "out", DB: "other"}, "primary", true},
+		{"reduce", MapReduceOutput{Action: MapReduceReduce, Collection: "out"}, "primary", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := MapReduce{
+				NS:           Namespace{DB: "db", Collection: "coll"},
+				Map:          "function() { emit(this.a, 1); }",
+				Reduce:       "function(k, vs) { return Array.sum(vs); }",
+				Out:          tc.out,
+				ReadPref:     readpref.Secondary(),
+				WriteConcern: writeconcern.New(writeconcern.WMajority()),
+			}
+
+			wm, err := cmd.Encode(desc)
+			testhelpers.RequireNil(t, err, "error encoding: %s", err)
+			msg, ok := wm.(wiremessage.Msg)
+			if !ok {
+				t.Fatalf("expected OP_MSG, got %T", wm)
+			}
+			doc := msg.Sections[0].(wiremessage.SectionBody).Document
+
+			mode, err := doc.LookupErr("$readPreference", "mode")
+			testhelpers.RequireNil(t, err, "$readPreference mode not found: %s", err)
+			if mode.StringValue() != tc.mode {
+				t.Errorf("read preference mode mismatch. got %s; want %s", mode.StringValue(), tc.mode)
+			}
+
+			_, err = doc.LookupErr("writeConcern")
+			if sent := err == nil; sent != tc.wcSent {
+				t.Errorf("writeConcern presence mismatch. got %v; want %v", sent, tc.wcSent)
+			}
+
+			out, err := doc.LookupErr("out")
+			testhelpers.RequireNil(t, err, "out not found: %s", err)
+			if tc.out.Inline() {
+				_, err = out.Document().LookupErr("inline")
+			} else {
+				_, err = out.Document().LookupErr(string(tc.out.Action))
+			}
+			testhelpers.RequireNil(t, err, "unexpected out document %s", out)
+		})
+	}
+}
@@ -29,6 +29,10 @@ type Read struct {
 	Clock       *session.ClusterClock
 	Session     *session.Client
 
+	// NoImplicitSession prevents dispatchers from starting an implicit session for this command
+	// when Session is nil, so no lsid is sent to the server.
+	NoImplicitSession bool
+
 	result bson.Raw
 	err    error
 }
@@ -56,6 +60,12 @@ func (r *Read) createReadPref(serverKind description.ServerKind, topologyKind de
 	case readpref.PrimaryPreferredMode:
 		doc = append(doc, bsonx.Elem{"mode", bsonx.String("primaryPreferred")})
 	case readpref.SecondaryPreferredMode:
+		// A mongos would fall back to the primary for secondaryPreferred, so strict tags are sent
+		// as secondary.
+		if r.ReadPref.StrictTags() {
+			doc = append(doc, bsonx.Elem{"mode", bsonx.String("secondary")})
+			break
+		}
 		_, ok := r.ReadPref.MaxStaleness()
 		if serverKind == description.Mongos && isOpQuery && !ok && len(r.ReadPref.TagSets()) == 0 {
 			return nil
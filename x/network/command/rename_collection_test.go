@@ -0,0 +1,84 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/mongo/writeconcern"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+func TestRenameCollection(t *testing.T) {
+	selected := func(max int32) description.SelectedServer {
+		return description.SelectedServer{
+			Server: description.Server{WireVersion: &description.VersionRange{Min: 0, Max: max}},
+		}
+	}
+	rename := func(dropTarget bool) RenameCollection {
+		return RenameCollection{
+			From:       NewNamespace("db", "src"),
+			To:         NewNamespace("other", "dst"),
+			DropTarget: dropTarget,
+		}
+	}
+	marshal := func(t *testing.T, write *Write) bson.Raw {
+		t.Helper()
+		doc, err := write.Command.MarshalBSON()
+		noerr(t, err)
+		return doc
+	}
+
+	t.Run("Encode against admin", func(t *testing.T) {
+		cmd := rename(false)
+		write, err := cmd.encode(selected(6))
+		noerr(t, err)
+		if write.DB != "admin" {
+			t.Errorf("expected command to run against admin, got %q", write.DB)
+		}
+		doc := marshal(t, write)
+		if got := doc.Lookup("renameCollection").StringValue(); got != "db.src" {
+			t.Errorf("expected renameCollection to be %q, got %q", "db.src", got)
+		}
+		if got := doc.Lookup("to").StringValue(); got != "other.dst" {
+			t.Errorf("expected to to be %q, got %q", "other.dst", got)
+		}
+		if _, err := doc.LookupErr("dropTarget"); err == nil {
+			t.Error("dropTarget should be omitted when not requested, but is present")
+		}
+	})
+	t.Run("Encode dropTarget", func(t *testing.T) {
+		cmd := rename(true)
+		write, err := cmd.encode(selected(6))
+		noerr(t, err)
+		val, err := marshal(t, write).LookupErr("dropTarget")
+		noerr(t, err)
+		if !val.Boolean() {
+			t.Error("expected dropTarget to be true")
+		}
+	})
+	t.Run("Encode invalid namespace", func(t *testing.T) {
+		cmd := RenameCollection{From: NewNamespace("db", "src"), To: NewNamespace("db", "")}
+		if _, err := cmd.encode(selected(6)); err == nil {
+			t.Error("expected an error for an invalid target namespace")
+		}
+	})
+	t.Run("Encode Write Concern for MaxWireVersion >= 5", func(t *testing.T) {
+		wc := writeconcern.New(writeconcern.WMajority())
+		cmd := rename(false)
+		cmd.WriteConcern = wc
+		write, err := cmd.encode(selected(5))
+		noerr(t, err)
+		if write.WriteConcern != wc {
+			t.Error("write concern should be added to write command, but is missing")
+		}
+	})
+	t.Run("Omit Write Concern for MaxWireVersion < 5", func(t *testing.T) {
+		cmd := rename(false)
+		cmd.WriteConcern = writeconcern.New(writeconcern.WMajority())
+		write, err := cmd.encode(selected(4))
+		noerr(t, err)
+		if write.WriteConcern != nil {
+			t.Error("write concern should be omitted from write command, but is present")
+		}
+	})
+}
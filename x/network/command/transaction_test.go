@@ -0,0 +1,68 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package command
+
+import (
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/session"
+	"github.com/lakshay2395/mongo-go-driver/x/mongo/driverlegacy/uuid"
+	"github.com/lakshay2395/mongo-go-driver/x/network/description"
+)
+
+func TestTransactionRecoveryToken(t *testing.T) {
+	desc := description.SelectedServer{
+		Server: description.Server{
+			WireVersion: &description.VersionRange{Min: 0, Max: 8},
+		},
+	}
+	token := bsonx.Doc{{"shard", bsonx.String("sh01")}}
+
+	newSession := func(t *testing.T) *session.Client {
+		id, err := uuid.New()
+		noerr(t, err)
+		sess, err := session.NewClientSession(session.NewPool(nil), id, session.Explicit)
+		noerr(t, err)
+
+		response, err := bsonx.Doc{{"ok", bsonx.Int32(1)}, {"recoveryToken", bsonx.Document(token)}}.MarshalBSON()
+		noerr(t, err)
+		sess.UpdateRecoveryToken(bson.Raw(response))
+		if sess.RecoveryToken == nil {
+			t.Fatal("expected recoveryToken to be stored on the session")
+		}
+		return sess
+	}
+
+	t.Run("commitTransaction", func(t *testing.T) {
+		write := (&CommitTransaction{Session: newSession(t)}).encode(desc)
+		got, err := write.Command.LookupErr("recoveryToken")
+		noerr(t, err)
+		if !got.Document().Equal(token) {
+			t.Errorf("recoveryTokens do not match. got %v; want %v", got.Document(), token)
+		}
+	})
+	t.Run("abortTransaction", func(t *testing.T) {
+		write := (&AbortTransaction{Session: newSession(t)}).encode(desc)
+		got, err := write.Command.LookupErr("recoveryToken")
+		noerr(t, err)
+		if !got.Document().Equal(token) {
+			t.Errorf("recoveryTokens do not match. got %v; want %v", got.Document(), token)
+		}
+	})
+	t.Run("no recoveryToken", func(t *testing.T) {
+		id, err := uuid.New()
+		noerr(t, err)
+		sess, err := session.NewClientSession(session.NewPool(nil), id, session.Explicit)
+		noerr(t, err)
+		write := (&AbortTransaction{Session: sess}).encode(desc)
+		if _, err := write.Command.LookupErr("recoveryToken"); err == nil {
+			t.Error("recoveryToken should not be sent when the session has none")
+		}
+	})
+}
@@ -51,12 +51,15 @@ func (u *Update) encode(desc description.SelectedServer) error {
 		return err
 	}
 
+	var offset int
 	for _, docs := range batches {
 		cmd, err := u.encodeBatch(docs, desc)
 		if err != nil {
 			return err
 		}
 
+		cmd.offset = offset
+		offset += len(docs)
 		u.batches = append(u.batches, cmd)
 	}
 
@@ -73,7 +76,7 @@ func (u *Update) encodeBatch(docs []bsonx.Doc, desc description.SelectedServer)
 	var options []bsonx.Elem
 	for _, opt := range u.Opts {
 		switch opt.Key {
-		case "upsert", "collation", "arrayFilters":
+		case "upsert", "collation", "arrayFilters", "hint":
 			// options that are encoded on each individual document
 			for idx := range copyDocs {
 				copyDocs[idx] = append(copyDocs[idx], opt)
@@ -89,14 +92,14 @@ func (u *Update) encodeBatch(docs []bsonx.Doc, desc description.SelectedServer)
 	}
 
 	return &WriteBatch{
-		&Write{
+		Write: &Write{
 			Clock:        u.Clock,
 			DB:           u.NS.DB,
 			Command:      command,
 			WriteConcern: u.WriteConcern,
 			Session:      u.Session,
 		},
-		len(docs),
+		numDocs: len(docs),
 	}, nil
 }
 
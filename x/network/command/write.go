@@ -29,6 +29,10 @@ type Write struct {
 	Clock        *session.ClusterClock
 	Session      *session.Client
 
+	// NoImplicitSession prevents dispatchers from starting an implicit session for this command
+	// when Session is nil, so no lsid is sent to the server.
+	NoImplicitSession bool
+
 	result bson.Raw
 	err    error
 }
@@ -171,6 +171,32 @@ func TestHeartbeatInterval(t *testing.T) {
 	}
 }
 
+func TestLoadBalanced(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected bool
+		err      bool
+	}{
+		{s: "loadBalanced=true", expected: true},
+		{s: "loadBalanced=false", expected: false},
+		{s: "loadBalanced=yes", err: true},
+	}
+
+	for _, test := range tests {
+		s := fmt.Sprintf("mongodb://localhost/?%s", test.s)
+		t.Run(s, func(t *testing.T) {
+			cs, err := connstring.Parse(s)
+			if test.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.expected, cs.LoadBalanced)
+				require.Equal(t, true, cs.LoadBalancedSet)
+			}
+		})
+	}
+}
+
 func TestLocalThreshold(t *testing.T) {
 	tests := []struct {
 		s        string
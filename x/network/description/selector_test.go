@@ -95,6 +95,44 @@ func TestServerSelection(t *testing.T) {
 			})
 		}
 	})
+	t.Run("MinWireVersionSelector", func(t *testing.T) {
+		v5 := Server{Addr: address.Address("localhost:27017"), Kind: Mongos, WireVersion: &VersionRange{Min: 0, Max: 5}}
+		v7 := Server{Addr: address.Address("localhost:27018"), Kind: Mongos, WireVersion: &VersionRange{Min: 0, Max: 7}}
+		unknown := Server{Addr: address.Address("localhost:27019"), Kind: Unknown}
+
+		t.Run("filters servers below the floor", func(t *testing.T) {
+			desc := Topology{Kind: Sharded, Servers: []Server{v5, v7}}
+			result, err := MinWireVersionSelector(6).SelectServer(desc, desc.Servers)
+			noerr(t, err)
+			if diff := cmp.Diff(result, []Server{v7}); diff != "" {
+				t.Errorf("Incorrect servers selected (-got +want):\n%s", diff)
+			}
+		})
+		t.Run("composes with other selectors", func(t *testing.T) {
+			desc := Topology{Kind: Sharded, Servers: []Server{v5, v7}}
+			selector := CompositeSelector([]ServerSelector{WriteSelector(), MinWireVersionSelector(6)})
+			result, err := selector.SelectServer(desc, desc.Servers)
+			noerr(t, err)
+			if diff := cmp.Diff(result, []Server{v7}); diff != "" {
+				t.Errorf("Incorrect servers selected (-got +want):\n%s", diff)
+			}
+		})
+		t.Run("errors when no known server qualifies", func(t *testing.T) {
+			desc := Topology{Kind: Sharded, Servers: []Server{v5, unknown}}
+			_, err := MinWireVersionSelector(6).SelectServer(desc, desc.Servers)
+			if err != ErrNoServerSupportsFeature {
+				t.Errorf("Unexpected error. got %v; want %v", err, ErrNoServerSupportsFeature)
+			}
+		})
+		t.Run("waits for unknown servers", func(t *testing.T) {
+			desc := Topology{Kind: Sharded, Servers: []Server{unknown}}
+			result, err := MinWireVersionSelector(6).SelectServer(desc, desc.Servers)
+			noerr(t, err)
+			if len(result) != 0 {
+				t.Errorf("Expected no servers to be selected. got %v", result)
+			}
+		})
+	})
 	t.Run("LatencySelector", func(t *testing.T) {
 		testCases := []struct {
 			name  string
@@ -520,6 +558,66 @@ func TestSelector_Secondary_with_tags_that_do_not_match(t *testing.T) {
 	require.Len(result, 0)
 }
 
+func TestSelector_Secondary_with_strict_tags(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+	subject := readpref.Secondary(
+		readpref.WithTags("a", "2"),
+		readpref.WithStrictTags(),
+	)
+
+	result, err := ReadPrefSelector(subject).SelectServer(readPrefTestTopology, readPrefTestTopology.Servers)
+
+	require.NoError(err)
+	require.Equal([]Server{readPrefTestSecondary2}, result)
+}
+
+func TestSelector_Secondary_with_strict_tags_that_do_not_match(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+	subject := readpref.Secondary(
+		readpref.WithTags("a", "3"),
+		readpref.WithStrictTags(),
+	)
+
+	result, err := ReadPrefSelector(subject).SelectServer(readPrefTestTopology, readPrefTestTopology.Servers)
+
+	require.Equal(ErrNoStrictTagMatch, err)
+	require.Len(result, 0)
+}
+
+func TestSelector_SecondaryPreferred_with_strict_tags_that_do_not_match(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+	subject := readpref.SecondaryPreferred(
+		readpref.WithTags("a", "3"),
+		readpref.WithStrictTags(),
+	)
+
+	result, err := ReadPrefSelector(subject).SelectServer(readPrefTestTopology, readPrefTestTopology.Servers)
+
+	require.Equal(ErrNoStrictTagMatch, err)
+	require.Len(result, 0)
+}
+
+func TestSelector_Secondary_with_strict_tags_and_no_known_servers(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+	subject := readpref.Secondary(
+		readpref.WithTags("a", "3"),
+		readpref.WithStrictTags(),
+	)
+
+	result, err := ReadPrefSelector(subject).SelectServer(Topology{Kind: ReplicaSetNoPrimary}, []Server{})
+
+	require.NoError(err)
+	require.Len(result, 0)
+}
+
 func TestSelector_Secondary_with_no_secondaries(t *testing.T) {
 	t.Parallel()
 
@@ -711,3 +809,27 @@ func TestSelector_Max_staleness_is_too_low(t *testing.T) {
 
 	require.Error(err)
 }
+
+func TestSelector_Address(t *testing.T) {
+	t.Parallel()
+
+	t.Run("selects the requested server", func(t *testing.T) {
+		result, err := AddressSelector(readPrefTestSecondary1.Addr).SelectServer(readPrefTestTopology, readPrefTestTopology.Servers)
+		require.NoError(t, err)
+		require.Equal(t, []Server{readPrefTestSecondary1}, result)
+	})
+	t.Run("canonicalizes the address", func(t *testing.T) {
+		result, err := AddressSelector(address.Address("LOCALHOST")).SelectServer(readPrefTestTopology, readPrefTestTopology.Servers)
+		require.NoError(t, err)
+		require.Equal(t, []Server{readPrefTestPrimary}, result)
+	})
+	t.Run("errors for an unknown address", func(t *testing.T) {
+		_, err := AddressSelector(address.Address("unknown:27017")).SelectServer(readPrefTestTopology, readPrefTestTopology.Servers)
+		require.Error(t, err)
+	})
+	t.Run("no candidates when the server is not a candidate", func(t *testing.T) {
+		result, err := AddressSelector(readPrefTestSecondary1.Addr).SelectServer(readPrefTestTopology, []Server{readPrefTestPrimary})
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+}
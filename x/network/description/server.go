@@ -45,9 +45,12 @@ type Server struct {
 	Members               []address.Address
 	ReadOnly              bool
 	SessionTimeoutMinutes uint32
+	ServerConnectionID    int64 // connection id assigned by the server, or 0 if not reported
+	ServiceID             primitive.ObjectID
 	SetName               string
 	SetVersion            uint32
 	Tags                  tag.Set
+	TopologyVersion       *TopologyVersion
 	Kind                  ServerKind
 	WireVersion           *VersionRange
 
@@ -68,6 +71,8 @@ func NewServer(addr address.Address, isMaster result.IsMaster) Server {
 		MaxDocumentSize:       isMaster.MaxBSONObjectSize,
 		MaxMessageSize:        isMaster.MaxMessageSizeBytes,
 		SaslSupportedMechs:    isMaster.SaslSupportedMechs,
+		ServerConnectionID:    isMaster.ConnectionID,
+		ServiceID:             isMaster.ServiceID,
 		SessionTimeoutMinutes: isMaster.LogicalSessionTimeoutMinutes,
 		SetName:               isMaster.SetName,
 		SetVersion:            isMaster.SetVersion,
@@ -78,6 +83,14 @@ func NewServer(addr address.Address, isMaster result.IsMaster) Server {
 		i.CanonicalAddr = addr
 	}
 
+	if lw := isMaster.LastWrite; lw != nil {
+		i.LastWriteTime = lw.LastWriteDate
+	}
+
+	if tv := isMaster.TopologyVersion; tv != nil {
+		i.TopologyVersion = &TopologyVersion{ProcessID: tv.ProcessID, Counter: tv.Counter}
+	}
+
 	if isMaster.OK != 1 {
 		i.LastError = fmt.Errorf("not ok")
 		return i
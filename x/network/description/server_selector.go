@@ -7,14 +7,24 @@
 package description
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"time"
 
 	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
 	"github.com/lakshay2395/mongo-go-driver/tag"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
 )
 
+// ErrNoStrictTagMatch is returned when a read preference with strict tags is used and no known
+// server matches its tag sets.
+var ErrNoStrictTagMatch = errors.New("no server available matching the read preference tag sets")
+
+// ErrNoServerSupportsFeature is returned by a selector created with MinWireVersionSelector when the
+// topology has known servers but none of them support the required wire version.
+var ErrNoServerSupportsFeature = errors.New("no server supports this feature: all known servers are below the required wire version")
+
 // ServerSelector is an interface implemented by types that can select a server given a
 // topology description.
 type ServerSelector interface {
@@ -95,6 +105,32 @@ func (ls *latencySelector) SelectServer(t Topology, candidates []Server) ([]Serv
 	}
 }
 
+// AddressSelector selects the server with the given address. It returns an error if the address is
+// not part of the topology, and no servers if the server is part of the topology but is not a
+// candidate.
+func AddressSelector(addr address.Address) ServerSelector {
+	addr = addr.Canonicalize()
+	return ServerSelectorFunc(func(t Topology, candidates []Server) ([]Server, error) {
+		found := false
+		for _, s := range t.Servers {
+			if s.Addr.Canonicalize() == addr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("server %s is not part of the topology", addr)
+		}
+
+		for _, candidate := range candidates {
+			if candidate.Addr.Canonicalize() == addr {
+				return []Server{candidate}, nil
+			}
+		}
+		return []Server{}, nil
+	})
+}
+
 // WriteSelector selects all the writable servers.
 func WriteSelector() ServerSelector {
 	return ServerSelectorFunc(func(t Topology, candidates []Server) ([]Server, error) {
@@ -114,6 +150,30 @@ func WriteSelector() ServerSelector {
 	})
 }
 
+// MinWireVersionSelector selects the servers whose maximum wire version is at least min. It can be
+// composed with other selectors to restrict an operation to servers that support a feature, and
+// returns ErrNoServerSupportsFeature if none of the known candidates are new enough. Servers that
+// have not been checked yet do not cause an error, so selection keeps waiting for them.
+func MinWireVersionSelector(min int32) ServerSelector {
+	return ServerSelectorFunc(func(t Topology, candidates []Server) ([]Server, error) {
+		result := []Server{}
+		known := false
+		for _, candidate := range candidates {
+			if candidate.Kind == Unknown || candidate.WireVersion == nil {
+				continue
+			}
+			known = true
+			if candidate.WireVersion.Max >= min {
+				result = append(result, candidate)
+			}
+		}
+		if len(result) == 0 && known {
+			return nil, ErrNoServerSupportsFeature
+		}
+		return result, nil
+	})
+}
+
 // ReadPrefSelector selects servers based on the provided read preference.
 func ReadPrefSelector(rp *readpref.ReadPref) ServerSelector {
 	return ServerSelectorFunc(func(t Topology, candidates []Server) ([]Server, error) {
@@ -145,6 +205,10 @@ func selectForReplicaSet(rp *readpref.ReadPref, t Topology, candidates []Server)
 		return nil, err
 	}
 
+	if rp.StrictTags() {
+		return selectStrict(rp, candidates)
+	}
+
 	switch rp.Mode() {
 	case readpref.PrimaryMode:
 		return selectByKind(candidates, RSPrimary), nil
@@ -176,6 +240,33 @@ func selectForReplicaSet(rp *readpref.ReadPref, t Topology, candidates []Server)
 	return nil, fmt.Errorf("unsupported mode: %d", rp.Mode())
 }
 
+// selectStrict selects servers for a read preference with strict tags. Only servers eligible for
+// the mode that match the tag sets are selected, and ErrNoStrictTagMatch is returned if the
+// topology has known servers but none of them match.
+func selectStrict(rp *readpref.ReadPref, candidates []Server) ([]Server, error) {
+	var selected []Server
+	switch rp.Mode() {
+	case readpref.PrimaryPreferredMode:
+		selected = selectByKind(candidates, RSPrimary)
+		if len(selected) == 0 {
+			selected = selectByTagSet(selectSecondaries(rp, candidates), rp.TagSets())
+		}
+	case readpref.SecondaryPreferredMode, readpref.SecondaryMode:
+		selected = selectByTagSet(selectSecondaries(rp, candidates), rp.TagSets())
+	case readpref.NearestMode:
+		selected = selectByKind(candidates, RSPrimary)
+		selected = append(selected, selectSecondaries(rp, candidates)...)
+		selected = selectByTagSet(selected, rp.TagSets())
+	default:
+		return nil, fmt.Errorf("unsupported mode: %d", rp.Mode())
+	}
+
+	if len(selected) == 0 && len(candidates) > 0 {
+		return nil, ErrNoStrictTagMatch
+	}
+	return selected, nil
+}
+
 func selectSecondaries(rp *readpref.ReadPref, candidates []Server) []Server {
 	secondaries := selectByKind(candidates, RSSecondary)
 	if len(secondaries) == 0 {
@@ -265,15 +356,21 @@ func verifyMaxStaleness(rp *readpref.ReadPref, t Topology) error {
 	}
 
 	// we'll assume all candidates have the same heartbeat interval.
-	s := t.Servers[0]
-	idleWritePeriod := 10 * time.Second
+	return ValidateMaxStaleness(maxStaleness, t.Servers[0].HeartbeatInterval)
+}
 
-	if maxStaleness < s.HeartbeatInterval+idleWritePeriod {
+// IdleWritePeriod is the interval at which a primary writes a no-op to the oplog when idle. It is
+// used along with the heartbeat interval to compute the smallest allowed max staleness.
+const IdleWritePeriod = 10 * time.Second
+
+// ValidateMaxStaleness returns an error if maxStaleness is less than heartbeatInterval plus
+// IdleWritePeriod.
+func ValidateMaxStaleness(maxStaleness, heartbeatInterval time.Duration) error {
+	if maxStaleness < heartbeatInterval+IdleWritePeriod {
 		return fmt.Errorf(
 			"max staleness (%s) must be greater than or equal to the heartbeat interval (%s) plus idle write period (%s)",
-			maxStaleness, s.HeartbeatInterval, idleWritePeriod,
+			maxStaleness, heartbeatInterval, IdleWritePeriod,
 		)
 	}
-
 	return nil
 }
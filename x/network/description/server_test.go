@@ -0,0 +1,101 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package description
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/bson/primitive"
+	"github.com/lakshay2395/mongo-go-driver/mongo/readpref"
+	"github.com/lakshay2395/mongo-go-driver/x/network/address"
+	"github.com/lakshay2395/mongo-go-driver/x/network/result"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServerLastWrite(t *testing.T) {
+	// hello returns the parsed reply of a replica set member whose last write happened at lastWrite.
+	hello := func(t *testing.T, primary bool, lastWrite time.Time) result.IsMaster {
+		t.Helper()
+		doc, err := bson.Marshal(bson.D{
+			{"ismaster", primary},
+			{"secondary", !primary},
+			{"setName", "rs"},
+			{"hosts", bson.A{"a:27017", "b:27017", "c:27017"}},
+			{"minWireVersion", int32(0)},
+			{"maxWireVersion", int32(7)},
+			{"lastWrite", bson.D{
+				{"opTime", bson.D{{"ts", primitive.Timestamp{T: uint32(lastWrite.Unix()), I: 1}}, {"t", int64(3)}}},
+				{"lastWriteDate", primitive.NewDateTimeFromTime(lastWrite)},
+			}},
+			{"ok", int32(1)},
+		})
+		require.NoError(t, err)
+		var isMaster result.IsMaster
+		require.NoError(t, bson.Unmarshal(doc, &isMaster))
+		return isMaster
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	t.Run("parses lastWrite", func(t *testing.T) {
+		isMaster := hello(t, false, now)
+		require.NotNil(t, isMaster.LastWrite)
+		require.Equal(t, int64(3), isMaster.LastWrite.OpTime.Term)
+		require.Equal(t, uint32(now.Unix()), isMaster.LastWrite.OpTime.TS.T)
+
+		server := NewServer(address.Address("b:27017"), isMaster)
+		require.True(t, server.LastWriteTime.Equal(now), "got %v; want %v", server.LastWriteTime, now)
+	})
+	t.Run("parses protocol version 0 opTime", func(t *testing.T) {
+		doc, err := bson.Marshal(bson.D{
+			{"ismaster", false},
+			{"secondary", true},
+			{"setName", "rs"},
+			{"lastWrite", bson.D{
+				{"opTime", primitive.Timestamp{T: uint32(now.Unix()), I: 2}},
+				{"lastWriteDate", primitive.NewDateTimeFromTime(now)},
+			}},
+			{"ok", int32(1)},
+		})
+		require.NoError(t, err)
+		var isMaster result.IsMaster
+		require.NoError(t, bson.Unmarshal(doc, &isMaster))
+		require.NotNil(t, isMaster.LastWrite)
+		require.Equal(t, primitive.Timestamp{T: uint32(now.Unix()), I: 2}, isMaster.LastWrite.OpTime.TS)
+		require.Equal(t, int64(0), isMaster.LastWrite.OpTime.Term)
+
+		server := NewServer(address.Address("b:27017"), isMaster)
+		require.True(t, server.LastWriteTime.Equal(now), "got %v; want %v", server.LastWriteTime, now)
+	})
+	t.Run("rejects an invalid opTime", func(t *testing.T) {
+		doc, err := bson.Marshal(bson.D{{"lastWrite", bson.D{{"opTime", "bogus"}}}, {"ok", int32(1)}})
+		require.NoError(t, err)
+		var isMaster result.IsMaster
+		require.Error(t, bson.Unmarshal(doc, &isMaster))
+	})
+	t.Run("missing lastWrite", func(t *testing.T) {
+		server := NewServer(address.Address("a:27017"), result.IsMaster{OK: 1, IsMaster: true})
+		require.True(t, server.LastWriteTime.IsZero())
+	})
+	t.Run("excludes stale secondaries", func(t *testing.T) {
+		primary := NewServer(address.Address("a:27017"), hello(t, true, now))
+		fresh := NewServer(address.Address("b:27017"), hello(t, false, now.Add(-time.Second)))
+		stale := NewServer(address.Address("c:27017"), hello(t, false, now.Add(-5*time.Minute)))
+		for _, s := range []*Server{&primary, &fresh, &stale} {
+			s.HeartbeatInterval = 10 * time.Second
+		}
+		topo := Topology{Kind: ReplicaSetWithPrimary, Servers: []Server{primary, fresh, stale}}
+
+		rp := readpref.Secondary(readpref.WithMaxStaleness(90 * time.Second))
+		selected, err := ReadPrefSelector(rp).SelectServer(topo, topo.Servers)
+		require.NoError(t, err)
+		require.Len(t, selected, 1)
+		require.Equal(t, fresh.Addr, selected[0].Addr)
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package description
+
+import (
+	"fmt"
+
+	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
+	"github.com/lakshay2395/mongo-go-driver/bson/primitive"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+)
+
+// TopologyVersion represents a server's topology version. Servers on MongoDB 4.4+ include it in
+// isMaster replies and command errors so that stale information can be detected.
+type TopologyVersion struct {
+	ProcessID primitive.ObjectID
+	Counter   int64
+}
+
+// NewTopologyVersion creates a TopologyVersion from a topologyVersion document. It returns nil if
+// the document does not contain a valid processId and counter.
+func NewTopologyVersion(doc bsoncore.Document) *TopologyVersion {
+	pid, ok := doc.Lookup("processId").ObjectIDOK()
+	if !ok {
+		return nil
+	}
+	var counter int64
+	switch val := doc.Lookup("counter"); val.Type {
+	case bsontype.Int64:
+		counter = val.Int64()
+	case bsontype.Int32:
+		counter = int64(val.Int32())
+	default:
+		return nil
+	}
+	return &TopologyVersion{ProcessID: pid, Counter: counter}
+}
+
+// CompareToIncoming compares the receiver, which represents the currently known TopologyVersion,
+// to an incoming TopologyVersion. It returns a negative number if incoming is newer, zero if they
+// are equal, and a positive number if the receiver is newer. If either version is nil or the
+// process IDs differ, the incoming version is considered newer.
+func (tv *TopologyVersion) CompareToIncoming(incoming *TopologyVersion) int {
+	if tv == nil || incoming == nil || tv.ProcessID != incoming.ProcessID {
+		return -1
+	}
+	switch {
+	case tv.Counter < incoming.Counter:
+		return -1
+	case tv.Counter > incoming.Counter:
+		return 1
+	}
+	return 0
+}
+
+// String implements the fmt.Stringer interface.
+func (tv *TopologyVersion) String() string {
+	if tv == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("{processId: %s, counter: %d}", tv.ProcessID.Hex(), tv.Counter)
+}
@@ -0,0 +1,52 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package description
+
+import (
+	"testing"
+
+	"github.com/lakshay2395/mongo-go-driver/bson/primitive"
+	"github.com/lakshay2395/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopologyVersion(t *testing.T) {
+	pid := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+
+	t.Run("NewTopologyVersion", func(t *testing.T) {
+		doc := bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendObjectIDElement(nil, "processId", pid),
+			bsoncore.AppendInt64Element(nil, "counter", 7),
+		)
+		require.Equal(t, &TopologyVersion{ProcessID: pid, Counter: 7}, NewTopologyVersion(doc))
+
+		missing := bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt64Element(nil, "counter", 7))
+		require.Nil(t, NewTopologyVersion(missing))
+	})
+	t.Run("CompareToIncoming", func(t *testing.T) {
+		current := &TopologyVersion{ProcessID: pid, Counter: 5}
+		testCases := []struct {
+			name     string
+			current  *TopologyVersion
+			incoming *TopologyVersion
+			want     int
+		}{
+			{"newer counter", current, &TopologyVersion{ProcessID: pid, Counter: 6}, -1},
+			{"equal", current, &TopologyVersion{ProcessID: pid, Counter: 5}, 0},
+			{"older counter", current, &TopologyVersion{ProcessID: pid, Counter: 3}, 1},
+			{"different process", current, &TopologyVersion{ProcessID: other, Counter: 1}, -1},
+			{"nil current", nil, current, -1},
+			{"nil incoming", current, nil, -1},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				require.Equal(t, tc.want, tc.current.CompareToIncoming(tc.incoming))
+			})
+		}
+	})
+}
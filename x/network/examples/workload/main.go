@@ -143,6 +143,7 @@ func work(ctx context.Context, idx int, c *topology.Topology) {
 				id,
 				&session.Pool{},
 				bson.DefaultRegistry,
+				false,
 				aggOpts,
 			)
 			if err != nil {
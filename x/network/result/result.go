@@ -8,9 +8,11 @@
 package result // import "github.com/lakshay2395/mongo-go-driver/x/network/result"
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/lakshay2395/mongo-go-driver/bson"
+	"github.com/lakshay2395/mongo-go-driver/bson/bsontype"
 	"github.com/lakshay2395/mongo-go-driver/bson/primitive"
 	"github.com/lakshay2395/mongo-go-driver/x/bsonx"
 )
@@ -70,16 +72,17 @@ type FindAndModify struct {
 // WriteError is an error from a write operation that is not a write concern
 // error.
 type WriteError struct {
-	Index  int
-	Code   int
-	ErrMsg string
+	Index   int
+	Code    int
+	ErrMsg  string
+	ErrInfo bson.Raw `bson:"errInfo"`
 }
 
 // WriteConcernError is an error related to a write concern.
 type WriteConcernError struct {
 	Code    int
 	ErrMsg  string
-	ErrInfo bson.Raw
+	ErrInfo bson.Raw `bson:"errInfo"`
 }
 
 func (wce WriteConcernError) Error() string { return wce.ErrMsg }
@@ -94,18 +97,46 @@ type ListDatabases struct {
 	TotalSize int64 `bson:"totalSize"`
 }
 
+// CollStats is the result from a collStats command.
+type CollStats struct {
+	Ns             string
+	Count          int64
+	Size           int64
+	AvgObjSize     float64 `bson:"avgObjSize"`
+	StorageSize    int64   `bson:"storageSize"`
+	Capped         bool
+	Indexes        int64            `bson:"nindexes"`
+	TotalIndexSize int64            `bson:"totalIndexSize"`
+	IndexSizes     map[string]int64 `bson:"indexSizes"`
+}
+
+// DbStats is the result from a dbStats command.
+type DbStats struct {
+	DB          string `bson:"db"`
+	Collections int64
+	Views       int64
+	Objects     int64
+	AvgObjSize  float64 `bson:"avgObjSize"`
+	DataSize    int64   `bson:"dataSize"`
+	StorageSize int64   `bson:"storageSize"`
+	Indexes     int64
+	IndexSize   int64 `bson:"indexSize"`
+}
+
 // IsMaster is a result of an IsMaster command.
 type IsMaster struct {
 	Arbiters                     []string           `bson:"arbiters,omitempty"`
 	ArbiterOnly                  bool               `bson:"arbiterOnly,omitempty"`
 	ClusterTime                  bson.Raw           `bson:"$clusterTime,omitempty"`
 	Compression                  []string           `bson:"compression,omitempty"`
+	ConnectionID                 int64              `bson:"connectionId,omitempty"`
 	ElectionID                   primitive.ObjectID `bson:"electionId,omitempty"`
 	Hidden                       bool               `bson:"hidden,omitempty"`
 	Hosts                        []string           `bson:"hosts,omitempty"`
 	IsMaster                     bool               `bson:"ismaster,omitempty"`
 	IsReplicaSet                 bool               `bson:"isreplicaset,omitempty"`
-	LastWriteTimestamp           time.Time          `bson:"lastWriteDate,omitempty"`
+	LastWrite                    *LastWrite         `bson:"lastWrite,omitempty"`
+	LastWriteTimestamp           time.Time          `bson:"lastWriteDate,omitempty"` // Deprecated: use LastWrite.
 	LogicalSessionTimeoutMinutes uint32             `bson:"logicalSessionTimeoutMinutes,omitempty"`
 	MaxBSONObjectSize            uint32             `bson:"maxBsonObjectSize,omitempty"`
 	MaxMessageSizeBytes          uint32             `bson:"maxMessageSizeBytes,omitempty"`
@@ -119,9 +150,59 @@ type IsMaster struct {
 	ReadOnly                     bool               `bson:"readOnly,omitempty"`
 	SaslSupportedMechs           []string           `bson:"saslSupportedMechs,omitempty"`
 	Secondary                    bool               `bson:"secondary,omitempty"`
+	ServiceID                    primitive.ObjectID `bson:"serviceId,omitempty"`
 	SetName                      string             `bson:"setName,omitempty"`
 	SetVersion                   uint32             `bson:"setVersion,omitempty"`
+	SpeculativeAuthenticate      bson.Raw           `bson:"speculativeAuthenticate,omitempty"`
 	Tags                         map[string]string  `bson:"tags,omitempty"`
+	TopologyVersion              *TopologyVersion   `bson:"topologyVersion,omitempty"`
+}
+
+// LastWrite is the lastWrite document returned by replica set members. It describes the most recent
+// write applied by the member and is used to estimate the staleness of secondaries.
+type LastWrite struct {
+	OpTime        OpTime    `bson:"opTime"`
+	LastWriteDate time.Time `bson:"lastWriteDate"`
+}
+
+// OpTime identifies an entry in a replica set's oplog. Members using replication protocol version 0
+// report only a timestamp, in which case Term is zero.
+type OpTime struct {
+	TS   primitive.Timestamp `bson:"ts"`
+	Term int64               `bson:"t"`
+}
+
+// UnmarshalBSONValue implements the bson.ValueUnmarshaler interface. It accepts both the {ts, t}
+// document sent by protocol version 1 members and the plain timestamp sent by protocol version 0
+// members.
+func (ot *OpTime) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	rv := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bsontype.Timestamp:
+		ts, i, ok := rv.TimestampOK()
+		if !ok {
+			return fmt.Errorf("invalid opTime timestamp")
+		}
+		*ot = OpTime{TS: primitive.Timestamp{T: ts, I: i}}
+		return nil
+	case bsontype.EmbeddedDocument:
+		// opTime is decoded through an alias so that this method is not called recursively.
+		type opTime OpTime
+		var decoded opTime
+		if err := bson.Unmarshal(data, &decoded); err != nil {
+			return err
+		}
+		*ot = OpTime(decoded)
+		return nil
+	default:
+		return fmt.Errorf("cannot decode BSON %s into an OpTime", t)
+	}
+}
+
+// TopologyVersion is the topologyVersion document returned by servers on MongoDB 4.4+.
+type TopologyVersion struct {
+	ProcessID primitive.ObjectID `bson:"processId"`
+	Counter   int64              `bson:"counter"`
 }
 
 // BuildInfo is a result of a BuildInfo command.